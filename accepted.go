@@ -0,0 +1,41 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"context"
+	"net/http"
+)
+
+// AcceptedError may be returned by a CreateFunc or PatchFunc, to indicate that the
+// request was accepted for asynchronous processing, instead of being completed
+// synchronously. The create and patch middlewares respond to it with a 202 Accepted,
+// pointing to the provided status location.
+type AcceptedError struct {
+	StatusLocation string
+}
+
+// Accepted returns an AcceptedError for the given status location, which may be
+// returned by a CreateFunc or PatchFunc to signal asynchronous processing.
+func Accepted(statusLocation string) error {
+	return &AcceptedError{StatusLocation: statusLocation}
+}
+
+func (acceptedError *AcceptedError) Error() string {
+	return "request accepted for asynchronous processing"
+}
+
+// WriteAccepted responds with a 202 Accepted status. If a status location is given,
+// it is set as both the Location and Content-Location header, so clients can poll
+// the status of the asynchronous processing.
+func WriteAccepted(ctx context.Context, w http.ResponseWriter, statusLocation string) {
+	zerolog.Ctx(ctx).Debug().Msgf("Accepted request for asynchronous processing, status at %q", statusLocation)
+
+	if statusLocation != "" {
+		w.Header().Set("Location", statusLocation)
+		w.Header().Set("Content-Location", statusLocation)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusAccepted)
+}
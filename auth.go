@@ -7,6 +7,7 @@ import (
 	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/rs/zerolog"
 
+	"bytes"
 	"context"
 	"crypto"
 	"errors"
@@ -14,7 +15,9 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 )
 
 var (
@@ -22,8 +25,13 @@ var (
 	// could not be validated.
 	ErrTokenValidationFailed = errors.New("failed to validate token signature")
 
+	// ErrTokenExpired indicates that the token provided is expired,
+	// so the client should refresh it and retry.
+	ErrTokenExpired = errors.New("token expired")
+
 	// ErrMissingAuthHeader indicates that a requested was
-	// missing an authentication header.
+	// missing an authentication header (or a token in any other
+	// location, see TokenExtractor).
 	ErrMissingAuthHeader = errors.New("authentication header missing")
 
 	// ErrAuthHeaderWrongFormat indicates that a requested contained an a authorization
@@ -38,53 +46,195 @@ var (
 
 	// ErrFailedToSetAlgorithm indicates a problem setting the alg field of a JWK.
 	ErrFailedToSetAlgorithm = errors.New("failed to set 'alg' field")
+
+	// ErrClaimNotFound indicates that a claim could not be found
+	// at the requested path.
+	ErrClaimNotFound = errors.New("claim not found")
 )
 
+type keySetOptions struct {
+	maxDepth int
+}
+
+// KeySetOption represents an option for reading key sets via ReadKeySetFromFolder.
+type KeySetOption func(*keySetOptions)
+
+// KeySetMaxDepth sets the maximum depth of sub folders to descend into. Folders
+// beyond the limit are logged and skipped. A depth of zero restricts reading to
+// the given folder itself.
+// The default is -1, which means no limit.
+func KeySetMaxDepth(maxDepth int) KeySetOption {
+	return func(c *keySetOptions) {
+		c.maxDepth = maxDepth
+	}
+}
+
 // ReadKeySetFromFolder recursively reads a folder for public keys
 // to assemble a JWK set from.
-func ReadKeySetFromFolder(ctx context.Context, path string) (jwk.Set, error) {
+// Files with a .hmac or .secret extension are read as raw symmetric (HMAC)
+// secrets instead, with trailing line breaks removed.
+// Symbolic links are followed, whereas every folder and file is only
+// read once - so symbolic link loops are detected and skipped.
+func ReadKeySetFromFolder(ctx context.Context, path string, opts ...KeySetOption) (jwk.Set, error) {
+	// default
+	config := &keySetOptions{
+		maxDepth: -1,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
 	set := jwk.NewSet()
 
+	if err := readKeySetFromFolder(ctx, set, path, 0, config, map[string]struct{}{}); err != nil {
+		return nil, err
+	}
+
+	return set, nil
+}
+
+func readKeySetFromFolder(
+	ctx context.Context,
+	set jwk.Set,
+	path string,
+	depth int,
+	config *keySetOptions,
+	visited map[string]struct{},
+) error {
 	logger := zerolog.Ctx(ctx)
 
-	if err := filepath.Walk(path,
-		func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return err
+	}
+
+	if _, found := visited[realPath]; found {
+		logger.Warn().Msgf("Skipping %s, as it was already read (symbolic link loop?)", path)
+		return nil
+	}
+
+	visited[realPath] = struct{}{}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entryPath := filepath.Join(path, entry.Name())
+
+		// Stat instead of Lstat, to follow symbolic links
+		info, err := os.Stat(entryPath)
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to stat %s", entryPath)
+			continue
+		}
+
+		if info.IsDir() {
+			if config.maxDepth >= 0 && depth >= config.maxDepth {
+				logger.Warn().Msgf("Skipping %s, as it exceeds the maximum depth of %d", entryPath, config.maxDepth)
+				continue
 			}
 
-			if err := ctx.Err(); err != nil {
+			if err := readKeySetFromFolder(ctx, set, entryPath, depth+1, config, visited); err != nil {
 				return err
 			}
 
-			if !info.IsDir() {
-				logger.Debug().Msgf("Reading %s for public key", path)
-
-				parseResult, err := keybox.LoadPublicKey(path)
-				if err != nil {
-					logger.Error().Err(err).Msgf("Failed to load %s as public key", path)
-					return nil
-				}
-
-				kid := strings.TrimRight(info.Name(), filepath.Ext(info.Name()))
-				key, err := JWKFromPublicKey(parseResult, kid)
-				if err != nil {
-					logger.Error().Err(err).Msgf("Failed to parse %s as JWK", path)
-					return nil
-				}
-
-				if err := set.AddKey(key); err != nil {
-					logger.Error().Err(err).Msgf("Failed to add %s to key set", path)
-					return nil
-				}
-			}
+			continue
+		}
 
-			return nil
-		}); err != nil {
-		return nil, err
+		realEntryPath, err := filepath.EvalSymlinks(entryPath)
+		if err != nil {
+			logger.Error().Err(err).Msgf("Failed to resolve %s", entryPath)
+			continue
+		}
+
+		if _, found := visited[realEntryPath]; found {
+			logger.Debug().Msgf("Skipping %s, as it was already read", entryPath)
+			continue
+		}
+
+		visited[realEntryPath] = struct{}{}
+
+		if isSecretKeyFile(info.Name()) {
+			readSecretKeyIntoSet(ctx, set, entryPath, info.Name())
+		} else {
+			readPublicKeyIntoSet(ctx, set, entryPath, info.Name())
+		}
 	}
 
-	return set, nil
+	return nil
+}
+
+func readPublicKeyIntoSet(ctx context.Context, set jwk.Set, path string, name string) {
+	logger := zerolog.Ctx(ctx)
+
+	logger.Debug().Msgf("Reading %s for public key", path)
+
+	parseResult, err := keybox.LoadPublicKey(path)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to load %s as public key", path)
+		return
+	}
+
+	kid := strings.TrimRight(name, filepath.Ext(name))
+	key, err := JWKFromPublicKey(parseResult, kid)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to parse %s as JWK", path)
+		return
+	}
+
+	if err := set.AddKey(key); err != nil {
+		logger.Error().Err(err).Msgf("Failed to add %s to key set", path)
+		return
+	}
+}
+
+// secretKeyExtensions are the file extensions of files containing raw symmetric (HMAC) secrets.
+var secretKeyExtensions = []string{".hmac", ".secret"}
+
+func isSecretKeyFile(name string) bool {
+	return slices.Contains(secretKeyExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+func readSecretKeyIntoSet(ctx context.Context, set jwk.Set, path string, name string) {
+	logger := zerolog.Ctx(ctx)
+
+	logger.Debug().Msgf("Reading %s for secret key", path)
+
+	secret, err := os.ReadFile(path)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to load %s as secret key", path)
+		return
+	}
+
+	secret = bytes.TrimRight(secret, "\r\n")
+	if len(secret) == 0 {
+		logger.Error().Msgf("Failed to load %s as secret key, as it is empty", path)
+		return
+	}
+
+	kid := strings.TrimSuffix(name, filepath.Ext(name))
+	key, err := JWKFromPublicKey(secret, kid)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Failed to parse %s as JWK", path)
+		return
+	}
+
+	if err := set.AddKey(key); err != nil {
+		logger.Error().Err(err).Msgf("Failed to add %s to key set", path)
+		return
+	}
 }
 
 // JWKFromPrivateKey parses a given crypto.PrivateKey as a JWK, and tries
@@ -158,8 +308,43 @@ func JWKFromPublicKey(publicKey crypto.PublicKey, kid string) (jwk.Key, error) {
 	return key, nil
 }
 
+type tokenOptions struct {
+	audience       string
+	issuer         string
+	acceptableSkew time.Duration
+}
+
+// TokenOption represents an option for validating tokens via ValidateTokenBySetWithOptions.
+type TokenOption func(*tokenOptions)
+
+// TokenExpectedAudience sets the audience (aud claim) a token must be issued for.
+// The default is empty, which means the audience is not validated.
+func TokenExpectedAudience(audience string) TokenOption {
+	return func(c *tokenOptions) {
+		c.audience = audience
+	}
+}
+
+// TokenExpectedIssuer sets the issuer (iss claim) a token must be issued by.
+// The default is empty, which means the issuer is not validated.
+func TokenExpectedIssuer(issuer string) TokenOption {
+	return func(c *tokenOptions) {
+		c.issuer = issuer
+	}
+}
+
+// TokenAcceptableSkew sets the tolerated clock skew between the token issuer and this service,
+// when validating the time based claims (exp, nbf and iat) of a token.
+// The default is zero, which means the time based claims are validated strictly.
+func TokenAcceptableSkew(skew time.Duration) TokenOption {
+	return func(c *tokenOptions) {
+		c.acceptableSkew = skew
+	}
+}
+
 // ValidateTokenBySet validates the given token with the given key set. If a key matches,
-// the containing claims are returned.
+// the containing claims are returned. Claims keep their JSON types, so private claims
+// are returned as string, float64, bool, []interface{} or map[string]interface{}.
 func ValidateTokenBySet(
 	tokenString string, keySet jwk.Set,
 ) (map[string]interface{}, error) {
@@ -171,6 +356,111 @@ func ValidateTokenBySet(
 	return token.AsMap(context.Background())
 }
 
+// ValidateTokenBySetWithOptions validates the given token with the given key set, and
+// according to the provided options. If a key matches and the token satisfies all
+// expectations (e.g. audience and issuer), the containing claims are returned.
+// Otherwise, the returned error wraps ErrTokenValidationFailed - and additionally
+// ErrTokenExpired for expired tokens.
+func ValidateTokenBySetWithOptions(
+	tokenString string, keySet jwk.Set, opts ...TokenOption,
+) (map[string]interface{}, error) {
+	// default
+	config := &tokenOptions{
+		audience:       "",
+		issuer:         "",
+		acceptableSkew: 0,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	parseOptions := []jwt.ParseOption{jwt.WithKeySet(keySet)}
+
+	if config.audience != "" {
+		parseOptions = append(parseOptions, jwt.WithAudience(config.audience))
+	}
+
+	if config.issuer != "" {
+		parseOptions = append(parseOptions, jwt.WithIssuer(config.issuer))
+	}
+
+	if config.acceptableSkew > 0 {
+		parseOptions = append(parseOptions, jwt.WithAcceptableSkew(config.acceptableSkew))
+	}
+
+	token, err := jwt.ParseString(tokenString, parseOptions...)
+	if errors.Is(err, jwt.ErrTokenExpired()) {
+		return nil, fmt.Errorf("%w: %w: %w", ErrTokenValidationFailed, ErrTokenExpired, err)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenValidationFailed, err)
+	}
+
+	return token.AsMap(context.Background())
+}
+
+// ClaimByPath retrieves a claim from the given claims via the given path.
+// The path may either be a top-level (possibly namespaced) claim key, such as
+// "https://example.com/roles", or a dotted path for accessing nested object claims,
+// such as "https://example.com/app.roles". Longer keys take precedence over
+// nested access. The claim value is returned as-is, retaining its type.
+// If no claim exists at the given path, ErrClaimNotFound is returned.
+func ClaimByPath(claims map[string]interface{}, path string) (interface{}, error) {
+	if value, ok := claims[path]; ok {
+		return value, nil
+	}
+
+	for i := strings.LastIndex(path, "."); i > 0; i = strings.LastIndex(path[:i], ".") {
+		nested, ok := claims[path[:i]].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if value, err := ClaimByPath(nested, path[i+1:]); err == nil {
+			return value, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrClaimNotFound, path)
+}
+
+// TokenExtractor is a function for extracting the bearer token from a given request
+// (see AuthTokenMiddleware). If the request does not carry a token in the location of
+// the extractor, ErrMissingAuthHeader is returned.
+type TokenExtractor func(r *http.Request) (string, error)
+
+// FromCookie returns a TokenExtractor, which extracts the token from the cookie with the given
+// name. This allows authenticating requests, which cannot carry an Authorization header
+// (e.g. EventSource connections).
+func FromCookie(name string) TokenExtractor {
+	return func(r *http.Request) (string, error) {
+		cookie, err := r.Cookie(name)
+		if err != nil || cookie.Value == "" {
+			return "", ErrMissingAuthHeader
+		}
+
+		return cookie.Value, nil
+	}
+}
+
+// FromQuery returns a TokenExtractor, which extracts the token from the query parameter with
+// the given name. This allows authenticating requests, which cannot carry an Authorization header
+// (e.g. download links). Note that query parameters are prone to leak via logs and referrers, so
+// tokens passed this way should be short-lived.
+func FromQuery(name string) TokenExtractor {
+	return func(r *http.Request) (string, error) {
+		token := r.URL.Query().Get(name)
+		if token == "" {
+			return "", ErrMissingAuthHeader
+		}
+
+		return token, nil
+	}
+}
+
 // FromAuthHeader is a "TokenExtractor" that takes a give request and extracts
 // the JWT token from the Authorization header.
 //
@@ -20,6 +20,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 type AuthSuite struct {
@@ -288,6 +289,210 @@ func (s *AuthSuite) Test_ValidateTokenBySet() {
 	})
 }
 
+func (s *AuthSuite) Test_ValidateTokenBySetWithOptions() {
+	// given
+	hmacKey, err := turtleware.JWKFromPrivateKey([]byte("supersecretpassphrase"), "hmac-key")
+	s.Require().NoError(err)
+
+	keys := jwk.NewSet()
+	s.Require().NoError(keys.AddKey(hmacKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		hmacKey,
+		map[string]interface{}{jwt.AudienceKey: "api", jwt.IssuerKey: "https://idp"},
+		map[string]interface{}{jwk.KeyIDKey: hmacKey.KeyID()},
+	)
+
+	cases := map[string]struct {
+		opts  []turtleware.TokenOption
+		valid bool
+	}{
+		"No_Expectations":   {opts: nil, valid: true},
+		"Matching_Audience": {opts: []turtleware.TokenOption{turtleware.TokenExpectedAudience("api")}, valid: true},
+		"Matching_Issuer":   {opts: []turtleware.TokenOption{turtleware.TokenExpectedIssuer("https://idp")}, valid: true},
+		"Matching_Both": {opts: []turtleware.TokenOption{
+			turtleware.TokenExpectedAudience("api"),
+			turtleware.TokenExpectedIssuer("https://idp"),
+		}, valid: true},
+		"Wrong_Audience": {opts: []turtleware.TokenOption{turtleware.TokenExpectedAudience("other-api")}, valid: false},
+		"Wrong_Issuer":   {opts: []turtleware.TokenOption{turtleware.TokenExpectedIssuer("https://other-idp")}, valid: false},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			claims, err := turtleware.ValidateTokenBySetWithOptions(token, keys, target.opts...)
+
+			// then
+			if target.valid {
+				s.NoError(err)
+				s.Equal("https://idp", claims[jwt.IssuerKey])
+			} else {
+				s.ErrorIs(err, turtleware.ErrTokenValidationFailed)
+				s.Nil(claims)
+			}
+		})
+	}
+}
+
+func (s *AuthSuite) Test_ValidateTokenBySetWithOptions_Expired() {
+	// given
+	hmacKey, err := turtleware.JWKFromPrivateKey([]byte("supersecretpassphrase"), "hmac-key")
+	s.Require().NoError(err)
+
+	keys := jwk.NewSet()
+	s.Require().NoError(keys.AddKey(hmacKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		hmacKey,
+		map[string]interface{}{jwt.ExpirationKey: time.Now().Add(-time.Hour)},
+		map[string]interface{}{jwk.KeyIDKey: hmacKey.KeyID()},
+	)
+
+	// when
+	claims, err := turtleware.ValidateTokenBySetWithOptions(token, keys)
+
+	// then
+	s.ErrorIs(err, turtleware.ErrTokenExpired)
+	s.ErrorIs(err, turtleware.ErrTokenValidationFailed)
+	s.Nil(claims)
+}
+
+func (s *AuthSuite) Test_ValidateTokenBySetWithOptions_AcceptableSkew() {
+	// given
+	hmacKey, err := turtleware.JWKFromPrivateKey([]byte("supersecretpassphrase"), "hmac-key")
+	s.Require().NoError(err)
+
+	keys := jwk.NewSet()
+	s.Require().NoError(keys.AddKey(hmacKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		hmacKey,
+		map[string]interface{}{jwt.NotBeforeKey: time.Now().Add(3 * time.Second)},
+		map[string]interface{}{jwk.KeyIDKey: hmacKey.KeyID()},
+	)
+
+	s.Run("Within_Skew", func() {
+		// when
+		_, err := turtleware.ValidateTokenBySetWithOptions(token, keys, turtleware.TokenAcceptableSkew(time.Minute))
+
+		// then
+		s.NoError(err)
+	})
+
+	s.Run("No_Skew", func() {
+		// when
+		claims, err := turtleware.ValidateTokenBySetWithOptions(token, keys)
+
+		// then
+		s.ErrorIs(err, turtleware.ErrTokenValidationFailed)
+		s.Nil(claims)
+	})
+}
+
+func (s *AuthSuite) Test_ValidateTokenBySet_ClaimTypes() {
+	// given
+	hmacKey := []byte("supersecretpassphrase")
+
+	publicKey, err := turtleware.JWKFromPublicKey(hmacKey, "hmac-key")
+	s.Require().NoError(err)
+	s.Require().NoError(publicKey.Set(jwk.AlgorithmKey, jwa.HS256))
+
+	keys := jwk.NewSet()
+	s.Require().NoError(keys.AddKey(publicKey))
+
+	token := s.generateToken(jwa.HS256, hmacKey, map[string]interface{}{
+		"uuid":        "some-uuid",
+		"roles":       []string{"admin", "editor"},
+		"tenant_tier": 3,
+		"verified":    true,
+		"settings":    map[string]interface{}{"theme": "dark"},
+	}, map[string]interface{}{jwk.KeyIDKey: "hmac-key"})
+
+	validators := map[string]func() (map[string]interface{}, error){
+		"ValidateTokenBySet": func() (map[string]interface{}, error) {
+			return turtleware.ValidateTokenBySet(token, keys)
+		},
+		"ValidateTokenBySetWithOptions": func() (map[string]interface{}, error) {
+			return turtleware.ValidateTokenBySetWithOptions(token, keys)
+		},
+	}
+
+	for testName, validate := range validators {
+		s.Run(testName, func() {
+			// when
+			claims, err := validate()
+
+			// then
+			s.Require().NoError(err)
+			s.Equal("some-uuid", claims["uuid"])
+			s.Equal([]interface{}{"admin", "editor"}, claims["roles"])
+			s.Equal(float64(3), claims["tenant_tier"])
+			s.Equal(true, claims["verified"])
+			s.Equal(map[string]interface{}{"theme": "dark"}, claims["settings"])
+		})
+	}
+}
+
+func (s *AuthSuite) Test_ClaimByPath() {
+	// given
+	hmacKey := []byte("supersecretpassphrase")
+
+	publicKey, err := turtleware.JWKFromPublicKey(hmacKey, "hmac-key")
+	s.Require().NoError(err)
+	s.Require().NoError(publicKey.Set(jwk.AlgorithmKey, jwa.HS256))
+
+	keys := jwk.NewSet()
+	s.Require().NoError(keys.AddKey(publicKey))
+
+	token := s.generateToken(jwa.HS256, hmacKey, map[string]interface{}{
+		"https://example.com/roles": []string{"admin", "editor"},
+		"https://example.com/app": map[string]interface{}{
+			"tier": "gold",
+			"limits": map[string]interface{}{
+				"uploads": 10,
+			},
+		},
+	}, map[string]interface{}{jwk.KeyIDKey: "hmac-key"})
+
+	claims, err := turtleware.ValidateTokenBySet(token, keys)
+	s.Require().NoError(err)
+
+	s.Run("Namespaced", func() {
+		// when
+		roles, err := turtleware.ClaimByPath(claims, "https://example.com/roles")
+
+		// then
+		s.NoError(err)
+		s.Equal([]interface{}{"admin", "editor"}, roles)
+	})
+
+	s.Run("Nested", func() {
+		// when
+		tier, err := turtleware.ClaimByPath(claims, "https://example.com/app.tier")
+		s.NoError(err)
+
+		uploads, err := turtleware.ClaimByPath(claims, "https://example.com/app.limits.uploads")
+		s.NoError(err)
+
+		// then
+		s.Equal("gold", tier)
+		s.Equal(float64(10), uploads)
+	})
+
+	s.Run("Missing", func() {
+		// when
+		value, err := turtleware.ClaimByPath(claims, "https://example.com/app.missing")
+
+		// then
+		s.ErrorIs(err, turtleware.ErrClaimNotFound)
+		s.Nil(value)
+	})
+}
+
 func (s *AuthSuite) Test_JWKFromPrivateKey() {
 	// given
 	kid := "some-key-id"
@@ -490,6 +695,99 @@ func (s *AuthSuite) Test_ReadKeySetFromFolder() {
 	})
 }
 
+func (s *AuthSuite) Test_ReadKeySetFromFolder_MaxDepth() {
+	// given
+	keyFolder := s.T().TempDir()
+	nestedFolder := filepath.Join(keyFolder, "first", "second")
+	s.Require().NoError(os.MkdirAll(nestedFolder, 0755))
+
+	for kid, folder := range map[string]string{
+		"root-key":   keyFolder,
+		"first-key":  filepath.Join(keyFolder, "first"),
+		"second-key": nestedFolder,
+	} {
+		ed25519PubKey, _, err := ed25519.GenerateKey(rand.Reader)
+		s.Require().NoError(err)
+		s.Require().NoError(createValidPublicKey(folder, kid+".pub", ed25519PubKey))
+	}
+
+	cases := map[string]struct {
+		opts     []turtleware.KeySetOption
+		expected []string
+	}{
+		"Unlimited": {opts: nil, expected: []string{"root-key", "first-key", "second-key"}},
+		"Depth_0":   {opts: []turtleware.KeySetOption{turtleware.KeySetMaxDepth(0)}, expected: []string{"root-key"}},
+		"Depth_1":   {opts: []turtleware.KeySetOption{turtleware.KeySetMaxDepth(1)}, expected: []string{"root-key", "first-key"}},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			keySet, err := turtleware.ReadKeySetFromFolder(context.Background(), keyFolder, target.opts...)
+
+			// then
+			s.Require().NoError(err)
+			s.Equal(len(target.expected), keySet.Len())
+
+			for _, kid := range target.expected {
+				s.True(containsKey(keySet, kid), "%s not loaded", kid)
+			}
+		})
+	}
+}
+
+func (s *AuthSuite) Test_ReadKeySetFromFolder_SymlinkLoop() {
+	// given
+	keyFolder := s.T().TempDir()
+	nestedFolder := filepath.Join(keyFolder, "nested")
+	s.Require().NoError(os.MkdirAll(nestedFolder, 0755))
+
+	ed25519PubKey, _, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+	s.Require().NoError(createValidPublicKey(nestedFolder, "nested-key.pub", ed25519PubKey))
+
+	if err := os.Symlink(keyFolder, filepath.Join(nestedFolder, "loop")); err != nil {
+		s.T().Skipf("symbolic links not supported: %s", err)
+	}
+
+	// when
+	keySet, err := turtleware.ReadKeySetFromFolder(context.Background(), keyFolder)
+
+	// then
+	s.NoError(err)
+	s.Equal(1, keySet.Len())
+	s.True(containsKey(keySet, "nested-key"))
+}
+
+func (s *AuthSuite) Test_ReadKeySetFromFolder_SecretKeys() {
+	// given
+	keyFolder := s.T().TempDir()
+
+	s.Require().NoError(os.WriteFile(filepath.Join(keyFolder, "hmac-key.hmac"), []byte("supersecretpassphrase\n"), 0600))
+	s.Require().NoError(os.WriteFile(filepath.Join(keyFolder, "other-key.SECRET"), []byte("othersecretpassphrase"), 0600))
+	s.Require().NoError(os.WriteFile(filepath.Join(keyFolder, "empty-key.secret"), []byte("\n"), 0600))
+
+	// when
+	keySet, err := turtleware.ReadKeySetFromFolder(context.Background(), keyFolder)
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(2, keySet.Len())
+	s.True(containsKey(keySet, "hmac-key"), "hmac key not loaded")
+	s.True(containsKey(keySet, "other-key"), "other key not loaded")
+
+	token := s.generateToken(
+		jwa.HS512,
+		[]byte("supersecretpassphrase"),
+		map[string]interface{}{"uuid": s.userUUID},
+		map[string]interface{}{jwk.KeyIDKey: "hmac-key"},
+	)
+
+	claims, err := turtleware.ValidateTokenBySet(token, keySet)
+	s.NoError(err)
+	s.Equal(s.userUUID, claims["uuid"])
+}
+
 func containsKey(keySet jwk.Set, keyID string) bool {
 	for i := 0; i < keySet.Len(); i++ {
 		key, _ := keySet.Key(i)
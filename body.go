@@ -0,0 +1,272 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+type bodyOptions struct {
+	trimStrings           bool
+	stripControlChars     bool
+	logBody               bool
+	disallowUnknownFields bool
+	maxBytes              int64
+}
+
+// BodyOption represents an option for decoding request bodies of create and
+// patch requests.
+type BodyOption func(*bodyOptions)
+
+// BodyTrimStrings sets whether leading and trailing whitespace should be trimmed
+// from all string fields of the decoded DTO, before it is validated.
+// The default is false.
+func BodyTrimStrings(trimStrings bool) BodyOption {
+	return func(c *bodyOptions) {
+		c.trimStrings = trimStrings
+	}
+}
+
+// BodyStripControlChars sets whether control characters (except for tabs and
+// line breaks) should be stripped from all string fields of the decoded DTO,
+// before it is validated.
+// The default is false.
+func BodyStripControlChars(stripControlChars bool) BodyOption {
+	return func(c *bodyOptions) {
+		c.stripControlChars = stripControlChars
+	}
+}
+
+// BodyLog sets whether the decoded DTO should be logged on debug level. Fields tagged
+// with `turtleware:"redact"` (e.g. passwords or tokens) are masked via Redact.
+// The default is false.
+func BodyLog(logBody bool) BodyOption {
+	return func(c *bodyOptions) {
+		c.logBody = logBody
+	}
+}
+
+// BodyDisallowUnknownFields sets whether the body is rejected with ErrMarshalling, if it
+// contains fields which do not match any exported field of the DTO.
+// The default is false.
+func BodyDisallowUnknownFields(disallowUnknownFields bool) BodyOption {
+	return func(c *bodyOptions) {
+		c.disallowUnknownFields = disallowUnknownFields
+	}
+}
+
+// BodyMaxBytes sets the maximum size of the body in bytes, enforced via http.MaxBytesReader
+// before decoding. Larger bodies are rejected with ErrMarshalling.
+// A limit of zero disables the check.
+// The default is zero.
+func BodyMaxBytes(maxBytes int64) BodyOption {
+	return func(c *bodyOptions) {
+		c.maxBytes = maxBytes
+	}
+}
+
+// DecodeBody decodes the JSON body of the given request into a new T, and applies
+// the provided options to it. If the body cannot be decoded, ErrMarshalling is returned.
+func DecodeBody[T any](r *http.Request, opts ...BodyOption) (T, error) {
+	config := newBodyOptions(opts...)
+
+	var body T
+	if err := newBodyDecoder(r, config).Decode(&body); err != nil {
+		return body, bodyError(err)
+	}
+
+	finishBody(r, &body, config)
+
+	return body, nil
+}
+
+// MergePatchFields contains the top level fields present in a JSON Merge Patch (RFC 7386)
+// document, mapped to whether the field was explicitly set to null (meaning its removal).
+type MergePatchFields map[string]bool
+
+// Has reports whether the given field was present in the merge patch document, either
+// with a value or explicitly set to null.
+func (f MergePatchFields) Has(field string) bool {
+	_, found := f[field]
+	return found
+}
+
+// IsNull reports whether the given field was explicitly set to null in the merge patch document.
+func (f MergePatchFields) IsNull(field string) bool {
+	return f[field]
+}
+
+// HasNulls reports whether any field was explicitly set to null in the merge patch document.
+func (f MergePatchFields) HasNulls() bool {
+	for _, null := range f {
+		if null {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DecodeMergePatchBody decodes the JSON Merge Patch (RFC 7386) body of the given request into
+// a new T, and applies the provided options to it. Additionally, the top level fields present
+// in the body are returned, which allows distinguishing absent fields from fields explicitly
+// set to null or their zero value. As a merge patch for a resource must be a JSON object,
+// ErrMarshalling is returned if the body is no object, or cannot be decoded.
+func DecodeMergePatchBody[T any](r *http.Request, opts ...BodyOption) (T, MergePatchFields, error) {
+	config := newBodyOptions(opts...)
+
+	var body T
+
+	rawBody, err := io.ReadAll(limitBody(r, config))
+	if err != nil {
+		return body, nil, bodyError(err)
+	}
+
+	var rawFields map[string]json.RawMessage
+	if err := json.Unmarshal(rawBody, &rawFields); err != nil || rawFields == nil {
+		return body, nil, ErrMarshalling
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(rawBody))
+	if config.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(&body); err != nil {
+		return body, nil, bodyError(err)
+	}
+
+	fields := make(MergePatchFields, len(rawFields))
+	for field, value := range rawFields {
+		fields[field] = bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+	}
+
+	finishBody(r, &body, config)
+
+	return body, fields, nil
+}
+
+func newBodyOptions(opts ...BodyOption) *bodyOptions {
+	// default
+	config := &bodyOptions{
+		trimStrings:           false,
+		stripControlChars:     false,
+		logBody:               false,
+		disallowUnknownFields: false,
+		maxBytes:              0,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+func limitBody(r *http.Request, config *bodyOptions) io.Reader {
+	if config.maxBytes > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, config.maxBytes)
+	}
+
+	return r.Body
+}
+
+func newBodyDecoder(r *http.Request, config *bodyOptions) *json.Decoder {
+	decoder := json.NewDecoder(limitBody(r, config))
+	if config.disallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
+
+	return decoder
+}
+
+// bodyError converts errors of decoding a body into ErrMarshalling. Errors of the opt-in
+// checks are kept as detail, as they are actionable for the client.
+func bodyError(err error) error {
+	if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+		return fmt.Errorf("%w: body exceeds limit of %d bytes", ErrMarshalling, maxBytesErr.Limit)
+	}
+
+	if strings.HasPrefix(err.Error(), "json: unknown field ") {
+		return fmt.Errorf("%w: %s", ErrMarshalling, strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	return ErrMarshalling
+}
+
+func finishBody[T any](r *http.Request, body *T, config *bodyOptions) {
+	if config.trimStrings || config.stripControlChars {
+		sanitizeStrings(reflect.ValueOf(body), config)
+	}
+
+	if debugLevel := zerolog.Ctx(r.Context()).Debug(); config.logBody && debugLevel.Enabled() {
+		debugLevel.Interface("body", Redact(*body)).Msg("Decoded request body")
+	}
+}
+
+func sanitizeStrings(v reflect.Value, config *bodyOptions) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			elem := v.Elem()
+
+			// Values contained in an interface are not addressable,
+			// so only strings behind pointers are modified here.
+			if v.Kind() == reflect.Pointer || elem.Kind() == reflect.Pointer {
+				sanitizeStrings(elem, config)
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				sanitizeStrings(v.Field(i), config)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			sanitizeStrings(v.Index(i), config)
+		}
+	case reflect.Map:
+		if v.Type().Elem().Kind() != reflect.String {
+			return
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(sanitizeString(iter.Value().String(), config)).Convert(v.Type().Elem()))
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(sanitizeString(v.String(), config))
+		}
+	default:
+		// Nothing to sanitize
+	}
+}
+
+func sanitizeString(value string, config *bodyOptions) string {
+	if config.stripControlChars {
+		value = strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+				return -1
+			}
+
+			return r
+		}, value)
+	}
+
+	if config.trimStrings {
+		value = strings.TrimSpace(value)
+	}
+
+	return value
+}
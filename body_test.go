@@ -0,0 +1,219 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type BodySuite struct {
+	CommonSuite
+}
+
+func TestBodySuite(t *testing.T) {
+	suite.Run(t, &BodySuite{})
+}
+
+type testSanitizeNested struct {
+	Value string
+}
+
+type testSanitizeModel struct {
+	Plain   string
+	Pointer *string
+	Nested  testSanitizeNested
+	List    []string
+	Mapping map[string]string
+	Number  int
+}
+
+func (s *BodySuite) Test_DecodeBody() {
+	body := `{
+		"Plain": " plain\u0000 ",
+		"Pointer": " pointer ",
+		"Nested": {"Value": "\tnested\t"},
+		"List": [" a ", "b\u001b"],
+		"Mapping": {"key": " value "},
+		"Number": 42
+	}`
+
+	s.Run("Sanitized", func() {
+		// given
+		r := httptest.NewRequest(http.MethodPost, "https://example.com", bytes.NewBufferString(body))
+
+		// when
+		model, err := turtleware.DecodeBody[testSanitizeModel](
+			r,
+			turtleware.BodyTrimStrings(true),
+			turtleware.BodyStripControlChars(true),
+		)
+
+		// then
+		s.NoError(err)
+		s.Equal("plain", model.Plain)
+		s.Require().NotNil(model.Pointer)
+		s.Equal("pointer", *model.Pointer)
+		s.Equal("nested", model.Nested.Value)
+		s.Equal([]string{"a", "b"}, model.List)
+		s.Equal(map[string]string{"key": "value"}, model.Mapping)
+		s.Equal(42, model.Number)
+	})
+
+	s.Run("Only_Strip_Control_Chars", func() {
+		// given
+		r := httptest.NewRequest(http.MethodPost, "https://example.com", bytes.NewBufferString(body))
+
+		// when
+		model, err := turtleware.DecodeBody[testSanitizeModel](r, turtleware.BodyStripControlChars(true))
+
+		// then
+		s.NoError(err)
+		s.Equal(" plain ", model.Plain)
+		s.Equal("\tnested\t", model.Nested.Value)
+	})
+
+	s.Run("Trash", func() {
+		// given
+		r := httptest.NewRequest(http.MethodPost, "https://example.com", bytes.NewBufferString("trash"))
+
+		// when
+		_, err := turtleware.DecodeBody[testSanitizeModel](r)
+
+		// then
+		s.ErrorIs(err, turtleware.ErrMarshalling)
+	})
+}
+
+func (s *BodySuite) Test_DecodeMergePatchBody() {
+	s.Run("Sanitized", func() {
+		// given
+		r := httptest.NewRequest(http.MethodPatch, "https://example.com", bytes.NewBufferString(`{"Plain": " plain ", "Pointer": null}`))
+
+		// when
+		model, fields, err := turtleware.DecodeMergePatchBody[testSanitizeModel](r, turtleware.BodyTrimStrings(true))
+
+		// then
+		s.NoError(err)
+		s.Equal("plain", model.Plain)
+		s.Nil(model.Pointer)
+		s.Equal(turtleware.MergePatchFields{"Plain": false, "Pointer": true}, fields)
+	})
+
+	s.Run("Trash", func() {
+		// given
+		r := httptest.NewRequest(http.MethodPatch, "https://example.com", bytes.NewBufferString(`{"Number": "trash"}`))
+
+		// when
+		_, _, err := turtleware.DecodeMergePatchBody[testSanitizeModel](r)
+
+		// then
+		s.ErrorIs(err, turtleware.ErrMarshalling)
+	})
+}
+
+func (s *BodySuite) Test_DecodeBody_Strict() {
+	body := `{"Plain": "plain", "Unknown": true}`
+
+	cases := map[string]struct {
+		opts        []turtleware.BodyOption
+		expectedErr error
+	}{
+		"Lenient": {},
+		"Unknown_Fields": {
+			opts:        []turtleware.BodyOption{turtleware.BodyDisallowUnknownFields(true)},
+			expectedErr: turtleware.ErrMarshalling,
+		},
+		"Max_Bytes_Exceeded": {
+			opts:        []turtleware.BodyOption{turtleware.BodyMaxBytes(int64(len(body) - 1))},
+			expectedErr: turtleware.ErrMarshalling,
+		},
+		"Max_Bytes_Within": {
+			opts: []turtleware.BodyOption{turtleware.BodyMaxBytes(int64(len(body)))},
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run("DecodeBody_"+testName, func() {
+			// given
+			r := httptest.NewRequest(http.MethodPost, "https://example.com", bytes.NewBufferString(body))
+
+			// when
+			model, err := turtleware.DecodeBody[testSanitizeModel](r, target.opts...)
+
+			// then
+			if target.expectedErr != nil {
+				s.ErrorIs(err, target.expectedErr)
+			} else {
+				s.NoError(err)
+				s.Equal("plain", model.Plain)
+			}
+		})
+
+		s.Run("DecodeMergePatchBody_"+testName, func() {
+			// given
+			r := httptest.NewRequest(http.MethodPatch, "https://example.com", bytes.NewBufferString(body))
+
+			// when
+			model, _, err := turtleware.DecodeMergePatchBody[testSanitizeModel](r, target.opts...)
+
+			// then
+			if target.expectedErr != nil {
+				s.ErrorIs(err, target.expectedErr)
+			} else {
+				s.NoError(err)
+				s.Equal("plain", model.Plain)
+			}
+		})
+	}
+}
+
+type testLoggedModel struct {
+	Username string `json:"username"`
+	Password string `json:"password" turtleware:"redact"`
+}
+
+func (s *BodySuite) Test_DecodeBody_Log() {
+	s.Run("Redacted", func() {
+		// given
+		logBuffer := &bytes.Buffer{}
+		logger := zerolog.New(logBuffer).Level(zerolog.DebugLevel)
+
+		r := httptest.NewRequest(http.MethodPost, "https://example.com", bytes.NewBufferString(
+			`{"username": "some-user", "password": "some-password"}`,
+		))
+		r = r.WithContext(logger.WithContext(r.Context()))
+
+		// when
+		model, err := turtleware.DecodeBody[testLoggedModel](r, turtleware.BodyLog(true))
+
+		// then
+		s.NoError(err)
+		s.Equal("some-password", model.Password)
+		s.Contains(logBuffer.String(), `"username":"some-user"`)
+		s.Contains(logBuffer.String(), `"password":"***"`)
+		s.NotContains(logBuffer.String(), "some-password")
+	})
+
+	s.Run("Disabled", func() {
+		// given
+		logBuffer := &bytes.Buffer{}
+		logger := zerolog.New(logBuffer).Level(zerolog.DebugLevel)
+
+		r := httptest.NewRequest(http.MethodPost, "https://example.com", bytes.NewBufferString(
+			`{"username": "some-user", "password": "some-password"}`,
+		))
+		r = r.WithContext(logger.WithContext(r.Context()))
+
+		// when
+		_, err := turtleware.DecodeBody[testLoggedModel](r)
+
+		// then
+		s.NoError(err)
+		s.Empty(logBuffer.String())
+	})
+}
@@ -0,0 +1,110 @@
+package turtleware
+
+import (
+	jsoniter "github.com/json-iterator/go"
+
+	"bytes"
+	"net/http"
+	"sync"
+)
+
+// BufferPool is a pool of reusable buffers, used for serializing response bodies.
+type BufferPool interface {
+	// Get returns an empty buffer from the pool.
+	Get() *bytes.Buffer
+
+	// Put returns the given buffer to the pool. The buffer must not be used afterward.
+	Put(buffer *bytes.Buffer)
+}
+
+type syncBufferPool struct {
+	pool        sync.Pool
+	maxCapacity int
+}
+
+// NewBufferPool returns a BufferPool backed by a sync.Pool. Buffers which grew beyond the
+// given maxCapacity are not returned to the pool, so a single large response does not
+// retain large amounts of memory. A maxCapacity of zero disables this limit.
+func NewBufferPool(maxCapacity int) BufferPool {
+	return &syncBufferPool{
+		pool: sync.Pool{
+			New: func() interface{} {
+				return &bytes.Buffer{}
+			},
+		},
+		maxCapacity: maxCapacity,
+	}
+}
+
+func (bufferPool *syncBufferPool) Get() *bytes.Buffer {
+	buffer := bufferPool.pool.Get().(*bytes.Buffer)
+	buffer.Reset()
+
+	return buffer
+}
+
+func (bufferPool *syncBufferPool) Put(buffer *bytes.Buffer) {
+	if bufferPool.maxCapacity > 0 && buffer.Cap() > bufferPool.maxCapacity {
+		return
+	}
+
+	buffer.Reset()
+	bufferPool.pool.Put(buffer)
+}
+
+// ResponseBufferPool is the globally used pool of buffers for serializing response
+// bodies via the EmissioneWriter. It may be replaced, before any requests are served.
+var ResponseBufferPool = NewBufferPool(1 << 20)
+
+// indented equivalent of jsoniter.ConfigDefault
+var jsonAPI = jsoniter.Config{
+	EscapeHTML:    true,
+	IndentionStep: 2,
+}.Froze()
+
+// pooledJSONWriter is an emissione.Writer, which serializes JSON into
+// buffers of the ResponseBufferPool.
+type pooledJSONWriter struct {
+	api jsoniter.API
+}
+
+func (writer pooledJSONWriter) Write(w http.ResponseWriter, i interface{}) error {
+	setJSONContentType(w)
+
+	buffer := ResponseBufferPool.Get()
+	defer ResponseBufferPool.Put(buffer)
+
+	if err := writer.serialize(buffer, i); err != nil {
+		return err
+	}
+
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+// serialize serializes the given value as JSON into the given buffer.
+func (writer pooledJSONWriter) serialize(buffer *bytes.Buffer, i interface{}) error {
+	stream := writer.api.BorrowStream(buffer)
+	defer writer.api.ReturnStream(stream)
+
+	stream.WriteVal(i)
+	if stream.Error != nil {
+		return stream.Error
+	}
+
+	return stream.Flush()
+}
+
+func setJSONContentType(w http.ResponseWriter) {
+	if len(w.Header().Get("Content-Type")) == 0 {
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	}
+}
+
+// streamBufferPool is a pool of buffers for copying streamed responses.
+var streamBufferPool = sync.Pool{
+	New: func() interface{} {
+		buffer := make([]byte, 32*1024)
+		return &buffer
+	},
+}
@@ -0,0 +1,178 @@
+package turtleware_test
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/kernle32dll/emissione-go"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type BufferPoolSuite struct {
+	CommonSuite
+}
+
+func TestBufferPoolSuite(t *testing.T) {
+	suite.Run(t, &BufferPoolSuite{})
+}
+
+func (s *BufferPoolSuite) Test_BufferPool_Reset() {
+	// given
+	pool := turtleware.NewBufferPool(0)
+
+	buffer := pool.Get()
+	buffer.WriteString("some leftover")
+
+	// when
+	pool.Put(buffer)
+	reused := pool.Get()
+
+	// then
+	s.Zero(reused.Len())
+}
+
+func (s *BufferPoolSuite) Test_EmissioneWriter_Reuse() {
+	// given
+	large := make([]string, 1000)
+	for i := range large {
+		large[i] = strings.Repeat("x", 32)
+	}
+
+	small := map[string]string{"key": "a&b"}
+
+	for _, payload := range []interface{}{large, small, large, small} {
+		s.Run("Payload", func() {
+			// given
+			request := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+			response := httptest.NewRecorder()
+
+			expected, err := jsoniter.MarshalIndent(payload, "", "  ")
+			s.Require().NoError(err)
+
+			// when
+			turtleware.EmissioneWriter.Write(response, request, http.StatusOK, payload)
+
+			// then
+			s.Equal(http.StatusOK, response.Code)
+			s.Equal("application/json;charset=utf-8", response.Header().Get("Content-Type"))
+			s.Equal(string(expected), response.Body.String())
+		})
+	}
+}
+
+func (s *BufferPoolSuite) Test_StreamResponse_Reuse() {
+	for _, payload := range []string{strings.Repeat("\x00binary", 100), "test"} {
+		s.Run("Payload", func() {
+			// given
+			request := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+			response := httptest.NewRecorder()
+			errorCapture := &ErrorHandlerCapture{}
+
+			// when
+			turtleware.StreamResponse(bytes.NewBufferString(payload), response, request, errorCapture.Capture)
+
+			// then
+			s.NoError(errorCapture.CapturedError)
+			s.Equal(payload, response.Body.String())
+			s.Equal(http.DetectContentType(append([]byte(payload), make([]byte, 512)...)[:512]), response.Header().Get("Content-Type"))
+		})
+	}
+}
+
+func (s *BufferPoolSuite) Test_EmissioneWriter_Allocations() {
+	// given
+	payload := benchmarkPayload()
+	request := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+	baseline := baselineEmissioneWriter()
+
+	// warm up the pool
+	turtleware.EmissioneWriter.Write(httptest.NewRecorder(), request, http.StatusOK, payload)
+
+	// when
+	allocations := testing.AllocsPerRun(10, func() {
+		turtleware.EmissioneWriter.Write(discardResponseWriter{header: http.Header{}}, request, http.StatusOK, payload)
+	})
+	baselineAllocations := testing.AllocsPerRun(10, func() {
+		baseline.Write(discardResponseWriter{header: http.Header{}}, request, http.StatusOK, payload)
+	})
+
+	// then
+	s.Less(allocations, baselineAllocations)
+}
+
+// discardResponseWriter is a http.ResponseWriter, which discards the body, so
+// allocations of the writer under test are measured in isolation.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w discardResponseWriter) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (w discardResponseWriter) WriteHeader(int) {}
+
+// baselineEmissioneWriter returns a writer equivalent to the EmissioneWriter without
+// buffer pooling, for comparing allocations.
+func baselineEmissioneWriter() *emissione.Handler {
+	jsonWriter := emissione.NewJSONWriter(emissione.MarshallMethod(func(v interface{}) ([]byte, error) {
+		return jsoniter.MarshalIndent(v, "", "  ")
+	}))
+
+	return emissione.New(jsonWriter, emissione.WriterMapping{
+		"application/json": jsonWriter,
+	})
+}
+
+type benchmarkEntity struct {
+	UUID  string `json:"uuid"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func benchmarkPayload() []benchmarkEntity {
+	payload := make([]benchmarkEntity, 100)
+	for i := range payload {
+		payload[i] = benchmarkEntity{
+			UUID:  "3c9e8c1c-2f4e-4b8e-9d6a-0e6f1c2b7a11",
+			Name:  "some name",
+			Count: i,
+		}
+	}
+
+	return payload
+}
+
+func BenchmarkEmissioneWriter_JSON(b *testing.B) {
+	payload := benchmarkPayload()
+	request := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		turtleware.EmissioneWriter.Write(discardResponseWriter{header: http.Header{}}, request, http.StatusOK, payload)
+	}
+}
+
+func BenchmarkEmissioneWriter_JSON_Baseline(b *testing.B) {
+	payload := benchmarkPayload()
+	request := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+	baseline := baselineEmissioneWriter()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		baseline.Write(discardResponseWriter{header: http.Header{}}, request, http.StatusOK, payload)
+	}
+}
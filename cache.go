@@ -3,14 +3,184 @@ package turtleware
 import (
 	"github.com/rs/zerolog"
 
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
+// ErrInvalidConditionalHeaders indicates that the request contained conflicting
+// or malformed conditional headers (If-Match, If-None-Match, If-Modified-Since
+// and If-Unmodified-Since).
+var ErrInvalidConditionalHeaders = errors.New("invalid conditional headers")
+
+// DefaultListCacheControl are the default directives of the Cache-Control header of the
+// list cache middlewares, which force clients to revalidate on every request.
+var DefaultListCacheControl = []string{"must-revalidate", "max-age=0"}
+
+type cacheOptions struct {
+	varyAuthorization bool
+	vary              []string
+	lastModEtag       bool
+	cacheControl      []string
+	ifUnmodifiedSince bool
+}
+
+// CacheOption represents an option for the cache middlewares.
+type CacheOption func(*cacheOptions)
+
+// CacheVaryAuthorization sets whether the Authorization header is included in the
+// Vary header of cached responses. This is required for authenticated endpoints,
+// so shared caches do not serve a response to a different user.
+// The default is true.
+func CacheVaryAuthorization(varyAuthorization bool) CacheOption {
+	return func(c *cacheOptions) {
+		c.varyAuthorization = varyAuthorization
+	}
+}
+
+// CacheVary adds additional header names (e.g. Accept or Accept-Encoding) to the
+// Vary header of cached responses. This is required if these headers influence
+// the response.
+// The default is empty.
+func CacheVary(headers ...string) CacheOption {
+	return func(c *cacheOptions) {
+		c.vary = append(c.vary, headers...)
+	}
+}
+
+// CacheLastModEtag sets whether the ResourceCacheMiddleware additionally emits a strong Etag,
+// derived from the entity UUID and the last modification date (see LastModEtag). If enabled,
+// the If-None-Match header is honored in addition to the If-Modified-Since header.
+// The default is false.
+func CacheLastModEtag(lastModEtag bool) CacheOption {
+	return func(c *cacheOptions) {
+		c.lastModEtag = lastModEtag
+	}
+}
+
+// CacheControl sets the directives of the Cache-Control header of cached responses (e.g.
+// max-age=300 and public for rarely changing reference data), overriding the default
+// directives of the respective middleware. Providing no directives disables the header.
+// The default is must-revalidate and max-age=0 for the list cache middlewares, and no
+// Cache-Control header for the resource cache middlewares.
+func CacheControl(directives ...string) CacheOption {
+	return func(c *cacheOptions) {
+		c.cacheControl = append([]string{}, directives...)
+	}
+}
+
+// CacheIfUnmodifiedSince sets whether the ResourceCacheMiddleware evaluates the If-Unmodified-Since
+// header of the request. If enabled, and the resource was modified after the given date, the request
+// is answered with a 412 Precondition Failed (see ErrPreconditionFailed). Malformed dates are ignored.
+// The default is false.
+func CacheIfUnmodifiedSince(ifUnmodifiedSince bool) CacheOption {
+	return func(c *cacheOptions) {
+		c.ifUnmodifiedSince = ifUnmodifiedSince
+	}
+}
+
+func newCacheOptions(opts ...CacheOption) *cacheOptions {
+	// default
+	config := &cacheOptions{
+		varyAuthorization: true,
+		vary:              nil,
+		lastModEtag:       false,
+		cacheControl:      nil,
+		ifUnmodifiedSince: false,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// varyHeaders returns the header names to be included in the Vary header of cached responses.
+func (c *cacheOptions) varyHeaders() []string {
+	headers := make([]string, 0, len(c.vary)+1)
+	if c.varyAuthorization {
+		headers = append(headers, "Authorization")
+	}
+
+	return append(headers, c.vary...)
+}
+
+// cacheControlDirectives returns the directives of the Cache-Control header of cached responses.
+// If no directives were set via CacheControl, the given default directives are returned.
+func (c *cacheOptions) cacheControlDirectives(defaultDirectives []string) []string {
+	if c.cacheControl != nil {
+		return c.cacheControl
+	}
+
+	return defaultDirectives
+}
+
+// ModifiedSince reports whether the given last modification date is after the date of the
+// If-Unmodified-Since header of the given request, with a resolution of one second. Requests
+// without or with a malformed If-Unmodified-Since header are never considered as modified.
+func ModifiedSince(r *http.Request, lastModified time.Time) bool {
+	ifUnmodifiedSince, err := GetIfUnmodifiedSince(r)
+	if err != nil {
+		return false
+	}
+
+	return lastModified.Truncate(time.Second).After(ifUnmodifiedSince.Truncate(time.Second))
+}
+
+// LastModEtag returns a strong Etag for the given entity UUID and last modification date, which
+// is the sha256 hash of both. Contrary to the Last-Modified header, the full precision of the
+// date is retained, so modifications within the same second result in different Etags.
+func LastModEtag(entityUUID string, lastModified time.Time) string {
+	hash := sha256.Sum256([]byte(entityUUID + ":" + lastModified.UTC().Format(time.RFC3339Nano)))
+
+	return StrongEtag(hex.EncodeToString(hash[:]))
+}
+
+// addVaryHeader adds the given header names to the Vary header, skipping names
+// which are already present (case-insensitively).
+func addVaryHeader(header http.Header, names ...string) {
+	existing := map[string]struct{}{}
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			existing[http.CanonicalHeaderKey(strings.TrimSpace(name))] = struct{}{}
+		}
+	}
+
+	for _, name := range names {
+		canonicalName := http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if _, found := existing[canonicalName]; found || canonicalName == "" {
+			continue
+		}
+
+		existing[canonicalName] = struct{}{}
+		header.Add("Vary", canonicalName)
+	}
+}
+
+// setCacheControlHeader replaces the Cache-Control header with the given directives.
+// If no directives are given, the header is left untouched.
+func setCacheControlHeader(header http.Header, directives ...string) {
+	if len(directives) == 0 {
+		return
+	}
+
+	header.Del("Cache-Control")
+	for _, directive := range directives {
+		header.Add("Cache-Control", directive)
+	}
+}
+
 // ExtractCacheHeader extracts the Etag (If-None-Match) and last modification (If-Modified-Since)
-// headers from a given request.
+// headers from a given request. If the request contains multiple If-None-Match headers, they
+// are combined into a single comma-separated list, which can be checked via EtagMatches.
 func ExtractCacheHeader(r *http.Request) (string, time.Time) {
-	etag := r.Header.Get("If-None-Match")
+	etag := strings.Join(r.Header.Values("If-None-Match"), ", ")
 	lastModifiedHeader := r.Header.Get("If-Modified-Since")
 
 	lastModifiedHeaderTime := time.Time{}
@@ -28,3 +198,112 @@ func ExtractCacheHeader(r *http.Request) (string, time.Time) {
 
 	return etag, lastModifiedHeaderTime
 }
+
+// StrongEtag returns the given value as a strong Etag, that is, surrounded by quotes
+// (e.g. "abc"). Values which are already quoted are returned as-is.
+func StrongEtag(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value
+	}
+
+	return `"` + value + `"`
+}
+
+// EtagMatches reports whether the given If-None-Match header value matches the given
+// Etag, by using the weak comparison (see RFC 7232, section 2.3.2). That is, the optional
+// weak indicator (W/) and the surrounding quotes are stripped from both sides before
+// comparing them. The header may contain multiple comma-separated entity tags, in which
+// case it matches if any of them matches. The wildcard "*" matches any (non-empty) Etag.
+// An empty If-None-Match header never matches.
+func EtagMatches(ifNoneMatch string, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	normalizedEtag := normalizeEtag(etag)
+
+	for _, candidate := range splitEtags(ifNoneMatch) {
+		if candidate == "*" || normalizeEtag(candidate) == normalizedEtag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// splitEtags splits a list of comma-separated entity tags. Commas within
+// quoted entity tags are retained.
+func splitEtags(header string) []string {
+	var etags []string
+
+	quoted := false
+	start := 0
+
+	for i := 0; i <= len(header); i++ {
+		if i < len(header) {
+			if header[i] == '"' {
+				quoted = !quoted
+			}
+
+			if header[i] != ',' || quoted {
+				continue
+			}
+		}
+
+		if etag := strings.TrimSpace(header[start:i]); etag != "" {
+			etags = append(etags, etag)
+		}
+
+		start = i + 1
+	}
+
+	return etags
+}
+
+func normalizeEtag(etag string) string {
+	etag = strings.TrimSpace(etag)
+	etag = strings.TrimPrefix(etag, "W/")
+
+	if len(etag) >= 2 && strings.HasPrefix(etag, `"`) && strings.HasSuffix(etag, `"`) {
+		etag = etag[1 : len(etag)-1]
+	}
+
+	return etag
+}
+
+// suffixedEtag returns the given strong Etag with the given suffix appended (e.g. "abc-gzip").
+// Weak Etags are returned as-is.
+func suffixedEtag(etag string, suffix string) string {
+	if len(etag) < 2 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+		return etag
+	}
+
+	return etag[:len(etag)-1] + suffix + `"`
+}
+
+// ValidateConditionalHeaders validates the conditional headers of a given request.
+// ErrInvalidConditionalHeaders is returned, if the request contains both If-Match and
+// If-None-Match, or if either date header is malformed. Combining If-Modified-Since and
+// If-Unmodified-Since is valid, as each is evaluated for the methods it applies to.
+func ValidateConditionalHeaders(r *http.Request) error {
+	if r.Header.Get("If-Match") != "" && r.Header.Get("If-None-Match") != "" {
+		return fmt.Errorf("%w: If-Match and If-None-Match are mutually exclusive", ErrInvalidConditionalHeaders)
+	}
+
+	ifModifiedSince := r.Header.Get("If-Modified-Since")
+	ifUnmodifiedSince := r.Header.Get("If-Unmodified-Since")
+
+	if ifModifiedSince != "" {
+		if _, err := time.Parse(time.RFC1123, ifModifiedSince); err != nil {
+			return fmt.Errorf("%w: malformed If-Modified-Since date", ErrInvalidConditionalHeaders)
+		}
+	}
+
+	if ifUnmodifiedSince != "" {
+		if _, err := GetIfUnmodifiedSince(r); err != nil {
+			return fmt.Errorf("%w: malformed If-Unmodified-Since date", ErrInvalidConditionalHeaders)
+		}
+	}
+
+	return nil
+}
@@ -1,10 +1,12 @@
 package turtleware_test
 
 import (
+	"github.com/justinas/alice"
 	"github.com/kernle32dll/turtleware"
 	"github.com/stretchr/testify/suite"
 	"net/http/httptest"
 
+	"context"
 	"net/http"
 	"testing"
 	"time"
@@ -75,3 +77,119 @@ func (s *CacheSuite) Test_ETag_And_Invalid_ModDate() {
 	s.Empty(etag)
 	s.True(lastModifiedDate.IsZero())
 }
+
+func (s *CacheSuite) Test_ModifiedSince() {
+	lastModTime := time.Date(1991, 5, 23, 1, 2, 3, 4, time.UTC)
+
+	cases := map[string]struct {
+		ifUnmodifiedSince string
+		expected          bool
+	}{
+		"Missing":   {ifUnmodifiedSince: "", expected: false},
+		"Malformed": {ifUnmodifiedSince: "not-a-date", expected: false},
+		"Same":      {ifUnmodifiedSince: lastModTime.Format(time.RFC1123), expected: false},
+		"Later":     {ifUnmodifiedSince: lastModTime.Add(time.Hour).Format(time.RFC1123), expected: false},
+		"Earlier":   {ifUnmodifiedSince: lastModTime.Add(-time.Second).Format(time.RFC1123), expected: true},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			request := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+			if target.ifUnmodifiedSince != "" {
+				request.Header.Set("If-Unmodified-Since", target.ifUnmodifiedSince)
+			}
+
+			// when
+			modified := turtleware.ModifiedSince(request, lastModTime)
+
+			// then
+			s.Equal(target.expected, modified)
+		})
+	}
+}
+
+func (s *CacheSuite) Test_LastModEtag() {
+	// given
+	lastModTime := time.Date(1991, 5, 23, 1, 2, 3, 4, time.UTC)
+
+	// when
+	etag := turtleware.LastModEtag("some-uuid", lastModTime)
+
+	// then
+	s.Regexp(`^"[0-9a-f]{64}"$`, etag)
+	s.Equal(etag, turtleware.LastModEtag("some-uuid", lastModTime.In(time.FixedZone("CEST", 2*60*60))))
+	s.NotEqual(etag, turtleware.LastModEtag("other-uuid", lastModTime))
+	s.NotEqual(etag, turtleware.LastModEtag("some-uuid", lastModTime.Add(time.Nanosecond)))
+}
+
+func (s *CacheSuite) Test_ListCacheMiddleware_Vary_Deduplicates() {
+	// given
+	response := httptest.NewRecorder()
+	response.Header().Set("Vary", "Origin, authorization")
+
+	hashFetcher := func(ctx context.Context, paging turtleware.Paging) (string, error) {
+		return "some-hash", nil
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+		turtleware.ListCacheMiddleware(hashFetcher, turtleware.DefaultErrorHandler, turtleware.CacheVary("accept", "Accept", "")),
+	).Then(&MiddlewareCapture{})
+
+	// when
+	testChain.ServeHTTP(response, s.request)
+
+	// then
+	s.Equal([]string{"Origin, authorization", "Accept"}, response.Header().Values("Vary"))
+}
+
+func (s *CacheSuite) Test_StrongEtag() {
+	s.Equal(`"some-hash"`, turtleware.StrongEtag("some-hash"))
+	s.Equal(`"some-hash"`, turtleware.StrongEtag(`"some-hash"`))
+	s.Equal(`""`, turtleware.StrongEtag(""))
+}
+
+func (s *CacheSuite) Test_EtagMatches() {
+	cases := map[string]struct {
+		ifNoneMatch string
+		etag        string
+		matches     bool
+	}{
+		"Unquoted":       {ifNoneMatch: "some-hash", etag: `"some-hash"`, matches: true},
+		"Quoted":         {ifNoneMatch: `"some-hash"`, etag: `"some-hash"`, matches: true},
+		"Weak":           {ifNoneMatch: `W/"some-hash"`, etag: `"some-hash"`, matches: true},
+		"Weak_Both":      {ifNoneMatch: `W/"some-hash"`, etag: `W/"some-hash"`, matches: true},
+		"Unquoted_Etag":  {ifNoneMatch: `W/"some-hash"`, etag: "some-hash", matches: true},
+		"Encoded":        {ifNoneMatch: `"some-hash-gzip"`, etag: `"some-hash"`, matches: false},
+		"Different":      {ifNoneMatch: `"other-hash"`, etag: `"some-hash"`, matches: false},
+		"Empty":          {ifNoneMatch: "", etag: `"some-hash"`, matches: false},
+		"Empty_Quoted":   {ifNoneMatch: `""`, etag: `"some-hash"`, matches: false},
+		"Case_Sensitive": {ifNoneMatch: `"SOME-HASH"`, etag: `"some-hash"`, matches: false},
+		"Multiple":       {ifNoneMatch: `"a", W/"some-hash", "c"`, etag: `"some-hash"`, matches: true},
+		"Multiple_None":  {ifNoneMatch: `"a","b" , "c"`, etag: `"some-hash"`, matches: false},
+		"Quoted_Comma":   {ifNoneMatch: `"a", "some,hash"`, etag: `"some,hash"`, matches: true},
+		"Quoted_Split":   {ifNoneMatch: `"some,hash"`, etag: `"hash"`, matches: false},
+		"Wildcard":       {ifNoneMatch: "*", etag: `"some-hash"`, matches: true},
+		"Wildcard_Empty": {ifNoneMatch: "*", etag: "", matches: false},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			s.Equal(target.matches, turtleware.EtagMatches(target.ifNoneMatch, target.etag))
+		})
+	}
+}
+
+func (s *CacheSuite) Test_Multiple_ETag_Headers() {
+	// given
+	s.request.Header.Add("If-None-Match", `"a", "b"`)
+	s.request.Header.Add("If-None-Match", `"c"`)
+
+	// when
+	etag, _ := turtleware.ExtractCacheHeader(s.request)
+
+	// then
+	s.Equal(`"a", "b", "c"`, etag)
+	s.True(turtleware.EtagMatches(etag, `"c"`))
+}
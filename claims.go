@@ -0,0 +1,203 @@
+package turtleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrClaimType indicates that a claim could not be converted into the requested type.
+var ErrClaimType = errors.New("claim has incompatible type")
+
+// ClaimString retrieves a claim from the given claims via ClaimByPath, and converts it into
+// a string. Numbers and booleans are formatted, and times are formatted as RFC 3339.
+// If no claim exists at the given path, ErrClaimNotFound is returned. If the claim cannot be
+// converted, ErrClaimType is returned.
+func ClaimString(claims map[string]interface{}, path string) (string, error) {
+	value, err := ClaimByPath(claims, path)
+	if err != nil {
+		return "", err
+	}
+
+	switch typed := value.(type) {
+	case string:
+		return typed, nil
+	case bool:
+		return strconv.FormatBool(typed), nil
+	case float64:
+		return strconv.FormatFloat(typed, 'f', -1, 64), nil
+	case float32:
+		return strconv.FormatFloat(float64(typed), 'f', -1, 32), nil
+	case int:
+		return strconv.Itoa(typed), nil
+	case int64:
+		return strconv.FormatInt(typed, 10), nil
+	case int32:
+		return strconv.FormatInt(int64(typed), 10), nil
+	case json.Number:
+		return typed.String(), nil
+	case time.Time:
+		return typed.Format(time.RFC3339), nil
+	default:
+		return "", claimTypeError(path, value, "string")
+	}
+}
+
+// ClaimInt64 retrieves a claim from the given claims via ClaimByPath, and converts it into
+// an int64. Numbers without fractional part and numeric strings (e.g. "42") are converted,
+// and times are converted into unix seconds.
+// If no claim exists at the given path, ErrClaimNotFound is returned. If the claim cannot be
+// converted, ErrClaimType is returned.
+func ClaimInt64(claims map[string]interface{}, path string) (int64, error) {
+	value, err := ClaimByPath(claims, path)
+	if err != nil {
+		return 0, err
+	}
+
+	return claimInt64(path, value)
+}
+
+func claimInt64(path string, value interface{}) (int64, error) {
+	switch typed := value.(type) {
+	case int:
+		return int64(typed), nil
+	case int64:
+		return typed, nil
+	case int32:
+		return int64(typed), nil
+	case float64:
+		return floatToInt64(typed, path, value)
+	case float32:
+		return floatToInt64(float64(typed), path, value)
+	case json.Number:
+		return stringToInt64(typed.String(), path, value)
+	case string:
+		return stringToInt64(typed, path, value)
+	case time.Time:
+		return typed.Unix(), nil
+	default:
+		return 0, claimTypeError(path, value, "int64")
+	}
+}
+
+// ClaimBool retrieves a claim from the given claims via ClaimByPath, and converts it into
+// a bool. Boolean strings (as understood by strconv.ParseBool, e.g. "true" or "0") and the
+// numbers 0 and 1 are converted.
+// If no claim exists at the given path, ErrClaimNotFound is returned. If the claim cannot be
+// converted, ErrClaimType is returned.
+func ClaimBool(claims map[string]interface{}, path string) (bool, error) {
+	value, err := ClaimByPath(claims, path)
+	if err != nil {
+		return false, err
+	}
+
+	switch typed := value.(type) {
+	case bool:
+		return typed, nil
+	case string:
+		parsed, err := strconv.ParseBool(strings.TrimSpace(typed))
+		if err != nil {
+			return false, claimTypeError(path, value, "bool")
+		}
+
+		return parsed, nil
+	default:
+		number, err := claimInt64(path, value)
+		if err != nil || (number != 0 && number != 1) {
+			return false, claimTypeError(path, value, "bool")
+		}
+
+		return number == 1, nil
+	}
+}
+
+// ClaimTime retrieves a claim from the given claims via ClaimByPath, and converts it into
+// a time.Time. Numbers and numeric strings are interpreted as (possibly fractional) unix
+// seconds, as used by the registered claims exp, iat and nbf. Other strings are parsed as
+// RFC 3339.
+// If no claim exists at the given path, ErrClaimNotFound is returned. If the claim cannot be
+// converted, ErrClaimType is returned.
+func ClaimTime(claims map[string]interface{}, path string) (time.Time, error) {
+	value, err := ClaimByPath(claims, path)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	switch typed := value.(type) {
+	case time.Time:
+		return typed, nil
+	case int:
+		return time.Unix(int64(typed), 0), nil
+	case int64:
+		return time.Unix(typed, 0), nil
+	case int32:
+		return time.Unix(int64(typed), 0), nil
+	case float64:
+		return unixFloatToTime(typed, path, value)
+	case float32:
+		return unixFloatToTime(float64(typed), path, value)
+	case json.Number:
+		return stringToTime(typed.String(), path, value)
+	case string:
+		return stringToTime(typed, path, value)
+	default:
+		return time.Time{}, claimTypeError(path, value, "time")
+	}
+}
+
+func claimTypeError(path string, value interface{}, target string) error {
+	return fmt.Errorf("%w: cannot convert %s of type %T to %s", ErrClaimType, path, value, target)
+}
+
+func floatToInt64(number float64, path string, value interface{}) (int64, error) {
+	if number != math.Trunc(number) || number < math.MinInt64 || number >= math.MaxInt64 {
+		return 0, claimTypeError(path, value, "int64")
+	}
+
+	return int64(number), nil
+}
+
+func stringToInt64(number string, path string, value interface{}) (int64, error) {
+	number = strings.TrimSpace(number)
+
+	if parsed, err := strconv.ParseInt(number, 10, 64); err == nil {
+		return parsed, nil
+	}
+
+	// Numbers in exponent notation, such as 1e3
+	parsed, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, claimTypeError(path, value, "int64")
+	}
+
+	return floatToInt64(parsed, path, value)
+}
+
+func unixFloatToTime(seconds float64, path string, value interface{}) (time.Time, error) {
+	if math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return time.Time{}, claimTypeError(path, value, "time")
+	}
+
+	whole, fraction := math.Modf(seconds)
+
+	return time.Unix(int64(whole), int64(fraction*float64(time.Second))), nil
+}
+
+func stringToTime(timestamp string, path string, value interface{}) (time.Time, error) {
+	timestamp = strings.TrimSpace(timestamp)
+
+	if seconds, err := strconv.ParseFloat(timestamp, 64); err == nil {
+		return unixFloatToTime(seconds, path, value)
+	}
+
+	parsed, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, claimTypeError(path, value, "time")
+	}
+
+	return parsed, nil
+}
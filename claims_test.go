@@ -0,0 +1,256 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/suite"
+
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type ClaimsSuite struct {
+	CommonSuite
+}
+
+func TestClaimsSuite(t *testing.T) {
+	suite.Run(t, &ClaimsSuite{})
+}
+
+func (s *ClaimsSuite) Test_ClaimString() {
+	cases := map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"String":     {value: "some-value", expected: "some-value"},
+		"Bool":       {value: true, expected: "true"},
+		"Float":      {value: float64(1.5), expected: "1.5"},
+		"Integral":   {value: float64(42), expected: "42"},
+		"Int":        {value: 42, expected: "42"},
+		"JSONNumber": {value: json.Number("42"), expected: "42"},
+		"Time":       {value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), expected: "2024-01-02T03:04:05Z"},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			value, err := turtleware.ClaimString(map[string]interface{}{"claim": target.value}, "claim")
+
+			// then
+			s.NoError(err)
+			s.Equal(target.expected, value)
+		})
+	}
+
+	s.Run("Incompatible", func() {
+		// when
+		_, err := turtleware.ClaimString(map[string]interface{}{"claim": []interface{}{"a"}}, "claim")
+
+		// then
+		s.ErrorIs(err, turtleware.ErrClaimType)
+	})
+
+	s.Run("Missing", func() {
+		// when
+		_, err := turtleware.ClaimString(map[string]interface{}{}, "claim")
+
+		// then
+		s.ErrorIs(err, turtleware.ErrClaimNotFound)
+	})
+}
+
+func (s *ClaimsSuite) Test_ClaimInt64() {
+	cases := map[string]struct {
+		value    interface{}
+		expected int64
+	}{
+		"Float":         {value: float64(42), expected: 42},
+		"Int":           {value: 42, expected: 42},
+		"Int64":         {value: int64(42), expected: 42},
+		"JSONNumber":    {value: json.Number("42"), expected: 42},
+		"String":        {value: "42", expected: 42},
+		"String_Spaced": {value: " -42 ", expected: -42},
+		"String_Float":  {value: "1e3", expected: 1000},
+		"Time":          {value: time.Unix(1700000000, 0), expected: 1700000000},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			value, err := turtleware.ClaimInt64(map[string]interface{}{"claim": target.value}, "claim")
+
+			// then
+			s.NoError(err)
+			s.Equal(target.expected, value)
+		})
+	}
+
+	incompatible := map[string]interface{}{
+		"Fractional":        1.5,
+		"String_Fractional": "1.5",
+		"String_Invalid":    "forty-two",
+		"Bool":              true,
+		"Object":            map[string]interface{}{},
+	}
+
+	for testName, value := range incompatible {
+		s.Run("Incompatible_"+testName, func() {
+			// when
+			_, err := turtleware.ClaimInt64(map[string]interface{}{"claim": value}, "claim")
+
+			// then
+			s.ErrorIs(err, turtleware.ErrClaimType)
+		})
+	}
+
+	s.Run("Missing", func() {
+		// when
+		_, err := turtleware.ClaimInt64(map[string]interface{}{}, "claim")
+
+		// then
+		s.ErrorIs(err, turtleware.ErrClaimNotFound)
+	})
+}
+
+func (s *ClaimsSuite) Test_ClaimBool() {
+	cases := map[string]struct {
+		value    interface{}
+		expected bool
+	}{
+		"Bool":         {value: true, expected: true},
+		"String_True":  {value: "true", expected: true},
+		"String_False": {value: "FALSE", expected: false},
+		"String_One":   {value: "1", expected: true},
+		"Number_One":   {value: float64(1), expected: true},
+		"Number_Zero":  {value: float64(0), expected: false},
+		"JSONNumber":   {value: json.Number("1"), expected: true},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			value, err := turtleware.ClaimBool(map[string]interface{}{"claim": target.value}, "claim")
+
+			// then
+			s.NoError(err)
+			s.Equal(target.expected, value)
+		})
+	}
+
+	incompatible := map[string]interface{}{
+		"String_Invalid": "yes",
+		"Number_Two":     float64(2),
+		"Fractional":     0.5,
+		"Array":          []interface{}{true},
+	}
+
+	for testName, value := range incompatible {
+		s.Run("Incompatible_"+testName, func() {
+			// when
+			_, err := turtleware.ClaimBool(map[string]interface{}{"claim": value}, "claim")
+
+			// then
+			s.ErrorIs(err, turtleware.ErrClaimType)
+		})
+	}
+
+	s.Run("Missing", func() {
+		// when
+		_, err := turtleware.ClaimBool(map[string]interface{}{}, "claim")
+
+		// then
+		s.ErrorIs(err, turtleware.ErrClaimNotFound)
+	})
+}
+
+func (s *ClaimsSuite) Test_ClaimTime() {
+	expected := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := map[string]struct {
+		value    interface{}
+		expected time.Time
+	}{
+		"Time":         {value: expected, expected: expected},
+		"Float":        {value: float64(expected.Unix()), expected: expected},
+		"Fractional":   {value: float64(expected.Unix()) + 0.5, expected: expected.Add(500 * time.Millisecond)},
+		"Int64":        {value: expected.Unix(), expected: expected},
+		"JSONNumber":   {value: json.Number("1704164645"), expected: expected},
+		"String_Unix":  {value: "1704164645", expected: expected},
+		"String_RFC":   {value: "2024-01-02T03:04:05Z", expected: expected},
+		"String_Zoned": {value: "2024-01-02T04:04:05+01:00", expected: expected},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			value, err := turtleware.ClaimTime(map[string]interface{}{"claim": target.value}, "claim")
+
+			// then
+			s.NoError(err)
+			s.True(target.expected.Equal(value), "expected %s, got %s", target.expected, value)
+		})
+	}
+
+	incompatible := map[string]interface{}{
+		"String_Invalid": "yesterday",
+		"Bool":           true,
+	}
+
+	for testName, value := range incompatible {
+		s.Run("Incompatible_"+testName, func() {
+			// when
+			_, err := turtleware.ClaimTime(map[string]interface{}{"claim": value}, "claim")
+
+			// then
+			s.ErrorIs(err, turtleware.ErrClaimType)
+		})
+	}
+
+	s.Run("Missing", func() {
+		// when
+		_, err := turtleware.ClaimTime(map[string]interface{}{}, "claim")
+
+		// then
+		s.ErrorIs(err, turtleware.ErrClaimNotFound)
+	})
+}
+
+func (s *ClaimsSuite) Test_Claims_ValidatedToken() {
+	// given
+	hmacKey := []byte("supersecretpassphrase")
+
+	publicKey, err := turtleware.JWKFromPublicKey(hmacKey, "hmac-key")
+	s.Require().NoError(err)
+	s.Require().NoError(publicKey.Set(jwk.AlgorithmKey, jwa.HS256))
+
+	keys := jwk.NewSet()
+	s.Require().NoError(keys.AddKey(publicKey))
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	token := s.generateToken(jwa.HS256, hmacKey, map[string]interface{}{
+		"exp": expiry.Unix(),
+		"https://example.com/app": map[string]interface{}{
+			"admin":   "true",
+			"uploads": "10",
+		},
+	}, map[string]interface{}{jwk.KeyIDKey: "hmac-key"})
+
+	claims, err := turtleware.ValidateTokenBySet(token, keys)
+	s.Require().NoError(err)
+
+	// when
+	exp, expErr := turtleware.ClaimTime(claims, "exp")
+	admin, adminErr := turtleware.ClaimBool(claims, "https://example.com/app.admin")
+	uploads, uploadsErr := turtleware.ClaimInt64(claims, "https://example.com/app.uploads")
+
+	// then
+	s.NoError(expErr)
+	s.True(expiry.Equal(exp))
+	s.NoError(adminErr)
+	s.True(admin)
+	s.NoError(uploadsErr)
+	s.Equal(int64(10), uploads)
+}
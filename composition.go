@@ -21,18 +21,23 @@ type GetEndpoint[T any] interface {
 
 // ResourceHandler composes a full http.Handler for retrieving a single resource.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements RelatedResources, preload hints are emitted for related resources.
+// If the endpoint implements DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements JSONAPIEndpoint, the resource may be wrapped into a JSON:API document.
+// If the endpoint implements CORSEndpoint, OPTIONS requests are answered via the OptionsMiddleware.
 func ResourceHandler[T any](
 	keySet jwk.Set,
 	getEndpoint GetEndpoint[T],
 ) http.Handler {
 	entityMiddleware := EntityUUIDMiddleware(getEndpoint.EntityUUID)
 	cacheMiddleware := ResourceCacheMiddleware(getEndpoint.LastModification, getEndpoint.HandleError)
-	dataMiddleware := ResourceDataHandler(getEndpoint.FetchEntity, getEndpoint.HandleError)
+	dataOpts := append(resourceTimeoutOptions(getEndpoint), resourceJSONAPIOptions(getEndpoint)...)
+	dataMiddleware := ResourceDataHandler(getEndpoint.FetchEntity, getEndpoint.HandleError, dataOpts...)
 
-	return resourcePreHandler(keySet).Append(
+	return optionsPreHandler(relatedPreHandler(readOnlyPreHandler(resourcePreHandler(keySet)).Append(
 		entityMiddleware,
 		cacheMiddleware,
-	).Then(
+	), getEndpoint), getEndpoint, http.MethodGet, http.MethodHead).Then(
 		dataMiddleware,
 	)
 }
@@ -50,24 +55,83 @@ type GetSQLListEndpoint[T any] interface {
 
 // ListSQLHandler composes a full http.Handler for retrieving a list of resources via SQL.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements TotalCountColumnEndpoint, the total count is derived from the result rows
+// (except for HEAD requests).
+// If the endpoint implements DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements JSONAPIEndpoint, the list may be wrapped into a JSON:API document.
+// If the endpoint implements PagingLinksEndpoint, Link headers for navigating the list are emitted.
+// If the endpoint implements CSVEndpoint, the list may be served as CSV.
+// If the endpoint implements CORSEndpoint, OPTIONS requests are answered via the OptionsMiddleware.
 func ListSQLHandler[T any](
 	keySet jwk.Set,
 	listEndpoint GetSQLListEndpoint[T],
 ) http.Handler {
 	cacheMiddleware := ListCacheMiddleware(listEndpoint.ListHash, listEndpoint.HandleError)
-	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError)
-	dataMiddleware := SQLListDataHandler(listEndpoint.FetchRows, listEndpoint.TransformEntity, listEndpoint.HandleError)
+	countOpts, dataOpts := countPreHandler(listEndpoint)
+	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError, countOpts...)
+	dataOpts = append(dataOpts, listTimeoutOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listJSONAPIOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listPagingLinksOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listCSVOptions(listEndpoint)...)
+	dataMiddleware := SQLListDataHandler(listEndpoint.FetchRows, listEndpoint.TransformEntity, listEndpoint.HandleError, dataOpts...)
 
-	return listPreHandler(keySet).Append(
+	return optionsPreHandler(csvPreHandler(listPreHandler(keySet), listEndpoint).Append(
 		cacheMiddleware,
 		countMiddleware,
-	).Then(
+	), listEndpoint, http.MethodGet, http.MethodHead).Then(
 		dataMiddleware,
 	)
 }
 
 // --------------------------
 
+// TotalCountColumnEndpoint is an optional interface for a GetSQLListEndpoint or GetSQLxListEndpoint.
+// If implemented, the total count is derived from the named column of the result rows (e.g. via
+// count(*) OVER() in Postgres), instead of a separate TotalCount query (see ListDataTotalCountColumn).
+// HEAD requests fetch no rows, so TotalCount is still used for them.
+type TotalCountColumnEndpoint interface {
+	TotalCountColumn() string
+}
+
+// --------------------------
+
+// DataTimeoutEndpoint is an optional interface for a GetEndpoint, or any of the list endpoints.
+// If implemented, the context passed to the data retrieval (e.g. FetchEntity or FetchRows) is
+// cancelled after the returned timeout, and ErrRequestTimeout is passed to HandleError
+// (see ResourceDataTimeout and ListDataTimeout).
+type DataTimeoutEndpoint interface {
+	DataTimeout() time.Duration
+}
+
+// --------------------------
+
+// JSONAPIEndpoint is an optional interface for a GetEndpoint, or any of the list endpoints.
+// If implemented, and JSONAPI returns true, lists are wrapped into a JSON:API document with the
+// total count inlined, and resources are wrapped likewise (see ListDataJSONAPI and ResourceDataJSONAPI).
+type JSONAPIEndpoint interface {
+	JSONAPI() bool
+}
+
+// --------------------------
+
+// PagingLinksEndpoint is an optional interface for any of the list endpoints. If implemented,
+// and PagingLinks returns true, Link headers with the relations first, prev, next and last are
+// emitted, derived from the paging and the total count (see ListDataPagingLinks).
+type PagingLinksEndpoint interface {
+	PagingLinks() bool
+}
+
+// --------------------------
+
+// CSVEndpoint is an optional interface for any of the list endpoints. If implemented, and CSV
+// returns true, the list is served as CSV for requests preferring text/csv (see NewCSVSerializer
+// and CSVMiddleware).
+type CSVEndpoint interface {
+	CSV() bool
+}
+
+// --------------------------
+
 // GetSQLxListEndpoint defines the contract for a ListSQLxHandler composition.
 type GetSQLxListEndpoint[T any] interface {
 	ListHash(ctx context.Context, paging Paging) (string, error)
@@ -79,18 +143,30 @@ type GetSQLxListEndpoint[T any] interface {
 
 // ListSQLxHandler composes a full http.Handler for retrieving a list of resources via SQL.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements TotalCountColumnEndpoint, the total count is derived from the result rows
+// (except for HEAD requests).
+// If the endpoint implements DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements JSONAPIEndpoint, the list may be wrapped into a JSON:API document.
+// If the endpoint implements PagingLinksEndpoint, Link headers for navigating the list are emitted.
+// If the endpoint implements CSVEndpoint, the list may be served as CSV.
+// If the endpoint implements CORSEndpoint, OPTIONS requests are answered via the OptionsMiddleware.
 func ListSQLxHandler[T any](
 	keySet jwk.Set,
 	listEndpoint GetSQLxListEndpoint[T],
 ) http.Handler {
 	cacheMiddleware := ListCacheMiddleware(listEndpoint.ListHash, listEndpoint.HandleError)
-	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError)
-	dataMiddleware := SQLxListDataHandler(listEndpoint.FetchRows, listEndpoint.TransformEntity, listEndpoint.HandleError)
+	countOpts, dataOpts := countPreHandler(listEndpoint)
+	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError, countOpts...)
+	dataOpts = append(dataOpts, listTimeoutOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listJSONAPIOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listPagingLinksOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listCSVOptions(listEndpoint)...)
+	dataMiddleware := SQLxListDataHandler(listEndpoint.FetchRows, listEndpoint.TransformEntity, listEndpoint.HandleError, dataOpts...)
 
-	return listPreHandler(keySet).Append(
+	return optionsPreHandler(csvPreHandler(listPreHandler(keySet), listEndpoint).Append(
 		cacheMiddleware,
 		countMiddleware,
-	).Then(
+	), listEndpoint, http.MethodGet, http.MethodHead).Then(
 		dataMiddleware,
 	)
 }
@@ -107,18 +183,27 @@ type GetStaticListEndpoint[T any] interface {
 
 // StaticListHandler composes a full http.Handler for retrieving a list of resources from a static list.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements JSONAPIEndpoint, the list may be wrapped into a JSON:API document.
+// If the endpoint implements PagingLinksEndpoint, Link headers for navigating the list are emitted.
+// If the endpoint implements CSVEndpoint, the list may be served as CSV.
+// If the endpoint implements CORSEndpoint, OPTIONS requests are answered via the OptionsMiddleware.
 func StaticListHandler[T any](
 	keySet jwk.Set,
 	listEndpoint GetStaticListEndpoint[T],
 ) http.Handler {
 	cacheMiddleware := ListCacheMiddleware(listEndpoint.ListHash, listEndpoint.HandleError)
 	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError)
-	dataMiddleware := StaticListDataHandler(listEndpoint.FetchEntities, listEndpoint.HandleError)
+	dataOpts := listTimeoutOptions(listEndpoint)
+	dataOpts = append(dataOpts, listJSONAPIOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listPagingLinksOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listCSVOptions(listEndpoint)...)
+	dataMiddleware := StaticListDataHandler(listEndpoint.FetchEntities, listEndpoint.HandleError, dataOpts...)
 
-	return listPreHandler(keySet).Append(
+	return optionsPreHandler(csvPreHandler(listPreHandler(keySet), listEndpoint).Append(
 		cacheMiddleware,
 		countMiddleware,
-	).Then(
+	), listEndpoint, http.MethodGet, http.MethodHead).Then(
 		dataMiddleware,
 	)
 }
@@ -132,8 +217,25 @@ type CreateEndpoint[T CreateDTO] interface {
 	HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error)
 }
 
+// CreatedEndpoint is an optional interface for a CreateEndpoint. If implemented, successful
+// creations are answered with a 201 Created, and a Location header built by CreatedLocation
+// (see ResourceCreatedMiddleware).
+type CreatedEndpoint interface {
+	CreatedLocation(r *http.Request, entityUUID string) string
+}
+
+// JSONContentTypeEndpoint is an optional interface for a CreateEndpoint or PatchEndpoint.
+// If implemented, and RequireJSONContentType returns true, requests with a non-JSON body are
+// rejected early with a 415 (see RequireJSONContentTypeMiddleware).
+type JSONContentTypeEndpoint interface {
+	RequireJSONContentType() bool
+}
+
 // ResourceCreateHandler composes a full http.Handler for creating a new resource.
 // This includes authentication, and delegation of resource creation.
+// If the endpoint implements CreatedEndpoint, a 201 Created with a Location header is written.
+// If the endpoint implements JSONContentTypeEndpoint, non-JSON request bodies are rejected early.
+// If the endpoint implements CORSEndpoint, OPTIONS requests are answered via the OptionsMiddleware.
 func ResourceCreateHandler[T CreateDTO](
 	keySet jwk.Set,
 	createEndpoint CreateEndpoint[T],
@@ -142,10 +244,10 @@ func ResourceCreateHandler[T CreateDTO](
 	entityMiddleware := EntityUUIDMiddleware(createEndpoint.EntityUUID)
 	createMiddleware := ResourceCreateMiddleware(createEndpoint.CreateEntity, createEndpoint.HandleError)
 
-	return resourcePreHandler(keySet).Append(
+	return optionsPreHandler(createdPostHandler(jsonContentTypePreHandler(resourcePreHandler(keySet), createEndpoint).Append(
 		entityMiddleware,
 		createMiddleware,
-	).Then(
+	), createEndpoint, createEndpoint.HandleError), createEndpoint, http.MethodPost).Then(
 		nextHandler,
 	)
 }
@@ -161,6 +263,8 @@ type PatchEndpoint[T PatchDTO] interface {
 
 // ResourcePatchHandler composes a full http.Handler for updating an existing resource.
 // This includes authentication, and delegation of resource updating.
+// If the endpoint implements JSONContentTypeEndpoint, non-JSON request bodies are rejected early.
+// If the endpoint implements CORSEndpoint, OPTIONS requests are answered via the OptionsMiddleware.
 func ResourcePatchHandler[T PatchDTO](
 	keySet jwk.Set,
 	patchEndpoint PatchEndpoint[T],
@@ -169,24 +273,220 @@ func ResourcePatchHandler[T PatchDTO](
 	entityMiddleware := EntityUUIDMiddleware(patchEndpoint.EntityUUID)
 	patchMiddleware := ResourcePatchMiddleware(patchEndpoint.UpdateEntity, patchEndpoint.HandleError)
 
-	return resourcePreHandler(keySet).Append(
+	return optionsPreHandler(jsonContentTypePreHandler(resourcePreHandler(keySet), patchEndpoint).Append(
 		entityMiddleware,
 		patchMiddleware,
-	).Then(
+	), patchEndpoint, http.MethodPatch).Then(
 		nextHandler,
 	)
 }
 
 // --------------------------
 
+// DeleteEndpoint defines the contract for a ResourceDeleteHandler composition.
+type DeleteEndpoint interface {
+	EntityUUID(r *http.Request) (string, error)
+	DeleteEntity(ctx context.Context, entityUUID, userUUID string, ifUnmodifiedSince time.Time) error
+	HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error)
+}
+
+// ResourceDeleteHandler composes a full http.Handler for deleting an existing resource.
+// This includes authentication, and delegation of resource deletion.
+// On success, a 204 No Content is written before the nextHandler is called.
+// If the endpoint implements CORSEndpoint, OPTIONS requests are answered via the OptionsMiddleware.
+func ResourceDeleteHandler(
+	keySet jwk.Set,
+	deleteEndpoint DeleteEndpoint,
+	nextHandler http.Handler,
+) http.Handler {
+	entityMiddleware := EntityUUIDMiddleware(deleteEndpoint.EntityUUID)
+	deleteMiddleware := ResourceDeleteMiddleware(deleteEndpoint.DeleteEntity, deleteEndpoint.HandleError)
+
+	return optionsPreHandler(resourcePreHandler(keySet).Append(
+		entityMiddleware,
+		deleteMiddleware,
+	), deleteEndpoint, http.MethodDelete).Then(
+		nextHandler,
+	)
+}
+
+// --------------------------
+
+// AppendIf extends the given chain with the given constructors, if cond is true.
+// Otherwise, the chain is returned unchanged. This allows for conditionally including
+// middlewares (e.g. optional authentication during development) while composing chains.
+func AppendIf(chain alice.Chain, cond bool, constructors ...alice.Constructor) alice.Chain {
+	if !cond {
+		return chain
+	}
+
+	return chain.Append(constructors...)
+}
+
+// ConstructorIf returns the given constructor, if cond is true. Otherwise, a constructor
+// is returned, which passes through to the next handler.
+// This allows for conditionally including a middleware inline, e.g. within alice.New.
+func ConstructorIf(cond bool, constructor alice.Constructor) alice.Constructor {
+	if cond {
+		return constructor
+	}
+
+	return func(h http.Handler) http.Handler {
+		return h
+	}
+}
+
+// --------------------------
+
 func listPreHandler(
 	keySet jwk.Set,
 ) alice.Chain {
 	pagingMiddleware := PagingMiddleware
 
-	return resourcePreHandler(keySet).Append(pagingMiddleware)
+	return readOnlyPreHandler(resourcePreHandler(keySet)).Append(pagingMiddleware)
+}
+
+func optionsPreHandler(
+	chain alice.Chain,
+	endpoint any,
+	allowed ...string,
+) alice.Chain {
+	if cors, ok := endpoint.(CORSEndpoint); ok {
+		return alice.New(OptionsMiddleware(allowed, cors.CORSOptions()...)).Extend(chain)
+	}
+
+	return chain
+}
+
+func createdPostHandler(
+	chain alice.Chain,
+	endpoint any,
+	errorHandler ErrorHandlerFunc,
+) alice.Chain {
+	if created, ok := endpoint.(CreatedEndpoint); ok {
+		return chain.Append(ResourceCreatedMiddleware(created.CreatedLocation, errorHandler))
+	}
+
+	return chain
+}
+
+func countPreHandler(
+	endpoint any,
+) ([]CountOption, []ListDataOption) {
+	if totalCount, ok := endpoint.(TotalCountColumnEndpoint); ok {
+		// HEAD requests bail out before any rows are fetched, so the count is still queried for them
+		return []CountOption{CountOnlyForMethods(http.MethodHead)}, []ListDataOption{
+			ListDataTotalCountColumn(totalCount.TotalCountColumn()),
+		}
+	}
+
+	return nil, nil
+}
+
+func listTimeoutOptions(
+	endpoint any,
+) []ListDataOption {
+	if timeout, ok := endpoint.(DataTimeoutEndpoint); ok {
+		return []ListDataOption{ListDataTimeout(timeout.DataTimeout())}
+	}
+
+	return nil
+}
+
+func resourceTimeoutOptions(
+	endpoint any,
+) []ResourceDataOption {
+	if timeout, ok := endpoint.(DataTimeoutEndpoint); ok {
+		return []ResourceDataOption{ResourceDataTimeout(timeout.DataTimeout())}
+	}
+
+	return nil
+}
+
+func listJSONAPIOptions(
+	endpoint any,
+) []ListDataOption {
+	if jsonAPI, ok := endpoint.(JSONAPIEndpoint); ok {
+		return []ListDataOption{ListDataJSONAPI(jsonAPI.JSONAPI())}
+	}
+
+	return nil
+}
+
+func listPagingLinksOptions(
+	endpoint any,
+) []ListDataOption {
+	if pagingLinks, ok := endpoint.(PagingLinksEndpoint); ok {
+		return []ListDataOption{ListDataPagingLinks(pagingLinks.PagingLinks())}
+	}
+
+	return nil
+}
+
+func listCSVOptions(
+	endpoint any,
+) []ListDataOption {
+	if csv, ok := endpoint.(CSVEndpoint); ok && csv.CSV() {
+		return []ListDataOption{ListDataSerializer(NewCSVSerializer())}
+	}
+
+	return nil
+}
+
+// csvPreHandler appends the CSVMiddleware, which has to be placed before the cache middleware.
+func csvPreHandler(
+	chain alice.Chain,
+	endpoint any,
+) alice.Chain {
+	if csv, ok := endpoint.(CSVEndpoint); ok && csv.CSV() {
+		return chain.Append(CSVMiddleware)
+	}
+
+	return chain
+}
+
+func resourceJSONAPIOptions(
+	endpoint any,
+) []ResourceDataOption {
+	if jsonAPI, ok := endpoint.(JSONAPIEndpoint); ok {
+		return []ResourceDataOption{ResourceDataJSONAPI(jsonAPI.JSONAPI())}
+	}
+
+	return nil
+}
+
+func jsonContentTypePreHandler(
+	chain alice.Chain,
+	endpoint any,
+) alice.Chain {
+	if jsonContentType, ok := endpoint.(JSONContentTypeEndpoint); ok && jsonContentType.RequireJSONContentType() {
+		return chain.Append(RequireJSONContentTypeMiddleware)
+	}
+
+	return chain
+}
+
+func readOnlyPreHandler(
+	chain alice.Chain,
+) alice.Chain {
+	methodMiddleware := RestrictMethods(http.MethodGet, http.MethodHead)
+
+	return alice.New(methodMiddleware).Extend(chain)
+}
+
+func relatedPreHandler(
+	chain alice.Chain,
+	endpoint any,
+) alice.Chain {
+	if related, ok := endpoint.(RelatedResources); ok {
+		return chain.Append(PreloadMiddleware(related.RelatedResources))
+	}
+
+	return chain
 }
 
+// resourcePreHandler builds the auth chain of all compositions. A CORSMiddleware has to be
+// placed before it, as CORS preflight requests carry no credentials.
 func resourcePreHandler(
 	keySet jwk.Set,
 ) alice.Chain {
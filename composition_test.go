@@ -0,0 +1,363 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type CompositionSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestCompositionSuite(t *testing.T) {
+	suite.Run(t, &CompositionSuite{})
+}
+
+func (s *CompositionSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *CompositionSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func headerMiddleware(value string) alice.Constructor {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("X-Middleware", value)
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (s *CompositionSuite) Test_AppendIf() {
+	s.Run("Included", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		chain := turtleware.AppendIf(alice.New(headerMiddleware("first")), true, headerMiddleware("second"))
+
+		// when
+		chain.Then(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Equal([]string{"first", "second"}, s.response.Header().Values("X-Middleware"))
+	})
+
+	s.Run("Excluded", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		chain := turtleware.AppendIf(alice.New(headerMiddleware("first")), false, headerMiddleware("second"))
+
+		// when
+		chain.Then(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Equal([]string{"first"}, s.response.Header().Values("X-Middleware"))
+	})
+}
+
+func (s *CompositionSuite) Test_ConstructorIf() {
+	s.Run("Included", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		chain := alice.New(
+			turtleware.ConstructorIf(true, headerMiddleware("first")),
+			headerMiddleware("second"),
+		)
+
+		// when
+		chain.Then(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Equal([]string{"first", "second"}, s.response.Header().Values("X-Middleware"))
+	})
+
+	s.Run("Excluded", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		chain := alice.New(
+			turtleware.ConstructorIf(false, headerMiddleware("first")),
+			headerMiddleware("second"),
+		)
+
+		// when
+		chain.Then(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Equal([]string{"second"}, s.response.Header().Values("X-Middleware"))
+	})
+}
+
+type corsGetEndpoint struct{}
+
+func (corsGetEndpoint) EntityUUID(*http.Request) (string, error) {
+	return "", nil
+}
+
+func (corsGetEndpoint) LastModification(context.Context, string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (corsGetEndpoint) FetchEntity(context.Context, string) (TestDataModel, error) {
+	return TestDataModel{}, nil
+}
+
+func (corsGetEndpoint) HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	turtleware.DefaultErrorHandler(ctx, w, r, err)
+}
+
+func (corsGetEndpoint) CORSOptions() []turtleware.CORSOption {
+	return []turtleware.CORSOption{turtleware.CORSAllowedOrigins("https://app.example.com")}
+}
+
+func (s *CompositionSuite) Test_ResourceHandler_Options() {
+	handler := turtleware.ResourceHandler[TestDataModel](jwk.NewSet(), corsGetEndpoint{})
+
+	s.Run("Preflight", func() {
+		// given
+		s.request.Method = http.MethodOptions
+		s.request.Header.Set("Origin", "https://app.example.com")
+		s.request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+		// when
+		handler.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusNoContent, s.response.Code)
+		s.Equal("GET, HEAD, OPTIONS", s.response.Header().Get("Allow"))
+		s.Equal(s.response.Header().Get("Allow"), s.response.Header().Get("Access-Control-Allow-Methods"))
+		s.Equal("https://app.example.com", s.response.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	s.Run("Other_Methods_Unaffected", func() {
+		// given
+		s.request.Method = http.MethodPost
+
+		// when
+		handler.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusMethodNotAllowed, s.response.Code)
+		s.Equal("GET, HEAD", s.response.Header().Get("Allow"))
+	})
+}
+
+type createdCreateEndpoint struct {
+	entityUUID string
+}
+
+func (e createdCreateEndpoint) EntityUUID(*http.Request) (string, error) {
+	return e.entityUUID, nil
+}
+
+func (createdCreateEndpoint) CreateEntity(context.Context, string, string, TestCreateModel) error {
+	return nil
+}
+
+func (createdCreateEndpoint) HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	turtleware.DefaultCreateErrorHandler(ctx, w, r, err)
+}
+
+func (createdCreateEndpoint) CreatedLocation(r *http.Request, entityUUID string) string {
+	return "/things/" + entityUUID
+}
+
+func (s *CompositionSuite) Test_ResourceCreateHandler_Created() {
+	// given
+	privateKey, err := jwk.FromRaw([]byte("secret-passphrase"))
+	s.Require().NoError(err)
+	s.Require().NoError(privateKey.Set(jwk.KeyIDKey, "super-key"))
+	s.Require().NoError(privateKey.Set(jwk.AlgorithmKey, jwa.HS512))
+
+	keySet := jwk.NewSet()
+	s.Require().NoError(keySet.AddKey(privateKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		privateKey,
+		map[string]interface{}{"uuid": s.userUUID},
+		map[string]interface{}{jwk.KeyIDKey: privateKey.KeyID()},
+	)
+
+	s.request = httptest.NewRequest(http.MethodPost, "https://example.com/things", strings.NewReader(`{"SomeString":"test"}`))
+	s.request.Header.Set("Authorization", "Bearer "+token)
+
+	handler := turtleware.ResourceCreateHandler[TestCreateModel](
+		keySet,
+		createdCreateEndpoint{entityUUID: s.entityUUID},
+		&MiddlewareCapture{},
+	)
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusCreated, s.response.Code)
+	s.Equal("/things/"+s.entityUUID, s.response.Header().Get("Location"))
+}
+
+type jsonAPIStaticListEndpoint struct{}
+
+func (jsonAPIStaticListEndpoint) ListHash(context.Context, turtleware.Paging) (string, error) {
+	return "some-hash", nil
+}
+
+func (jsonAPIStaticListEndpoint) TotalCount(context.Context) (uint, error) {
+	return 1, nil
+}
+
+func (jsonAPIStaticListEndpoint) FetchEntities(context.Context, turtleware.Paging) ([]TestDataModel, error) {
+	return []TestDataModel{{SomeString: "test1", SomeInt: 42}}, nil
+}
+
+func (jsonAPIStaticListEndpoint) HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	turtleware.DefaultErrorHandler(ctx, w, r, err)
+}
+
+func (jsonAPIStaticListEndpoint) JSONAPI() bool {
+	return true
+}
+
+func (s *CompositionSuite) Test_StaticListHandler_JSONAPI() {
+	// given
+	privateKey, err := jwk.FromRaw([]byte("secret-passphrase"))
+	s.Require().NoError(err)
+	s.Require().NoError(privateKey.Set(jwk.KeyIDKey, "super-key"))
+	s.Require().NoError(privateKey.Set(jwk.AlgorithmKey, jwa.HS512))
+
+	keySet := jwk.NewSet()
+	s.Require().NoError(keySet.AddKey(privateKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		privateKey,
+		map[string]interface{}{"uuid": s.userUUID},
+		map[string]interface{}{jwk.KeyIDKey: privateKey.KeyID()},
+	)
+
+	s.request.Header.Set("Authorization", "Bearer "+token)
+
+	handler := turtleware.StaticListHandler[TestDataModel](keySet, jsonAPIStaticListEndpoint{})
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.JSONEq(`{"data":[{"SomeString":"test1","SomeInt":42}],"meta":{"total":1}}`, s.response.Body.String())
+}
+
+type csvStaticListEndpoint struct {
+	jsonAPIStaticListEndpoint
+}
+
+func (csvStaticListEndpoint) JSONAPI() bool {
+	return false
+}
+
+func (csvStaticListEndpoint) CSV() bool {
+	return true
+}
+
+func (s *CompositionSuite) Test_StaticListHandler_CSV() {
+	// given
+	privateKey, err := jwk.FromRaw([]byte("secret-passphrase"))
+	s.Require().NoError(err)
+	s.Require().NoError(privateKey.Set(jwk.KeyIDKey, "super-key"))
+	s.Require().NoError(privateKey.Set(jwk.AlgorithmKey, jwa.HS512))
+
+	keySet := jwk.NewSet()
+	s.Require().NoError(keySet.AddKey(privateKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		privateKey,
+		map[string]interface{}{"uuid": s.userUUID},
+		map[string]interface{}{jwk.KeyIDKey: privateKey.KeyID()},
+	)
+
+	s.request.Header.Set("Authorization", "Bearer "+token)
+	s.request.Header.Set("Accept", "text/csv")
+	s.request.Header.Set("If-None-Match", `"some-hash"`)
+
+	handler := turtleware.StaticListHandler[TestDataModel](keySet, csvStaticListEndpoint{})
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal(`"some-hash-csv"`, s.response.Header().Get("Etag"))
+	s.Contains(s.response.Header().Values("Vary"), "Accept")
+	s.Equal("text/csv;charset=utf-8", s.response.Header().Get("Content-Type"))
+	s.Equal("SomeString,SomeInt\ntest1,42\n", s.response.Body.String())
+}
+
+type jsonContentTypeCreateEndpoint struct {
+	createdCreateEndpoint
+}
+
+func (jsonContentTypeCreateEndpoint) RequireJSONContentType() bool {
+	return true
+}
+
+func (s *CompositionSuite) Test_ResourceCreateHandler_JSONContentType() {
+	// given
+	privateKey, err := jwk.FromRaw([]byte("secret-passphrase"))
+	s.Require().NoError(err)
+	s.Require().NoError(privateKey.Set(jwk.KeyIDKey, "super-key"))
+	s.Require().NoError(privateKey.Set(jwk.AlgorithmKey, jwa.HS512))
+
+	keySet := jwk.NewSet()
+	s.Require().NoError(keySet.AddKey(privateKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		privateKey,
+		map[string]interface{}{"uuid": s.userUUID},
+		map[string]interface{}{jwk.KeyIDKey: privateKey.KeyID()},
+	)
+
+	s.request = httptest.NewRequest(http.MethodPost, "https://example.com/things", strings.NewReader(`{"SomeString":"test"}`))
+	s.request.Header.Set("Authorization", "Bearer "+token)
+	s.request.Header.Set("Content-Type", "text/plain")
+
+	nextCapture := &MiddlewareCapture{}
+
+	handler := turtleware.ResourceCreateHandler[TestCreateModel](
+		keySet,
+		jsonContentTypeCreateEndpoint{createdCreateEndpoint{entityUUID: s.entityUUID}},
+		nextCapture,
+	)
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusUnsupportedMediaType, s.response.Code)
+	s.False(nextCapture.Called)
+}
@@ -0,0 +1,402 @@
+package turtleware
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type compressionOptions struct {
+	minSize int
+}
+
+// CompressionOption represents an option for the CompressionMiddleware.
+type CompressionOption func(*compressionOptions)
+
+// CompressionMinSize sets the minimum size of a response body in bytes, for the body
+// to be compressed. Smaller bodies are written uncompressed, as the overhead of
+// compression outweighs its benefits.
+// The default is 1024 bytes.
+func CompressionMinSize(minSize int) CompressionOption {
+	return func(c *compressionOptions) {
+		c.minSize = minSize
+	}
+}
+
+// incompressibleContentTypes are content types, which are already compressed.
+var incompressibleContentTypes = []string{
+	"application/gzip",
+	"application/x-gzip",
+	"application/zip",
+	"application/zstd",
+	"audio/",
+	"image/gif",
+	"image/jpeg",
+	"image/png",
+	"image/webp",
+	"video/",
+}
+
+// CompressionMiddleware is an opt-in http middleware for compressing response bodies with gzip
+// or deflate, as negotiated via the Accept-Encoding header of the request. The Content-Encoding
+// header is set accordingly, and Accept-Encoding is added to the Vary header.
+// Bodies below the minimum size, bodies of already compressed content types, and responses
+// which already carry a Content-Encoding are written as-is. Streamed responses are compressed
+// on the fly, and flushing is passed through.
+// Strong Etags of compressed responses get the encoding appended (e.g. "abc-gzip"), as required by
+// RFC 7232, section 2.3.3. Likewise, that suffix is removed from the If-None-Match and If-Match
+// headers passed on, if it denotes the negotiated encoding - so conditional requests keep working
+// for the compressed representation. Last-Modified headers are kept as-is.
+// If multiple CompressionMiddleware are chained, only the outermost one compresses.
+func CompressionMiddleware(opts ...CompressionOption) func(http.Handler) http.Handler {
+	// default
+	config := &compressionOptions{
+		minSize: 1024,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, alreadyCompressing := w.(*compressWriter); alreadyCompressing {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			addVaryHeader(w.Header(), "Accept-Encoding")
+
+			encoding := negotiateEncoding(r.Header.Values("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: w,
+				encoding:       encoding,
+				ifNoneMatch:    strings.Join(r.Header.Values("If-None-Match"), ", "),
+				minSize:        config.minSize,
+			}
+			defer cw.close()
+
+			r = r.Clone(r.Context())
+			for _, name := range []string{"If-None-Match", "If-Match"} {
+				if values := r.Header.Values(name); len(values) > 0 {
+					r.Header.Set(name, trimEncodedEtags(values, encoding))
+				}
+			}
+
+			h.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// negotiateEncoding returns the preferred encoding ("gzip" or "deflate") of the given
+// Accept-Encoding header values, or an empty string if neither is acceptable.
+func negotiateEncoding(acceptEncodings []string) string {
+	qualities := map[string]float64{}
+
+	for _, acceptEncoding := range acceptEncodings {
+		for _, entry := range strings.Split(acceptEncoding, ",") {
+			coding, params, _ := strings.Cut(entry, ";")
+			coding = strings.ToLower(strings.TrimSpace(coding))
+
+			quality := 1.0
+			for _, param := range strings.Split(params, ";") {
+				name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || strings.ToLower(strings.TrimSpace(name)) != "q" {
+					continue
+				}
+
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					quality = parsed
+				}
+			}
+
+			qualities[coding] = quality
+		}
+	}
+
+	best, bestQuality := "", 0.0
+	for _, coding := range []string{"gzip", "deflate"} {
+		quality, found := qualities[coding]
+		if !found {
+			quality, found = qualities["*"]
+		}
+
+		if found && quality > bestQuality {
+			best, bestQuality = coding, quality
+		}
+	}
+
+	return best
+}
+
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var (
+	gzipWriterPool = sync.Pool{
+		New: func() interface{} {
+			return gzip.NewWriter(io.Discard)
+		},
+	}
+
+	flateWriterPool = sync.Pool{
+		New: func() interface{} {
+			// nolint errcheck: Error is only returned for invalid compression levels
+			writer, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+			return writer
+		},
+	}
+)
+
+// compressWriter is a wrapper for a http.ResponseWriter, which buffers the body until
+// the minimum size is reached, and then decides whether to compress the body.
+// etagEncodingSuffixes are the suffixes, which the CompressionMiddleware appends to strong
+// Etags of compressed responses. A compressed representation must not share the strong
+// Etag of the identity representation (see RFC 7232, section 2.3.3).
+var etagEncodingSuffixes = map[string]string{
+	"gzip":    "-gzip",
+	"deflate": "-deflate",
+}
+
+// encodedEtag returns the given strong Etag with the suffix of the given content encoding
+// (e.g. "abc-gzip"). Weak Etags are returned as-is, as they may be shared by encodings.
+func encodedEtag(etag string, encoding string) string {
+	suffix, found := etagEncodingSuffixes[encoding]
+	if !found {
+		return etag
+	}
+
+	return suffixedEtag(etag, suffix)
+}
+
+// trimEncodedEtags removes the suffix of the given encoding from the entity tags of the given
+// conditional header values, so they match the Etag of the identity representation again.
+// Suffixes of any other encoding are kept, as such an Etag denotes another representation.
+func trimEncodedEtags(values []string, encoding string) string {
+	suffix := etagEncodingSuffixes[encoding] + `"`
+
+	etags := splitEtags(strings.Join(values, ", "))
+	for i, etag := range etags {
+		if trimmed, found := strings.CutSuffix(etag, suffix); found {
+			etags[i] = trimmed + `"`
+		}
+	}
+
+	return strings.Join(etags, ", ")
+}
+
+type compressWriter struct {
+	http.ResponseWriter
+
+	encoding    string
+	ifNoneMatch string
+	minSize     int
+
+	statusCode int
+	buffer     []byte
+	decided    bool
+	compressor compressor
+}
+
+func (w *compressWriter) WriteHeader(statusCode int) {
+	if w.decided {
+		w.ResponseWriter.WriteHeader(statusCode)
+
+		return
+	}
+
+	// Informational responses are followed by the actual response
+	if statusCode < http.StatusOK {
+		w.ResponseWriter.WriteHeader(statusCode)
+
+		return
+	}
+
+	w.statusCode = statusCode
+
+	// A not modified response carries the Etag of the representation the client has
+	if statusCode == http.StatusNotModified && w.acceptsEncodedEtag() {
+		w.encodeEtag()
+	}
+
+	// Responses without body are passed through
+	if statusCode == http.StatusNoContent || statusCode == http.StatusNotModified {
+		// nolint errcheck: Nothing is buffered yet, so nothing can fail
+		_ = w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.compressor != nil {
+			return w.compressor.Write(b)
+		}
+
+		return w.ResponseWriter.Write(b)
+	}
+
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+
+	w.buffer = append(w.buffer, b...)
+
+	if len(w.buffer) >= w.minSize {
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(b), nil
+}
+
+// Flush decides on the compression right away (regardless of the minimum size), as
+// flushing indicates a streamed response. Flushing before anything was written
+// implies a 200, as the underlying writer would send one anyway.
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if w.statusCode == 0 {
+			w.statusCode = http.StatusOK
+		}
+
+		if err := w.decide(true); err != nil {
+			return
+		}
+	}
+
+	if w.compressor != nil {
+		if err := w.compressor.Flush(); err != nil {
+			return
+		}
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// decide writes the header, and the buffered body so far. If compress is set, and the
+// response is eligible for compression, the body is compressed from here on.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+
+	if compress && w.compressible() {
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Encoding", w.encoding)
+		w.encodeEtag()
+
+		if w.encoding == "gzip" {
+			w.compressor = gzipWriterPool.Get().(*gzip.Writer)
+		} else {
+			w.compressor = flateWriterPool.Get().(*flate.Writer)
+		}
+
+		w.compressor.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(w.statusCode)
+
+	buffer := w.buffer
+	w.buffer = nil
+
+	if len(buffer) == 0 {
+		return nil
+	}
+
+	if w.compressor != nil {
+		_, err := w.compressor.Write(buffer)
+		return err
+	}
+
+	_, err := w.ResponseWriter.Write(buffer)
+	return err
+}
+
+func (w *compressWriter) encodeEtag() {
+	if etag := w.Header().Get("Etag"); etag != "" {
+		w.Header().Set("Etag", encodedEtag(etag, w.encoding))
+	}
+}
+
+// acceptsEncodedEtag reports whether the client revalidates a compressed representation,
+// that is, if any Etag of the If-None-Match header carries the suffix of the encoding.
+func (w *compressWriter) acceptsEncodedEtag() bool {
+	suffix := etagEncodingSuffixes[w.encoding] + `"`
+
+	for _, candidate := range splitEtags(w.ifNoneMatch) {
+		if strings.HasSuffix(candidate, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *compressWriter) compressible() bool {
+	if w.statusCode == http.StatusPartialContent {
+		return false
+	}
+
+	if encoding := w.Header().Get("Content-Encoding"); encoding != "" && encoding != "identity" {
+		return false
+	}
+
+	contentType := strings.ToLower(w.Header().Get("Content-Type"))
+	for _, incompressible := range incompressibleContentTypes {
+		if strings.HasPrefix(contentType, incompressible) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// close writes out any remaining buffered body, and finishes the compression.
+func (w *compressWriter) close() {
+	if !w.decided {
+		// Nothing was written at all - leave the response to the server
+		if w.statusCode == 0 {
+			return
+		}
+
+		// nolint errcheck: The client is gone, if writing the remaining body fails
+		_ = w.decide(false)
+	}
+
+	if w.compressor == nil {
+		return
+	}
+
+	// nolint errcheck: The client is gone, if finishing the compression fails
+	_ = w.compressor.Close()
+
+	switch compressor := w.compressor.(type) {
+	case *gzip.Writer:
+		compressor.Reset(io.Discard)
+		gzipWriterPool.Put(compressor)
+	case *flate.Writer:
+		compressor.Reset(io.Discard)
+		flateWriterPool.Put(compressor)
+	}
+
+	w.compressor = nil
+}
@@ -0,0 +1,416 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type CompressionSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestCompressionSuite(t *testing.T) {
+	suite.Run(t, &CompressionSuite{})
+}
+
+func (s *CompressionSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *CompressionSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *CompressionSuite) largeEntity() map[string]string {
+	return map[string]string{
+		"content": strings.Repeat("turtleware ", 200),
+	}
+}
+
+func (s *CompressionSuite) entityHandler(entity interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		turtleware.EmissioneWriter.Write(w, r, http.StatusOK, entity)
+	})
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_Encodings() {
+	cases := map[string]struct {
+		acceptEncoding   string
+		expectedEncoding string
+		decompress       func(io.Reader) (io.Reader, error)
+	}{
+		"Gzip": {
+			acceptEncoding:   "gzip",
+			expectedEncoding: "gzip",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+		"Deflate": {
+			acceptEncoding:   "deflate",
+			expectedEncoding: "deflate",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return flate.NewReader(r), nil
+			},
+		},
+		"Preferred_By_Quality": {
+			acceptEncoding:   "gzip;q=0.5, deflate",
+			expectedEncoding: "deflate",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return flate.NewReader(r), nil
+			},
+		},
+		"Wildcard": {
+			acceptEncoding:   "br, *",
+			expectedEncoding: "gzip",
+			decompress: func(r io.Reader) (io.Reader, error) {
+				return gzip.NewReader(r)
+			},
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			s.request.Header.Set("Accept-Encoding", target.acceptEncoding)
+
+			uncompressed := httptest.NewRecorder()
+			turtleware.EmissioneWriter.Write(uncompressed, s.request, http.StatusOK, s.largeEntity())
+
+			middleware := turtleware.CompressionMiddleware()
+
+			// when
+			middleware(s.entityHandler(s.largeEntity())).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(http.StatusOK, s.response.Code)
+			s.Equal(target.expectedEncoding, s.response.Header().Get("Content-Encoding"))
+			s.Equal("Accept-Encoding", s.response.Header().Get("Vary"))
+			s.Equal("application/json;charset=utf-8", s.response.Header().Get("Content-Type"))
+			s.Less(s.response.Body.Len(), uncompressed.Body.Len())
+
+			reader, err := target.decompress(s.response.Body)
+			s.Require().NoError(err)
+
+			body, err := io.ReadAll(reader)
+			s.Require().NoError(err)
+			s.Equal(uncompressed.Body.String(), string(body))
+		})
+	}
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_Uncompressed() {
+	cases := map[string]struct {
+		acceptEncoding string
+		handler        http.Handler
+		opts           []turtleware.CompressionOption
+	}{
+		"No_Accept_Encoding": {
+			handler: s.entityHandler(s.largeEntity()),
+		},
+		"Rejected_Encoding": {
+			acceptEncoding: "gzip;q=0, deflate;q=0",
+			handler:        s.entityHandler(s.largeEntity()),
+		},
+		"Unsupported_Encoding": {
+			acceptEncoding: "br",
+			handler:        s.entityHandler(s.largeEntity()),
+		},
+		"Below_Min_Size": {
+			acceptEncoding: "gzip",
+			handler:        s.entityHandler(map[string]string{"content": "small"}),
+		},
+		"Below_Configured_Min_Size": {
+			acceptEncoding: "gzip",
+			handler:        s.entityHandler(s.largeEntity()),
+			opts:           []turtleware.CompressionOption{turtleware.CompressionMinSize(1 << 20)},
+		},
+		"Already_Encoded": {
+			acceptEncoding: "gzip",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "br")
+				_, _ = w.Write(bytes.Repeat([]byte("x"), 2048))
+			}),
+		},
+		"Already_Compressed_Stream": {
+			acceptEncoding: "gzip",
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				buffer := &bytes.Buffer{}
+				gzipWriter := gzip.NewWriter(buffer)
+				_, _ = gzipWriter.Write(bytes.Repeat([]byte("x"), 4096))
+				_ = gzipWriter.Close()
+
+				turtleware.StreamResponse(
+					io.MultiReader(buffer, bytes.NewReader(bytes.Repeat([]byte("x"), 2048))),
+					w, r, turtleware.DefaultErrorHandler,
+				)
+			}),
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			if target.acceptEncoding != "" {
+				s.request.Header.Set("Accept-Encoding", target.acceptEncoding)
+			}
+
+			expected := httptest.NewRecorder()
+			target.handler.ServeHTTP(expected, s.request)
+
+			middleware := turtleware.CompressionMiddleware(target.opts...)
+
+			// when
+			middleware(target.handler).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(http.StatusOK, s.response.Code)
+			s.Equal(expected.Header().Get("Content-Encoding"), s.response.Header().Get("Content-Encoding"))
+			s.Equal("Accept-Encoding", s.response.Header().Get("Vary"))
+			s.Equal(expected.Body.String(), s.response.Body.String())
+		})
+	}
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_Chained() {
+	// given
+	s.request.Header.Set("Accept-Encoding", "gzip")
+
+	middleware := turtleware.CompressionMiddleware()
+
+	testChain := alice.New(
+		middleware,
+		middleware,
+	).Then(s.entityHandler(s.largeEntity()))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	reader, err := gzip.NewReader(s.response.Body)
+	s.Require().NoError(err)
+
+	body, err := io.ReadAll(reader)
+	s.Require().NoError(err)
+	s.Contains(string(body), "turtleware")
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_Stream() {
+	// given
+	s.request.Header.Set("Accept-Encoding", "gzip")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		turtleware.StreamResponse(strings.NewReader("some streamed text"), w, r, turtleware.DefaultErrorHandler)
+		w.(http.Flusher).Flush()
+	})
+
+	middleware := turtleware.CompressionMiddleware()
+
+	// when
+	middleware(handler).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal("gzip", s.response.Header().Get("Content-Encoding"))
+	s.True(s.response.Flushed)
+
+	reader, err := gzip.NewReader(s.response.Body)
+	s.Require().NoError(err)
+
+	body, err := io.ReadAll(reader)
+	s.Require().NoError(err)
+	s.Equal("some streamed text", string(body))
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_Flush_Before_Write() {
+	// given
+	s.request.Header.Set("Accept-Encoding", "gzip")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.(http.Flusher).Flush()
+
+		_, err := w.Write([]byte("some streamed text"))
+		s.Require().NoError(err)
+	})
+
+	middleware := turtleware.CompressionMiddleware()
+
+	// when
+	middleware(handler).ServeHTTP(s.response, s.request)
+
+	// then
+	result := s.response.Result()
+	s.Equal(http.StatusOK, result.StatusCode)
+	s.Equal("gzip", result.Header.Get("Content-Encoding"))
+	s.True(s.response.Flushed)
+
+	reader, err := gzip.NewReader(s.response.Body)
+	s.Require().NoError(err)
+
+	body, err := io.ReadAll(reader)
+	s.Require().NoError(err)
+	s.Equal("some streamed text", string(body))
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_Head() {
+	// given
+	s.request.Method = http.MethodHead
+	s.request.Header.Set("Accept-Encoding", "gzip")
+
+	nextCapture := &MiddlewareCapture{}
+
+	middleware := turtleware.CompressionMiddleware()
+
+	// when
+	middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Empty(s.response.Header().Get("Content-Encoding"))
+	s.Empty(s.response.Body.String())
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_ResourceCacheMiddleware() {
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	lastModFunc := func(ctx context.Context, entityUUID string) (time.Time, error) {
+		return lastModified, nil
+	}
+
+	s.Run("Modified", func() {
+		// given
+		s.request.Header.Set("Accept-Encoding", "gzip")
+
+		testChain := alice.New(
+			turtleware.CompressionMiddleware(),
+			s.buildEntityUUIDChain,
+			turtleware.ResourceCacheMiddleware(lastModFunc, turtleware.DefaultErrorHandler),
+		).Then(s.entityHandler(s.largeEntity()))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusOK, s.response.Code)
+		s.Equal("gzip", s.response.Header().Get("Content-Encoding"))
+		s.Equal(lastModified.Format(time.RFC1123), s.response.Header().Get("Last-Modified"))
+	})
+
+	s.Run("Not_Modified", func() {
+		// given
+		s.request.Header.Set("Accept-Encoding", "gzip")
+		s.request.Header.Set("If-Modified-Since", lastModified.Format(time.RFC1123))
+
+		testChain := alice.New(
+			turtleware.CompressionMiddleware(),
+			s.buildEntityUUIDChain,
+			turtleware.ResourceCacheMiddleware(lastModFunc, turtleware.DefaultErrorHandler),
+		).Then(s.entityHandler(s.largeEntity()))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusNotModified, s.response.Code)
+		s.Empty(s.response.Header().Get("Content-Encoding"))
+		s.Empty(s.response.Body.String())
+	})
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_Etag() {
+	hashFetcher := func(ctx context.Context, paging turtleware.Paging) (string, error) {
+		return "some-hash", nil
+	}
+
+	cases := map[string]struct {
+		acceptEncoding string
+		ifNoneMatch    string
+		expectedStatus int
+		expectedEtag   string
+	}{
+		"Compressed":            {acceptEncoding: "gzip", expectedStatus: http.StatusOK, expectedEtag: `"some-hash-gzip"`},
+		"Identity":              {expectedStatus: http.StatusOK, expectedEtag: `"some-hash"`},
+		"Not_Modified":          {acceptEncoding: "gzip", ifNoneMatch: `"some-hash-gzip"`, expectedStatus: http.StatusNotModified, expectedEtag: `"some-hash-gzip"`},
+		"Not_Modified_Identity": {acceptEncoding: "gzip", ifNoneMatch: `"some-hash"`, expectedStatus: http.StatusNotModified, expectedEtag: `"some-hash"`},
+		"Other_Encoding":        {acceptEncoding: "deflate", ifNoneMatch: `"some-hash-gzip"`, expectedStatus: http.StatusOK, expectedEtag: `"some-hash-deflate"`},
+		"Suffixed_Identity":     {ifNoneMatch: `"some-hash-gzip"`, expectedStatus: http.StatusOK, expectedEtag: `"some-hash"`},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			if target.acceptEncoding != "" {
+				s.request.Header.Set("Accept-Encoding", target.acceptEncoding)
+			}
+			if target.ifNoneMatch != "" {
+				s.request.Header.Set("If-None-Match", target.ifNoneMatch)
+			}
+
+			testChain := alice.New(
+				turtleware.CompressionMiddleware(),
+				turtleware.PagingMiddleware,
+				turtleware.ListCacheMiddleware(hashFetcher, turtleware.DefaultErrorHandler),
+			).Then(s.entityHandler(s.largeEntity()))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedStatus, s.response.Code)
+			s.Equal(target.expectedEtag, s.response.Header().Get("Etag"))
+		})
+	}
+}
+
+func (s *CompressionSuite) Test_CompressionMiddleware_IfMatch() {
+	cases := map[string]struct {
+		acceptEncoding string
+		ifMatch        string
+		expected       string
+	}{
+		"Encoded":        {acceptEncoding: "gzip", ifMatch: `"some-hash-gzip"`, expected: "some-hash"},
+		"Identity":       {acceptEncoding: "gzip", ifMatch: `"some-hash"`, expected: "some-hash"},
+		"Other_Encoding": {acceptEncoding: "deflate", ifMatch: `"some-hash-gzip"`, expected: "some-hash-gzip"},
+		"Not_Encoded":    {ifMatch: `"some-hash-gzip"`, expected: "some-hash-gzip"},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			if target.acceptEncoding != "" {
+				s.request.Header.Set("Accept-Encoding", target.acceptEncoding)
+			}
+			s.request.Header.Set("If-Match", target.ifMatch)
+
+			var ifMatch string
+			testChain := alice.New(
+				turtleware.CompressionMiddleware(),
+			).ThenFunc(func(w http.ResponseWriter, r *http.Request) {
+				ifMatch, _ = turtleware.GetIfMatch(r)
+			})
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expected, ifMatch)
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package turtleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrCORSWildcardCredentials indicates that credentials were allowed for CORS requests
+// from any origin, which would expose credentialed responses to every website.
+var ErrCORSWildcardCredentials = errors.New("CORS credentials require an explicit list of allowed origins")
+
+type corsOptions struct {
+	allowedOrigins   []string
+	allowedMethods   []string
+	allowedHeaders   []string
+	exposedHeaders   []string
+	allowCredentials bool
+	maxAge           time.Duration
+}
+
+// CORSOption represents an option for handling CORS requests.
+type CORSOption func(*corsOptions)
+
+// CORSAllowedOrigins sets the origins which are allowed to access the resource.
+// An origin of "*" allows access from any origin.
+// The default is "*".
+func CORSAllowedOrigins(origins ...string) CORSOption {
+	return func(c *corsOptions) {
+		c.allowedOrigins = origins
+	}
+}
+
+// CORSAllowedMethods sets the methods a client may use when accessing the resource.
+// The option is only used by the CORSMiddleware, as the OptionsMiddleware announces
+// the methods of the handler it belongs to.
+// The default is GET, HEAD, POST, PUT, PATCH and DELETE.
+func CORSAllowedMethods(methods ...string) CORSOption {
+	return func(c *corsOptions) {
+		c.allowedMethods = methods
+	}
+}
+
+// CORSAllowedHeaders sets the request headers a client may use when accessing the resource.
+// The default is Authorization, Content-Type, and the conditional request headers.
+func CORSAllowedHeaders(headers ...string) CORSOption {
+	return func(c *corsOptions) {
+		c.allowedHeaders = headers
+	}
+}
+
+// CORSExposedHeaders sets the response headers a client may read when accessing the resource.
+// The default is X-Total-Count, Etag, Location and Link.
+func CORSExposedHeaders(headers ...string) CORSOption {
+	return func(c *corsOptions) {
+		c.exposedHeaders = headers
+	}
+}
+
+// CORSAllowCredentials sets whether the client may include credentials (e.g. cookies or
+// the Authorization header) when accessing the resource. Credentials require an explicit
+// list of allowed origins (see CORSAllowedOrigins) - combined with the "*" origin, the
+// middlewares panic with ErrCORSWildcardCredentials on construction.
+// The default is false.
+func CORSAllowCredentials(allowCredentials bool) CORSOption {
+	return func(c *corsOptions) {
+		c.allowCredentials = allowCredentials
+	}
+}
+
+// CORSMaxAge sets how long the result of a preflight request may be cached by the client.
+// A max age of zero omits the Access-Control-Max-Age header.
+// The default is ten minutes.
+func CORSMaxAge(maxAge time.Duration) CORSOption {
+	return func(c *corsOptions) {
+		c.maxAge = maxAge
+	}
+}
+
+// newCORSOptions applies the given options to the defaults, and panics with
+// ErrCORSWildcardCredentials if credentials are allowed for any origin.
+func newCORSOptions(opts ...CORSOption) *corsOptions {
+	// default
+	config := &corsOptions{
+		allowedOrigins: []string{"*"},
+		allowedMethods: []string{
+			http.MethodGet,
+			http.MethodHead,
+			http.MethodPost,
+			http.MethodPut,
+			http.MethodPatch,
+			http.MethodDelete,
+		},
+		allowedHeaders: []string{
+			"Authorization",
+			"Content-Type",
+			"If-Match",
+			"If-None-Match",
+			"If-Modified-Since",
+			"If-Unmodified-Since",
+		},
+		exposedHeaders: []string{
+			"X-Total-Count",
+			"Etag",
+			"Location",
+			"Link",
+		},
+		allowCredentials: false,
+		maxAge:           10 * time.Minute,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.allowCredentials && slices.Contains(config.allowedOrigins, "*") {
+		panic(ErrCORSWildcardCredentials)
+	}
+
+	return config
+}
+
+// allowedOrigin returns the value for the Access-Control-Allow-Origin header
+// for the given origin, or an empty string if the origin is not allowed.
+func (c *corsOptions) allowedOrigin(origin string) string {
+	if slices.Contains(c.allowedOrigins, "*") {
+		return "*"
+	}
+
+	if slices.Contains(c.allowedOrigins, origin) {
+		return origin
+	}
+
+	return ""
+}
+
+// setOriginHeaders sets the Access-Control-Allow-Origin (and -Credentials) header for the
+// given origin, and reports whether the origin is allowed at all.
+func (c *corsOptions) setOriginHeaders(header http.Header, origin string) bool {
+	allowedOrigin := c.allowedOrigin(origin)
+	if origin == "" || allowedOrigin == "" {
+		return false
+	}
+
+	if allowedOrigin != "*" {
+		addVaryHeader(header, "Origin")
+	}
+
+	header.Set("Access-Control-Allow-Origin", allowedOrigin)
+
+	if c.allowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	return true
+}
+
+func (c *corsOptions) setPreflightHeaders(header http.Header, allowMethodsHeader string, allowHeadersHeader string) {
+	header.Set("Access-Control-Allow-Methods", allowMethodsHeader)
+
+	if allowHeadersHeader != "" {
+		header.Set("Access-Control-Allow-Headers", allowHeadersHeader)
+	}
+
+	if c.maxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.maxAge.Seconds())))
+	}
+}
+
+// OptionsMiddleware is a http middleware for answering OPTIONS requests to a handler supporting
+// the given methods. OPTIONS requests are answered early with a 204, and an Allow header listing
+// the allowed methods. If the request is a CORS preflight request from an allowed origin, the
+// Access-Control-Allow-* headers are set in the same response, with Access-Control-Allow-Methods
+// matching the Allow header. All other requests are passed through.
+// As preflight requests carry no credentials, the middleware belongs before any auth middleware.
+func OptionsMiddleware(allowed []string, opts ...CORSOption) func(http.Handler) http.Handler {
+	config := newCORSOptions(opts...)
+
+	methods := allowed
+	if !slices.Contains(methods, http.MethodOptions) {
+		methods = append(slices.Clone(methods), http.MethodOptions)
+	}
+
+	allowHeader := strings.Join(methods, ", ")
+	allowHeadersHeader := strings.Join(config.allowedHeaders, ", ")
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodOptions {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Set("Allow", allowHeader)
+
+			origin := r.Header.Get("Origin")
+			isPreflight := origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if isPreflight && config.setOriginHeaders(w.Header(), origin) {
+				config.setPreflightHeaders(w.Header(), allowHeader, allowHeadersHeader)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// CORSMiddleware is a http middleware for handling CORS requests of browser clients.
+// CORS preflight requests (OPTIONS requests with an Origin and Access-Control-Request-Method
+// header) are answered early with a 204, carrying the configured Access-Control-Allow-* headers.
+// For all other requests from an allowed origin, the Access-Control-Allow-Origin (and -Credentials)
+// and Access-Control-Expose-Headers headers are set, before the request is passed through.
+// As preflight requests carry no credentials, the middleware belongs before the auth middlewares
+// (AuthBearerHeaderMiddleware and AuthClaimsMiddleware), e.g. by wrapping a composed handler such
+// as ResourceHandler.
+func CORSMiddleware(opts ...CORSOption) func(http.Handler) http.Handler {
+	config := newCORSOptions(opts...)
+
+	allowMethodsHeader := strings.Join(config.allowedMethods, ", ")
+	allowHeadersHeader := strings.Join(config.allowedHeaders, ", ")
+	exposeHeadersHeader := strings.Join(config.exposedHeaders, ", ")
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			allowed := config.setOriginHeaders(w.Header(), origin)
+
+			isPreflight := r.Method == http.MethodOptions &&
+				origin != "" &&
+				r.Header.Get("Access-Control-Request-Method") != ""
+
+			if !isPreflight {
+				if allowed && exposeHeadersHeader != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposeHeadersHeader)
+				}
+
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			if allowed {
+				config.setPreflightHeaders(w.Header(), allowMethodsHeader, allowHeadersHeader)
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// CORSEndpoint may optionally be implemented by an endpoint, to answer OPTIONS requests
+// (including CORS preflight requests) within the composed handler via the OptionsMiddleware.
+type CORSEndpoint interface {
+	CORSOptions() []CORSOption
+}
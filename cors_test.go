@@ -0,0 +1,331 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type CORSSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestCORSSuite(t *testing.T) {
+	suite.Run(t, &CORSSuite{})
+}
+
+func (s *CORSSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodOptions, "https://example.com/foo", http.NoBody)
+}
+
+func (s *CORSSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *CORSSuite) Test_OptionsMiddleware_Preflight() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	s.request.Header.Set("Origin", "https://app.example.com")
+	s.request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+	middleware := turtleware.OptionsMiddleware([]string{http.MethodGet, http.MethodHead})
+
+	// when
+	middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusNoContent, s.response.Code)
+	s.Equal("GET, HEAD, OPTIONS", s.response.Header().Get("Allow"))
+	s.Equal("GET, HEAD, OPTIONS", s.response.Header().Get("Access-Control-Allow-Methods"))
+	s.Equal("*", s.response.Header().Get("Access-Control-Allow-Origin"))
+	s.Equal(
+		"Authorization, Content-Type, If-Match, If-None-Match, If-Modified-Since, If-Unmodified-Since",
+		s.response.Header().Get("Access-Control-Allow-Headers"),
+	)
+	s.Equal("600", s.response.Header().Get("Access-Control-Max-Age"))
+}
+
+func (s *CORSSuite) Test_OptionsMiddleware_Configured() {
+	// given
+	middleware := turtleware.OptionsMiddleware(
+		[]string{http.MethodPatch},
+		turtleware.CORSAllowedOrigins("https://app.example.com"),
+		turtleware.CORSAllowedHeaders("Authorization"),
+		turtleware.CORSMaxAge(0),
+	)
+
+	s.Run("Allowed_Origin", func() {
+		// given
+		s.request.Header.Set("Origin", "https://app.example.com")
+		s.request.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+
+		// when
+		middleware(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusNoContent, s.response.Code)
+		s.Equal("PATCH, OPTIONS", s.response.Header().Get("Allow"))
+		s.Equal("PATCH, OPTIONS", s.response.Header().Get("Access-Control-Allow-Methods"))
+		s.Equal("https://app.example.com", s.response.Header().Get("Access-Control-Allow-Origin"))
+		s.Equal("Authorization", s.response.Header().Get("Access-Control-Allow-Headers"))
+		s.Equal("Origin", s.response.Header().Get("Vary"))
+		s.Empty(s.response.Header().Get("Access-Control-Max-Age"))
+	})
+
+	s.Run("Disallowed_Origin", func() {
+		// given
+		s.request.Header.Set("Origin", "https://evil.example.com")
+		s.request.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+
+		// when
+		middleware(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusNoContent, s.response.Code)
+		s.Equal("PATCH, OPTIONS", s.response.Header().Get("Allow"))
+		s.Empty(s.response.Header().Get("Access-Control-Allow-Methods"))
+		s.Empty(s.response.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
+
+func (s *CORSSuite) Test_OptionsMiddleware_PlainOptions() {
+	// given
+	middleware := turtleware.OptionsMiddleware([]string{http.MethodGet, http.MethodOptions}, turtleware.CORSMaxAge(time.Minute))
+
+	// when
+	middleware(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusNoContent, s.response.Code)
+	s.Equal("GET, OPTIONS", s.response.Header().Get("Allow"))
+	s.Empty(s.response.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func (s *CORSSuite) Test_OptionsMiddleware_PassThrough() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	s.request.Method = http.MethodGet
+
+	// when
+	turtleware.OptionsMiddleware([]string{http.MethodGet})(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Empty(s.response.Header().Get("Allow"))
+}
+
+func (s *CORSSuite) Test_CORSMiddleware_Preflight() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	s.request.Header.Set("Origin", "https://app.example.com")
+	s.request.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+
+	middleware := turtleware.CORSMiddleware()
+
+	// when
+	middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusNoContent, s.response.Code)
+	s.Equal("*", s.response.Header().Get("Access-Control-Allow-Origin"))
+	s.Equal("GET, HEAD, POST, PUT, PATCH, DELETE", s.response.Header().Get("Access-Control-Allow-Methods"))
+	s.Equal(
+		"Authorization, Content-Type, If-Match, If-None-Match, If-Modified-Since, If-Unmodified-Since",
+		s.response.Header().Get("Access-Control-Allow-Headers"),
+	)
+	s.Equal("600", s.response.Header().Get("Access-Control-Max-Age"))
+	s.Empty(s.response.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func (s *CORSSuite) Test_CORSMiddleware_Preflight_Configured() {
+	cases := map[string]struct {
+		origin          string
+		opts            []turtleware.CORSOption
+		expectedOrigin  string
+		expectedMethods string
+		expectedCreds   string
+	}{
+		"Allowed_Origin": {
+			origin: "https://app.example.com",
+			opts: []turtleware.CORSOption{
+				turtleware.CORSAllowedOrigins("https://app.example.com"),
+				turtleware.CORSAllowedMethods(http.MethodGet, http.MethodPost),
+			},
+			expectedOrigin:  "https://app.example.com",
+			expectedMethods: "GET, POST",
+		},
+		"Disallowed_Origin": {
+			origin: "https://evil.example.com",
+			opts: []turtleware.CORSOption{
+				turtleware.CORSAllowedOrigins("https://app.example.com"),
+			},
+		},
+		"Credentials": {
+			origin: "https://app.example.com",
+			opts: []turtleware.CORSOption{
+				turtleware.CORSAllowedOrigins("https://app.example.com"),
+				turtleware.CORSAllowCredentials(true),
+			},
+			expectedOrigin:  "https://app.example.com",
+			expectedMethods: "GET, HEAD, POST, PUT, PATCH, DELETE",
+			expectedCreds:   "true",
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			s.request.Header.Set("Origin", target.origin)
+			s.request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+			middleware := turtleware.CORSMiddleware(target.opts...)
+
+			// when
+			middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.Equal(http.StatusNoContent, s.response.Code)
+			s.Equal(target.expectedOrigin, s.response.Header().Get("Access-Control-Allow-Origin"))
+			s.Equal(target.expectedMethods, s.response.Header().Get("Access-Control-Allow-Methods"))
+			s.Equal(target.expectedCreds, s.response.Header().Get("Access-Control-Allow-Credentials"))
+		})
+	}
+}
+
+func (s *CORSSuite) Test_CORSMiddleware_ActualRequest() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	s.request.Method = http.MethodGet
+	s.request.Header.Set("Origin", "https://app.example.com")
+
+	middleware := turtleware.CORSMiddleware(
+		turtleware.CORSAllowedOrigins("https://app.example.com"),
+		turtleware.CORSAllowCredentials(true),
+	)
+
+	// when
+	middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Equal("https://app.example.com", s.response.Header().Get("Access-Control-Allow-Origin"))
+	s.Equal("true", s.response.Header().Get("Access-Control-Allow-Credentials"))
+	s.Equal("Origin", s.response.Header().Get("Vary"))
+	s.Equal("X-Total-Count, Etag, Location, Link", s.response.Header().Get("Access-Control-Expose-Headers"))
+	s.Empty(s.response.Header().Get("Access-Control-Allow-Methods"))
+}
+
+func (s *CORSSuite) Test_CORSMiddleware_ExposedHeaders() {
+	cases := map[string]struct {
+		origin   string
+		opts     []turtleware.CORSOption
+		expected string
+	}{
+		"Configured": {
+			origin:   "https://app.example.com",
+			opts:     []turtleware.CORSOption{turtleware.CORSExposedHeaders("X-Custom")},
+			expected: "X-Custom",
+		},
+		"None": {
+			origin: "https://app.example.com",
+			opts:   []turtleware.CORSOption{turtleware.CORSExposedHeaders()},
+		},
+		"Disallowed_Origin": {
+			origin: "https://evil.example.com",
+			opts:   []turtleware.CORSOption{turtleware.CORSAllowedOrigins("https://app.example.com")},
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			s.request.Method = http.MethodGet
+			s.request.Header.Set("Origin", target.origin)
+
+			// when
+			turtleware.CORSMiddleware(target.opts...)(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expected, s.response.Header().Get("Access-Control-Expose-Headers"))
+		})
+	}
+}
+
+func (s *CORSSuite) Test_CORSMiddleware_WildcardCredentials() {
+	s.PanicsWithValue(turtleware.ErrCORSWildcardCredentials, func() {
+		turtleware.CORSMiddleware(turtleware.CORSAllowCredentials(true))
+	})
+
+	s.PanicsWithValue(turtleware.ErrCORSWildcardCredentials, func() {
+		turtleware.OptionsMiddleware(
+			[]string{http.MethodGet},
+			turtleware.CORSAllowedOrigins("https://app.example.com", "*"),
+			turtleware.CORSAllowCredentials(true),
+		)
+	})
+}
+
+func (s *CORSSuite) Test_CORSMiddleware_PassThrough() {
+	cases := map[string]func(r *http.Request){
+		"No_Origin": func(r *http.Request) {
+			r.Method = http.MethodGet
+		},
+		"Plain_Options": func(r *http.Request) {
+			r.Header.Set("Origin", "https://app.example.com")
+		},
+	}
+
+	for testName, prepare := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			prepare(s.request)
+
+			middleware := turtleware.CORSMiddleware()
+
+			// when
+			middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+			// then
+			s.True(nextCapture.Called)
+			s.Empty(s.response.Header().Get("Access-Control-Allow-Methods"))
+		})
+	}
+}
+
+func (s *CORSSuite) Test_CORSMiddleware_BeforeAuth() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	s.request.Header.Set("Origin", "https://app.example.com")
+	s.request.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+	handler := turtleware.CORSMiddleware()(turtleware.AuthBearerHeaderMiddleware(nextCapture))
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusNoContent, s.response.Code)
+	s.Equal("*", s.response.Header().Get("Access-Control-Allow-Origin"))
+}
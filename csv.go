@@ -0,0 +1,480 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"encoding"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type csvOptions struct {
+	fallback     ResponseSerializer
+	fileNameFunc func(r *http.Request) string
+}
+
+// CSVOption represents an option for the NewCSVSerializer.
+type CSVOption func(*csvOptions)
+
+// CSVFallback sets the ResponseSerializer used for all requests, which do not prefer CSV.
+// The default is the EmissioneWriter.
+func CSVFallback(fallback ResponseSerializer) CSVOption {
+	return func(c *csvOptions) {
+		c.fallback = fallback
+	}
+}
+
+// CSVFileName sets the function for deriving the file name of the Content-Disposition header.
+// The default is the last segment of the request path, suffixed with .csv.
+func CSVFileName(fileNameFunc func(r *http.Request) string) CSVOption {
+	return func(c *csvOptions) {
+		c.fileNameFunc = fileNameFunc
+	}
+}
+
+type csvSerializer struct {
+	fallback     ResponseSerializer
+	fileNameFunc func(r *http.Request) string
+}
+
+// NewCSVSerializer creates a ResponseSerializer, which serializes lists as CSV, if the request
+// prefers text/csv via its Accept header. Any other request is passed to the fallback serializer.
+// Columns are derived from the fields of the list elements. The header of a column is taken from
+// the csv struct tag, the json struct tag, or the field name (in that order), and fields tagged
+// with "-" are skipped. Nested values (such as structs, maps and slices) are serialized as JSON.
+// Text cells starting with a formula character (=, +, -, @, tab or carriage return) are prefixed
+// with a single quote, so spreadsheet applications do not evaluate them.
+// As the representation depends on the Accept header, it is added to the Vary header of all responses.
+// If the list cannot be serialized, ErrMarshalling is written as an error instead.
+// CSV is opt-in per list (see ListDataSerializer), and requires a CSVMiddleware before any
+// cache middleware, so the CSV and JSON representations are cached apart.
+func NewCSVSerializer(opts ...CSVOption) ResponseSerializer {
+	// default
+	config := &csvOptions{
+		fallback:     nil,
+		fileNameFunc: defaultCSVFileName,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return &csvSerializer{
+		fallback:     config.fallback,
+		fileNameFunc: config.fileNameFunc,
+	}
+}
+
+func defaultCSVFileName(r *http.Request) string {
+	name := path.Base(strings.TrimSuffix(r.URL.Path, "/"))
+	if name == "." || name == "/" || name == "" {
+		name = "export"
+	}
+
+	return name + ".csv"
+}
+
+func (s *csvSerializer) Write(w http.ResponseWriter, r *http.Request, code int, i interface{}) {
+	addVaryHeader(w.Header(), "Accept")
+
+	if !prefersMediaType(r.Header.Values("Accept"), "text/csv") {
+		orEmissioneWriter(s.fallback).Write(w, r, code, i)
+
+		return
+	}
+
+	buffer := ResponseBufferPool.Get()
+	defer ResponseBufferPool.Put(buffer)
+
+	csvWriter := csv.NewWriter(buffer)
+	if err := writeCSV(csvWriter, i); err != nil {
+		WriteError(r.Context(), w, r, http.StatusInternalServerError, fmt.Errorf("%w: %w", ErrMarshalling, err))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv;charset=utf-8")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": s.fileNameFunc(r),
+	}))
+	w.WriteHeader(code)
+
+	if _, err := buffer.WriteTo(w); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to write CSV response")
+	}
+}
+
+// csvEtagSuffix is the suffix, which the CSVMiddleware appends to strong Etags of CSV responses.
+const csvEtagSuffix = "-csv"
+
+// CSVMiddleware is a middleware for lists serialized via NewCSVSerializer, which has to be placed
+// before any cache middleware. It adds Accept to the Vary header right away, so responses the cache
+// middlewares answer themselves (such as a 304 Not Modified) vary on Accept, too.
+// For requests preferring text/csv, strong Etags get "-csv" appended, as the CSV representation must
+// not share the strong Etag of the JSON representation (see RFC 7232, section 2.3.3). Likewise, only
+// Etags of the If-None-Match header carrying that suffix are passed on to the cache middlewares,
+// without the suffix.
+func CSVMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addVaryHeader(w.Header(), "Accept")
+
+		if !prefersMediaType(r.Header.Values("Accept"), "text/csv") {
+			h.ServeHTTP(w, r)
+
+			return
+		}
+
+		r = r.Clone(r.Context())
+		if ifNoneMatch := csvIfNoneMatch(r.Header.Values("If-None-Match")); ifNoneMatch != "" {
+			r.Header.Set("If-None-Match", ifNoneMatch)
+		} else {
+			r.Header.Del("If-None-Match")
+		}
+
+		h.ServeHTTP(&csvEtagWriter{ResponseWriter: w}, r)
+	})
+}
+
+// csvIfNoneMatch returns the Etags of the given If-None-Match header values, which carry the suffix
+// of the CSV representation, without that suffix. Any other Etag cannot match the CSV representation.
+func csvIfNoneMatch(ifNoneMatch []string) string {
+	var etags []string
+
+	for _, etag := range splitEtags(strings.Join(ifNoneMatch, ", ")) {
+		if etag == "*" {
+			etags = append(etags, etag)
+
+			continue
+		}
+
+		if trimmed, found := strings.CutSuffix(etag, csvEtagSuffix+`"`); found {
+			etags = append(etags, trimmed+`"`)
+		}
+	}
+
+	return strings.Join(etags, ", ")
+}
+
+// csvEtagWriter is a wrapper for a http.ResponseWriter, which appends the suffix of the
+// CSV representation to a strong Etag, as soon as the response header is written.
+type csvEtagWriter struct {
+	http.ResponseWriter
+
+	wroteHeader bool
+}
+
+func (w *csvEtagWriter) WriteHeader(statusCode int) {
+	// Informational responses are followed by the actual response
+	if statusCode >= http.StatusOK {
+		w.suffixEtag()
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *csvEtagWriter) Write(b []byte) (int, error) {
+	w.suffixEtag()
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *csvEtagWriter) Flush() {
+	w.suffixEtag()
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *csvEtagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *csvEtagWriter) suffixEtag() {
+	if w.wroteHeader {
+		return
+	}
+
+	w.wroteHeader = true
+
+	if etag := w.Header().Get("Etag"); etag != "" {
+		w.Header().Set("Etag", suffixedEtag(etag, csvEtagSuffix))
+	}
+}
+
+func writeCSV(csvWriter *csv.Writer, data interface{}) error {
+	list := reflect.ValueOf(data)
+	for list.Kind() == reflect.Pointer || list.Kind() == reflect.Interface {
+		list = list.Elem()
+	}
+
+	if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+		return fmt.Errorf("cannot serialize %T as CSV", data)
+	}
+
+	rows := make([]reflect.Value, list.Len())
+	for i := range rows {
+		rows[i] = indirectValue(list.Index(i))
+	}
+
+	columns, err := csvColumns(list.Type().Elem(), rows)
+	if err != nil {
+		return err
+	}
+
+	header := make([]string, len(columns))
+	for i, column := range columns {
+		header[i] = escapeCSVFormula(column.name)
+	}
+
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			value, err := csvFieldString(column.value(row))
+			if err != nil {
+				return fmt.Errorf("column %s: %w", column.name, err)
+			}
+
+			record[i] = value
+		}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+type csvColumn struct {
+	name  string
+	value func(row reflect.Value) reflect.Value
+}
+
+// csvColumns derives the columns from the element type of the list. For interface elements
+// (e.g. maps created by FilterFields), the columns are derived from the rows themselves.
+func csvColumns(elemType reflect.Type, rows []reflect.Value) ([]csvColumn, error) {
+	for elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+
+	if elemType.Kind() == reflect.Interface {
+		for _, row := range rows {
+			if row.IsValid() {
+				elemType = row.Type()
+
+				break
+			}
+		}
+	}
+
+	switch {
+	case elemType.Kind() == reflect.Struct && !isCSVScalar(elemType):
+		return csvStructColumns(elemType, nil), nil
+	case elemType.Kind() == reflect.Map:
+		if elemType.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("cannot serialize map with %s keys as CSV", elemType.Key())
+		}
+
+		return csvMapColumns(rows), nil
+	default:
+		return []csvColumn{{
+			name:  "value",
+			value: func(row reflect.Value) reflect.Value { return row },
+		}}, nil
+	}
+}
+
+func csvStructColumns(structType reflect.Type, index []int) []csvColumn {
+	var columns []csvColumn
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		name, skip := csvColumnName(field)
+		if skip {
+			continue
+		}
+
+		// Fields of embedded structs are promoted, the same as for encoding/json
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !hasCSVName(field) {
+			columns = append(columns, csvStructColumns(field.Type, fieldIndex)...)
+
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		columns = append(columns, csvColumn{
+			name: name,
+			value: func(row reflect.Value) reflect.Value {
+				if !row.IsValid() {
+					return row
+				}
+
+				return row.FieldByIndex(fieldIndex)
+			},
+		})
+	}
+
+	return columns
+}
+
+func csvMapColumns(rows []reflect.Value) []csvColumn {
+	keys := map[string]struct{}{}
+	for _, row := range rows {
+		if !row.IsValid() || row.Kind() != reflect.Map {
+			continue
+		}
+
+		for _, key := range row.MapKeys() {
+			keys[key.String()] = struct{}{}
+		}
+	}
+
+	names := make([]string, 0, len(keys))
+	for key := range keys {
+		names = append(names, key)
+	}
+
+	sort.Strings(names)
+
+	columns := make([]csvColumn, len(names))
+	for i, name := range names {
+		columns[i] = csvColumn{
+			name: name,
+			value: func(row reflect.Value) reflect.Value {
+				if !row.IsValid() || row.Kind() != reflect.Map {
+					return reflect.Value{}
+				}
+
+				return row.MapIndex(reflect.ValueOf(name).Convert(row.Type().Key()))
+			},
+		}
+	}
+
+	return columns
+}
+
+func csvColumnName(field reflect.StructField) (string, bool) {
+	for _, tagName := range []string{"csv", "json"} {
+		tag, found := field.Tag.Lookup(tagName)
+		if !found {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", true
+		}
+
+		if name != "" {
+			return name, false
+		}
+	}
+
+	return field.Name, false
+}
+
+func hasCSVName(field reflect.StructField) bool {
+	for _, tagName := range []string{"csv", "json"} {
+		if name, _, _ := strings.Cut(field.Tag.Get(tagName), ","); name != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func isCSVScalar(t reflect.Type) bool {
+	return t == timeType || t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)
+}
+
+// escapeCSVFormula prefixes text starting with a formula character with a single quote,
+// to prevent formula injection into spreadsheet applications.
+func escapeCSVFormula(text string) string {
+	if text != "" && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+
+	return text
+}
+
+func indirectValue(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
+
+func csvFieldString(v reflect.Value) (string, error) {
+	v = indirectValue(v)
+	if !v.IsValid() {
+		return "", nil
+	}
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339Nano), nil
+	}
+
+	if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		return escapeCSVFormula(string(text)), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return escapeCSVFormula(v.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, v.Type().Bits()), nil
+	case reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return "", nil
+		}
+
+		// Nested values are stringified as JSON
+		encoded, err := json.Marshal(v.Interface())
+		return string(encoded), err
+	case reflect.Struct, reflect.Array:
+		// Nested values are stringified as JSON
+		encoded, err := json.Marshal(v.Interface())
+		return string(encoded), err
+	default:
+		return "", fmt.Errorf("cannot serialize %s as CSV", v.Type())
+	}
+}
@@ -0,0 +1,324 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type CSVSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestCSVSuite(t *testing.T) {
+	suite.Run(t, &CSVSuite{})
+}
+
+func (s *CSVSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/api/things", http.NoBody)
+}
+
+func (s *CSVSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+type testCSVEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type testCSVModel struct {
+	testCSVEmbedded
+
+	Name     string            `csv:"name" json:"ignored"`
+	Count    int               `json:"count,omitempty"`
+	Ratio    float64           `json:"ratio"`
+	Active   bool              `json:"active"`
+	Optional *string           `json:"optional"`
+	Created  time.Time         `json:"created"`
+	Tags     []string          `json:"tags"`
+	Labels   map[string]string `json:"labels"`
+	Secret   string            `json:"-"`
+	Plain    string
+	hidden   string
+}
+
+func (s *CSVSuite) Test_NewCSVSerializer_Structs() {
+	// given
+	s.request.Header.Set("Accept", "text/csv")
+
+	optional := "opt"
+	data := []testCSVModel{
+		{
+			testCSVEmbedded: testCSVEmbedded{Embedded: "e1"},
+			Name:            "first, with comma",
+			Count:           1,
+			Ratio:           0.5,
+			Active:          true,
+			Optional:        &optional,
+			Created:         time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			Tags:            []string{"a", "b"},
+			Labels:          map[string]string{"key": "value"},
+			Secret:          "secret",
+			Plain:           "plain",
+			hidden:          "hidden",
+		},
+		{
+			Name: "second",
+		},
+	}
+
+	// when
+	turtleware.NewCSVSerializer().Write(s.response, s.request, http.StatusOK, data)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("text/csv;charset=utf-8", s.response.Header().Get("Content-Type"))
+	s.Equal(`attachment; filename=things.csv`, s.response.Header().Get("Content-Disposition"))
+	s.Equal(
+		"embedded,name,count,ratio,active,optional,created,tags,labels,Plain\n"+
+			"e1,\"first, with comma\",1,0.5,true,opt,2024-01-02T03:04:05Z,\"[\"\"a\"\",\"\"b\"\"]\",\"{\"\"key\"\":\"\"value\"\"}\",plain\n"+
+			",second,0,0,false,,0001-01-01T00:00:00Z,,,\n",
+		s.response.Body.String(),
+	)
+}
+
+func (s *CSVSuite) Test_NewCSVSerializer_Maps() {
+	// given
+	s.request.Header.Set("Accept", "text/csv")
+
+	data := []interface{}{
+		map[string]interface{}{"b": 2, "a": "first"},
+		map[string]interface{}{"a": "second", "c": true},
+	}
+
+	// when
+	turtleware.NewCSVSerializer().Write(s.response, s.request, http.StatusOK, data)
+
+	// then
+	s.Equal("a,b,c\nfirst,2,\nsecond,,true\n", s.response.Body.String())
+}
+
+func (s *CSVSuite) Test_NewCSVSerializer_Scalars() {
+	// given
+	s.request.Header.Set("Accept", "text/csv")
+
+	// when
+	turtleware.NewCSVSerializer().Write(s.response, s.request, http.StatusOK, []string{"a", "b"})
+
+	// then
+	s.Equal("value\na\nb\n", s.response.Body.String())
+}
+
+func (s *CSVSuite) Test_NewCSVSerializer_Formulas() {
+	// given
+	s.request.Header.Set("Accept", "text/csv")
+
+	data := []interface{}{
+		map[string]interface{}{"=header": "=1+2", "plus": "+1", "minus": "-1", "at": "@SUM(A1)", "number": -1},
+	}
+
+	// when
+	turtleware.NewCSVSerializer().Write(s.response, s.request, http.StatusOK, data)
+
+	// then
+	s.Equal("'=header,at,minus,number,plus\n'=1+2,'@SUM(A1),'-1,-1,'+1\n", s.response.Body.String())
+}
+
+func (s *CSVSuite) Test_NewCSVSerializer_Unsupported() {
+	cases := map[string]interface{}{
+		"No_List":   testCSVModel{},
+		"Func_List": []func(){func() {}},
+	}
+
+	for testName, data := range cases {
+		s.Run(testName, func() {
+			// given
+			s.request.Header.Set("Accept", "text/csv")
+
+			// when
+			turtleware.NewCSVSerializer().Write(s.response, s.request, http.StatusOK, data)
+
+			// then
+			s.Equal(http.StatusInternalServerError, s.response.Code)
+			s.Contains(s.response.Body.String(), turtleware.ErrMarshalling.Error())
+		})
+	}
+}
+
+func (s *CSVSuite) Test_NewCSVSerializer_Negotiation() {
+	cases := map[string]struct {
+		accept      string
+		expectedCSV bool
+	}{
+		"No_Accept":         {},
+		"CSV":               {accept: "text/csv", expectedCSV: true},
+		"CSV_Preferred":     {accept: "application/json;q=0.5, text/csv", expectedCSV: true},
+		"CSV_Not_Preferred": {accept: "application/json, text/csv;q=0.5"},
+		"CSV_Rejected":      {accept: "text/csv;q=0"},
+		"Wildcard":          {accept: "*/*"},
+		"JSON":              {accept: "application/json"},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			if target.accept != "" {
+				s.request.Header.Set("Accept", target.accept)
+			}
+
+			// when
+			turtleware.NewCSVSerializer().Write(s.response, s.request, http.StatusOK, []string{"a"})
+
+			// then
+			s.Equal("Accept", s.response.Header().Get("Vary"))
+
+			if target.expectedCSV {
+				s.Equal("text/csv;charset=utf-8", s.response.Header().Get("Content-Type"))
+			} else {
+				s.Equal("application/json;charset=utf-8", s.response.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func (s *CSVSuite) Test_NewCSVSerializer_Options() {
+	serializer := turtleware.NewCSVSerializer(
+		turtleware.CSVFallback(TestSerializer{}),
+		turtleware.CSVFileName(func(r *http.Request) string {
+			return "export.csv"
+		}),
+	)
+
+	s.Run("Fallback", func() {
+		// given
+		s.request.Header.Set("Accept", "text/plain")
+
+		// when
+		serializer.Write(s.response, s.request, http.StatusOK, []string{"a"})
+
+		// then
+		s.Equal("text/plain", s.response.Header().Get("Content-Type"))
+		s.Equal("[a]", s.response.Body.String())
+	})
+
+	s.Run("File_Name", func() {
+		// given
+		s.request.Header.Set("Accept", "text/csv")
+
+		// when
+		serializer.Write(s.response, s.request, http.StatusOK, []string{"a"})
+
+		// then
+		s.Equal("attachment; filename=export.csv", s.response.Header().Get("Content-Disposition"))
+	})
+}
+
+func (s *CSVSuite) Test_StaticListDataHandler_CSV() {
+	// given
+	s.request.Header.Set("Accept", "text/csv")
+
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		return []TestDataModel{{SomeString: "test1", SomeInt: 42}}, nil
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+	).Then(turtleware.StaticListDataHandler(
+		dataFetcherFunc,
+		turtleware.DefaultErrorHandler,
+		turtleware.ListDataSerializer(turtleware.NewCSVSerializer()),
+	))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("text/csv;charset=utf-8", s.response.Header().Get("Content-Type"))
+	s.Equal("SomeString,SomeInt\ntest1,42\n", s.response.Body.String())
+}
+
+func (s *CSVSuite) Test_StaticListDataHandler_CSV_Opt_In() {
+	// given
+	s.request.Header.Set("Accept", "text/csv")
+
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		return []TestDataModel{{SomeString: "test1", SomeInt: 42}}, nil
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+	).Then(turtleware.StaticListDataHandler(dataFetcherFunc, turtleware.DefaultErrorHandler))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("application/json;charset=utf-8", s.response.Header().Get("Content-Type"))
+}
+
+func (s *CSVSuite) Test_CSVMiddleware_Etag() {
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		return []TestDataModel{{SomeString: "test1", SomeInt: 42}}, nil
+	}
+
+	hashFetcher := func(ctx context.Context, paging turtleware.Paging) (string, error) {
+		return "abc", nil
+	}
+
+	tests := map[string]struct {
+		accept       string
+		ifNoneMatch  string
+		expectedCode int
+		expectedEtag string
+	}{
+		"JSON":              {accept: "application/json", expectedCode: http.StatusOK, expectedEtag: `"abc"`},
+		"JSON_Cached":       {accept: "application/json", ifNoneMatch: `"abc"`, expectedCode: http.StatusNotModified, expectedEtag: `"abc"`},
+		"JSON_CSV_Etag":     {accept: "application/json", ifNoneMatch: `"abc-csv"`, expectedCode: http.StatusOK, expectedEtag: `"abc"`},
+		"CSV":               {accept: "text/csv", expectedCode: http.StatusOK, expectedEtag: `"abc-csv"`},
+		"CSV_Cached":        {accept: "text/csv", ifNoneMatch: `"abc-csv"`, expectedCode: http.StatusNotModified, expectedEtag: `"abc-csv"`},
+		"CSV_JSON_Etag":     {accept: "text/csv", ifNoneMatch: `"abc"`, expectedCode: http.StatusOK, expectedEtag: `"abc-csv"`},
+		"CSV_Multiple_Tags": {accept: "text/csv", ifNoneMatch: `"abc", "abc-csv"`, expectedCode: http.StatusNotModified, expectedEtag: `"abc-csv"`},
+	}
+
+	for name, target := range tests {
+		s.Run(name, func() {
+			// given
+			s.request.Header.Set("Accept", target.accept)
+			if target.ifNoneMatch != "" {
+				s.request.Header.Set("If-None-Match", target.ifNoneMatch)
+			}
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+				turtleware.CSVMiddleware,
+				turtleware.ListCacheMiddleware(hashFetcher, turtleware.DefaultErrorHandler),
+			).Then(turtleware.StaticListDataHandler(
+				dataFetcherFunc,
+				turtleware.DefaultErrorHandler,
+				turtleware.ListDataSerializer(turtleware.NewCSVSerializer()),
+			))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedCode, s.response.Code)
+			s.Equal(target.expectedEtag, s.response.Header().Get("Etag"))
+			s.Contains(s.response.Header().Values("Vary"), "Accept")
+		})
+	}
+}
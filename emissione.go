@@ -3,18 +3,136 @@ package turtleware
 import (
 	jsoniter "github.com/json-iterator/go"
 	"github.com/kernle32dll/emissione-go"
+	"github.com/rs/zerolog"
+
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
 )
 
-var (
-	// use a custom json writer, which uses jsoniter.
-	jsonWriter = emissione.NewJSONWriter(emissione.MarshallMethod(func(v interface{}) ([]byte, error) {
-		return jsoniter.MarshalIndent(v, "", "  ")
-	}))
+// ResponseSerializer serializes response bodies with the given status code, e.g. by negotiating
+// the format via the Accept header of the request. It is implemented by the EmissioneWriter, and
+// can be replaced per handler (see ListDataSerializer and ResourceDataSerializer), e.g. for
+// serving CSV.
+type ResponseSerializer interface {
+	Write(w http.ResponseWriter, r *http.Request, code int, i interface{})
+}
+
+type emissioneOptions struct {
+	indent     int
+	escapeHTML bool
+}
+
+// EmissioneOption represents an option for the NewEmissioneWriter.
+type EmissioneOption func(*emissioneOptions)
+
+// EmissioneIndent sets the number of spaces used for indenting JSON and XML response
+// bodies. An indent of zero disables pretty-printing.
+// The default is 2.
+func EmissioneIndent(indent int) EmissioneOption {
+	return func(c *emissioneOptions) {
+		c.indent = indent
+	}
+}
+
+// EmissioneEscapeHTML sets whether the characters <, > and & are escaped in JSON response
+// bodies (e.g. & as \u0026), so the JSON can be embedded into HTML safely.
+// The default is true.
+func EmissioneEscapeHTML(escapeHTML bool) EmissioneOption {
+	return func(c *emissioneOptions) {
+		c.escapeHTML = escapeHTML
+	}
+}
+
+// EmissioneHandler is a ResponseSerializer for writing out response bodies, negotiating between
+// JSON and XML via the Accept header of the request (see NewEmissioneWriter). It wraps an
+// emissione.Handler, and exposes its JSON configuration for JSON which is not written via
+// the handler itself (see JSONAPI).
+type EmissioneHandler struct {
+	*emissione.Handler
+
+	jsonAPI    jsoniter.API
+	jsonWriter pooledJSONWriter
+	xmlWriter  emissione.Writer
+}
+
+// JSONAPI returns the JSON configuration of the handler.
+func (h *EmissioneHandler) JSONAPI() jsoniter.API {
+	return h.jsonAPI
+}
+
+// Write writes the given status code and object to the ResponseWriter, negotiating the format via
+// the Accept header of the request. JSON is written unless XML is preferred. The body is serialized
+// into a buffer of the ResponseBufferPool, and written from there without further copies.
+func (h *EmissioneHandler) Write(w http.ResponseWriter, r *http.Request, code int, i interface{}) {
+	h.write(w, r, code, i, false)
+}
+
+// write serializes the given object into a pooled buffer, before writing the status code
+// and the buffer. If contentLength is set, the Content-Length header is set as well.
+// Like the emissione.Handler, serialization errors panic, before anything is written.
+func (h *EmissioneHandler) write(w http.ResponseWriter, r *http.Request, code int, i interface{}, contentLength bool) {
+	buffer := ResponseBufferPool.Get()
+	defer ResponseBufferPool.Put(buffer)
+
+	accepts := r.Header.Values("Accept")
+
+	var err error
+	if prefersMediaType(accepts, "application/xml") && !prefersMediaType(accepts, "application/json") {
+		err = h.xmlWriter.Write(&bufferedResponseWriter{ResponseWriter: w, buffer: buffer}, i)
+	} else {
+		setJSONContentType(w)
+		err = h.jsonWriter.serialize(buffer, i)
+	}
+
+	if err != nil {
+		panic(err)
+	}
 
-	xmlWriter = emissione.NewXmlIndentWriter("", "  ")
+	if contentLength {
+		w.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+	}
 
-	// EmissioneWriter is the globally used writer for writing out response bodies.
-	EmissioneWriter = emissione.New(jsonWriter, emissione.WriterMapping{
+	w.WriteHeader(code)
+
+	if _, err := buffer.WriteTo(w); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to write buffered response")
+	}
+}
+
+// NewEmissioneWriter creates a new writer for writing out response bodies, negotiating
+// between JSON and XML via the Accept header of the request. JSON is serialized via
+// buffers of the ResponseBufferPool.
+// The serialization can be adjusted via the provided options. If the writer is used as the
+// EmissioneWriter, the JSON options apply to problem documents (see WriteProblem) and
+// resource streams (see ResourceStream) as well.
+func NewEmissioneWriter(opts ...EmissioneOption) *EmissioneHandler {
+	// default
+	config := &emissioneOptions{
+		indent:     2,
+		escapeHTML: true,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	api := jsoniter.Config{
+		EscapeHTML:    config.escapeHTML,
+		IndentionStep: config.indent,
+	}.Froze()
+
+	jsonWriter := pooledJSONWriter{api: api}
+
+	xmlWriter := emissione.NewXmlWriter()
+	if config.indent > 0 {
+		xmlWriter = emissione.NewXmlIndentWriter("", strings.Repeat(" ", config.indent))
+	}
+
+	handler := emissione.New(jsonWriter, emissione.WriterMapping{
 		"application/json":                jsonWriter,
 		"application/json;charset=utf-8":  jsonWriter,
 		"application/json; charset=utf-8": jsonWriter,
@@ -22,4 +140,144 @@ var (
 		"application/xml;charset=utf-8":   xmlWriter,
 		"application/xml; charset=utf-8":  xmlWriter,
 	})
-)
+
+	return &EmissioneHandler{
+		Handler:    handler,
+		jsonAPI:    api,
+		jsonWriter: jsonWriter,
+		xmlWriter:  xmlWriter,
+	}
+}
+
+// EmissioneWriter is the globally used writer for writing out response bodies.
+// It may be replaced (e.g. via NewEmissioneWriter), before any requests are served.
+var EmissioneWriter = NewEmissioneWriter()
+
+// WriteJSON writes the given payload with the given status code, as the counterpart to
+// WriteError for handlers which are not one of the list or resource data handlers (e.g. a
+// next handler after the ResourceCreateMiddleware). The payload is written via the
+// EmissioneWriter, so the format is negotiated via the Accept header of the request
+// (JSON by default, or XML if requested).
+// Unless a Cache-Control header was already set (e.g. by the cache middlewares), the
+// Cache-Control header is set to no-store, as ad-hoc responses are not meant to be cached.
+// For HEAD requests, only the status code is written.
+func WriteJSON(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	code int,
+	payload interface{},
+) {
+	if w.Header().Get("Cache-Control") == "" {
+		w.Header().Set("Cache-Control", "no-store")
+	}
+
+	if r.Method == http.MethodHead {
+		// No body, but we still require the status code
+		w.WriteHeader(code)
+
+		return
+	}
+
+	zerolog.Ctx(ctx).Trace().Int("status_code", code).Msg("Writing response")
+
+	EmissioneWriter.Write(w, r, code, payload)
+}
+
+// orEmissioneWriter returns the given ResponseSerializer, or the EmissioneWriter if nil.
+// The EmissioneWriter is resolved on each call, so replacing it takes effect for all handlers.
+func orEmissioneWriter(serializer ResponseSerializer) ResponseSerializer {
+	if serializer == nil {
+		return EmissioneWriter
+	}
+
+	return serializer
+}
+
+// prefersMediaType reports whether the given media type has the highest quality of all
+// media types of the given Accept header values. Wildcards never select the media type.
+func prefersMediaType(accepts []string, preferred string) bool {
+	preferredQuality, bestOtherQuality := 0.0, 0.0
+
+	for _, accept := range accepts {
+		for _, entry := range strings.Split(accept, ",") {
+			mediaType, params, _ := strings.Cut(entry, ";")
+			mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+			quality := 1.0
+			for _, param := range strings.Split(params, ";") {
+				name, value, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || strings.ToLower(strings.TrimSpace(name)) != "q" {
+					continue
+				}
+
+				if parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					quality = parsed
+				}
+			}
+
+			if mediaType == preferred {
+				preferredQuality = max(preferredQuality, quality)
+			} else {
+				bestOtherQuality = max(bestOtherQuality, quality)
+			}
+		}
+	}
+
+	return preferredQuality > 0 && preferredQuality >= bestOtherQuality
+}
+
+// contentLengthSerializer is a ResponseSerializer, which buffers the response body written
+// by the wrapped serializer, so the Content-Length header can be set before writing it.
+type contentLengthSerializer struct {
+	serializer ResponseSerializer
+}
+
+func (s contentLengthSerializer) Write(w http.ResponseWriter, r *http.Request, code int, i interface{}) {
+	// The EmissioneHandler buffers anyway, so no second buffer is required
+	if handler, ok := s.serializer.(*EmissioneHandler); ok {
+		handler.write(w, r, code, i, true)
+
+		return
+	}
+
+	buffer := ResponseBufferPool.Get()
+	defer ResponseBufferPool.Put(buffer)
+
+	bufferedWriter := &bufferedResponseWriter{
+		ResponseWriter: w,
+		buffer:         buffer,
+	}
+
+	s.serializer.Write(bufferedWriter, r, code, i)
+
+	if bufferedWriter.statusCode == 0 {
+		bufferedWriter.statusCode = http.StatusOK
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(buffer.Len()))
+	w.WriteHeader(bufferedWriter.statusCode)
+
+	if _, err := buffer.WriteTo(w); err != nil {
+		zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to write buffered response")
+	}
+}
+
+// bufferedResponseWriter is a wrapper for a http.ResponseWriter, which holds back
+// the status code, and writes the body into a buffer.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+
+	buffer     *bytes.Buffer
+	statusCode int
+}
+
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+func (w *bufferedResponseWriter) Write(b []byte) (int, error) {
+	return w.buffer.Write(b)
+}
@@ -0,0 +1,230 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type EmissioneSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestEmissioneSuite(t *testing.T) {
+	suite.Run(t, &EmissioneSuite{})
+}
+
+func (s *EmissioneSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *EmissioneSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *EmissioneSuite) Test_NewEmissioneWriter_JSON() {
+	payload := map[string]string{"url": "https://example.com/?a=1&b=2"}
+
+	cases := map[string]struct {
+		opts     []turtleware.EmissioneOption
+		expected string
+	}{
+		"Default": {
+			expected: "{\n  \"url\": \"https://example.com/?a=1\\u0026b=2\"\n}",
+		},
+		"No_Indent": {
+			opts:     []turtleware.EmissioneOption{turtleware.EmissioneIndent(0)},
+			expected: `{"url":"https://example.com/?a=1\u0026b=2"}`,
+		},
+		"Custom_Indent": {
+			opts:     []turtleware.EmissioneOption{turtleware.EmissioneIndent(4)},
+			expected: "{\n    \"url\": \"https://example.com/?a=1\\u0026b=2\"\n}",
+		},
+		"No_HTML_Escaping": {
+			opts: []turtleware.EmissioneOption{
+				turtleware.EmissioneIndent(0),
+				turtleware.EmissioneEscapeHTML(false),
+			},
+			expected: `{"url":"https://example.com/?a=1&b=2"}`,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			writer := turtleware.NewEmissioneWriter(target.opts...)
+
+			// when
+			writer.Write(s.response, s.request, http.StatusOK, payload)
+
+			// then
+			s.Equal(http.StatusOK, s.response.Code)
+			s.Equal("application/json;charset=utf-8", s.response.Header().Get("Content-Type"))
+			s.Equal(target.expected, s.response.Body.String())
+		})
+	}
+}
+
+func (s *EmissioneSuite) Test_NewEmissioneWriter_XML() {
+	type xmlPayload struct {
+		Value string
+	}
+
+	cases := map[string]struct {
+		opts     []turtleware.EmissioneOption
+		expected string
+	}{
+		"Default": {
+			expected: "<xmlPayload>\n  <Value>a&amp;b</Value>\n</xmlPayload>",
+		},
+		"No_Indent": {
+			opts:     []turtleware.EmissioneOption{turtleware.EmissioneIndent(0)},
+			expected: "<xmlPayload><Value>a&amp;b</Value></xmlPayload>",
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			s.request.Header.Set("Accept", "application/xml")
+
+			writer := turtleware.NewEmissioneWriter(target.opts...)
+
+			// when
+			writer.Write(s.response, s.request, http.StatusOK, xmlPayload{Value: "a&b"})
+
+			// then
+			s.Equal(http.StatusOK, s.response.Code)
+			s.Equal(target.expected, s.response.Body.String())
+		})
+	}
+}
+
+func (s *EmissioneSuite) Test_NewEmissioneWriter_Negotiation() {
+	type payload struct {
+		Value string
+	}
+
+	cases := map[string]struct {
+		accept      string
+		contentType string
+	}{
+		"None":              {accept: "", contentType: "application/json;charset=utf-8"},
+		"Wildcard":          {accept: "*/*", contentType: "application/json;charset=utf-8"},
+		"Unsupported":       {accept: "image/png", contentType: "application/json;charset=utf-8"},
+		"JSON":              {accept: "application/json", contentType: "application/json;charset=utf-8"},
+		"JSON_And_XML":      {accept: "application/json, application/xml", contentType: "application/json;charset=utf-8"},
+		"XML":               {accept: "application/xml", contentType: "application/xml;charset=utf-8"},
+		"XML_Over_JSON":     {accept: "application/json;q=0.5, application/xml", contentType: "application/xml;charset=utf-8"},
+		"XML_Over_Wildcard": {accept: "application/xml, */*;q=0.1", contentType: "application/xml;charset=utf-8"},
+		"JSON_Over_XML":     {accept: "application/xml;q=0.5, application/json", contentType: "application/json;charset=utf-8"},
+		"XML_With_Charset":  {accept: "application/xml; charset=utf-8", contentType: "application/xml;charset=utf-8"},
+		"Wildcard_Over_XML": {accept: "application/xml;q=0.5, */*", contentType: "application/json;charset=utf-8"},
+		"JSON_With_Charset": {accept: "application/json;charset=utf-8", contentType: "application/json;charset=utf-8"},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			if target.accept != "" {
+				s.request.Header.Set("Accept", target.accept)
+			}
+
+			// when
+			turtleware.NewEmissioneWriter().Write(s.response, s.request, http.StatusCreated, payload{Value: "some-value"})
+
+			// then
+			s.Equal(http.StatusCreated, s.response.Code)
+			s.Equal(target.contentType, s.response.Header().Get("Content-Type"))
+			s.Contains(s.response.Body.String(), "some-value")
+		})
+	}
+}
+
+func (s *EmissioneSuite) Test_NewEmissioneWriter_Problem() {
+	// given
+	emissioneWriter := turtleware.EmissioneWriter
+	turtleware.EmissioneWriter = turtleware.NewEmissioneWriter(
+		turtleware.EmissioneIndent(0),
+		turtleware.EmissioneEscapeHTML(false),
+	)
+	defer func() {
+		turtleware.EmissioneWriter = emissioneWriter
+	}()
+
+	// when
+	turtleware.WriteProblem(context.Background(), s.response, s.request, turtleware.ProblemDetails{
+		Title:  "Bad Request",
+		Status: http.StatusBadRequest,
+		Detail: "a & b",
+	})
+
+	// then
+	s.Equal(http.StatusBadRequest, s.response.Code)
+	s.Equal(`{"title":"Bad Request","status":400,"detail":"a & b"}`, s.response.Body.String())
+}
+
+func (s *EmissioneSuite) Test_WriteJSON() {
+	payload := map[string]string{"some": "value"}
+
+	s.Run("JSON", func() {
+		// when
+		turtleware.WriteJSON(context.Background(), s.response, s.request, http.StatusCreated, payload)
+
+		// then
+		s.Equal(http.StatusCreated, s.response.Code)
+		s.Equal("application/json;charset=utf-8", s.response.Header().Get("Content-Type"))
+		s.Equal("no-store", s.response.Header().Get("Cache-Control"))
+		s.JSONEq(`{"some":"value"}`, s.response.Body.String())
+	})
+
+	s.Run("XML", func() {
+		// given
+		type xmlPayload struct {
+			Value string
+		}
+
+		s.request.Header.Set("Accept", "application/xml")
+
+		// when
+		turtleware.WriteJSON(context.Background(), s.response, s.request, http.StatusOK, xmlPayload{Value: "some-value"})
+
+		// then
+		s.Equal(http.StatusOK, s.response.Code)
+		s.Equal("<xmlPayload>\n  <Value>some-value</Value>\n</xmlPayload>", s.response.Body.String())
+	})
+
+	s.Run("Existing_Cache_Control", func() {
+		// given
+		s.response.Header().Set("Cache-Control", "must-revalidate")
+
+		// when
+		turtleware.WriteJSON(context.Background(), s.response, s.request, http.StatusOK, payload)
+
+		// then
+		s.Equal("must-revalidate", s.response.Header().Get("Cache-Control"))
+	})
+
+	s.Run("Head", func() {
+		// given
+		s.request.Method = http.MethodHead
+
+		// when
+		turtleware.WriteJSON(context.Background(), s.response, s.request, http.StatusOK, payload)
+
+		// then
+		s.Equal(http.StatusOK, s.response.Code)
+		s.Equal("no-store", s.response.Header().Get("Cache-Control"))
+		s.Empty(s.response.Body.String())
+	})
+}
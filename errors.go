@@ -2,19 +2,27 @@ package turtleware
 
 import (
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 
 	"context"
 	"encoding/xml"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 )
 
 type errorList []string
 
 func (errorList errorList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return marshalXMLList(e, start, "Error", errorList)
+}
+
+func marshalXMLList(e *xml.Encoder, start xml.StartElement, name string, values []string) error {
 	tokens := []xml.Token{start}
 
-	for _, value := range errorList {
-		t := xml.StartElement{Name: xml.Name{Local: "Error"}}
+	for _, value := range values {
+		t := xml.StartElement{Name: xml.Name{Local: name}}
 		tokens = append(tokens, t, xml.CharData(value), t.End())
 	}
 
@@ -36,15 +44,173 @@ func (errorList errorList) MarshalXML(e *xml.Encoder, start xml.StartElement) er
 	return nil
 }
 
+type codedErrorObject struct {
+	Message string `json:"message" xml:"Message"`
+	Code    string `json:"code,omitempty" xml:"Code,omitempty"`
+}
+
+type codedErrorList []codedErrorObject
+
+func (codedErrorList codedErrorList) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, codedErrorObject := range codedErrorList {
+		if err := e.EncodeElement(codedErrorObject, xml.StartElement{Name: xml.Name{Local: "Error"}}); err != nil {
+			return err
+		}
+	}
+
+	if err := e.EncodeToken(start.End()); err != nil {
+		return err
+	}
+
+	// flush to ensure tokens are written
+	return e.Flush()
+}
+
+// CodedError is an error, which carries a stable, machine-readable code (e.g. for
+// clients to switch on). The codes are written by WriteError, within an ErrorCodesMiddleware.
+type CodedError interface {
+	error
+	Code() string
+}
+
+type codedError struct {
+	error
+	code string
+}
+
+func (codedError codedError) Code() string {
+	return codedError.code
+}
+
+func (codedError codedError) Unwrap() error {
+	return codedError.error
+}
+
+// WithErrorCode wraps the given error into a CodedError, carrying the given code.
+func WithErrorCode(err error, code string) error {
+	return codedError{error: err, code: code}
+}
+
+// ErrorCode returns the code of the first CodedError in the chain of the given error,
+// or the code of the turtleware sentinel error it wraps (e.g. "resource_not_found" for
+// ErrResourceNotFound). If neither exists, an empty string is returned.
+func ErrorCode(err error) string {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return coded.Code()
+	}
+
+	for _, sentinelCode := range sentinelCodes {
+		if errors.Is(err, sentinelCode.err) {
+			return sentinelCode.code
+		}
+	}
+
+	return ""
+}
+
+// sentinelCodes maps the sentinel errors of turtleware to their codes.
+var sentinelCodes = []struct {
+	err  error
+	code string
+}{
+	{err: ErrResourceNotFound, code: "resource_not_found"},
+	{err: ErrResourceGone, code: "resource_gone"},
+	{err: ErrPreconditionFailed, code: "precondition_failed"},
+	{err: ErrMissingUserUUID, code: "missing_user_uuid"},
+	{err: ErrInvalidEntityUUID, code: "invalid_entity_uuid"},
+	{err: ErrMarshalling, code: "malformed_body"},
+	{err: ErrConflict, code: "conflict"},
+	{err: ErrSerializationFailure, code: "serialization_failure"},
+	{err: ErrUnavailableForLegalReasons, code: "unavailable_for_legal_reasons"},
+	{err: ErrRequestTimeout, code: "request_timeout"},
+	{err: ErrReceivingResults, code: "receiving_results_failed"},
+	{err: ErrReceivingMeta, code: "receiving_meta_failed"},
+	{err: ErrMethodNotAllowed, code: "method_not_allowed"},
+	{err: ErrUnsupportedMediaType, code: "unsupported_media_type"},
+	{err: ErrInsufficientScope, code: "insufficient_scope"},
+	{err: ErrNoChanges, code: "no_changes"},
+	{err: ErrUnmodifiedSinceHeaderMissing, code: "unmodified_since_header_missing"},
+	{err: ErrUnmodifiedSinceHeaderInvalid, code: "unmodified_since_header_invalid"},
+	{err: ErrMatchHeaderInvalid, code: "match_header_invalid"},
+	{err: ErrNoBulkIDs, code: "no_bulk_ids"},
+	{err: ErrTooManyParams, code: "too_many_params"},
+	{err: ErrDisallowedFileType, code: "disallowed_file_type"},
+	{err: ErrMissingAuthHeader, code: "missing_auth_header"},
+	{err: ErrAuthHeaderWrongFormat, code: "auth_header_wrong_format"},
+	{err: ErrTokenValidationFailed, code: "token_validation_failed"},
+	{err: ErrTokenExpired, code: "token_expired"},
+	{err: ErrInvalidConditionalHeaders, code: "invalid_conditional_headers"},
+	{err: ErrInvalidOffset, code: "invalid_offset"},
+	{err: ErrInvalidLimit, code: "invalid_limit"},
+	{err: ErrUnalignedOffset, code: "unaligned_offset"},
+	{err: ErrCursorOffsetConflict, code: "cursor_offset_conflict"},
+	{err: ErrInvalidInclude, code: "invalid_include"},
+	{err: ErrQueryTooComplex, code: "query_too_complex"},
+	{err: ErrInvalidClientTimeout, code: "invalid_client_timeout"},
+	{err: ErrClientTimeoutExceeded, code: "client_timeout_exceeded"},
+	{err: ErrInvalidSignature, code: "invalid_signature"},
+	{err: ErrSignedURLExpired, code: "signed_url_expired"},
+	{err: ErrRateLimitExceeded, code: "rate_limit_exceeded"},
+	{err: ErrIdempotencyKeyReused, code: "idempotency_key_reused"},
+	{err: ErrIdempotencyKeyInFlight, code: "idempotency_key_in_flight"},
+}
+
+// withErrorCode wraps the given error into a CodedError, if it is not one already, and
+// a code is known for it (see ErrorCode). Otherwise, the error is returned as-is.
+func withErrorCode(err error) error {
+	var coded CodedError
+	if errors.As(err, &coded) {
+		return err
+	}
+
+	if code := ErrorCode(err); code != "" {
+		return WithErrorCode(err, code)
+	}
+
+	return err
+}
+
+// codedErrors returns the messages of the given errors, alongside the codes of the errors
+// being a CodedError.
+func codedErrors(errs []error) codedErrorList {
+	codedErrors := make(codedErrorList, len(errs))
+
+	for i, err := range errs {
+		codedErrors[i].Message = err.Error()
+
+		var coded CodedError
+		if errors.As(err, &coded) {
+			codedErrors[i].Code = coded.Code()
+		}
+	}
+
+	return codedErrors
+}
+
 type errorResponse struct {
-	XMLName xml.Name  `xml:"ErrorResponse" json:"-"`
-	Status  int       `json:"status" xml:"Status"`
-	Text    string    `json:"text" xml:"Text"`
-	Errors  errorList `json:"errors" xml:"ErrorList"`
+	XMLName   xml.Name    `xml:"ErrorResponse" json:"-"`
+	Status    int         `json:"status" xml:"Status"`
+	Text      string      `json:"text" xml:"Text"`
+	Errors    interface{} `json:"errors" xml:"ErrorList"`
+	TraceID   string      `json:"trace_id,omitempty" xml:"TraceID,omitempty"`
+	RequestID string      `json:"request_id,omitempty" xml:"RequestID,omitempty"`
 }
 
 // WriteError sets the given status code, and writes a nicely formatted json
 // errors to the response body - if the request type is not HEAD.
+// The errors are written as XML instead, if requested via the Accept header
+// (application/xml), or as plain text with one error per line (text/plain).
+// By default, each error is written as its plain message. Within an ErrorCodesMiddleware,
+// each error is written as an object with its message, and the code of the error if it
+// is a CodedError (e.g. {"message": "resource not found", "code": "resource_not_found"}).
+// For plain text, each coded error is then prefixed with its code in brackets (e.g.
+// "[resource_not_found] resource not found").
+// Within an ErrorIdentifiersMiddleware, the trace ID and request ID are written as well.
 func WriteError(
 	ctx context.Context,
 	w http.ResponseWriter,
@@ -78,6 +244,26 @@ func WriteError(
 			Errors: errList,
 		}
 
+		var codedErrList codedErrorList
+		if withCodes, _ := ctx.Value(ctxErrorCodes).(bool); withCodes {
+			codedErrList = codedErrors(errors)
+			errorMap.Errors = codedErrList
+		}
+
+		if requestID, ok := ctx.Value(ctxRequestID).(string); ok {
+			if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+				errorMap.TraceID = spanContext.TraceID().String()
+			}
+
+			errorMap.RequestID = requestID
+		}
+
+		if prefersMediaType(r.Header.Values("Accept"), "text/plain") {
+			writePlainErrors(w, code, errList, codedErrList, logger)
+
+			return
+		}
+
 		defer func() {
 			if r := recover(); r != nil {
 				w.WriteHeader(http.StatusInternalServerError)
@@ -90,3 +276,20 @@ func WriteError(
 		w.WriteHeader(code)
 	}
 }
+
+func writePlainErrors(w http.ResponseWriter, code int, errList errorList, codedErrList codedErrorList, logger zerolog.Logger) {
+	w.Header().Set("Content-Type", "text/plain;charset=utf-8")
+	w.WriteHeader(code)
+
+	lines := make([]string, len(errList))
+	for i, message := range errList {
+		lines[i] = message
+		if codedErrList != nil && codedErrList[i].Code != "" {
+			lines[i] = "[" + codedErrList[i].Code + "] " + message
+		}
+	}
+
+	if _, err := io.WriteString(w, strings.Join(lines, "\n")); err != nil {
+		logger.Error().Err(err).Msg("Error while writing error message")
+	}
+}
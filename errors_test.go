@@ -3,9 +3,11 @@ package turtleware_test
 import (
 	"github.com/kernle32dll/turtleware"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace"
 
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -113,6 +115,173 @@ func (s *ErrorsSuite) Test_xml_MultipleErrors() {
 	)
 }
 
+// codedErrorsHandler returns a handler, which writes a coded and an uncoded error
+// within an ErrorCodesMiddleware.
+func (s *ErrorsSuite) codedErrorsHandler() http.Handler {
+	return turtleware.ErrorCodesMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		turtleware.WriteError(r.Context(), w, r, http.StatusTeapot, turtleware.WithErrorCode(s.err1, "some_code"), s.err2)
+	}))
+}
+
+func (s *ErrorsSuite) Test_Json_CodedErrors() {
+	// given
+	r := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+	r.Header.Set("Accept", "application/json")
+
+	// when
+	s.codedErrorsHandler().ServeHTTP(s.w, r)
+
+	// then
+	s.Equal(http.StatusTeapot, s.w.Code)
+	s.JSONEq(
+		s.loadTestDataString("errors/coded_errors.json"),
+		s.w.Body.String(),
+	)
+}
+
+func (s *ErrorsSuite) Test_Json_CodedErrors_Disabled() {
+	// given
+	ctx := context.Background()
+	r := &http.Request{
+		Method: http.MethodGet,
+		Header: map[string][]string{"Accept": {"application/json"}},
+	}
+
+	// when
+	turtleware.WriteError(ctx, s.w, r, http.StatusTeapot, turtleware.WithErrorCode(s.err1, "some_code"), s.err2)
+
+	// then
+	s.Equal(http.StatusTeapot, s.w.Code)
+	s.JSONEq(
+		s.loadTestDataString("errors/multiple_errors.json"),
+		s.w.Body.String(),
+	)
+}
+
+func (s *ErrorsSuite) Test_xml_CodedErrors() {
+	// given
+	r := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+	r.Header.Set("Accept", "application/xml")
+
+	// when
+	s.codedErrorsHandler().ServeHTTP(s.w, r)
+
+	// then
+	s.Equal(http.StatusTeapot, s.w.Code)
+	s.Equal(
+		// Note: We need to replace spaces here, since whitespaces
+		// loaded on different OSes differ (\r vs \r\n)
+		stripSpaces(s.loadTestDataString("errors/coded_errors.xml")),
+		stripSpaces(s.w.Body.String()),
+	)
+}
+
+func (s *ErrorsSuite) Test_ErrorCode() {
+	cases := map[string]struct {
+		err      error
+		expected string
+	}{
+		"Coded":            {err: turtleware.WithErrorCode(s.err1, "some_code"), expected: "some_code"},
+		"Wrapped_Coded":    {err: fmt.Errorf("wrapped: %w", turtleware.WithErrorCode(s.err1, "some_code")), expected: "some_code"},
+		"Sentinel":         {err: turtleware.ErrResourceNotFound, expected: "resource_not_found"},
+		"Wrapped_Sentinel": {err: fmt.Errorf("%w: some detail", turtleware.ErrMarshalling), expected: "malformed_body"},
+		"Overridden":       {err: turtleware.WithErrorCode(turtleware.ErrNoChanges, "some_code"), expected: "some_code"},
+		"Unknown":          {err: s.err1, expected: ""},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			code := turtleware.ErrorCode(target.err)
+
+			// then
+			s.Equal(target.expected, code)
+		})
+	}
+
+	s.Run("Unwrap", func() {
+		// when
+		err := turtleware.WithErrorCode(turtleware.ErrNoChanges, "some_code")
+
+		// then
+		s.ErrorIs(err, turtleware.ErrNoChanges)
+		s.Equal(turtleware.ErrNoChanges.Error(), err.Error())
+	})
+}
+
+func (s *ErrorsSuite) Test_Json_Identifiers() {
+	// given
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanID:  trace.SpanID{1, 2, 3, 4, 5, 6, 7, 8},
+	})
+
+	errorHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		turtleware.WriteError(r.Context(), w, r, http.StatusTeapot, s.err1)
+	})
+
+	newRequest := func(requestID string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+		r = r.WithContext(trace.ContextWithSpanContext(r.Context(), spanContext))
+		r.Header.Set("Accept", "application/json")
+
+		if requestID != "" {
+			r.Header.Set("X-Request-Id", requestID)
+		}
+
+		return r
+	}
+
+	s.Run("Enabled", func() {
+		// given
+		s.w = httptest.NewRecorder()
+
+		// when
+		turtleware.ErrorIdentifiersMiddleware(errorHandler).ServeHTTP(s.w, newRequest("some-request-id"))
+
+		// then
+		s.Equal("some-request-id", s.w.Header().Get("X-Request-Id"))
+		s.JSONEq(
+			s.loadTestDataString("errors/identifiers.json"),
+			s.w.Body.String(),
+		)
+	})
+
+	s.Run("Disabled", func() {
+		// given
+		s.w = httptest.NewRecorder()
+
+		// when
+		errorHandler.ServeHTTP(s.w, newRequest("some-request-id"))
+
+		// then
+		s.NotContains(s.w.Body.String(), "trace_id")
+		s.NotContains(s.w.Body.String(), "request_id")
+	})
+
+	generated := map[string]string{
+		"Missing":       "",
+		"Too_Long":      strings.Repeat("a", 129),
+		"Invalid_Chars": "some-request-id\"}<script>",
+	}
+
+	for testName, requestID := range generated {
+		s.Run("Generated_"+testName, func() {
+			// given
+			s.w = httptest.NewRecorder()
+
+			// when
+			turtleware.ErrorIdentifiersMiddleware(errorHandler).ServeHTTP(s.w, newRequest(requestID))
+
+			// then
+			generatedID := s.w.Header().Get("X-Request-Id")
+			s.NotEmpty(generatedID)
+			s.NotEqual(requestID, generatedID)
+			s.Contains(s.w.Body.String(), generatedID)
+		})
+	}
+}
+
 func stripSpaces(str string) string {
 	return strings.Map(func(r rune) rune {
 		if unicode.IsSpace(r) {
@@ -143,3 +312,53 @@ func (s *ErrorsSuite) Test_xml_EmptyErrors() {
 		stripSpaces(s.w.Body.String()),
 	)
 }
+
+func (s *ErrorsSuite) Test_Plain_MultipleErrors() {
+	// given
+	ctx := context.Background()
+	r := &http.Request{
+		Method: http.MethodGet,
+		Header: map[string][]string{"Accept": {"text/plain"}},
+	}
+
+	// when
+	turtleware.WriteError(ctx, s.w, r, http.StatusTeapot, s.err1, s.err2)
+
+	// then
+	s.Equal(http.StatusTeapot, s.w.Code)
+	s.Equal("no-store", s.w.Header().Get("Cache-Control"))
+	s.Equal("text/plain;charset=utf-8", s.w.Header().Get("Content-Type"))
+	s.Equal("error1\nerror2", s.w.Body.String())
+}
+
+func (s *ErrorsSuite) Test_Plain_CodedErrors() {
+	// given
+	r := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+	r.Header.Set("Accept", "text/plain")
+
+	// when
+	s.codedErrorsHandler().ServeHTTP(s.w, r)
+
+	// then
+	s.Equal(http.StatusTeapot, s.w.Code)
+	s.Equal("[some_code] error1\nerror2", s.w.Body.String())
+}
+
+func (s *ErrorsSuite) Test_Plain_NotPreferred() {
+	// given
+	ctx := context.Background()
+	r := &http.Request{
+		Method: http.MethodGet,
+		Header: map[string][]string{"Accept": {"text/plain;q=0.5, application/json"}},
+	}
+
+	// when
+	turtleware.WriteError(ctx, s.w, r, http.StatusTeapot, s.err1, s.err2)
+
+	// then
+	s.Equal(http.StatusTeapot, s.w.Code)
+	s.JSONEq(
+		s.loadTestDataString("errors/multiple_errors.json"),
+		s.w.Body.String(),
+	)
+}
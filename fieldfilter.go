@@ -0,0 +1,376 @@
+package turtleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrContextMissingFieldRoles is an internal error indicating missing field roles
+// in the request context, whereas they were expected.
+var ErrContextMissingFieldRoles = errors.New("missing field roles in context")
+
+// ErrRestrictedFieldNotOmitEmpty indicates a field restricted to specific roles, which would
+// still be serialized after being filtered (see FilterFields).
+var ErrRestrictedFieldNotOmitEmpty = errors.New("restricted field is not tagged with omitempty")
+
+// RolesFunc is a function for extracting the roles of the caller from a given request.
+type RolesFunc func(r *http.Request) ([]string, error)
+
+// RolesFromClaim returns a RolesFunc, which reads the roles of the caller from the
+// auth claims, via the given claim path (see ClaimByPath). The claim may either be
+// an array of strings, or a space separated string. A missing claim is treated as
+// no roles at all.
+func RolesFromClaim(claimPath string) RolesFunc {
+	return func(r *http.Request) ([]string, error) {
+		claims, err := AuthClaimsFromRequestContext(r.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		claim, err := ClaimByPath(claims, claimPath)
+		if errors.Is(err, ErrClaimNotFound) {
+			return []string{}, nil
+		}
+
+		switch roles := claim.(type) {
+		case string:
+			return strings.Fields(roles), nil
+		case []string:
+			return roles, nil
+		case []interface{}:
+			result := make([]string, 0, len(roles))
+			for _, role := range roles {
+				if roleString, ok := role.(string); ok {
+					result = append(result, roleString)
+				}
+			}
+
+			return result, nil
+		default:
+			return nil, fmt.Errorf("roles claim %s has unexpected type %T", claimPath, claim)
+		}
+	}
+}
+
+// FieldRolesMiddleware is a http middleware for extracting the roles of the caller via the
+// given RolesFunc, and passing them down. If present, responses written by the data handlers
+// are filtered via FilterFields.
+func FieldRolesMiddleware(rolesFunc RolesFunc) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			roles, err := rolesFunc(r)
+			if err != nil {
+				WriteError(r.Context(), w, r, http.StatusInternalServerError, err)
+
+				return
+			}
+
+			h.ServeHTTP(
+				w,
+				r.WithContext(context.WithValue(r.Context(), ctxFieldRoles, roles)),
+			)
+		})
+	}
+}
+
+// FieldRolesFromRequestContext returns the roles of the caller, as provided by the FieldRolesMiddleware.
+func FieldRolesFromRequestContext(ctx context.Context) ([]string, error) {
+	roles, ok := ctx.Value(ctxFieldRoles).([]string)
+	if !ok {
+		return nil, ErrContextMissingFieldRoles
+	}
+
+	return roles, nil
+}
+
+// FilterFields resets all struct fields of the given data, which the caller is not authorized
+// to see, to their zero value, so they are omitted from the response.
+// Fields are restricted to specific roles via a struct tag, such as `turtleware:"roles=admin|auditor"`.
+// Restricted fields must be omitted when empty, that is, they must be tagged with omitempty for both
+// JSON and XML (or be skipped via "-"), and must not be structs. Otherwise, FilterFields panics with
+// ErrRestrictedFieldNotOmitEmpty, as the field would still be disclosed with its zero value.
+// The roles of the caller are taken from the context, as provided by the FieldRolesMiddleware.
+// If no roles are present in the context, no restricted fields are visible at all.
+// If the data does not contain any restricted fields, the data is returned as-is. Otherwise,
+// a filtered copy of the same type is returned, so it serializes the same way (e.g. as XML).
+// The given data itself is never modified.
+func FilterFields(ctx context.Context, data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(data)
+	if !needsFiltering(value) {
+		return data
+	}
+
+	roles, err := FieldRolesFromRequestContext(ctx)
+	if err != nil {
+		roles = nil
+	}
+
+	return filterValue(value, roles).Interface()
+}
+
+func filterValue(v reflect.Value, roles []string) reflect.Value {
+	if !needsFiltering(v) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		result := reflect.New(v.Type()).Elem()
+		result.Set(filterValue(v.Elem(), roles))
+
+		return result
+	case reflect.Pointer:
+		result := reflect.New(v.Type().Elem())
+		result.Elem().Set(filterValue(v.Elem(), roles))
+
+		return result
+	case reflect.Slice:
+		result := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(filterValue(v.Index(i), roles))
+		}
+
+		return result
+	case reflect.Array:
+		result := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			result.Index(i).Set(filterValue(v.Index(i), roles))
+		}
+
+		return result
+	case reflect.Map:
+		result := reflect.MakeMapWithSize(v.Type(), v.Len())
+
+		iter := v.MapRange()
+		for iter.Next() {
+			result.SetMapIndex(iter.Key(), filterValue(iter.Value(), roles))
+		}
+
+		return result
+	case reflect.Struct:
+		result := reflect.New(v.Type()).Elem()
+		result.Set(v)
+
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			if !isFieldAllowed(field, roles) {
+				result.Field(i).SetZero()
+
+				continue
+			}
+
+			result.Field(i).Set(filterValue(v.Field(i), roles))
+		}
+
+		return result
+	default:
+		return v
+	}
+}
+
+func isFieldAllowed(field reflect.StructField, roles []string) bool {
+	for _, option := range strings.Split(field.Tag.Get("turtleware"), ",") {
+		requiredRoles, ok := strings.CutPrefix(strings.TrimSpace(option), "roles=")
+		if !ok {
+			continue
+		}
+
+		for _, requiredRole := range strings.Split(requiredRoles, "|") {
+			for _, role := range roles {
+				if role == requiredRole {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// isOmittedWhenEmpty reports if the given field is omitted from both JSON and XML, if its value is empty.
+func isOmittedWhenEmpty(field reflect.StructField) bool {
+	if field.Type.Kind() == reflect.Struct {
+		return false
+	}
+
+	for _, encoding := range []string{"json", "xml"} {
+		tag := field.Tag.Get(encoding)
+		if tag == "-" {
+			continue
+		}
+
+		_, options, _ := strings.Cut(tag, ",")
+		if !slices.Contains(strings.Split(options, ","), "omitempty") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func jsonFieldName(field reflect.StructField) (string, bool, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+
+	omitEmpty := false
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+
+	return name, omitEmpty, false
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	default:
+		return false
+	}
+}
+
+// needsFiltering reports if the given value contains fields restricted to specific roles.
+// Values of interface types are inspected at runtime.
+func needsFiltering(v reflect.Value) bool {
+	switch typeRestriction(v.Type()) {
+	case restrictionNone:
+		return false
+	case restrictionStatic:
+		return !v.IsZero()
+	case restrictionDynamic:
+		// Inspect the value below
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return !v.IsNil() && needsFiltering(v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if needsFiltering(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if needsFiltering(iter.Value()) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() && needsFiltering(v.Field(i)) {
+				return true
+			}
+		}
+	default:
+		// Nothing to restrict
+	}
+
+	return false
+}
+
+type restriction int
+
+const (
+	// restrictionNone marks types without any fields restricted to specific roles.
+	restrictionNone restriction = iota
+
+	// restrictionDynamic marks types, which may contain restricted fields in the
+	// dynamic values of interface types (e.g. map[string]interface{}).
+	restrictionDynamic
+
+	// restrictionStatic marks types, which contain restricted fields.
+	restrictionStatic
+)
+
+var restrictedTypes sync.Map
+
+// typeRestriction reports if the given type (or any type contained in it) has fields
+// restricted to specific roles, or may have them via interface types. The result is
+// cached per type.
+func typeRestriction(t reflect.Type) restriction {
+	if cached, ok := restrictedTypes.Load(t); ok {
+		return cached.(restriction)
+	}
+
+	result := inspectTypeRestriction(t, map[reflect.Type]struct{}{})
+
+	// Only the result of the inspected type itself is cached, as the results of the types
+	// contained in it are incomplete, if they refer back to the inspected type.
+	restrictedTypes.Store(t, result)
+
+	return result
+}
+
+func inspectTypeRestriction(t reflect.Type, visiting map[reflect.Type]struct{}) restriction {
+	if cached, ok := restrictedTypes.Load(t); ok {
+		return cached.(restriction)
+	}
+
+	// Guard against recursive types - the result is accounted for by the outer inspection
+	if _, found := visiting[t]; found {
+		return restrictionNone
+	}
+
+	visiting[t] = struct{}{}
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return restrictionDynamic
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return inspectTypeRestriction(t.Elem(), visiting)
+	case reflect.Struct:
+		result := restrictionNone
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			if strings.Contains(field.Tag.Get("turtleware"), "roles=") {
+				if !isOmittedWhenEmpty(field) {
+					panic(fmt.Errorf("%w: %s.%s", ErrRestrictedFieldNotOmitEmpty, t, field.Name))
+				}
+
+				return restrictionStatic
+			}
+
+			result = max(result, inspectTypeRestriction(field.Type, visiting))
+		}
+
+		return result
+	default:
+		return restrictionNone
+	}
+}
@@ -0,0 +1,245 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type FieldFilterSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestFieldFilterSuite(t *testing.T) {
+	suite.Run(t, &FieldFilterSuite{})
+}
+
+func (s *FieldFilterSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *FieldFilterSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+type testFieldFilterModel struct {
+	Name      string `json:"name"`
+	Salary    int    `json:"salary,omitempty" xml:",omitempty" turtleware:"roles=admin|payroll"`
+	Note      string `json:"note,omitempty"`
+	Internal  string `json:"-"`
+	Untouched string
+}
+
+func (s *FieldFilterSuite) fieldFilterRoles(roles ...string) turtleware.RolesFunc {
+	return func(r *http.Request) ([]string, error) {
+		return roles, nil
+	}
+}
+
+func (s *FieldFilterSuite) Test_StaticListDataHandler_FieldRoles() {
+	// given
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]testFieldFilterModel, error) {
+		return []testFieldFilterModel{
+			{Name: "Alice", Salary: 1337, Internal: "secret", Untouched: "value"},
+		}, nil
+	}
+
+	s.Run("Admin", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+			turtleware.FieldRolesMiddleware(s.fieldFilterRoles("user", "admin")),
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(errorCapture.CapturedError)
+		s.JSONEq(`[{"name": "Alice", "salary": 1337, "Untouched": "value"}]`, s.response.Body.String())
+	})
+
+	s.Run("User", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+			turtleware.FieldRolesMiddleware(s.fieldFilterRoles("user")),
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(errorCapture.CapturedError)
+		s.JSONEq(`[{"name": "Alice", "Untouched": "value"}]`, s.response.Body.String())
+	})
+
+	s.Run("Without_Middleware", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(errorCapture.CapturedError)
+		s.JSONEq(`[{"name": "Alice", "Untouched": "value"}]`, s.response.Body.String())
+	})
+
+	s.Run("XML", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		s.request.Header.Set("Accept", "application/xml")
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+			turtleware.FieldRolesMiddleware(s.fieldFilterRoles("user")),
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(errorCapture.CapturedError)
+		s.Contains(s.response.Body.String(), "<testFieldFilterModel>")
+		s.Contains(s.response.Body.String(), "<Name>Alice</Name>")
+		s.NotContains(s.response.Body.String(), "Salary")
+	})
+}
+
+func (s *FieldFilterSuite) Test_FilterFields() {
+	ctx := context.Background()
+
+	model := testFieldFilterModel{Name: "Alice", Salary: 1337}
+
+	cases := map[string]struct {
+		data     interface{}
+		expected interface{}
+	}{
+		"Struct": {
+			data:     model,
+			expected: testFieldFilterModel{Name: "Alice"},
+		},
+		"Pointer": {
+			data:     &model,
+			expected: &testFieldFilterModel{Name: "Alice"},
+		},
+		"Map": {
+			data:     map[string]testFieldFilterModel{"alice": model},
+			expected: map[string]testFieldFilterModel{"alice": {Name: "Alice"}},
+		},
+		"Interface_Map": {
+			data:     map[string]interface{}{"alice": model, "plain": "plain"},
+			expected: map[string]interface{}{"alice": testFieldFilterModel{Name: "Alice"}, "plain": "plain"},
+		},
+		"Interface_Slice": {
+			data:     []interface{}{model},
+			expected: []interface{}{testFieldFilterModel{Name: "Alice"}},
+		},
+		"Plain": {
+			data:     []interface{}{"plain"},
+			expected: []interface{}{"plain"},
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			filtered := turtleware.FilterFields(ctx, target.data)
+
+			// then
+			s.Equal(target.expected, filtered)
+			s.Equal(1337, model.Salary)
+		})
+	}
+}
+
+type testFieldFilterNotOmitEmptyModel struct {
+	Name   string `json:"name"`
+	Salary int    `json:"salary" turtleware:"roles=admin"`
+}
+
+type testFieldFilterNotOmitEmptyXMLModel struct {
+	Name   string `json:"name"`
+	Salary int    `json:"salary,omitempty" turtleware:"roles=admin"`
+}
+
+type testFieldFilterStructModel struct {
+	Name    string               `json:"name"`
+	Details testFieldFilterModel `json:"details,omitempty" xml:",omitempty" turtleware:"roles=admin"`
+}
+
+func (s *FieldFilterSuite) Test_FilterFields_Not_OmitEmpty() {
+	cases := map[string]struct {
+		data interface{}
+	}{
+		"JSON":   {data: testFieldFilterNotOmitEmptyModel{Name: "Alice", Salary: 1337}},
+		"XML":    {data: []testFieldFilterNotOmitEmptyXMLModel{{Name: "Alice", Salary: 1337}}},
+		"Struct": {data: &testFieldFilterStructModel{Name: "Alice"}},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			var recovered interface{}
+			func() {
+				defer func() {
+					recovered = recover()
+				}()
+
+				turtleware.FilterFields(context.Background(), target.data)
+			}()
+
+			// then
+			err, ok := recovered.(error)
+			s.Require().True(ok)
+			s.ErrorIs(err, turtleware.ErrRestrictedFieldNotOmitEmpty)
+		})
+	}
+}
+
+func (s *FieldFilterSuite) Test_FieldRolesMiddleware_Error() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	rolesFunc := func(r *http.Request) ([]string, error) {
+		return nil, errors.New("some-error")
+	}
+
+	// when
+	turtleware.FieldRolesMiddleware(rolesFunc)(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusInternalServerError, s.response.Code)
+}
+
+func (s *FieldFilterSuite) Test_FieldRolesFromRequestContext_Error() {
+	// when
+	roles, err := turtleware.FieldRolesFromRequestContext(context.Background())
+
+	// then
+	s.Nil(roles)
+	s.ErrorIs(err, turtleware.ErrContextMissingFieldRoles)
+}
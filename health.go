@@ -0,0 +1,124 @@
+package turtleware
+
+import (
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/rs/zerolog"
+
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// ErrEmptyKeySet indicates that a key set contains no keys (see KeySetHealthCheck).
+var ErrEmptyKeySet = errors.New("key set is empty")
+
+// HealthCheck is a function for checking the health of a dependency of the service,
+// for the HealthHandler. A returned error marks the check as failing.
+type HealthCheck func(ctx context.Context) error
+
+// NamedHealthCheck is a HealthCheck, which is reported under the given name
+// in the summary of the HealthHandler.
+type NamedHealthCheck struct {
+	Name  string
+	Check HealthCheck
+}
+
+const (
+	healthStatusOK      = "ok"
+	healthStatusFailing = "failing"
+)
+
+type healthCheckResult struct {
+	Name   string `json:"name" xml:"Name"`
+	Status string `json:"status" xml:"Status"`
+}
+
+type healthResponse struct {
+	XMLName xml.Name            `xml:"HealthResponse" json:"-"`
+	Status  string              `json:"status" xml:"Status"`
+	Checks  []healthCheckResult `json:"checks" xml:"Checks>Check"`
+}
+
+// HealthHandler is a handler for health and readiness probes (e.g. of Kubernetes). All provided
+// checks are run concurrently, and summarized in the response body by name, in the order
+// the checks were provided. If all checks succeed, the handler answers with a 200 - otherwise
+// with a 503 Service Unavailable.
+// The handler requires no auth middlewares, as the summary only contains the name and status of
+// each check. The errors of failing checks are logged, but never exposed in the response.
+// Only GET and HEAD requests are served, any other method is answered with a 405 (see RestrictMethods).
+func HealthHandler(checks ...NamedHealthCheck) http.Handler {
+	return RestrictMethods(http.MethodGet, http.MethodHead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := healthResponse{
+			Status: healthStatusOK,
+			Checks: make([]healthCheckResult, len(checks)),
+		}
+
+		errs := make([]error, len(checks))
+
+		wg := sync.WaitGroup{}
+		for i, check := range checks {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				errs[i] = runHealthCheck(r.Context(), check.Check)
+			}()
+		}
+
+		wg.Wait()
+
+		code := http.StatusOK
+		for i, check := range checks {
+			response.Checks[i] = healthCheckResult{Name: check.Name, Status: healthStatusOK}
+
+			if errs[i] != nil {
+				zerolog.Ctx(r.Context()).Warn().Err(errs[i]).Str("check", check.Name).Msg("Health check failing")
+
+				response.Checks[i].Status = healthStatusFailing
+				response.Status = healthStatusFailing
+				code = http.StatusServiceUnavailable
+			}
+		}
+
+		WriteJSON(r.Context(), w, r, code, response)
+	}))
+}
+
+func runHealthCheck(ctx context.Context, check HealthCheck) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+
+	return check(ctx)
+}
+
+// KeySetHealthCheck creates a HealthCheck, which verifies that tokens can be validated via the
+// given key set. The check fails with ErrEmptyKeySet if the key set contains no keys, and with
+// the respective error if any of its keys cannot be converted into a raw key.
+func KeySetHealthCheck(keySet jwk.Set) HealthCheck {
+	return func(ctx context.Context) error {
+		if keySet == nil || keySet.Len() == 0 {
+			return ErrEmptyKeySet
+		}
+
+		for i := 0; i < keySet.Len(); i++ {
+			key, ok := keySet.Key(i)
+			if !ok {
+				return ErrEmptyKeySet
+			}
+
+			var raw interface{}
+			if err := key.Raw(&raw); err != nil {
+				return fmt.Errorf("key %q: %w", key.KeyID(), err)
+			}
+		}
+
+		return nil
+	}
+}
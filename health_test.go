@@ -0,0 +1,166 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/suite"
+
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type HealthSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestHealthSuite(t *testing.T) {
+	suite.Run(t, &HealthSuite{})
+}
+
+func (s *HealthSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/health", http.NoBody)
+}
+
+func (s *HealthSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *HealthSuite) Test_HealthHandler() {
+	healthy := func(ctx context.Context) error {
+		return nil
+	}
+
+	failing := func(ctx context.Context) error {
+		return errors.New("some-error")
+	}
+
+	panicking := func(ctx context.Context) error {
+		panic("check exploded")
+	}
+
+	cases := map[string]struct {
+		checks     []turtleware.NamedHealthCheck
+		statusCode int
+		expected   string
+	}{
+		"No_Checks": {
+			statusCode: http.StatusOK,
+			expected:   `{"status":"ok","checks":[]}`,
+		},
+		"Healthy": {
+			checks: []turtleware.NamedHealthCheck{
+				{Name: "first", Check: healthy},
+				{Name: "second", Check: healthy},
+			},
+			statusCode: http.StatusOK,
+			expected:   `{"status":"ok","checks":[{"name":"first","status":"ok"},{"name":"second","status":"ok"}]}`,
+		},
+		"Failing": {
+			checks: []turtleware.NamedHealthCheck{
+				{Name: "first", Check: healthy},
+				{Name: "second", Check: failing},
+			},
+			statusCode: http.StatusServiceUnavailable,
+			expected:   `{"status":"failing","checks":[{"name":"first","status":"ok"},{"name":"second","status":"failing"}]}`,
+		},
+		"Panicking": {
+			checks: []turtleware.NamedHealthCheck{
+				{Name: "exploding", Check: panicking},
+			},
+			statusCode: http.StatusServiceUnavailable,
+			expected:   `{"status":"failing","checks":[{"name":"exploding","status":"failing"}]}`,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			turtleware.HealthHandler(target.checks...).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.statusCode, s.response.Code)
+			s.Equal("no-store", s.response.Header().Get("Cache-Control"))
+			s.JSONEq(target.expected, s.response.Body.String())
+			s.NotContains(s.response.Body.String(), "some-error")
+			s.NotContains(s.response.Body.String(), "check exploded")
+		})
+	}
+}
+
+func (s *HealthSuite) Test_HealthHandler_Log() {
+	// given
+	logBuffer := &bytes.Buffer{}
+	logger := zerolog.New(logBuffer)
+	s.request = s.request.WithContext(logger.WithContext(s.request.Context()))
+
+	failing := turtleware.NamedHealthCheck{
+		Name: "database",
+		Check: func(ctx context.Context) error {
+			return errors.New("connection refused by 10.0.0.1")
+		},
+	}
+
+	// when
+	turtleware.HealthHandler(failing).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusServiceUnavailable, s.response.Code)
+	s.NotContains(s.response.Body.String(), "connection refused")
+	s.Contains(logBuffer.String(), `"check":"database"`)
+	s.Contains(logBuffer.String(), `"error":"connection refused by 10.0.0.1"`)
+}
+
+func (s *HealthSuite) Test_HealthHandler_MethodNotAllowed() {
+	// given
+	s.request.Method = http.MethodPost
+
+	// when
+	turtleware.HealthHandler().ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusMethodNotAllowed, s.response.Code)
+}
+
+func (s *HealthSuite) Test_KeySetHealthCheck() {
+	s.Run("Valid", func() {
+		// given
+		key, err := jwk.FromRaw([]byte("secret-passphrase"))
+		s.Require().NoError(err)
+
+		keySet := jwk.NewSet()
+		s.Require().NoError(keySet.AddKey(key))
+
+		// when
+		err = turtleware.KeySetHealthCheck(keySet)(context.Background())
+
+		// then
+		s.NoError(err)
+	})
+
+	s.Run("Empty", func() {
+		// when
+		err := turtleware.KeySetHealthCheck(jwk.NewSet())(context.Background())
+
+		// then
+		s.ErrorIs(err, turtleware.ErrEmptyKeySet)
+	})
+
+	s.Run("Nil", func() {
+		// when
+		err := turtleware.KeySetHealthCheck(nil)(context.Background())
+
+		// then
+		s.ErrorIs(err, turtleware.ErrEmptyKeySet)
+	})
+}
@@ -0,0 +1,313 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+var (
+	// ErrIdempotencyKeyReused indicates that an idempotency key was reused for a request
+	// with a different body (see IdempotencyMiddleware).
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with different request body")
+
+	// ErrIdempotencyKeyInFlight indicates that an idempotency key was repeated, while the
+	// request originally carrying it is still being handled (see IdempotencyMiddleware).
+	ErrIdempotencyKeyInFlight = errors.New("request with idempotency key still in progress")
+)
+
+// IdempotentResponse is a response stored by the IdempotencyMiddleware. Pending responses
+// mark requests, which are still being handled.
+type IdempotentResponse struct {
+	RequestHash string
+	Pending     bool
+	StatusCode  int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyStore is the contract for stores used by the IdempotencyMiddleware. Keys consist
+// of the idempotency key of the request, scoped to the authenticated principal (see IdempotencyScope),
+// and the method and path of the request.
+// Implementations must be safe for concurrent use.
+type IdempotencyStore interface {
+	// Get returns the response stored for the given key, and whether one was found.
+	Get(ctx context.Context, key string) (IdempotentResponse, bool, error)
+
+	// Add stores the response for the given key, for at most the given TTL - unless a response
+	// is already stored for the key. It reports whether the response was stored, and must be
+	// atomic, so concurrent requests with the same key cannot both reserve it.
+	Add(ctx context.Context, key string, response IdempotentResponse, ttl time.Duration) (bool, error)
+
+	// Set stores the response for the given key, for at most the given TTL.
+	Set(ctx context.Context, key string, response IdempotentResponse, ttl time.Duration) error
+
+	// Delete removes the response stored for the given key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// IdempotencyScopeFunc is a function for deriving the scope of an idempotency key, so
+// keys of different principals never collide. An empty scope marks requests without a
+// principal, which are not handled idempotently.
+type IdempotencyScopeFunc func(r *http.Request) string
+
+type idempotencyOptions struct {
+	ttl          time.Duration
+	pendingTTL   time.Duration
+	maxBodyBytes int64
+	headerName   string
+	scopeFunc    IdempotencyScopeFunc
+}
+
+// IdempotencyOption represents an option for the IdempotencyMiddleware.
+type IdempotencyOption func(*idempotencyOptions)
+
+// IdempotencyTTL sets the duration responses are replayed for repeated idempotency keys.
+// The default is 24 hours.
+func IdempotencyTTL(ttl time.Duration) IdempotencyOption {
+	return func(c *idempotencyOptions) {
+		c.ttl = ttl
+	}
+}
+
+// IdempotencyPendingTTL sets the duration an idempotency key is reserved for, while the request
+// carrying it is being handled. This bounds the time a key stays blocked, if the handling
+// never finishes (e.g. due to a crash).
+// The default is one minute.
+func IdempotencyPendingTTL(pendingTTL time.Duration) IdempotencyOption {
+	return func(c *idempotencyOptions) {
+		c.pendingTTL = pendingTTL
+	}
+}
+
+// IdempotencyMaxBodyBytes sets the maximum size of request bodies in bytes, enforced via
+// http.MaxBytesReader, as the body is read completely for hashing. Larger bodies are rejected
+// with ErrMarshalling.
+// The default is 1 MiB.
+func IdempotencyMaxBodyBytes(maxBodyBytes int64) IdempotencyOption {
+	return func(c *idempotencyOptions) {
+		c.maxBodyBytes = maxBodyBytes
+	}
+}
+
+// IdempotencyHeader sets the name of the header, which carries the idempotency key.
+// The default is Idempotency-Key.
+func IdempotencyHeader(headerName string) IdempotencyOption {
+	return func(c *idempotencyOptions) {
+		c.headerName = headerName
+	}
+}
+
+// IdempotencyScope sets the function for deriving the scope of idempotency keys.
+// The default is DefaultIdempotencyScope.
+func IdempotencyScope(scopeFunc IdempotencyScopeFunc) IdempotencyOption {
+	return func(c *idempotencyOptions) {
+		c.scopeFunc = scopeFunc
+	}
+}
+
+// DefaultIdempotencyScope scopes idempotency keys by the user UUID of the auth claims
+// (see UserUUIDFromRequestContext). Requests without auth claims have an empty scope, so
+// they are not handled idempotently.
+func DefaultIdempotencyScope(r *http.Request) string {
+	if userUUID, err := UserUUIDFromRequestContext(r.Context()); err == nil {
+		return "user:" + userUUID
+	}
+
+	return ""
+}
+
+// IdempotencyMiddleware is an opt-in middleware for making requests retryable without
+// repeating their effects, as identified via the Idempotency-Key header of the request.
+// The first response for a key is stored in the provided IdempotencyStore, and replayed for
+// any repeated request with the same key until the TTL elapses - without calling the next
+// handler (e.g. the ResourceCreateMiddleware) again. Replayed responses carry an
+// Idempotent-Replayed header. Headers belonging to a single request (such as X-Request-Id,
+// the trace context or Set-Cookie) are neither stored nor replayed. If a key is repeated with a different request body, the request
+// is answered with a 422 Unprocessable Entity and ErrIdempotencyKeyReused. If a key is repeated
+// while the original request is still being handled, the request is answered with a 409 Conflict
+// and ErrIdempotencyKeyInFlight.
+// Keys are scoped via DefaultIdempotencyScope by default, which requires the auth claims - so the
+// middleware belongs after the AuthClaimsMiddleware, and before the create middleware in the chain.
+// Keys are also scoped to the method and path of the request, so a key cannot replay the response
+// of another endpoint.
+// Requests without the header or without a scope (e.g. anonymous requests), GET and HEAD requests,
+// and responses with a 5xx status code are passed through without being stored, so failed requests
+// can be retried.
+// Errors of the IdempotencyStore are only logged, and the request is handled as a new one.
+func IdempotencyMiddleware(store IdempotencyStore, opts ...IdempotencyOption) func(http.Handler) http.Handler {
+	// default
+	config := &idempotencyOptions{
+		ttl:          24 * time.Hour,
+		pendingTTL:   time.Minute,
+		maxBodyBytes: 1 << 20,
+		headerName:   "Idempotency-Key",
+		scopeFunc:    DefaultIdempotencyScope,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := zerolog.Ctx(r.Context())
+
+			idempotencyKey := r.Header.Get(config.headerName)
+			if idempotencyKey == "" || r.Method == http.MethodGet || r.Method == http.MethodHead {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			scope := config.scopeFunc(r)
+			if scope == "" {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, config.maxBodyBytes))
+			if err != nil {
+				WriteError(r.Context(), w, r, http.StatusBadRequest, bodyError(err))
+
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			requestHash := sha256.Sum256(body)
+			key := scope + ":" + r.Method + ":" + r.URL.Path + ":" + idempotencyKey
+
+			pending := IdempotentResponse{RequestHash: hex.EncodeToString(requestHash[:]), Pending: true}
+
+			reserved, err := store.Add(r.Context(), key, pending, config.pendingTTL)
+			if err != nil {
+				logger.Warn().Err(err).Msg("Failed to reserve idempotency key")
+			} else if !reserved {
+				replayIdempotentResponse(w, r, store, key, pending.RequestHash)
+
+				return
+			}
+
+			writer := &cachingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			h.ServeHTTP(writer, r)
+
+			if writer.statusCode >= http.StatusInternalServerError {
+				if reserved {
+					if err := store.Delete(r.Context(), key); err != nil {
+						logger.Warn().Err(err).Msg("Failed to release idempotency key")
+					}
+				}
+
+				return
+			}
+
+			if err := store.Set(r.Context(), key, IdempotentResponse{
+				RequestHash: pending.RequestHash,
+				StatusCode:  writer.statusCode,
+				Header:      replayableHeader(writer.Header()),
+				Body:        writer.body.Bytes(),
+			}, config.ttl); err != nil {
+				logger.Warn().Err(err).Msg("Failed to store idempotent response")
+			}
+		})
+	}
+}
+
+// replayIdempotentResponse answers a request with an already reserved idempotency key.
+func replayIdempotentResponse(w http.ResponseWriter, r *http.Request, store IdempotencyStore, key string, requestHash string) {
+	logger := zerolog.Ctx(r.Context())
+
+	stored, found, err := store.Get(r.Context(), key)
+	if err != nil || !found {
+		// The reservation vanished in between, or cannot be read - so its state is unknown
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to receive idempotent response")
+		}
+
+		WriteError(r.Context(), w, r, http.StatusConflict, ErrIdempotencyKeyInFlight)
+
+		return
+	}
+
+	if stored.RequestHash != requestHash {
+		WriteError(r.Context(), w, r, http.StatusUnprocessableEntity, ErrIdempotencyKeyReused)
+
+		return
+	}
+
+	if stored.Pending {
+		WriteError(r.Context(), w, r, http.StatusConflict, ErrIdempotencyKeyInFlight)
+
+		return
+	}
+
+	logger.Debug().Msg("Replaying response for idempotency key")
+	writeIdempotentResponse(w, stored)
+}
+
+func writeIdempotentResponse(w http.ResponseWriter, stored IdempotentResponse) {
+	writeReplayedHeader(w, stored.Header)
+
+	w.Header().Set("Idempotent-Replayed", "true")
+	w.WriteHeader(stored.StatusCode)
+
+	_, _ = w.Write(stored.Body)
+}
+
+// MemoryIdempotencyStore is an in-memory implementation of IdempotencyStore.
+// Expired entries are evicted lazily, when accessed, and swept once the store is full.
+type MemoryIdempotencyStore struct {
+	entries *expiringMap[IdempotentResponse]
+}
+
+// NewMemoryIdempotencyStore creates a new, empty MemoryIdempotencyStore, which holds at
+// most DefaultMemoryMaxEntries entries.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return NewMemoryIdempotencyStoreWithMaxEntries(DefaultMemoryMaxEntries)
+}
+
+// NewMemoryIdempotencyStoreWithMaxEntries creates a new, empty MemoryIdempotencyStore, which
+// holds at most the given number of entries. If the store is full, and no entry is expired yet,
+// the entry expiring the soonest is evicted.
+func NewMemoryIdempotencyStoreWithMaxEntries(maxEntries int) *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		entries: newExpiringMap[IdempotentResponse](maxEntries),
+	}
+}
+
+// Get returns the response stored for the given key, if it is not expired yet.
+func (store *MemoryIdempotencyStore) Get(_ context.Context, key string) (IdempotentResponse, bool, error) {
+	response, found := store.entries.get(key)
+
+	return response, found, nil
+}
+
+// Add stores the response for the given key, for at most the given TTL - unless a
+// response is already stored for the key.
+func (store *MemoryIdempotencyStore) Add(_ context.Context, key string, response IdempotentResponse, ttl time.Duration) (bool, error) {
+	return store.entries.add(key, response, ttl), nil
+}
+
+// Set stores the response for the given key, for at most the given TTL.
+func (store *MemoryIdempotencyStore) Set(_ context.Context, key string, response IdempotentResponse, ttl time.Duration) error {
+	store.entries.set(key, response, ttl)
+
+	return nil
+}
+
+// Delete removes the response stored for the given key, if any.
+func (store *MemoryIdempotencyStore) Delete(_ context.Context, key string) error {
+	store.entries.delete(key)
+
+	return nil
+}
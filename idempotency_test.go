@@ -0,0 +1,348 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type IdempotencySuite struct {
+	CommonSuite
+
+	store *turtleware.MemoryIdempotencyStore
+}
+
+func TestIdempotencySuite(t *testing.T) {
+	suite.Run(t, &IdempotencySuite{})
+}
+
+func (s *IdempotencySuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.store = turtleware.NewMemoryIdempotencyStore()
+}
+
+func (s *IdempotencySuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *IdempotencySuite) request(method string, idempotencyKey string, body string) *http.Request {
+	request := httptest.NewRequest(method, "https://example.com/foo", strings.NewReader(body))
+	if idempotencyKey != "" {
+		request.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	return request
+}
+
+// middleware returns the IdempotencyMiddleware with a fixed scope, as the
+// requests carry no auth claims.
+func (s *IdempotencySuite) middleware(opts ...turtleware.IdempotencyOption) func(http.Handler) http.Handler {
+	scope := turtleware.IdempotencyScope(func(r *http.Request) string {
+		return "user:some-user"
+	})
+
+	return turtleware.IdempotencyMiddleware(s.store, append([]turtleware.IdempotencyOption{scope}, opts...)...)
+}
+
+// creatingHandler returns a handler, which counts its calls, and responds with
+// the given status code and the request body.
+func (s *IdempotencySuite) creatingHandler(calls *int, statusCode int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+
+		body, err := io.ReadAll(r.Body)
+		s.Require().NoError(err)
+
+		w.Header().Set("Location", "/foo/some-uuid")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write(body)
+	})
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_Replay() {
+	// given
+	calls := 0
+	handler := s.middleware()(s.creatingHandler(&calls, http.StatusCreated))
+
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	response := httptest.NewRecorder()
+
+	// when
+	handler.ServeHTTP(response, s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// then
+	s.Equal(1, calls)
+	s.Equal(http.StatusCreated, response.Code)
+	s.Equal("/foo/some-uuid", response.Header().Get("Location"))
+	s.Equal("true", response.Header().Get("Idempotent-Replayed"))
+	s.Equal(`{"some":"data"}`, response.Body.String())
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_Replay_Per_Request_Headers() {
+	// given
+	calls := 0
+	handler := turtleware.ErrorIdentifiersMiddleware(s.middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.Header().Set("Set-Cookie", "session=some-session")
+		w.Header().Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+		w.WriteHeader(http.StatusCreated)
+	})))
+
+	firstRequest := s.request(http.MethodPost, "some-key", `{"some":"data"}`)
+	firstRequest.Header.Set("X-Request-Id", "first-request")
+	handler.ServeHTTP(httptest.NewRecorder(), firstRequest)
+
+	secondRequest := s.request(http.MethodPost, "some-key", `{"some":"data"}`)
+	secondRequest.Header.Set("X-Request-Id", "second-request")
+
+	response := httptest.NewRecorder()
+
+	// when
+	handler.ServeHTTP(response, secondRequest)
+
+	// then
+	s.Equal(1, calls)
+	s.Equal(http.StatusCreated, response.Code)
+	s.Equal("true", response.Header().Get("Idempotent-Replayed"))
+	s.Equal("second-request", response.Header().Get("X-Request-Id"))
+	s.Empty(response.Header().Get("Set-Cookie"))
+	s.Empty(response.Header().Get("Traceparent"))
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_DifferentBody() {
+	// given
+	calls := 0
+	handler := s.middleware()(s.creatingHandler(&calls, http.StatusCreated))
+
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	response := httptest.NewRecorder()
+
+	// when
+	handler.ServeHTTP(response, s.request(http.MethodPost, "some-key", `{"other":"data"}`))
+
+	// then
+	s.Equal(1, calls)
+	s.Equal(http.StatusUnprocessableEntity, response.Code)
+	s.Contains(response.Body.String(), turtleware.ErrIdempotencyKeyReused.Error())
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_PassThrough() {
+	cases := map[string]struct {
+		method     string
+		key        string
+		statusCode int
+	}{
+		"No_Key":       {method: http.MethodPost, statusCode: http.StatusCreated},
+		"GET":          {method: http.MethodGet, key: "some-key", statusCode: http.StatusOK},
+		"Server_Error": {method: http.MethodPost, key: "some-key", statusCode: http.StatusInternalServerError},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			calls := 0
+			handler := s.middleware()(s.creatingHandler(&calls, target.statusCode))
+
+			// when
+			handler.ServeHTTP(httptest.NewRecorder(), s.request(target.method, target.key, `{"some":"data"}`))
+			handler.ServeHTTP(httptest.NewRecorder(), s.request(target.method, target.key, `{"some":"data"}`))
+
+			// then
+			s.Equal(2, calls)
+		})
+	}
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_Scope() {
+	// given
+	calls := 0
+
+	scope := "first"
+	handler := s.middleware(
+		turtleware.IdempotencyScope(func(r *http.Request) string { return scope }),
+	)(s.creatingHandler(&calls, http.StatusCreated))
+
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// when
+	scope = "second"
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// then
+	s.Equal(2, calls)
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_DefaultScope() {
+	// given
+	var recordedScope string
+
+	scopeCapture := func(r *http.Request) string {
+		recordedScope = turtleware.DefaultIdempotencyScope(r)
+		return recordedScope
+	}
+
+	handler := turtleware.IdempotencyMiddleware(s.store, turtleware.IdempotencyScope(scopeCapture))(&MiddlewareCapture{})
+
+	// when
+	s.buildAuthChain(handler).ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// then
+	s.Equal("user:"+s.userUUID, recordedScope)
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_Expired() {
+	// given
+	calls := 0
+	handler := s.middleware(
+		turtleware.IdempotencyTTL(time.Nanosecond),
+	)(s.creatingHandler(&calls, http.StatusCreated))
+
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+	time.Sleep(time.Millisecond)
+
+	// when
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// then
+	s.Equal(2, calls)
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_Anonymous() {
+	// given
+	calls := 0
+	handler := turtleware.IdempotencyMiddleware(s.store)(s.creatingHandler(&calls, http.StatusCreated))
+
+	// when
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// then
+	s.Equal(2, calls)
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_DifferentEndpoint() {
+	// given
+	calls := 0
+	handler := s.middleware()(s.creatingHandler(&calls, http.StatusCreated))
+
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	request := s.request(http.MethodPost, "some-key", `{"some":"data"}`)
+	request.URL.Path = "/bar"
+
+	// when
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+
+	// then
+	s.Equal(2, calls)
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_InFlight() {
+	// given
+	var handler http.Handler
+
+	retryResponse := httptest.NewRecorder()
+	handler = s.middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The client retries, while the original request is still being handled
+		if retryResponse.Body.Len() == 0 {
+			handler.ServeHTTP(retryResponse, s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+		}
+
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	response := httptest.NewRecorder()
+
+	// when
+	handler.ServeHTTP(response, s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// then
+	s.Equal(http.StatusCreated, response.Code)
+	s.Equal(http.StatusConflict, retryResponse.Code)
+	s.Contains(retryResponse.Body.String(), turtleware.ErrIdempotencyKeyInFlight.Error())
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_ServerError_Releases() {
+	// given
+	statusCode := http.StatusInternalServerError
+
+	calls := 0
+	handler := s.middleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(statusCode)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	response := httptest.NewRecorder()
+
+	// when
+	statusCode = http.StatusCreated
+	handler.ServeHTTP(response, s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// then
+	s.Equal(2, calls)
+	s.Equal(http.StatusCreated, response.Code)
+}
+
+func (s *IdempotencySuite) Test_IdempotencyMiddleware_MaxBodyBytes() {
+	// given
+	calls := 0
+	handler := s.middleware(turtleware.IdempotencyMaxBodyBytes(4))(s.creatingHandler(&calls, http.StatusCreated))
+
+	response := httptest.NewRecorder()
+
+	// when
+	handler.ServeHTTP(response, s.request(http.MethodPost, "some-key", `{"some":"data"}`))
+
+	// then
+	s.Equal(0, calls)
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.Contains(response.Body.String(), "body exceeds limit of 4 bytes")
+}
+
+func (s *IdempotencySuite) Test_MemoryIdempotencyStore_Add() {
+	// given
+	ctx := context.Background()
+
+	// when
+	added, err := s.store.Add(ctx, "key", turtleware.IdempotentResponse{Pending: true}, time.Minute)
+	addedAgain, errAgain := s.store.Add(ctx, "key", turtleware.IdempotentResponse{}, time.Minute)
+	s.Require().NoError(s.store.Delete(ctx, "key"))
+	_, found, _ := s.store.Get(ctx, "key")
+
+	// then
+	s.NoError(err)
+	s.True(added)
+	s.NoError(errAgain)
+	s.False(addedAgain)
+	s.False(found)
+}
+
+func (s *IdempotencySuite) Test_MemoryIdempotencyStore() {
+	// given
+	ctx := context.Background()
+	s.Require().NoError(s.store.Set(ctx, "key", turtleware.IdempotentResponse{StatusCode: http.StatusCreated}, time.Minute))
+
+	// when
+	response, found, err := s.store.Get(ctx, "key")
+	_, foundOther, errOther := s.store.Get(ctx, "other-key")
+
+	// then
+	s.NoError(err)
+	s.True(found)
+	s.Equal(http.StatusCreated, response.StatusCode)
+	s.NoError(errOther)
+	s.False(foundOther)
+}
@@ -0,0 +1,49 @@
+package turtleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidInclude indicates that the query contained an include
+// parameter value, which is not allowed for the requested endpoint.
+var ErrInvalidInclude = errors.New("invalid include parameter")
+
+// ParseIncludesFromRequest parses the comma separated include query
+// parameter (e.g. ?include=author,comments) from a given request.
+// Each requested include must be part of the provided allowlist, or
+// ErrInvalidInclude is returned. Duplicates are removed, while the
+// order of first occurrence is preserved.
+func ParseIncludesFromRequest(r *http.Request, allowed ...string) ([]string, error) {
+	allowlist := make(map[string]struct{}, len(allowed))
+	for _, include := range allowed {
+		allowlist[include] = struct{}{}
+	}
+
+	includes := make([]string, 0)
+	seen := make(map[string]struct{})
+
+	for _, value := range r.URL.Query()["include"] {
+		for _, include := range strings.Split(value, ",") {
+			include = strings.TrimSpace(include)
+			if include == "" {
+				continue
+			}
+
+			if _, ok := allowlist[include]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidInclude, include)
+			}
+
+			if _, ok := seen[include]; ok {
+				continue
+			}
+
+			seen[include] = struct{}{}
+			includes = append(includes, include)
+		}
+	}
+
+	return includes, nil
+}
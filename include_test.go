@@ -0,0 +1,112 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type IncludeSuite struct {
+	CommonSuite
+}
+
+func TestIncludeSuite(t *testing.T) {
+	suite.Run(t, &IncludeSuite{})
+}
+
+func (s *IncludeSuite) Test_ParseIncludesFromRequest() {
+	s.Run("Valid_Includes", func() {
+		// given
+		r := buildTestRequest(map[string]string{
+			"include": "author, comments,author,",
+		})
+
+		// when
+		includes, err := turtleware.ParseIncludesFromRequest(r, "author", "comments", "tags")
+
+		// then
+		s.NoError(err)
+		s.Equal([]string{"author", "comments"}, includes)
+	})
+
+	s.Run("Disallowed_Include", func() {
+		// given
+		r := buildTestRequest(map[string]string{
+			"include": "author,password",
+		})
+
+		// when
+		includes, err := turtleware.ParseIncludesFromRequest(r, "author", "comments")
+
+		// then
+		s.ErrorIs(err, turtleware.ErrInvalidInclude)
+		s.Nil(includes)
+	})
+
+	s.Run("No_Parameters", func() {
+		// given
+		r := buildTestRequest(nil)
+
+		// when
+		includes, err := turtleware.ParseIncludesFromRequest(r, "author")
+
+		// then
+		s.NoError(err)
+		s.Empty(includes)
+	})
+}
+
+func (s *IncludeSuite) Test_IncludeMiddleware() {
+	s.Run("Success", func() {
+		// given
+		response := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "https://example.com/foo?include=comments", http.NoBody)
+
+		var recordedIncludes []string
+		middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			includes, err := turtleware.IncludesFromRequestContext(r.Context())
+			s.Require().NoError(err)
+
+			recordedIncludes = includes
+		})
+
+		// when
+		turtleware.IncludeMiddleware("author", "comments")(middlewareVerify).ServeHTTP(response, request)
+
+		// then
+		s.Equal([]string{"comments"}, recordedIncludes)
+		s.Empty(response.Body.String())
+	})
+
+	s.Run("ErrInvalidInclude", func() {
+		// given
+		response := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodGet, "https://example.com/foo?include=secrets", http.NoBody)
+
+		middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			s.Fail("unexpected middleware invocation")
+		})
+
+		// when
+		turtleware.IncludeMiddleware("author", "comments")(middlewareVerify).ServeHTTP(response, request)
+
+		// then
+		s.Equal(http.StatusBadRequest, response.Code)
+	})
+}
+
+func (s *IncludeSuite) Test_IncludesFromRequestContext_Error() {
+	// given
+	ctx := context.Background()
+
+	// when
+	includes, err := turtleware.IncludesFromRequestContext(ctx)
+
+	// then
+	s.Nil(includes)
+	s.ErrorIs(err, turtleware.ErrContextMissingIncludes)
+}
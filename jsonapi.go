@@ -0,0 +1,47 @@
+package turtleware
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// JSONAPIDocument is the top-level document of responses in the JSON:API mode of the
+// data handlers (see ListDataJSONAPI and ResourceDataJSONAPI). Data is either a list
+// of resources, or a single resource.
+type JSONAPIDocument struct {
+	Data interface{}  `json:"data"`
+	Meta *JSONAPIMeta `json:"meta,omitempty"`
+}
+
+// JSONAPIMeta is the meta information of a JSONAPIDocument.
+type JSONAPIMeta struct {
+	Total uint64 `json:"total"`
+}
+
+// jsonAPISerializer is a ResponseSerializer, which wraps the response into a JSONAPIDocument,
+// before passing it to the wrapped serializer. If the response carries an exact total count
+// in the first of the count headers (see ListDataCountHeaderNames), it is inlined into the
+// meta information.
+// Requests preferring CSV are passed through as-is, as CSV has no notion of an envelope.
+type jsonAPISerializer struct {
+	serializer       ResponseSerializer
+	countHeaderNames []string
+}
+
+func (s jsonAPISerializer) Write(w http.ResponseWriter, r *http.Request, code int, i interface{}) {
+	if prefersMediaType(r.Header.Values("Accept"), "text/csv") {
+		s.serializer.Write(w, r, code, i)
+
+		return
+	}
+
+	document := JSONAPIDocument{Data: i}
+
+	if countHeaderNames := resolveCountHeaderNames(r.Context(), s.countHeaderNames); len(countHeaderNames) > 0 {
+		if totalCount, err := strconv.ParseUint(w.Header().Get(countHeaderNames[0]), 10, 64); err == nil {
+			document.Meta = &JSONAPIMeta{Total: totalCount}
+		}
+	}
+
+	s.serializer.Write(w, r, code, document)
+}
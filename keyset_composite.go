@@ -0,0 +1,110 @@
+package turtleware
+
+import (
+	"github.com/lestrrat-go/jwx/v2/jwk"
+
+	"context"
+	"errors"
+)
+
+// ErrKeySetImmutable indicates an attempt to modify a key set, which cannot be modified.
+var ErrKeySetImmutable = errors.New("key set cannot be modified")
+
+// KeySource is a source of keys for a CompositeKeySet. Every jwk.Set is a KeySource, so
+// static key sets, key sets read via ReadKeySetFromFolder, and RefreshingKeySets can be
+// combined freely.
+type KeySource interface {
+	LookupKeyID(kid string) (jwk.Key, bool)
+	Keys(ctx context.Context) jwk.KeyIterator
+}
+
+type compositeKeySet struct {
+	sources []KeySource
+}
+
+// CompositeKeySet combines the given sources into a single jwk.Set, which can be passed
+// straight into the AuthClaimsMiddleware. Keys are looked up in the given order of the
+// sources, so the first source containing a key ID wins.
+// Refreshing is up to the individual sources - e.g. for a remote source, a RefreshingKeySet
+// with RefreshingKeySetTolerateInitialErrors can be used, so keys of the other sources
+// still validate while the remote source is unavailable.
+// The returned key set cannot be modified, and all modifications return ErrKeySetImmutable.
+func CompositeKeySet(sources ...KeySource) jwk.Set {
+	return &compositeKeySet{
+		sources: sources,
+	}
+}
+
+func (s *compositeKeySet) LookupKeyID(kid string) (jwk.Key, bool) {
+	for _, source := range s.sources {
+		if key, found := source.LookupKeyID(kid); found {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+// merged returns a snapshot of the keys of all sources, in order of the sources.
+func (s *compositeKeySet) merged() jwk.Set {
+	set := jwk.NewSet()
+
+	for _, source := range s.sources {
+		for iter := source.Keys(context.Background()); iter.Next(context.Background()); {
+			if key, ok := iter.Pair().Value.(jwk.Key); ok {
+				// Keys contained in multiple sources are only added once
+				_ = set.AddKey(key)
+			}
+		}
+	}
+
+	return set
+}
+
+func (s *compositeKeySet) AddKey(jwk.Key) error {
+	return ErrKeySetImmutable
+}
+
+func (s *compositeKeySet) Clear() error {
+	return ErrKeySetImmutable
+}
+
+func (s *compositeKeySet) Key(idx int) (jwk.Key, bool) {
+	return s.merged().Key(idx)
+}
+
+func (s *compositeKeySet) Get(string) (interface{}, bool) {
+	return nil, false
+}
+
+func (s *compositeKeySet) Set(string, interface{}) error {
+	return ErrKeySetImmutable
+}
+
+func (s *compositeKeySet) Remove(string) error {
+	return ErrKeySetImmutable
+}
+
+func (s *compositeKeySet) Index(key jwk.Key) int {
+	return s.merged().Index(key)
+}
+
+func (s *compositeKeySet) Len() int {
+	return s.merged().Len()
+}
+
+func (s *compositeKeySet) RemoveKey(jwk.Key) error {
+	return ErrKeySetImmutable
+}
+
+func (s *compositeKeySet) Keys(ctx context.Context) jwk.KeyIterator {
+	return s.merged().Keys(ctx)
+}
+
+func (s *compositeKeySet) Iterate(ctx context.Context) jwk.HeaderIterator {
+	return jwk.NewSet().Iterate(ctx)
+}
+
+func (s *compositeKeySet) Clone() (jwk.Set, error) {
+	return s.merged(), nil
+}
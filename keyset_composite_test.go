@@ -0,0 +1,131 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type CompositeKeySetSuite struct {
+	CommonSuite
+}
+
+func TestCompositeKeySetSuite(t *testing.T) {
+	suite.Run(t, &CompositeKeySetSuite{})
+}
+
+func (s *CompositeKeySetSuite) keySet(kid string, secret string) jwk.Set {
+	key, err := turtleware.JWKFromPublicKey([]byte(secret), kid)
+	s.Require().NoError(err)
+	s.Require().NoError(key.Set(jwk.AlgorithmKey, jwa.HS256))
+
+	set := jwk.NewSet()
+	s.Require().NoError(set.AddKey(key))
+
+	return set
+}
+
+func (s *CompositeKeySetSuite) Test_CompositeKeySet_RemoteDown() {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	s.T().Cleanup(cancel)
+
+	remoteSource, err := turtleware.NewRefreshingKeySet(ctx, func(ctx context.Context) (jwk.Set, error) {
+		return nil, errors.New("remote down")
+	},
+		turtleware.RefreshingKeySetTolerateInitialErrors(true),
+		turtleware.RefreshingKeySetInterval(time.Millisecond),
+		turtleware.RefreshingKeySetMinInterval(0),
+	)
+	s.Require().NoError(err)
+
+	keySet := turtleware.CompositeKeySet(
+		remoteSource,
+		s.keySet("static-key", "supersecretpassphrase"),
+	)
+
+	token := s.generateToken(
+		jwa.HS256,
+		[]byte("supersecretpassphrase"),
+		map[string]interface{}{"uuid": s.userUUID},
+		map[string]interface{}{jwk.KeyIDKey: "static-key"},
+	)
+
+	// when
+	claims, err := turtleware.ValidateTokenBySet(token, keySet)
+
+	// then
+	s.NoError(err)
+	s.Equal(s.userUUID, claims["uuid"])
+	s.Equal(1, keySet.Len())
+}
+
+func (s *CompositeKeySetSuite) Test_CompositeKeySet_Order() {
+	// given
+	keySet := turtleware.CompositeKeySet(
+		s.keySet("shared-key", "first-secret"),
+		s.keySet("shared-key", "second-secret"),
+		s.keySet("other-key", "other-secret"),
+	)
+
+	s.Run("First_Source_Wins", func() {
+		// given
+		token := s.generateToken(jwa.HS256, []byte("first-secret"), map[string]interface{}{}, map[string]interface{}{jwk.KeyIDKey: "shared-key"})
+
+		// when
+		_, err := turtleware.ValidateTokenBySet(token, keySet)
+
+		// then
+		s.NoError(err)
+	})
+
+	s.Run("Shadowed_Key", func() {
+		// given
+		token := s.generateToken(jwa.HS256, []byte("second-secret"), map[string]interface{}{}, map[string]interface{}{jwk.KeyIDKey: "shared-key"})
+
+		// when
+		_, err := turtleware.ValidateTokenBySet(token, keySet)
+
+		// then
+		s.Error(err)
+	})
+
+	s.Run("Later_Source", func() {
+		// when
+		key, found := keySet.LookupKeyID("other-key")
+
+		// then
+		s.True(found)
+		s.Equal("other-key", key.KeyID())
+		s.Equal(3, keySet.Len())
+	})
+
+	s.Run("Unknown", func() {
+		// when
+		_, found := keySet.LookupKeyID("unknown-key")
+
+		// then
+		s.False(found)
+	})
+}
+
+func (s *CompositeKeySetSuite) Test_CompositeKeySet_Immutable() {
+	// given
+	keySet := turtleware.CompositeKeySet(s.keySet("static-key", "supersecretpassphrase"))
+
+	key, err := turtleware.JWKFromPublicKey([]byte("other-secret"), "other-key")
+	s.Require().NoError(err)
+
+	// when
+	err = keySet.AddKey(key)
+
+	// then
+	s.ErrorIs(err, turtleware.ErrKeySetImmutable)
+	s.Equal(1, keySet.Len())
+}
@@ -0,0 +1,256 @@
+package turtleware
+
+import (
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/rs/zerolog"
+
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// KeySetLoadFunc is a function for (re-)loading a key set, e.g. from a folder.
+type KeySetLoadFunc func(ctx context.Context) (jwk.Set, error)
+
+type refreshingKeySetOptions struct {
+	interval              time.Duration
+	minInterval           time.Duration
+	tolerateInitialErrors bool
+}
+
+// RefreshingKeySetOption represents an option for the RefreshingKeySet.
+type RefreshingKeySetOption func(*refreshingKeySetOptions)
+
+// RefreshingKeySetInterval sets the interval in which the key set is reloaded in the background.
+// An interval of zero disables the background reloading.
+// The default is one hour.
+func RefreshingKeySetInterval(interval time.Duration) RefreshingKeySetOption {
+	return func(c *refreshingKeySetOptions) {
+		c.interval = interval
+	}
+}
+
+// RefreshingKeySetMinInterval sets the minimum interval between two reloads of the key set,
+// which are triggered by a lookup of an unknown key ID. This avoids hammering the source
+// with tokens signed by unknown keys.
+// The default is one minute.
+func RefreshingKeySetMinInterval(minInterval time.Duration) RefreshingKeySetOption {
+	return func(c *refreshingKeySetOptions) {
+		c.minInterval = minInterval
+	}
+}
+
+// RefreshingKeySetTolerateInitialErrors sets whether a failing initial load is tolerated.
+// If so, the error is logged, and the key set starts out empty until a reload succeeds.
+// This is useful for remote sources, which might be temporarily unavailable
+// (see CompositeKeySet).
+// The default is false.
+func RefreshingKeySetTolerateInitialErrors(tolerateInitialErrors bool) RefreshingKeySetOption {
+	return func(c *refreshingKeySetOptions) {
+		c.tolerateInitialErrors = tolerateInitialErrors
+	}
+}
+
+// RefreshingKeySet is a jwk.Set, which is periodically reloaded from its source. Additionally,
+// the key set is reloaded if a key ID is looked up, which is not contained in the key set (e.g.
+// after a key rotation). As it implements jwk.Set, it can be passed straight into the
+// AuthClaimsMiddleware.
+// Modifications of the key set (e.g. via AddKey) are discarded with the next reload.
+type RefreshingKeySet struct {
+	ctx         context.Context
+	loader      KeySetLoadFunc
+	minInterval time.Duration
+
+	current atomic.Value
+
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
+}
+
+// NewRefreshingKeySet creates a new RefreshingKeySet, which loads its keys via the given
+// KeySetLoadFunc. The key set is initially loaded before returning, and any error of
+// the initial load is returned (see RefreshingKeySetTolerateInitialErrors). Failing reloads are logged, and the previous keys are kept.
+// Reloading stops as soon as the given context is canceled.
+func NewRefreshingKeySet(ctx context.Context, loader KeySetLoadFunc, opts ...RefreshingKeySetOption) (*RefreshingKeySet, error) {
+	// default
+	config := &refreshingKeySetOptions{
+		interval:              time.Hour,
+		minInterval:           time.Minute,
+		tolerateInitialErrors: false,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	keySet := &RefreshingKeySet{
+		ctx:         ctx,
+		loader:      loader,
+		minInterval: config.minInterval,
+	}
+
+	if err := keySet.Refresh(ctx); err != nil {
+		if !config.tolerateInitialErrors {
+			return nil, err
+		}
+
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Failed to load key set, starting out empty")
+		keySet.current.Store(jwk.NewSet())
+	}
+
+	if config.interval > 0 {
+		go keySet.refreshPeriodically(config.interval)
+	}
+
+	return keySet, nil
+}
+
+// RefreshingKeySetFromFolder creates a new RefreshingKeySet, which reads its keys from
+// the given folder via ReadKeySetFromFolder.
+func RefreshingKeySetFromFolder(ctx context.Context, path string, opts ...RefreshingKeySetOption) (*RefreshingKeySet, error) {
+	return NewRefreshingKeySet(ctx, func(ctx context.Context) (jwk.Set, error) {
+		return ReadKeySetFromFolder(ctx, path)
+	}, opts...)
+}
+
+func (s *RefreshingKeySet) refreshPeriodically(interval time.Duration) {
+	logger := zerolog.Ctx(s.ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(s.ctx); err != nil {
+				logger.Error().Err(err).Msg("Failed to refresh key set")
+			}
+		}
+	}
+}
+
+// Refresh reloads the key set from its source. If reloading fails, the previous keys are kept.
+func (s *RefreshingKeySet) Refresh(ctx context.Context) error {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	return s.refresh(ctx)
+}
+
+func (s *RefreshingKeySet) refresh(ctx context.Context) error {
+	keySet, err := s.loader(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.current.Store(keySet)
+	s.lastRefresh = time.Now()
+
+	return nil
+}
+
+// refreshOnMiss reloads the key set, if the key set was not reloaded within the minimum
+// interval. It reports if the key set was reloaded.
+func (s *RefreshingKeySet) refreshOnMiss(kid string) bool {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	// Another lookup might have refreshed the key set in the meantime
+	if _, found := s.set().LookupKeyID(kid); found {
+		return true
+	}
+
+	if time.Since(s.lastRefresh) < s.minInterval {
+		return false
+	}
+
+	if err := s.refresh(s.ctx); err != nil {
+		zerolog.Ctx(s.ctx).Error().Err(err).Msgf("Failed to refresh key set for unknown key %s", kid)
+
+		return false
+	}
+
+	return true
+}
+
+func (s *RefreshingKeySet) set() jwk.Set {
+	return s.current.Load().(jwk.Set)
+}
+
+// LookupKeyID returns the first key matching the given key id. If no key matches,
+// the key set is reloaded (see RefreshingKeySetMinInterval), and the lookup is repeated.
+func (s *RefreshingKeySet) LookupKeyID(kid string) (jwk.Key, bool) {
+	if key, found := s.set().LookupKeyID(kid); found {
+		return key, true
+	}
+
+	if !s.refreshOnMiss(kid) {
+		return nil, false
+	}
+
+	return s.set().LookupKeyID(kid)
+}
+
+// AddKey adds the key to the current key set.
+func (s *RefreshingKeySet) AddKey(key jwk.Key) error {
+	return s.set().AddKey(key)
+}
+
+// Clear resets the current key set to the initial state.
+func (s *RefreshingKeySet) Clear() error {
+	return s.set().Clear()
+}
+
+// Key returns the key at the given index of the current key set.
+func (s *RefreshingKeySet) Key(idx int) (jwk.Key, bool) {
+	return s.set().Key(idx)
+}
+
+// Get returns the value of a private field of the current key set.
+func (s *RefreshingKeySet) Get(name string) (interface{}, bool) {
+	return s.set().Get(name)
+}
+
+// Set sets the value of a private field of the current key set.
+func (s *RefreshingKeySet) Set(name string, value interface{}) error {
+	return s.set().Set(name, value)
+}
+
+// Remove removes the private field of the current key set.
+func (s *RefreshingKeySet) Remove(name string) error {
+	return s.set().Remove(name)
+}
+
+// Index returns the index of the given key in the current key set.
+func (s *RefreshingKeySet) Index(key jwk.Key) int {
+	return s.set().Index(key)
+}
+
+// Len returns the number of keys in the current key set.
+func (s *RefreshingKeySet) Len() int {
+	return s.set().Len()
+}
+
+// RemoveKey removes the key from the current key set.
+func (s *RefreshingKeySet) RemoveKey(key jwk.Key) error {
+	return s.set().RemoveKey(key)
+}
+
+// Keys creates an iterator to iterate through all keys of the current key set.
+func (s *RefreshingKeySet) Keys(ctx context.Context) jwk.KeyIterator {
+	return s.set().Keys(ctx)
+}
+
+// Iterate creates an iterator to iterate through all private fields of the current key set.
+func (s *RefreshingKeySet) Iterate(ctx context.Context) jwk.HeaderIterator {
+	return s.set().Iterate(ctx)
+}
+
+// Clone returns a static copy of the current key set.
+func (s *RefreshingKeySet) Clone() (jwk.Set, error) {
+	return s.set().Clone()
+}
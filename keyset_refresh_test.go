@@ -0,0 +1,157 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type RefreshingKeySetSuite struct {
+	CommonSuite
+}
+
+func TestRefreshingKeySetSuite(t *testing.T) {
+	suite.Run(t, &RefreshingKeySetSuite{})
+}
+
+// context returns a context, which is canceled after the current test,
+// so background refreshing stops.
+func (s *RefreshingKeySetSuite) context() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.T().Cleanup(cancel)
+
+	return ctx
+}
+
+func (s *RefreshingKeySetSuite) writeKey(keyFolder string, kid string) ed25519.PrivateKey {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	s.Require().NoError(err)
+	s.Require().NoError(createValidPublicKey(keyFolder, kid+".pub", publicKey))
+
+	return privateKey
+}
+
+func (s *RefreshingKeySetSuite) Test_RefreshingKeySet_UnknownKeyID() {
+	s.Run("Refreshed", func() {
+		// given
+		keyFolder := s.T().TempDir()
+		s.writeKey(keyFolder, "old-key")
+
+		keySet, err := turtleware.RefreshingKeySetFromFolder(
+			s.context(),
+			keyFolder,
+			turtleware.RefreshingKeySetInterval(0),
+			turtleware.RefreshingKeySetMinInterval(0),
+		)
+		s.Require().NoError(err)
+
+		privateKey := s.writeKey(keyFolder, "rotated-key")
+		token := s.generateToken(jwa.EdDSA, privateKey, map[string]interface{}{"uuid": s.userUUID}, map[string]interface{}{jwk.KeyIDKey: "rotated-key"})
+
+		// when
+		claims, err := turtleware.ValidateTokenBySet(token, keySet)
+
+		// then
+		s.NoError(err)
+		s.Equal(s.userUUID, claims["uuid"])
+		s.Equal(2, keySet.Len())
+	})
+
+	s.Run("Min_Interval", func() {
+		// given
+		keyFolder := s.T().TempDir()
+		s.writeKey(keyFolder, "old-key")
+
+		keySet, err := turtleware.RefreshingKeySetFromFolder(
+			s.context(),
+			keyFolder,
+			turtleware.RefreshingKeySetInterval(0),
+			turtleware.RefreshingKeySetMinInterval(time.Hour),
+		)
+		s.Require().NoError(err)
+
+		s.writeKey(keyFolder, "rotated-key")
+
+		// when
+		key, found := keySet.LookupKeyID("rotated-key")
+
+		// then
+		s.False(found)
+		s.Nil(key)
+		s.Equal(1, keySet.Len())
+	})
+}
+
+func (s *RefreshingKeySetSuite) Test_RefreshingKeySet_Interval() {
+	// given
+	keyFolder := s.T().TempDir()
+	s.writeKey(keyFolder, "old-key")
+
+	keySet, err := turtleware.RefreshingKeySetFromFolder(
+		s.context(),
+		keyFolder,
+		turtleware.RefreshingKeySetInterval(10*time.Millisecond),
+	)
+	s.Require().NoError(err)
+
+	// when
+	s.writeKey(keyFolder, "rotated-key")
+
+	// then
+	s.Eventually(func() bool {
+		return containsKey(keySet, "rotated-key")
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (s *RefreshingKeySetSuite) Test_RefreshingKeySet_Errors() {
+	s.Run("Initial_Load", func() {
+		// given
+		targetErr := errors.New("some-error")
+
+		// when
+		keySet, err := turtleware.NewRefreshingKeySet(s.context(), func(ctx context.Context) (jwk.Set, error) {
+			return nil, targetErr
+		})
+
+		// then
+		s.ErrorIs(err, targetErr)
+		s.Nil(keySet)
+	})
+
+	s.Run("Keeps_Previous_Keys", func() {
+		// given
+		initialSet := jwk.NewSet()
+		key, err := turtleware.JWKFromPublicKey([]byte("supersecretpassphrase"), "some-key")
+		s.Require().NoError(err)
+		s.Require().NoError(initialSet.AddKey(key))
+
+		calls := atomic.Int32{}
+		keySet, err := turtleware.NewRefreshingKeySet(s.context(), func(ctx context.Context) (jwk.Set, error) {
+			if calls.Add(1) > 1 {
+				return nil, errors.New("some-error")
+			}
+
+			return initialSet, nil
+		}, turtleware.RefreshingKeySetInterval(0), turtleware.RefreshingKeySetMinInterval(0))
+		s.Require().NoError(err)
+
+		// when
+		_, found := keySet.LookupKeyID("unknown-key")
+		refreshErr := keySet.Refresh(s.context())
+
+		// then
+		s.False(found)
+		s.Error(refreshErr)
+		s.Equal(int32(3), calls.Load())
+		s.True(containsKey(keySet, "some-key"))
+	})
+}
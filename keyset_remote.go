@@ -0,0 +1,116 @@
+package turtleware
+
+import (
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/rs/zerolog"
+
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ErrJWKSFetchFailed indicates that a remote JWKS could not be fetched.
+var ErrJWKSFetchFailed = errors.New("failed to fetch JWKS")
+
+type jwksOptions struct {
+	httpClient  *http.Client
+	minCacheTTL time.Duration
+}
+
+// JWKSOption represents an option for fetching remote key sets via JWKSFromURL.
+type JWKSOption func(*jwksOptions)
+
+// JWKSHTTPClient sets the http.Client used for fetching the JWKS, e.g. for using
+// the TracingTransport.
+// The default is http.DefaultClient.
+func JWKSHTTPClient(httpClient *http.Client) JWKSOption {
+	return func(c *jwksOptions) {
+		c.httpClient = httpClient
+	}
+}
+
+// JWKSMinCacheTTL sets the minimum duration the fetched keys are cached for. If set, the
+// returned key set is a RefreshingKeySet, which fetches the JWKS again in this interval,
+// and on lookups of unknown key IDs - but never more often than the interval.
+// The default is zero, which means the JWKS is only fetched once.
+func JWKSMinCacheTTL(minCacheTTL time.Duration) JWKSOption {
+	return func(c *jwksOptions) {
+		c.minCacheTTL = minCacheTTL
+	}
+}
+
+// JWKSFromURL fetches a remote JWKS (e.g. from an OIDC provider) to assemble a JWK set from.
+// Responses with a status other than 200 are rejected with ErrJWKSFetchFailed.
+func JWKSFromURL(ctx context.Context, url string, opts ...JWKSOption) (jwk.Set, error) {
+	// default
+	config := &jwksOptions{
+		httpClient:  http.DefaultClient,
+		minCacheTTL: 0,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if config.minCacheTTL <= 0 {
+		return fetchJWKS(ctx, config.httpClient, url)
+	}
+
+	keySet, err := NewRefreshingKeySet(ctx, func(ctx context.Context) (jwk.Set, error) {
+		return fetchJWKS(ctx, config.httpClient, url)
+	},
+		RefreshingKeySetInterval(config.minCacheTTL),
+		RefreshingKeySetMinInterval(config.minCacheTTL),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return keySet, nil
+}
+
+func fetchJWKS(ctx context.Context, httpClient *http.Client, url string) (jwk.Set, error) {
+	logger := zerolog.Ctx(ctx)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	logger.Debug().Msgf("Fetching JWKS from %s", url)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJWKSFetchFailed, err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrJWKSFetchFailed, err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			logger.Warn().Err(err).Msg("Failed to close JWKS response body")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		// Drain the body, so the connection can be reused
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return nil, fmt.Errorf("%w: %s responded with status %d", ErrJWKSFetchFailed, url, resp.StatusCode)
+	}
+
+	set, err := jwk.ParseReader(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to parse response of %s: %w", ErrJWKSFetchFailed, url, err)
+	}
+
+	return set, nil
+}
@@ -0,0 +1,142 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type JWKSSuite struct {
+	CommonSuite
+}
+
+func TestJWKSSuite(t *testing.T) {
+	suite.Run(t, &JWKSSuite{})
+}
+
+func (s *JWKSSuite) jwksServer(status int, kids ...string) (*httptest.Server, *atomic.Int32) {
+	set := jwk.NewSet()
+	for _, kid := range kids {
+		key, err := turtleware.JWKFromPublicKey([]byte("supersecretpassphrase"), kid)
+		s.Require().NoError(err)
+		s.Require().NoError(set.AddKey(key))
+	}
+
+	body, err := json.Marshal(set)
+	s.Require().NoError(err)
+
+	requests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}))
+	s.T().Cleanup(server.Close)
+
+	return server, requests
+}
+
+func (s *JWKSSuite) Test_JWKSFromURL_Success() {
+	// given
+	server, requests := s.jwksServer(http.StatusOK, "remote-key")
+
+	// when
+	keySet, err := turtleware.JWKSFromURL(context.Background(), server.URL, turtleware.JWKSHTTPClient(server.Client()))
+
+	// then
+	s.Require().NoError(err)
+	s.Equal(1, keySet.Len())
+	s.True(containsKey(keySet, "remote-key"))
+	s.Equal(int32(1), requests.Load())
+
+	token := s.generateToken(
+		jwa.HS512,
+		[]byte("supersecretpassphrase"),
+		map[string]interface{}{"uuid": s.userUUID},
+		map[string]interface{}{jwk.KeyIDKey: "remote-key"},
+	)
+
+	claims, err := turtleware.ValidateTokenBySet(token, keySet)
+	s.NoError(err)
+	s.Equal(s.userUUID, claims["uuid"])
+}
+
+func (s *JWKSSuite) Test_JWKSFromURL_MinCacheTTL() {
+	// given
+	ctx, cancel := context.WithCancel(context.Background())
+	s.T().Cleanup(cancel)
+
+	server, requests := s.jwksServer(http.StatusOK, "remote-key")
+
+	keySet, err := turtleware.JWKSFromURL(ctx, server.URL, turtleware.JWKSMinCacheTTL(time.Hour))
+	s.Require().NoError(err)
+
+	// when
+	_, found := keySet.LookupKeyID("unknown-key")
+
+	// then
+	s.False(found)
+	s.IsType(&turtleware.RefreshingKeySet{}, keySet)
+	s.Equal(int32(1), requests.Load())
+}
+
+func (s *JWKSSuite) Test_JWKSFromURL_Errors() {
+	s.Run("Status", func() {
+		// given
+		server, _ := s.jwksServer(http.StatusServiceUnavailable)
+
+		for testName, opts := range map[string][]turtleware.JWKSOption{
+			"Once":   nil,
+			"Cached": {turtleware.JWKSMinCacheTTL(time.Hour)},
+		} {
+			// when
+			keySet, err := turtleware.JWKSFromURL(context.Background(), server.URL, opts...)
+
+			// then
+			s.ErrorIs(err, turtleware.ErrJWKSFetchFailed, testName)
+			s.ErrorContains(err, "status 503", testName)
+			s.Nil(keySet, testName)
+		}
+	})
+
+	s.Run("Garbage", func() {
+		// given
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("garbage"))
+		}))
+		s.T().Cleanup(server.Close)
+
+		// when
+		keySet, err := turtleware.JWKSFromURL(context.Background(), server.URL)
+
+		// then
+		s.ErrorIs(err, turtleware.ErrJWKSFetchFailed)
+		s.Nil(keySet)
+	})
+
+	s.Run("Context_Error", func() {
+		// given
+		server, requests := s.jwksServer(http.StatusOK, "remote-key")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// when
+		keySet, err := turtleware.JWKSFromURL(ctx, server.URL)
+
+		// then
+		s.ErrorIs(err, context.Canceled)
+		s.Nil(keySet)
+		s.Zero(requests.Load())
+	})
+}
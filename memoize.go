@@ -0,0 +1,106 @@
+package turtleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoizeListHash wraps the given ListHashFunc, so its results are memoized for the given
+// TTL, keyed by the paging of the request. Concurrent calls for the same paging share
+// a single invocation of the wrapped function. This allows a burst of (conditional)
+// requests to share one metadata computation. Errors are not memoized.
+// As the memoization is keyed by the paging only, the wrapped function must not depend
+// on anything else of the request context (e.g. the calling user).
+func MemoizeListHash(hashFetcher ListHashFunc, ttl time.Duration) ListHashFunc {
+	memo := newMemoizer[string, string](ttl)
+
+	return func(ctx context.Context, paging Paging) (string, error) {
+		return memo.get(ctx, paging.String(), func(ctx context.Context) (string, error) {
+			return hashFetcher(ctx, paging)
+		})
+	}
+}
+
+// MemoizeResourceLastMod wraps the given ResourceLastModFunc, so its results are memoized
+// for the given TTL, keyed by the entity UUID. Concurrent calls for the same entity share
+// a single invocation of the wrapped function. This allows a burst of (conditional)
+// requests to share one metadata computation. Errors are not memoized.
+// As the memoization is keyed by the entity UUID only, the wrapped function must not depend
+// on anything else of the request context (e.g. the calling user).
+func MemoizeResourceLastMod(lastModFetcher ResourceLastModFunc, ttl time.Duration) ResourceLastModFunc {
+	memo := newMemoizer[string, time.Time](ttl)
+
+	return func(ctx context.Context, entityUUID string) (time.Time, error) {
+		return memo.get(ctx, entityUUID, func(ctx context.Context) (time.Time, error) {
+			return lastModFetcher(ctx, entityUUID)
+		})
+	}
+}
+
+type memoEntry[V any] struct {
+	done    chan struct{}
+	value   V
+	err     error
+	expires time.Time
+}
+
+type memoizer[K comparable, V any] struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[K]*memoEntry[V]
+}
+
+func newMemoizer[K comparable, V any](ttl time.Duration) *memoizer[K, V] {
+	return &memoizer[K, V]{
+		ttl:     ttl,
+		entries: map[K]*memoEntry[V]{},
+	}
+}
+
+func (m *memoizer[K, V]) get(ctx context.Context, key K, fetch func(ctx context.Context) (V, error)) (V, error) {
+	m.mu.Lock()
+
+	now := time.Now()
+	if entry, found := m.entries[key]; found && (entry.expires.IsZero() || now.Before(entry.expires)) {
+		m.mu.Unlock()
+
+		return m.wait(ctx, entry)
+	}
+
+	// Clean up expired entries, so the memoizer does not grow unbounded
+	for entryKey, entry := range m.entries {
+		if !entry.expires.IsZero() && !now.Before(entry.expires) {
+			delete(m.entries, entryKey)
+		}
+	}
+
+	entry := &memoEntry[V]{done: make(chan struct{})}
+	m.entries[key] = entry
+	m.mu.Unlock()
+
+	entry.value, entry.err = fetch(ctx)
+
+	m.mu.Lock()
+	if entry.err != nil {
+		delete(m.entries, key)
+	} else {
+		entry.expires = time.Now().Add(m.ttl)
+	}
+	m.mu.Unlock()
+
+	close(entry.done)
+
+	return entry.value, entry.err
+}
+
+func (m *memoizer[K, V]) wait(ctx context.Context, entry *memoEntry[V]) (V, error) {
+	select {
+	case <-entry.done:
+		return entry.value, entry.err
+	case <-ctx.Done():
+		var empty V
+		return empty, ctx.Err()
+	}
+}
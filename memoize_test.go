@@ -0,0 +1,167 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type MemoizeSuite struct {
+	CommonSuite
+}
+
+func TestMemoizeSuite(t *testing.T) {
+	suite.Run(t, &MemoizeSuite{})
+}
+
+func (s *MemoizeSuite) Test_MemoizeListHash_Burst() {
+	// given
+	calls := atomic.Int32{}
+	release := make(chan struct{})
+
+	hashFetcher := turtleware.MemoizeListHash(func(ctx context.Context, paging turtleware.Paging) (string, error) {
+		calls.Add(1)
+		<-release
+
+		return "some-hash", nil
+	}, time.Minute)
+
+	handler := alice.New(
+		turtleware.PagingMiddleware,
+		turtleware.ListCacheMiddleware(hashFetcher, turtleware.DefaultErrorHandler),
+	).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.Fail("unexpected cache miss")
+	}))
+
+	// when
+	responses := make([]*httptest.ResponseRecorder, 20)
+
+	wg := sync.WaitGroup{}
+	for i := range responses {
+		responses[i] = httptest.NewRecorder()
+
+		wg.Add(1)
+		go func(response *httptest.ResponseRecorder) {
+			defer wg.Done()
+
+			request := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+			request.Header.Set("If-None-Match", "some-hash")
+
+			handler.ServeHTTP(response, request)
+		}(responses[i])
+	}
+
+	// Let the burst pile up on the in-flight computation
+	s.Eventually(func() bool {
+		return calls.Load() > 0
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+
+	wg.Wait()
+
+	// then
+	s.Equal(int32(1), calls.Load())
+	for _, response := range responses {
+		s.Equal(http.StatusNotModified, response.Code)
+	}
+}
+
+func (s *MemoizeSuite) Test_MemoizeListHash_Keyed() {
+	// given
+	calls := atomic.Int32{}
+
+	hashFetcher := turtleware.MemoizeListHash(func(ctx context.Context, paging turtleware.Paging) (string, error) {
+		calls.Add(1)
+
+		return paging.String(), nil
+	}, time.Minute)
+
+	// when
+	first, err1 := hashFetcher(context.Background(), turtleware.Paging{Offset: 0, Limit: 10})
+	second, err2 := hashFetcher(context.Background(), turtleware.Paging{Offset: 10, Limit: 10})
+	third, err3 := hashFetcher(context.Background(), turtleware.Paging{Offset: 0, Limit: 10})
+
+	// then
+	s.NoError(errors.Join(err1, err2, err3))
+	s.NotEqual(first, second)
+	s.Equal(first, third)
+	s.Equal(int32(2), calls.Load())
+}
+
+func (s *MemoizeSuite) Test_MemoizeResourceLastMod() {
+	s.Run("Memoized", func() {
+		// given
+		calls := atomic.Int32{}
+		lastMod := time.Now()
+
+		lastModFetcher := turtleware.MemoizeResourceLastMod(func(ctx context.Context, entityUUID string) (time.Time, error) {
+			calls.Add(1)
+
+			return lastMod, nil
+		}, time.Minute)
+
+		// when
+		for range 5 {
+			result, err := lastModFetcher(context.Background(), s.entityUUID)
+			s.NoError(err)
+			s.Equal(lastMod, result)
+		}
+
+		// then
+		s.Equal(int32(1), calls.Load())
+	})
+
+	s.Run("Expired", func() {
+		// given
+		calls := atomic.Int32{}
+
+		lastModFetcher := turtleware.MemoizeResourceLastMod(func(ctx context.Context, entityUUID string) (time.Time, error) {
+			calls.Add(1)
+
+			return time.Now(), nil
+		}, time.Millisecond)
+
+		// when
+		_, err := lastModFetcher(context.Background(), s.entityUUID)
+		s.NoError(err)
+
+		time.Sleep(5 * time.Millisecond)
+
+		_, err = lastModFetcher(context.Background(), s.entityUUID)
+		s.NoError(err)
+
+		// then
+		s.Equal(int32(2), calls.Load())
+	})
+
+	s.Run("Errors_Not_Memoized", func() {
+		// given
+		calls := atomic.Int32{}
+		targetErr := errors.New("some-error")
+
+		lastModFetcher := turtleware.MemoizeResourceLastMod(func(ctx context.Context, entityUUID string) (time.Time, error) {
+			calls.Add(1)
+
+			return time.Time{}, targetErr
+		}, time.Minute)
+
+		// when
+		_, err1 := lastModFetcher(context.Background(), s.entityUUID)
+		_, err2 := lastModFetcher(context.Background(), s.entityUUID)
+
+		// then
+		s.ErrorIs(err1, targetErr)
+		s.ErrorIs(err2, targetErr)
+		s.Equal(int32(2), calls.Load())
+	})
+}
@@ -1,15 +1,23 @@
 package turtleware
 
 import (
+	"github.com/google/uuid"
 	"github.com/lestrrat-go/jwx/v2/jwk"
 	"github.com/rs/zerolog"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 
 	"context"
 	"errors"
+	"fmt"
+	"mime"
+	"net"
 	"net/http"
+	"slices"
+	"strings"
 )
 
 type ctxKey int
@@ -21,13 +29,44 @@ const (
 	// ctxEntityUUID is the context key used to pass down the entity UUID.
 	ctxEntityUUID
 
+	// ctxEntityUUIDParsed is the context key used to pass down the parsed entity UUID.
+	ctxEntityUUIDParsed
+
 	// ctxPaging is the context key used to pass down paging information.
 	ctxPaging
 
 	// ctxAuthClaims is the context key used to pass down jwt claims.
 	ctxAuthClaims
+
+	// ctxIncludes is the context key used to pass down requested includes.
+	ctxIncludes
+
+	// ctxRetryState is the context key used to pass down the state of the RetryMiddleware.
+	ctxRetryState
+
+	// ctxFieldRoles is the context key used to pass down the roles for field filtering.
+	ctxFieldRoles
+
+	// ctxUserClaim is the context key used to pass down the claim containing the user UUID.
+	ctxUserClaim
+
+	// ctxCountHeaderNames is the context key used to pass down the names of the count headers.
+	ctxCountHeaderNames
+
+	// ctxRequestID is the context key used to pass down the request ID for error responses.
+	ctxRequestID
+
+	// ctxAuthRealm is the context key used to pass down the realm of bearer challenges.
+	ctxAuthRealm
+
+	// ctxErrorCodes is the context key used to pass down whether error codes are written.
+	ctxErrorCodes
 )
 
+// defaultUserClaim is the claim containing the user UUID, if not configured otherwise
+// via AuthClaimsMiddlewareWithUserClaim.
+const defaultUserClaim = "uuid"
+
 var (
 	// ErrContextMissingAuthToken is an internal error indicating a missing
 	// auth token in the request context, whereas one was expected.
@@ -45,6 +84,10 @@ var (
 	// claims in the request context, whereas they were expected.
 	ErrContextMissingAuthClaims = errors.New("missing auth claims in context")
 
+	// ErrContextMissingIncludes is an internal error indicating missing includes
+	// in the request context, whereas they were expected.
+	ErrContextMissingIncludes = errors.New("missing includes in context")
+
 	// ErrMarshalling signals that an error occurred while marshalling.
 	ErrMarshalling = errors.New("failed to parse message body")
 
@@ -52,25 +95,88 @@ var (
 	// from the database or similar.
 	ErrReceivingResults = errors.New("error while receiving results")
 
+	// ErrRequestTimeout signals that the results could not be received within the
+	// timeout of the data handler (see ListDataTimeout and ResourceDataTimeout).
+	ErrRequestTimeout = errors.New("timeout while receiving results")
+
 	// ErrResourceNotFound indicates that a requested resource was not found.
 	ErrResourceNotFound = errors.New("resource not found")
 
+	// ErrInvalidEntityUUID indicates that the entity UUID of the request is not
+	// a valid UUID (see EntityUUIDMiddlewareParsed).
+	ErrInvalidEntityUUID = errors.New("invalid entity UUID")
+
+	// ErrResourceGone indicates that a requested resource existed, but is no longer
+	// available (e.g. because it was soft-deleted).
+	ErrResourceGone = errors.New("resource gone")
+
+	// ErrPreconditionFailed indicates that a precondition of the request (e.g. the
+	// If-Unmodified-Since header) does not hold for the requested resource.
+	ErrPreconditionFailed = errors.New("precondition failed")
+
 	// ErrReceivingMeta signals that an error occurred while receiving the metadata
 	// from the database or remotes.
 	ErrReceivingMeta = errors.New("error while receiving metadata")
 
 	// ErrMissingUserUUID signals that a received JWT did not contain an user UUID.
 	ErrMissingUserUUID = errors.New("token does not include user UUID")
+
+	// ErrInsufficientScope signals that the auth claims of the request do not grant
+	// access to the requested handler (see RequireClaimMiddleware).
+	ErrInsufficientScope = errors.New("insufficient scope")
+
+	// ErrMethodNotAllowed signals that the request method is not supported by the handler.
+	ErrMethodNotAllowed = errors.New("method not allowed")
+
+	// ErrUnsupportedMediaType signals that the content type of the request body is not
+	// supported by the handler (see RequireJSONContentTypeMiddleware).
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+	// ErrUnavailableForLegalReasons indicates that a requested resource is not
+	// available, due to legal demands (e.g. geo-blocking or a court order).
+	// See UnavailableForLegalReasonsError for naming the blocking authority.
+	ErrUnavailableForLegalReasons = errors.New("resource unavailable for legal reasons")
 )
 
+// UnavailableForLegalReasonsError is an ErrUnavailableForLegalReasons, which names the
+// authority implementing the legal demand. The DefaultErrorHandler references the authority
+// via a Link header, with the relation "blocked-by" (see RFC 7725).
+type UnavailableForLegalReasonsError struct {
+	BlockedBy string
+}
+
+func (unavailableForLegalReasonsError UnavailableForLegalReasonsError) Error() string {
+	return ErrUnavailableForLegalReasons.Error()
+}
+
+func (unavailableForLegalReasonsError UnavailableForLegalReasonsError) Unwrap() error {
+	return ErrUnavailableForLegalReasons
+}
+
+// addBlockedByLink references the authority of an UnavailableForLegalReasonsError
+// via a Link header, if the given error contains one.
+func addBlockedByLink(header http.Header, err error) {
+	legalErr := UnavailableForLegalReasonsError{}
+	if errors.As(err, &legalErr) && legalErr.BlockedBy != "" {
+		header.Add("Link", "<"+legalErr.BlockedBy+`>; rel="blocked-by"`)
+	}
+}
+
 type ResourceEntityFunc func(r *http.Request) (string, error)
 
 // IsHandledByDefaultErrorHandler indicates if the DefaultErrorHandler has any special
 // handling for the given error, or if it defaults to handing it out as-is.
 func IsHandledByDefaultErrorHandler(err error) bool {
 	if errors.Is(err, ErrResourceNotFound) ||
+		errors.Is(err, ErrResourceGone) ||
+		errors.Is(err, ErrPreconditionFailed) ||
 		errors.Is(err, ErrMissingUserUUID) ||
-		errors.Is(err, ErrMarshalling) {
+		errors.Is(err, ErrInvalidEntityUUID) ||
+		errors.Is(err, ErrMarshalling) ||
+		errors.Is(err, ErrConflict) ||
+		errors.Is(err, ErrUnsupportedMediaType) ||
+		errors.Is(err, ErrUnavailableForLegalReasons) ||
+		errors.Is(err, ErrRequestTimeout) {
 		return true
 	}
 
@@ -80,16 +186,51 @@ func IsHandledByDefaultErrorHandler(err error) bool {
 
 // DefaultErrorHandler is a default error handler, which sensibly handles errors known by turtleware.
 func DefaultErrorHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	err = withErrorCode(err)
+
 	if errors.Is(err, ErrResourceNotFound) {
 		WriteError(ctx, w, r, http.StatusNotFound, err)
 		return
 	}
 
-	if errors.Is(err, ErrMissingUserUUID) || errors.Is(err, ErrMarshalling) {
+	if errors.Is(err, ErrResourceGone) {
+		WriteError(ctx, w, r, http.StatusGone, err)
+		return
+	}
+
+	if errors.Is(err, ErrPreconditionFailed) {
+		WriteError(ctx, w, r, http.StatusPreconditionFailed, err)
+		return
+	}
+
+	if errors.Is(err, ErrMissingUserUUID) ||
+		errors.Is(err, ErrInvalidEntityUUID) ||
+		errors.Is(err, ErrMarshalling) {
 		WriteError(ctx, w, r, http.StatusBadRequest, err)
 		return
 	}
 
+	if errors.Is(err, ErrConflict) {
+		WriteError(ctx, w, r, http.StatusConflict, err)
+		return
+	}
+
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		WriteError(ctx, w, r, http.StatusUnsupportedMediaType, err)
+		return
+	}
+
+	if errors.Is(err, ErrUnavailableForLegalReasons) {
+		addBlockedByLink(w.Header(), err)
+		WriteError(ctx, w, r, http.StatusUnavailableForLegalReasons, err)
+		return
+	}
+
+	if errors.Is(err, ErrRequestTimeout) {
+		WriteError(ctx, w, r, http.StatusGatewayTimeout, err)
+		return
+	}
+
 	validationErr := &ValidationWrapperError{}
 	if errors.As(err, validationErr) {
 		WriteError(ctx, w, r, http.StatusBadRequest, validationErr.Errors...)
@@ -119,34 +260,134 @@ func EntityUUIDMiddleware(entityFunc ResourceEntityFunc) func(h http.Handler) ht
 	}
 }
 
+// EntityUUIDMiddlewareParsed is the same as EntityUUIDMiddleware, but parses the UUID of the resource
+// requested via uuid.Parse, and passes it down both as uuid.UUID (see EntityUUIDFromRequestContextParsed),
+// and in its canonical string form (see EntityUUIDFromRequestContext).
+// If the UUID is invalid, the request is answered with a 400 and ErrInvalidEntityUUID, so malformed
+// identifiers never reach the data layer.
+func EntityUUIDMiddlewareParsed(entityFunc ResourceEntityFunc) func(h http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rawEntityUUID, err := entityFunc(r)
+			if err != nil {
+				WriteError(r.Context(), w, r, http.StatusInternalServerError, err)
+
+				return
+			}
+
+			entityUUID, err := uuid.Parse(rawEntityUUID)
+			if err != nil {
+				WriteError(r.Context(), w, r, http.StatusBadRequest, fmt.Errorf("%w: %q", ErrInvalidEntityUUID, rawEntityUUID))
+
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), ctxEntityUUID, entityUUID.String())
+			ctx = context.WithValue(ctx, ctxEntityUUIDParsed, entityUUID)
+
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// AuthRealmMiddleware is a http middleware for announcing the given realm in the WWW-Authenticate
+// challenges of the AuthTokenMiddleware and the AuthClaimsMiddleware further down the chain
+// (see RFC 6750). Without this middleware, the realm is omitted from the challenges.
+func AuthRealmMiddleware(realm string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(
+				w,
+				r.WithContext(context.WithValue(r.Context(), ctxAuthRealm, realm)),
+			)
+		})
+	}
+}
+
+// bearerChallenge builds a WWW-Authenticate challenge for the bearer scheme, as per RFC 6750.
+// An empty error code omits the error attributes, as for requests without any token.
+func bearerChallenge(ctx context.Context, errorCode string, errorDescription string) string {
+	attributes := make([]string, 0, 3)
+
+	if realm, ok := ctx.Value(ctxAuthRealm).(string); ok && realm != "" {
+		attributes = append(attributes, fmt.Sprintf("realm=%q", realm))
+	}
+
+	if errorCode != "" {
+		attributes = append(attributes, fmt.Sprintf("error=%q", errorCode))
+
+		if errorDescription != "" {
+			attributes = append(attributes, fmt.Sprintf("error_description=%q", errorDescription))
+		}
+	}
+
+	if len(attributes) == 0 {
+		return "Bearer"
+	}
+
+	return "Bearer " + strings.Join(attributes, ", ")
+}
+
 // AuthBearerHeaderMiddleware is a http middleware for extracting the bearer token from
 // the authorization header, and passing it down. If the header is not existing, the
 // WWW-Authenticate header is set and the handler bails out.
 func AuthBearerHeaderMiddleware(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		token, err := FromAuthHeader(r)
-		if err != nil {
-			if errors.Is(err, ErrMissingAuthHeader) {
-				// If it was a browser request, give it a chance to authenticate
-				w.Header().Add("WWW-Authenticate", `bearer`)
-				WriteError(r.Context(), w, r, http.StatusUnauthorized, err)
-			} else {
-				WriteError(r.Context(), w, r, http.StatusBadRequest, err)
-			}
+	return AuthTokenMiddleware(FromAuthHeader)(h)
+}
 
-			return
-		}
+// AuthTokenMiddleware is a http middleware for extracting the bearer token via the given
+// TokenExtractor functions (e.g. FromAuthHeader, FromCookie and FromQuery), and passing it down.
+// The extractors are tried in order, and the first token found is passed down. If no extractor
+// finds a token, the WWW-Authenticate header is set (without an error code, see AuthRealmMiddleware
+// for announcing a realm) and the handler bails out with a 401. Any other error (e.g. a malformed
+// Authorization header) is answered with a 400 right away.
+// If no extractors are provided, the token is extracted via FromAuthHeader.
+func AuthTokenMiddleware(extractors ...TokenExtractor) func(http.Handler) http.Handler {
+	if len(extractors) == 0 {
+		extractors = []TokenExtractor{FromAuthHeader}
+	}
 
-		h.ServeHTTP(
-			w,
-			r.WithContext(context.WithValue(r.Context(), ctxAuthToken, token)),
-		)
-	})
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, extractor := range extractors {
+				token, err := extractor(r)
+				if errors.Is(err, ErrMissingAuthHeader) {
+					continue
+				}
+
+				if err != nil {
+					WriteError(r.Context(), w, r, http.StatusBadRequest, err)
+
+					return
+				}
+
+				h.ServeHTTP(
+					w,
+					r.WithContext(context.WithValue(r.Context(), ctxAuthToken, token)),
+				)
+
+				return
+			}
+
+			// If it was a browser request, give it a chance to authenticate
+			w.Header().Add("WWW-Authenticate", bearerChallenge(r.Context(), "", ""))
+			WriteError(r.Context(), w, r, http.StatusUnauthorized, ErrMissingAuthHeader)
+		})
+	}
 }
 
 // AuthClaimsMiddleware is a http middleware for extracting authentication claims, and
 // passing them down.
 func AuthClaimsMiddleware(keySet jwk.Set) func(http.Handler) http.Handler {
+	return AuthClaimsMiddlewareWithOptions(keySet)
+}
+
+// AuthClaimsMiddlewareWithOptions is a http middleware for extracting authentication claims,
+// and passing them down. The token is validated according to the provided options (e.g. the
+// expected audience and issuer). Tokens failing validation are rejected with a 401 and
+// ErrTokenValidationFailed (or ErrTokenExpired for expired tokens), and an invalid_token
+// challenge in the WWW-Authenticate header (see AuthRealmMiddleware).
+func AuthClaimsMiddlewareWithOptions(keySet jwk.Set, opts ...TokenOption) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token, err := AuthTokenFromRequestContext(r.Context())
@@ -156,9 +397,17 @@ func AuthClaimsMiddleware(keySet jwk.Set) func(http.Handler) http.Handler {
 				return
 			}
 
-			claims, err := ValidateTokenBySet(token, keySet)
+			claims, err := ValidateTokenBySetWithOptions(token, keySet, opts...)
 			if err != nil {
-				WriteError(r.Context(), w, r, http.StatusBadRequest, ErrTokenValidationFailed)
+				zerolog.Ctx(r.Context()).Debug().Err(err).Msg("Token validation failed")
+
+				validationErr := ErrTokenValidationFailed
+				if errors.Is(err, ErrTokenExpired) {
+					validationErr = ErrTokenExpired
+				}
+
+				w.Header().Add("WWW-Authenticate", bearerChallenge(r.Context(), "invalid_token", validationErr.Error()))
+				WriteError(r.Context(), w, r, http.StatusUnauthorized, validationErr)
 
 				return
 			}
@@ -171,27 +420,269 @@ func AuthClaimsMiddleware(keySet jwk.Set) func(http.Handler) http.Handler {
 	}
 }
 
-// PagingMiddleware is a http middleware for extracting paging information, and passing
-// it down.
-func PagingMiddleware(h http.Handler) http.Handler {
+// AuthClaimsMiddlewareWithUserClaim is a http middleware for extracting authentication claims,
+// and passing them down, just as AuthClaimsMiddlewareWithOptions. Additionally, it records the
+// claim holding the user UUID (e.g. "sub"), which is then used by UserUUIDFromRequestContext
+// instead of the default "uuid" claim.
+func AuthClaimsMiddlewareWithUserClaim(keySet jwk.Set, userClaim string, opts ...TokenOption) func(http.Handler) http.Handler {
+	claimsMiddleware := AuthClaimsMiddlewareWithOptions(keySet, opts...)
+
+	return func(h http.Handler) http.Handler {
+		return claimsMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(
+				w,
+				r.WithContext(context.WithValue(r.Context(), ctxUserClaim, userClaim)),
+			)
+		}))
+	}
+}
+
+// RequireClaimMiddleware is a http middleware for restricting access to callers, whose auth claims
+// (as passed down by the AuthClaimsMiddleware) contain at least one of the given values in the given
+// claim (see ClaimByPath), e.g. a role or scope. The claim may either be an array of strings, or a
+// (space separated) string - the same as for RolesFromClaim.
+// If the claim is missing or contains none of the values, the request is answered with a 403 and
+// ErrInsufficientScope.
+func RequireClaimMiddleware(claim string, anyOf ...string) func(http.Handler) http.Handler {
+	rolesFunc := RolesFromClaim(claim)
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			values, err := rolesFunc(r)
+			if errors.Is(err, ErrContextMissingAuthClaims) {
+				WriteError(r.Context(), w, r, http.StatusInternalServerError, err)
+
+				return
+			}
+
+			if err == nil {
+				for _, value := range values {
+					if slices.Contains(anyOf, value) {
+						h.ServeHTTP(w, r)
+
+						return
+					}
+				}
+			}
+
+			zerolog.Ctx(r.Context()).Debug().Msgf("Claim %s does not contain any of %v", claim, anyOf)
+			WriteError(r.Context(), w, r, http.StatusForbidden, ErrInsufficientScope)
+		})
+	}
+}
+
+// RestrictMethods is a http middleware for restricting the allowed request methods of a handler.
+// Requests with any other method are answered early with a 405, and an Allow header listing
+// the allowed methods.
+func RestrictMethods(allowed ...string) func(http.Handler) http.Handler {
+	allowHeader := strings.Join(allowed, ", ")
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, method := range allowed {
+				if r.Method == method {
+					h.ServeHTTP(w, r)
+
+					return
+				}
+			}
+
+			w.Header().Set("Allow", allowHeader)
+			WriteError(r.Context(), w, r, http.StatusMethodNotAllowed, fmt.Errorf("%w: %s", ErrMethodNotAllowed, r.Method))
+		})
+	}
+}
+
+// RequireJSONContentTypeMiddleware is a http middleware for rejecting write requests (POST, PUT
+// and PATCH) with a body, whose Content-Type is not JSON. Such requests are answered early with a
+// 415 and ErrUnsupportedMediaType, instead of failing while decoding the body.
+// Accepted are application/json, JSON based media types with a +json suffix (e.g.
+// application/merge-patch+json), and */*. Requests of other methods, and requests without a
+// body (that is, with a Content-Length of zero) are passed through, so the middleware can be
+// placed in front of read handlers as well.
+func RequireJSONContentTypeMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		paging, err := ParsePagingFromRequest(r)
-		if err != nil {
-			WriteError(r.Context(), w, r, http.StatusInternalServerError, err)
+		isWrite := r.Method == http.MethodPost || r.Method == http.MethodPut || r.Method == http.MethodPatch
+
+		if isWrite && r.ContentLength != 0 && !isJSONContentType(r.Header.Get("Content-Type")) {
+			WriteError(r.Context(), w, r, http.StatusUnsupportedMediaType, fmt.Errorf("%w: %q", ErrUnsupportedMediaType, r.Header.Get("Content-Type")))
 
 			return
 		}
 
+		h.ServeHTTP(w, r)
+	})
+}
+
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" ||
+		mediaType == "*/*" ||
+		(strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// ConditionalHeadersMiddleware is a http middleware for rejecting requests with conflicting or
+// malformed conditional headers (see ValidateConditionalHeaders). Such requests are answered
+// early with a 400, instead of silently ignoring the headers.
+func ConditionalHeadersMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := ValidateConditionalHeaders(r); err != nil {
+			WriteError(r.Context(), w, r, http.StatusBadRequest, err)
+
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// PagingMiddleware is a http middleware for extracting paging information, and passing
+// it down. A default limit of 100 is applied, and limits are clamped to 500.
+func PagingMiddleware(h http.Handler) http.Handler {
+	return PagingMiddlewareWithOptions()(h)
+}
+
+// PagingMiddlewareWithOptions is a http middleware for extracting paging information, and
+// passing it down. The paging information is parsed and validated according to the provided options.
+// Invalid paging parameters are answered with a 400 Bad Request.
+func PagingMiddlewareWithOptions(opts ...PagingOption) func(http.Handler) http.Handler {
+	// default
+	config := &pagingOptions{
+		alignedOffset: false,
+		defaultLimit:  defaultPagingLimit,
+		maxLimit:      maxPagingLimit,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			paging, err := parsePagingFromRequest(r, config.defaultLimit, config.maxLimit)
+			if err != nil {
+				WriteError(r.Context(), w, r, http.StatusBadRequest, err)
+
+				return
+			}
+
+			if config.alignedOffset && paging.Limit > 0 && paging.Offset%uint32(paging.Limit) != 0 {
+				WriteError(r.Context(), w, r, http.StatusBadRequest, ErrUnalignedOffset)
+
+				return
+			}
+
+			h.ServeHTTP(
+				w,
+				r.WithContext(context.WithValue(r.Context(), ctxPaging, paging)),
+			)
+		})
+	}
+}
+
+// IncludeMiddleware is a http middleware for extracting the requested includes of related
+// resources, and passing them down. Only includes contained in the provided allowlist
+// are accepted - any other include results in a bad request.
+func IncludeMiddleware(allowed ...string) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			includes, err := ParseIncludesFromRequest(r, allowed...)
+			if err != nil {
+				WriteError(r.Context(), w, r, http.StatusBadRequest, err)
+
+				return
+			}
+
+			h.ServeHTTP(
+				w,
+				r.WithContext(context.WithValue(r.Context(), ctxIncludes, includes)),
+			)
+		})
+	}
+}
+
+// QueryComplexityMiddleware is a http middleware for limiting the combined complexity of
+// sparse fieldsets, includes, sorts and filters of a query (see QueryComplexity).
+// Requests exceeding the provided budget are answered early with a 400 (ErrQueryTooComplex).
+func QueryComplexityMiddleware(budget uint) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if complexity := QueryComplexity(r); complexity > budget {
+				WriteError(r.Context(), w, r, http.StatusBadRequest, fmt.Errorf("%w: complexity %d exceeds budget of %d", ErrQueryTooComplex, complexity, budget))
+
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maxRequestIDLength is the maximum length of a request ID taken over from the X-Request-Id header.
+const maxRequestIDLength = 128
+
+// ErrorIdentifiersMiddleware is a http middleware for including identifiers in the error responses
+// written via WriteError, for correlating client reported errors. These are the trace ID of the span
+// in the context, and the request ID. The request ID is taken over from the X-Request-Id header, if it
+// is at most 128 characters long and consists of letters, digits, '-', '_', '.' and ':' only.
+// Otherwise, a new request ID is generated. The request ID is echoed via the X-Request-Id header
+// of the response. Without this middleware, error responses do not include any identifiers.
+func ErrorIdentifiersMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("X-Request-Id")
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+
+		w.Header().Set("X-Request-Id", requestID)
+
 		h.ServeHTTP(
 			w,
-			r.WithContext(context.WithValue(r.Context(), ctxPaging, paging)),
+			r.WithContext(context.WithValue(r.Context(), ctxRequestID, requestID)),
 		)
 	})
 }
 
+// ErrorCodesMiddleware is a http middleware for writing the errors of error responses written via
+// WriteError as objects, carrying the message and - if the error is a CodedError - the code of each
+// error. Without this middleware, errors are written as plain messages, and no codes are written.
+func ErrorCodesMiddleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(
+			w,
+			r.WithContext(context.WithValue(r.Context(), ctxErrorCodes, true)),
+		)
+	})
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for _, c := range requestID {
+		isAlphanumeric := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlphanumeric && !strings.ContainsRune("-_.:", c) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // TracingMiddleware is a http middleware for injecting a new named open telemetry
 // span into the request context. If tracer is nil, otel.GetTracerProvider()
-// is used.
+// is used. If name is empty, the span is named by the request method (e.g. "HTTP GET").
+// The span carries the semantic HTTP attributes of the request (http.method, http.target,
+// http.scheme and net.host.name), so it can be correlated with the client spans of the
+// TracingTransport. The target is recorded without the query, as query parameters may carry
+// credentials (e.g. see FromQuery). The status code of the response is recorded on the span,
+// and responses with a 5xx status code mark the span as errored.
 func TracingMiddleware(name string, traceProvider trace.TracerProvider) func(http.Handler) http.Handler {
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -215,10 +706,22 @@ func TracingMiddleware(name string, traceProvider trace.TracerProvider) func(htt
 				logger.Trace().Msg("Missing span context")
 			}
 
+			spanName := name
+			if spanName == "" {
+				spanName = "HTTP " + r.Method
+			}
+
 			locTracer := locTraceProvider.Tracer(TracerName)
-			spanCtx, span := locTracer.Start(wireCtx, name)
+			spanCtx, span := locTracer.Start(wireCtx, spanName, trace.WithSpanKind(trace.SpanKindServer))
 			defer span.End()
 
+			span.SetAttributes(
+				attribute.String("http.method", r.Method),
+				attribute.String("http.target", r.URL.EscapedPath()),
+				attribute.String("http.scheme", requestScheme(r)),
+				attribute.String("net.host.name", requestHostName(r)),
+			)
+
 			// Create a logger, which contains the root span and trace,
 			// and inject that back into the context for root level trace logging
 			logger = WrapZerologTracing(spanCtx)
@@ -240,14 +743,49 @@ func TracingMiddleware(name string, traceProvider trace.TracerProvider) func(htt
 				}
 			}
 
+			sw := &statusWriter{ResponseWriter: w}
+
 			h.ServeHTTP(
-				w,
+				sw,
 				r.WithContext(spanCtx),
 			)
+
+			// Nothing written at all is answered with an implicit 200 OK
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			span.SetAttributes(
+				attribute.Int("http.status_code", status),
+			)
+
+			// Client errors are not errors of the server (see the OpenTelemetry semantic conventions)
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
 		})
 	}
 }
 
+// requestScheme returns the scheme of the given server request.
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// requestHostName returns the host of the given server request, without its port.
+func requestHostName(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.Host); err == nil {
+		return host
+	}
+
+	return r.Host
+}
+
 func EntityUUIDFromRequestContext(ctx context.Context) (string, error) {
 	entityUUID, ok := ctx.Value(ctxEntityUUID).(string)
 	if !ok {
@@ -257,6 +795,27 @@ func EntityUUIDFromRequestContext(ctx context.Context) (string, error) {
 	return entityUUID, nil
 }
 
+// EntityUUIDFromRequestContextParsed returns the entity UUID as passed down by the
+// EntityUUIDMiddlewareParsed. If the entity UUID was passed down by the EntityUUIDMiddleware
+// instead, it is parsed on the fly, and ErrInvalidEntityUUID is returned if it is not a valid UUID.
+func EntityUUIDFromRequestContextParsed(ctx context.Context) (uuid.UUID, error) {
+	if entityUUID, ok := ctx.Value(ctxEntityUUIDParsed).(uuid.UUID); ok {
+		return entityUUID, nil
+	}
+
+	rawEntityUUID, err := EntityUUIDFromRequestContext(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	entityUUID, err := uuid.Parse(rawEntityUUID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: %q", ErrInvalidEntityUUID, rawEntityUUID)
+	}
+
+	return entityUUID, nil
+}
+
 func AuthTokenFromRequestContext(ctx context.Context) (string, error) {
 	token, ok := ctx.Value(ctxAuthToken).(string)
 	if !ok {
@@ -275,6 +834,17 @@ func PagingFromRequestContext(ctx context.Context) (Paging, error) {
 	return paging, nil
 }
 
+// IncludesFromRequestContext returns the includes requested by the client, as
+// passed down by the IncludeMiddleware.
+func IncludesFromRequestContext(ctx context.Context) ([]string, error) {
+	includes, ok := ctx.Value(ctxIncludes).([]string)
+	if !ok {
+		return nil, ErrContextMissingIncludes
+	}
+
+	return includes, nil
+}
+
 func AuthClaimsFromRequestContext(ctx context.Context) (map[string]interface{}, error) {
 	claims, ok := ctx.Value(ctxAuthClaims).(map[string]interface{})
 	if !ok {
@@ -284,15 +854,23 @@ func AuthClaimsFromRequestContext(ctx context.Context) (map[string]interface{},
 	return claims, nil
 }
 
+// UserUUIDFromRequestContext returns the user UUID from the auth claims, as passed down by
+// the AuthClaimsMiddleware. The UUID is read from the "uuid" claim, unless a different claim
+// was configured via AuthClaimsMiddlewareWithUserClaim.
 func UserUUIDFromRequestContext(ctx context.Context) (string, error) {
 	claims, err := AuthClaimsFromRequestContext(ctx)
 	if err != nil {
 		return "", err
 	}
 
+	userClaim, ok := ctx.Value(ctxUserClaim).(string)
+	if !ok {
+		userClaim = defaultUserClaim
+	}
+
 	// ----------------
 
-	userUUID, ok := claims["uuid"].(string)
+	userUUID, ok := claims[userClaim].(string)
 	if !ok || userUUID == "" {
 		return "", ErrMissingUserUUID
 	}
@@ -4,13 +4,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/justinas/alice"
 	"github.com/kernle32dll/turtleware"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
 
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 type MiddlewareCommonSuite struct {
@@ -47,16 +54,51 @@ func (s *MiddlewareCommonSuite) Test_DefaultErrorHandler_Handled() {
 			goldenFile: "error_errresourcenotfound.json",
 			statusCode: http.StatusNotFound,
 		},
+		"ErrResourceGone": {
+			err:        turtleware.ErrResourceGone,
+			goldenFile: "error_errresourcegone.json",
+			statusCode: http.StatusGone,
+		},
+		"ErrPreconditionFailed": {
+			err:        turtleware.ErrPreconditionFailed,
+			goldenFile: "error_errpreconditionfailed.json",
+			statusCode: http.StatusPreconditionFailed,
+		},
 		"ErrMissingUserUUID": {
 			err:        turtleware.ErrMissingUserUUID,
 			goldenFile: "error_errmissinguseruuid.json",
 			statusCode: http.StatusBadRequest,
 		},
+		"ErrInvalidEntityUUID": {
+			err:        turtleware.ErrInvalidEntityUUID,
+			goldenFile: "error_errinvalidentityuuid.json",
+			statusCode: http.StatusBadRequest,
+		},
 		"ErrMarshalling": {
 			err:        turtleware.ErrMarshalling,
 			goldenFile: "error_errmarshalling.json",
 			statusCode: http.StatusBadRequest,
 		},
+		"ErrConflict": {
+			err:        turtleware.ErrConflict,
+			goldenFile: "error_errconflict.json",
+			statusCode: http.StatusConflict,
+		},
+		"ErrUnsupportedMediaType": {
+			err:        turtleware.ErrUnsupportedMediaType,
+			goldenFile: "error_errunsupportedmediatype.json",
+			statusCode: http.StatusUnsupportedMediaType,
+		},
+		"ErrUnavailableForLegalReasons": {
+			err:        turtleware.ErrUnavailableForLegalReasons,
+			goldenFile: "error_errunavailableforlegalreasons.json",
+			statusCode: http.StatusUnavailableForLegalReasons,
+		},
+		"ErrRequestTimeout": {
+			err:        turtleware.ErrRequestTimeout,
+			goldenFile: "error_errrequesttimeout.json",
+			statusCode: http.StatusGatewayTimeout,
+		},
 		"ValidationWrapperError": {
 			err: &turtleware.ValidationWrapperError{
 				Errors: []error{
@@ -85,6 +127,36 @@ func (s *MiddlewareCommonSuite) Test_DefaultErrorHandler_Handled() {
 	}
 }
 
+func (s *MiddlewareCommonSuite) Test_DefaultErrorHandler_UnavailableForLegalReasons() {
+	s.Run("BlockedBy", func() {
+		// given
+		targetError := fmt.Errorf("some-context: %w", turtleware.UnavailableForLegalReasonsError{
+			BlockedBy: "https://authority.example.com",
+		})
+
+		// when
+		turtleware.DefaultErrorHandler(context.Background(), s.response, s.request, targetError)
+
+		// then
+		s.Equal(http.StatusUnavailableForLegalReasons, s.response.Code)
+		s.Equal(`<https://authority.example.com>; rel="blocked-by"`, s.response.Header().Get("Link"))
+		s.ErrorIs(targetError, turtleware.ErrUnavailableForLegalReasons)
+		s.True(turtleware.IsHandledByDefaultErrorHandler(targetError))
+	})
+
+	s.Run("Without_BlockedBy", func() {
+		// given
+		targetError := turtleware.UnavailableForLegalReasonsError{}
+
+		// when
+		turtleware.DefaultErrorHandler(context.Background(), s.response, s.request, targetError)
+
+		// then
+		s.Equal(http.StatusUnavailableForLegalReasons, s.response.Code)
+		s.Empty(s.response.Header().Values("Link"))
+	})
+}
+
 func (s *MiddlewareCommonSuite) Test_DefaultErrorHandler_NotHandled() {
 	// given
 	targetError := errors.New("some-error")
@@ -138,6 +210,122 @@ func (s *MiddlewareCommonSuite) Test_EntityUUIDMiddleware_Error() {
 	s.JSONEq(s.loadTestDataString("errors/some_error.json"), s.response.Body.String())
 }
 
+func (s *MiddlewareCommonSuite) Test_EntityUUIDMiddlewareParsed_Success() {
+	// given
+	recordedUUID := ""
+	recordedParsedUUID := uuid.Nil
+	middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		entityUUID, err := turtleware.EntityUUIDFromRequestContext(r.Context())
+		s.Require().NoError(err)
+
+		parsedEntityUUID, err := turtleware.EntityUUIDFromRequestContextParsed(r.Context())
+		s.Require().NoError(err)
+
+		recordedUUID = entityUUID
+		recordedParsedUUID = parsedEntityUUID
+	})
+
+	expectedUUID := uuid.New()
+	middleware := turtleware.EntityUUIDMiddlewareParsed(func(r *http.Request) (string, error) {
+		return strings.ToUpper(expectedUUID.String()), nil
+	})
+
+	// when
+	middleware(middlewareVerify).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Body.String())
+	s.Equal(expectedUUID.String(), recordedUUID)
+	s.Equal(expectedUUID, recordedParsedUUID)
+}
+
+func (s *MiddlewareCommonSuite) Test_EntityUUIDMiddlewareParsed_Invalid() {
+	// given
+	middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		s.Fail("unexpected middleware invocation")
+	})
+
+	middleware := turtleware.EntityUUIDMiddlewareParsed(func(r *http.Request) (string, error) {
+		return "not-a-uuid", nil
+	})
+
+	// when
+	middleware(middlewareVerify).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusBadRequest, s.response.Code)
+	s.Contains(s.response.Body.String(), turtleware.ErrInvalidEntityUUID.Error())
+}
+
+func (s *MiddlewareCommonSuite) Test_EntityUUIDMiddlewareParsed_Error() {
+	// given
+	middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		s.Fail("unexpected middleware invocation")
+	})
+
+	middleware := turtleware.EntityUUIDMiddlewareParsed(func(r *http.Request) (string, error) {
+		return "", errors.New("some-error")
+	})
+
+	// when
+	middleware(middlewareVerify).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusInternalServerError, s.response.Code)
+	s.JSONEq(s.loadTestDataString("errors/some_error.json"), s.response.Body.String())
+}
+
+func (s *MiddlewareCommonSuite) Test_EntityUUIDFromRequestContextParsed() {
+	s.Run("From_String", func() {
+		// given
+		expectedUUID := uuid.New()
+
+		var recordedUUID uuid.UUID
+		var recordedErr error
+		middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			recordedUUID, recordedErr = turtleware.EntityUUIDFromRequestContextParsed(r.Context())
+		})
+
+		middleware := turtleware.EntityUUIDMiddleware(func(r *http.Request) (string, error) {
+			return expectedUUID.String(), nil
+		})
+
+		// when
+		middleware(middlewareVerify).ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(recordedErr)
+		s.Equal(expectedUUID, recordedUUID)
+	})
+
+	s.Run("Invalid_String", func() {
+		// given
+		var recordedErr error
+		middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			_, recordedErr = turtleware.EntityUUIDFromRequestContextParsed(r.Context())
+		})
+
+		middleware := turtleware.EntityUUIDMiddleware(func(r *http.Request) (string, error) {
+			return "some-identifier", nil
+		})
+
+		// when
+		middleware(middlewareVerify).ServeHTTP(s.response, s.request)
+
+		// then
+		s.ErrorIs(recordedErr, turtleware.ErrInvalidEntityUUID)
+	})
+
+	s.Run("Missing", func() {
+		// when
+		entityUUID, err := turtleware.EntityUUIDFromRequestContextParsed(context.Background())
+
+		// then
+		s.Equal(uuid.Nil, entityUUID)
+		s.ErrorIs(err, turtleware.ErrContextMissingEntityUUID)
+	})
+}
+
 func (s *MiddlewareCommonSuite) Test_EntityUUIDFromRequestContext_Error() {
 	// given
 	ctx := context.Background()
@@ -186,7 +374,7 @@ func (s *MiddlewareCommonSuite) Test_AuthBearerHeaderMiddleware_ErrMissingAuthHe
 	middleware(middlewareVerify).ServeHTTP(s.response, s.request)
 
 	// then
-	s.Equal("bearer", s.response.Header().Get("WWW-Authenticate"))
+	s.Equal("Bearer", s.response.Header().Get("WWW-Authenticate"))
 	s.Equal(http.StatusUnauthorized, s.response.Code)
 	s.JSONEq(s.loadTestDataString("authbearerheader/missing_auth_header.json"), s.response.Body.String())
 }
@@ -209,6 +397,82 @@ func (s *MiddlewareCommonSuite) Test_AuthBearerHeaderMiddleware_ErrAuthHeaderWro
 	s.JSONEq(s.loadTestDataString("authbearerheader/wrong_auth_header_format.json"), s.response.Body.String())
 }
 
+func (s *MiddlewareCommonSuite) Test_AuthTokenMiddleware() {
+	middleware := turtleware.AuthTokenMiddleware(
+		turtleware.FromAuthHeader,
+		turtleware.FromCookie("access_token"),
+		turtleware.FromQuery("access_token"),
+	)
+
+	cases := map[string]struct {
+		header        string
+		cookie        string
+		query         string
+		expectedToken string
+	}{
+		"Header":       {header: "Bearer header-token", cookie: "cookie-token", query: "query-token", expectedToken: "header-token"},
+		"Cookie":       {cookie: "cookie-token", query: "query-token", expectedToken: "cookie-token"},
+		"Query":        {query: "query-token", expectedToken: "query-token"},
+		"Empty_Cookie": {cookie: "", query: "query-token", expectedToken: "query-token"},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			recordedToken := ""
+			middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				token, err := turtleware.AuthTokenFromRequestContext(r.Context())
+				s.Require().NoError(err)
+
+				recordedToken = token
+			})
+
+			s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo?access_token="+target.query, http.NoBody)
+			if target.header != "" {
+				s.request.Header.Set("Authorization", target.header)
+			}
+
+			s.request.AddCookie(&http.Cookie{Name: "access_token", Value: target.cookie})
+
+			// when
+			middleware(middlewareVerify).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedToken, recordedToken)
+		})
+	}
+
+	s.Run("Missing", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		// when
+		middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.False(nextCapture.Called)
+		s.Equal("Bearer", s.response.Header().Get("WWW-Authenticate"))
+		s.Equal(http.StatusUnauthorized, s.response.Code)
+		s.JSONEq(s.loadTestDataString("authbearerheader/missing_auth_header.json"), s.response.Body.String())
+	})
+
+	s.Run("Wrong_Header_Format", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		s.request.Header.Set("Authorization", "borked")
+		s.request.AddCookie(&http.Cookie{Name: "access_token", Value: "cookie-token"})
+
+		// when
+		middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.False(nextCapture.Called)
+		s.Equal(http.StatusBadRequest, s.response.Code)
+		s.JSONEq(s.loadTestDataString("authbearerheader/wrong_auth_header_format.json"), s.response.Body.String())
+	})
+}
+
 func (s *MiddlewareCommonSuite) Test_AuthTokenFromRequestContext_Error() {
 	// given
 	ctx := context.Background()
@@ -238,8 +502,10 @@ func (s *MiddlewareCommonSuite) Test_PagingMiddleware_Success() {
 
 	// then
 	s.Equal(turtleware.Paging{
-		Offset: 0,
-		Limit:  100,
+		Offset:       0,
+		Limit:        100,
+		DefaultLimit: 100,
+		MaxLimit:     500,
 	}, recordedPaging)
 	s.Empty(s.response.Body.String())
 }
@@ -266,10 +532,394 @@ func (s *MiddlewareCommonSuite) Test_PagingMiddleware_ErrInvalidOffset() {
 		Offset: 0,
 		Limit:  0,
 	}, recordedPaging)
-	s.Equal(http.StatusInternalServerError, s.response.Code)
+	s.Equal(http.StatusBadRequest, s.response.Code)
 	s.JSONEq(s.loadTestDataString("paging/invalid_offset.json"), s.response.Body.String())
 }
 
+func (s *MiddlewareCommonSuite) Test_PagingMiddleware_BadRequest() {
+	cases := map[string]struct {
+		query         string
+		expectedError error
+	}{
+		"Invalid_Offset":         {query: "offset=-1", expectedError: turtleware.ErrInvalidOffset},
+		"Invalid_Limit":          {query: "limit=kaese", expectedError: turtleware.ErrInvalidLimit},
+		"Cursor_Offset_Conflict": {query: "cursor=abc&offset=10", expectedError: turtleware.ErrCursorOffsetConflict},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			s.request.URL.RawQuery = target.query
+
+			// when
+			turtleware.PagingMiddleware(nextCapture).ServeHTTP(s.response, s.request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.Equal(http.StatusBadRequest, s.response.Code)
+			s.Contains(s.response.Body.String(), target.expectedError.Error())
+		})
+	}
+}
+
+func (s *MiddlewareCommonSuite) Test_PagingMiddlewareWithOptions_AlignedOffset() {
+	middleware := turtleware.PagingMiddlewareWithOptions(turtleware.PagingAlignedOffset(true))
+
+	s.Run("Aligned", func() {
+		// given
+		recordedPaging := turtleware.Paging{}
+		middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			paging, err := turtleware.PagingFromRequestContext(r.Context())
+			s.Require().NoError(err)
+
+			recordedPaging = paging
+		})
+
+		s.request.URL.RawQuery = "offset=50&limit=25"
+
+		// when
+		middleware(middlewareVerify).ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(turtleware.Paging{
+			Offset:       50,
+			Limit:        25,
+			DefaultLimit: 100,
+			MaxLimit:     500,
+		}, recordedPaging)
+		s.Equal(http.StatusOK, s.response.Code)
+	})
+
+	s.Run("Unaligned", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		s.request.URL.RawQuery = "offset=30&limit=25"
+
+		// when
+		middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.False(nextCapture.Called)
+		s.Equal(http.StatusBadRequest, s.response.Code)
+		s.JSONEq(s.loadTestDataString("paging/unaligned_offset.json"), s.response.Body.String())
+	})
+}
+
+func (s *MiddlewareCommonSuite) Test_PagingMiddlewareWithOptions_Limits() {
+	cases := map[string]struct {
+		opts     []turtleware.PagingOption
+		query    string
+		expected turtleware.Paging
+	}{
+		"Default_Limit": {
+			opts:     []turtleware.PagingOption{turtleware.PagingDefaultLimit(20), turtleware.PagingMaxLimit(50)},
+			query:    "",
+			expected: turtleware.Paging{Limit: 20, DefaultLimit: 20, MaxLimit: 50},
+		},
+		"Max_Limit_Clamped": {
+			opts:     []turtleware.PagingOption{turtleware.PagingDefaultLimit(20), turtleware.PagingMaxLimit(50)},
+			query:    "limit=9001",
+			expected: turtleware.Paging{Limit: 50, DefaultLimit: 20, MaxLimit: 50},
+		},
+		"Within_Max_Limit": {
+			opts:     []turtleware.PagingOption{turtleware.PagingDefaultLimit(20), turtleware.PagingMaxLimit(50)},
+			query:    "limit=30",
+			expected: turtleware.Paging{Limit: 30, DefaultLimit: 20, MaxLimit: 50},
+		},
+		"No_Limit": {
+			opts:     []turtleware.PagingOption{turtleware.PagingDefaultLimit(0)},
+			query:    "",
+			expected: turtleware.Paging{Limit: 0, DefaultLimit: 0, MaxLimit: 500},
+		},
+		"No_Limit_Skips_Clamp": {
+			opts:     []turtleware.PagingOption{turtleware.PagingDefaultLimit(0)},
+			query:    "limit=9001",
+			expected: turtleware.Paging{Limit: 9001, DefaultLimit: 0, MaxLimit: 500},
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			recordedPaging := turtleware.Paging{}
+			middlewareVerify := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				paging, err := turtleware.PagingFromRequestContext(r.Context())
+				s.NoError(err)
+
+				recordedPaging = paging
+			})
+
+			s.request.URL.RawQuery = target.query
+
+			// when
+			turtleware.PagingMiddlewareWithOptions(target.opts...)(middlewareVerify).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expected, recordedPaging)
+			s.Equal(http.StatusOK, s.response.Code)
+		})
+	}
+}
+
+func (s *MiddlewareCommonSuite) Test_ConditionalHeadersMiddleware() {
+	cases := map[string]struct {
+		headers    map[string]string
+		goldenFile string
+	}{
+		"If_Match_And_If_None_Match": {
+			headers:    map[string]string{"If-Match": `"abc"`, "If-None-Match": `"def"`},
+			goldenFile: "match_conflict.json",
+		},
+		"Malformed_If_Modified_Since": {
+			headers:    map[string]string{"If-Modified-Since": "Käsekuchen"},
+			goldenFile: "malformed_if_modified_since.json",
+		},
+		"Malformed_If_Unmodified_Since": {
+			headers:    map[string]string{"If-Unmodified-Since": "Käsekuchen"},
+			goldenFile: "malformed_if_unmodified_since.json",
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			for key, value := range target.headers {
+				s.request.Header.Set(key, value)
+			}
+
+			// when
+			turtleware.ConditionalHeadersMiddleware(nextCapture).ServeHTTP(s.response, s.request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.Equal(http.StatusBadRequest, s.response.Code)
+			s.JSONEq(s.loadTestDataString("conditional/"+target.goldenFile), s.response.Body.String())
+		})
+	}
+
+	s.Run("Valid", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		s.request.Header.Set("If-None-Match", `"abc"`)
+		s.request.Header.Set("If-Modified-Since", "Thu, 23 May 1991 01:02:03 UTC")
+
+		// when
+		turtleware.ConditionalHeadersMiddleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Equal(http.StatusOK, s.response.Code)
+	})
+
+	s.Run("Valid_Both_Dates", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		s.request.Header.Set("If-Modified-Since", "Thu, 23 May 1991 01:02:03 UTC")
+		s.request.Header.Set("If-Unmodified-Since", "Thu, 23 May 1991 01:02:03 UTC")
+
+		// when
+		turtleware.ConditionalHeadersMiddleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Equal(http.StatusOK, s.response.Code)
+	})
+}
+
+func (s *MiddlewareCommonSuite) Test_QueryComplexityMiddleware() {
+	middleware := turtleware.QueryComplexityMiddleware(4)
+
+	s.Run("At_Budget", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		s.request.URL.RawQuery = "fields[articles]=title,body&include=author&sort=-created"
+
+		// when
+		middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Equal(http.StatusOK, s.response.Code)
+	})
+
+	s.Run("Over_Budget", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		s.request.URL.RawQuery = "fields[articles]=title,body&include=author&sort=-created&filter[author]=bob"
+
+		// when
+		middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.False(nextCapture.Called)
+		s.Equal(http.StatusBadRequest, s.response.Code)
+		s.JSONEq(s.loadTestDataString("query/too_complex.json"), s.response.Body.String())
+	})
+}
+
+func (s *MiddlewareCommonSuite) Test_RestrictMethods() {
+	middleware := turtleware.RestrictMethods(http.MethodGet, http.MethodHead)
+
+	s.Run("Allowed", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		// when
+		middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Empty(s.response.Header().Get("Allow"))
+	})
+
+	s.Run("Disallowed", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		s.request.Method = http.MethodPost
+
+		// when
+		middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.False(nextCapture.Called)
+		s.Equal(http.StatusMethodNotAllowed, s.response.Code)
+		s.Equal("GET, HEAD", s.response.Header().Get("Allow"))
+		s.JSONEq(s.loadTestDataString("errors/method_not_allowed.json"), s.response.Body.String())
+	})
+}
+
+func (s *MiddlewareCommonSuite) Test_RequireJSONContentTypeMiddleware() {
+	cases := map[string]struct {
+		method      string
+		contentType string
+		body        string
+		rejected    bool
+	}{
+		"JSON":            {method: http.MethodPost, contentType: "application/json", body: "{}"},
+		"JSON_Charset":    {method: http.MethodPost, contentType: "application/json; charset=utf-8", body: "{}"},
+		"Merge_Patch":     {method: http.MethodPatch, contentType: "application/merge-patch+json", body: "{}"},
+		"Wildcard":        {method: http.MethodPut, contentType: "*/*", body: "{}"},
+		"No_Body":         {method: http.MethodPost, contentType: ""},
+		"GET":             {method: http.MethodGet, contentType: "text/plain", body: "{}"},
+		"Plain_Text":      {method: http.MethodPost, contentType: "text/plain", body: "{}", rejected: true},
+		"Form":            {method: http.MethodPatch, contentType: "application/x-www-form-urlencoded", body: "a=b", rejected: true},
+		"Missing":         {method: http.MethodPut, contentType: "", body: "{}", rejected: true},
+		"Malformed":       {method: http.MethodPost, contentType: "application/", body: "{}", rejected: true},
+		"Non_Application": {method: http.MethodPost, contentType: "text/foo+json", body: "{}", rejected: true},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			s.request = httptest.NewRequest(target.method, "https://example.com/foo", strings.NewReader(target.body))
+			if target.contentType != "" {
+				s.request.Header.Set("Content-Type", target.contentType)
+			}
+
+			// when
+			turtleware.RequireJSONContentTypeMiddleware(nextCapture).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(!target.rejected, nextCapture.Called)
+			if target.rejected {
+				s.Equal(http.StatusUnsupportedMediaType, s.response.Code)
+				s.Contains(s.response.Body.String(), turtleware.ErrUnsupportedMediaType.Error())
+			}
+		})
+	}
+}
+
+func (s *MiddlewareCommonSuite) Test_RequireClaimMiddleware() {
+	// given
+	hmacKey, err := turtleware.JWKFromPrivateKey([]byte("supersecretpassphrase"), "hmac-key")
+	s.Require().NoError(err)
+
+	keySet := jwk.NewSet()
+	s.Require().NoError(keySet.AddKey(hmacKey))
+
+	cases := map[string]struct {
+		claims  map[string]interface{}
+		allowed bool
+	}{
+		"Scalar": {
+			claims:  map[string]interface{}{"roles": "admin"},
+			allowed: true,
+		},
+		"Scalar_Space_Separated": {
+			claims:  map[string]interface{}{"roles": "reader editor"},
+			allowed: true,
+		},
+		"Array": {
+			claims:  map[string]interface{}{"roles": []string{"reader", "admin"}},
+			allowed: true,
+		},
+		"Scalar_Mismatch": {
+			claims:  map[string]interface{}{"roles": "reader"},
+			allowed: false,
+		},
+		"Array_Mismatch": {
+			claims:  map[string]interface{}{"roles": []string{"reader"}},
+			allowed: false,
+		},
+		"Missing": {
+			claims:  map[string]interface{}{"uuid": s.userUUID},
+			allowed: false,
+		},
+		"Wrong_Type": {
+			claims:  map[string]interface{}{"roles": 42},
+			allowed: false,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			token := s.generateToken(jwa.HS512, hmacKey, target.claims, map[string]interface{}{jwk.KeyIDKey: hmacKey.KeyID()})
+			s.request.Header.Set("Authorization", "Bearer "+token)
+
+			nextCapture := &MiddlewareCapture{}
+
+			// when
+			alice.New(
+				turtleware.AuthBearerHeaderMiddleware,
+				turtleware.AuthClaimsMiddleware(keySet),
+				turtleware.RequireClaimMiddleware("roles", "admin", "editor"),
+			).Then(nextCapture).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.allowed, nextCapture.Called)
+			if !target.allowed {
+				s.Equal(http.StatusForbidden, s.response.Code)
+				s.Contains(s.response.Body.String(), turtleware.ErrInsufficientScope.Error())
+			}
+		})
+	}
+
+	s.Run("ErrContextMissingAuthClaims", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		// when
+		turtleware.RequireClaimMiddleware("roles", "admin")(nextCapture).ServeHTTP(s.response, s.request)
+
+		// then
+		s.False(nextCapture.Called)
+		s.Equal(http.StatusInternalServerError, s.response.Code)
+	})
+}
+
 func (s *MiddlewareCommonSuite) Test_AuthClaimsMiddleware_Success() {
 	// given
 	recordedClaims := map[string]interface{}{}
@@ -324,10 +974,164 @@ func (s *MiddlewareCommonSuite) Test_AuthClaimsMiddleware_ErrTokenValidationFail
 	).Then(middlewareVerify).ServeHTTP(s.response, s.request)
 
 	// then
-	s.Equal(http.StatusBadRequest, s.response.Code)
+	s.Equal(http.StatusUnauthorized, s.response.Code)
+	s.Equal(`Bearer error="invalid_token", error_description="failed to validate token signature"`, s.response.Header().Get("WWW-Authenticate"))
+	s.JSONEq(s.loadTestDataString("authclaims/token_validation_failed.json"), s.response.Body.String())
+}
+
+func (s *MiddlewareCommonSuite) Test_AuthClaimsMiddleware_ErrTokenExpired() {
+	// given
+	hmacKey, err := turtleware.JWKFromPrivateKey([]byte("supersecretpassphrase"), "hmac-key")
+	s.Require().NoError(err)
+
+	keySet := jwk.NewSet()
+	s.Require().NoError(keySet.AddKey(hmacKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		hmacKey,
+		map[string]interface{}{jwt.ExpirationKey: time.Now().Add(-time.Hour)},
+		map[string]interface{}{jwk.KeyIDKey: hmacKey.KeyID()},
+	)
+	s.request.Header.Set("Authorization", "Bearer "+token)
+
+	middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		s.Fail("unexpected middleware invocation")
+	})
+
+	// when
+	alice.New(
+		turtleware.AuthBearerHeaderMiddleware,
+		turtleware.AuthClaimsMiddleware(keySet),
+	).Then(middlewareVerify).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusUnauthorized, s.response.Code)
+	s.Equal(`Bearer error="invalid_token", error_description="token expired"`, s.response.Header().Get("WWW-Authenticate"))
+	s.JSONEq(s.loadTestDataString("authclaims/token_expired.json"), s.response.Body.String())
+}
+
+func (s *MiddlewareCommonSuite) Test_AuthRealmMiddleware() {
+	s.Run("Missing_Token", func() {
+		// when
+		alice.New(
+			turtleware.AuthRealmMiddleware("example"),
+			turtleware.AuthBearerHeaderMiddleware,
+		).Then(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusUnauthorized, s.response.Code)
+		s.Equal(`Bearer realm="example"`, s.response.Header().Get("WWW-Authenticate"))
+	})
+
+	s.Run("Invalid_Token", func() {
+		// given
+		s.request.Header.Set("Authorization", "Bearer 123")
+
+		// when
+		alice.New(
+			turtleware.AuthRealmMiddleware("example"),
+			turtleware.AuthBearerHeaderMiddleware,
+			turtleware.AuthClaimsMiddleware(nil),
+		).Then(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusUnauthorized, s.response.Code)
+		s.Equal(
+			`Bearer realm="example", error="invalid_token", error_description="failed to validate token signature"`,
+			s.response.Header().Get("WWW-Authenticate"),
+		)
+	})
+}
+
+func (s *MiddlewareCommonSuite) Test_AuthClaimsMiddlewareWithOptions_WrongAudience() {
+	// given
+	hmacKey, err := turtleware.JWKFromPrivateKey([]byte("supersecretpassphrase"), "hmac-key")
+	s.Require().NoError(err)
+
+	keySet := jwk.NewSet()
+	s.Require().NoError(keySet.AddKey(hmacKey))
+
+	token := s.generateToken(
+		jwa.HS512,
+		hmacKey,
+		map[string]interface{}{jwt.AudienceKey: "other-api"},
+		map[string]interface{}{jwk.KeyIDKey: hmacKey.KeyID()},
+	)
+	s.request.Header.Set("Authorization", "Bearer "+token)
+
+	middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		s.Fail("unexpected middleware invocation")
+	})
+
+	// when
+	alice.New(
+		turtleware.AuthBearerHeaderMiddleware,
+		turtleware.AuthClaimsMiddlewareWithOptions(keySet, turtleware.TokenExpectedAudience("api")),
+	).Then(middlewareVerify).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusUnauthorized, s.response.Code)
+	s.Equal(`Bearer error="invalid_token", error_description="failed to validate token signature"`, s.response.Header().Get("WWW-Authenticate"))
 	s.JSONEq(s.loadTestDataString("authclaims/token_validation_failed.json"), s.response.Body.String())
 }
 
+func (s *MiddlewareCommonSuite) Test_AuthClaimsMiddlewareWithUserClaim() {
+	// given
+	hmacKey, err := turtleware.JWKFromPrivateKey([]byte("supersecretpassphrase"), "hmac-key")
+	s.Require().NoError(err)
+
+	keySet := jwk.NewSet()
+	s.Require().NoError(keySet.AddKey(hmacKey))
+
+	cases := map[string]struct {
+		claims   map[string]interface{}
+		expected string
+		err      error
+	}{
+		"Subject": {
+			claims:   map[string]interface{}{jwt.SubjectKey: s.userUUID, "uuid": "other-uuid"},
+			expected: s.userUUID,
+		},
+		"Missing": {
+			claims: map[string]interface{}{"uuid": s.userUUID},
+			err:    turtleware.ErrMissingUserUUID,
+		},
+		"Empty": {
+			claims: map[string]interface{}{jwt.SubjectKey: ""},
+			err:    turtleware.ErrMissingUserUUID,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			token := s.generateToken(jwa.HS512, hmacKey, target.claims, map[string]interface{}{jwk.KeyIDKey: hmacKey.KeyID()})
+			s.request.Header.Set("Authorization", "Bearer "+token)
+
+			var capturedUserUUID string
+			var capturedErr error
+			middlewareVerify := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				capturedUserUUID, capturedErr = turtleware.UserUUIDFromRequestContext(r.Context())
+			})
+
+			// when
+			alice.New(
+				turtleware.AuthBearerHeaderMiddleware,
+				turtleware.AuthClaimsMiddlewareWithUserClaim(keySet, jwt.SubjectKey),
+			).Then(middlewareVerify).ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expected, capturedUserUUID)
+			if target.err != nil {
+				s.ErrorIs(capturedErr, target.err)
+			} else {
+				s.NoError(capturedErr)
+			}
+		})
+	}
+}
+
 func (s *MiddlewareCommonSuite) Test_PagingFromRequestContext_Error() {
 	// given
 	ctx := context.Background()
@@ -382,3 +1186,114 @@ func (s *MiddlewareCommonSuite) Test_UserUUIDFromRequestContext_ErrMissingUserUU
 	// then
 	s.ErrorIs(capturedErr, turtleware.ErrMissingUserUUID)
 }
+
+func (s *MiddlewareCommonSuite) Test_TracingMiddleware_Status() {
+	cases := map[string]struct {
+		handler            http.Handler
+		expectedStatusCode int
+		expectedSpanStatus codes.Code
+	}{
+		"Implicit_OK": {
+			handler:            http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}),
+			expectedStatusCode: http.StatusOK,
+			expectedSpanStatus: codes.Unset,
+		},
+		"Body_Only": {
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("some-body"))
+			}),
+			expectedStatusCode: http.StatusOK,
+			expectedSpanStatus: codes.Unset,
+		},
+		"Client_Error": {
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			}),
+			expectedStatusCode: http.StatusNotFound,
+			expectedSpanStatus: codes.Unset,
+		},
+		"Server_Error": {
+			handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			}),
+			expectedStatusCode: http.StatusBadGateway,
+			expectedSpanStatus: codes.Error,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			recorder := &SpanRecorder{}
+
+			middleware := turtleware.TracingMiddleware("test", recorder)
+
+			// when
+			middleware(target.handler).ServeHTTP(s.response, s.request)
+
+			// then
+			span := recorder.Span("test")
+			s.Require().NotNil(span)
+			s.True(span.Ended)
+			s.Equal(target.expectedStatusCode, s.response.Code)
+			s.Equal(int64(target.expectedStatusCode), span.Attributes["http.status_code"].AsInt64())
+			s.Equal(target.expectedSpanStatus, span.StatusCode)
+		})
+	}
+}
+
+func (s *MiddlewareCommonSuite) Test_TracingMiddleware_Attributes() {
+	cases := map[string]struct {
+		name             string
+		url              string
+		expectedSpanName string
+		expectedTarget   string
+		expectedScheme   string
+		expectedHostName string
+	}{
+		"Named": {
+			name:             "some-span",
+			url:              "https://example.com/foo?bar=baz",
+			expectedSpanName: "some-span",
+			expectedTarget:   "/foo",
+			expectedScheme:   "https",
+			expectedHostName: "example.com",
+		},
+		"Query_Token": {
+			url:              "https://example.com/foo%20bar?access_token=secret",
+			expectedSpanName: "HTTP GET",
+			expectedTarget:   "/foo%20bar",
+			expectedScheme:   "https",
+			expectedHostName: "example.com",
+		},
+		"Unnamed": {
+			url:              "http://example.com:8080/foo",
+			expectedSpanName: "HTTP GET",
+			expectedTarget:   "/foo",
+			expectedScheme:   "http",
+			expectedHostName: "example.com",
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			recorder := &SpanRecorder{}
+
+			s.request = httptest.NewRequest(http.MethodGet, target.url, http.NoBody)
+
+			middleware := turtleware.TracingMiddleware(target.name, recorder)
+
+			// when
+			middleware(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+			// then
+			span := recorder.Span(target.expectedSpanName)
+			s.Require().NotNil(span)
+			s.Equal(http.MethodGet, span.Attributes["http.method"].AsString())
+			s.Equal(target.expectedTarget, span.Attributes["http.target"].AsString())
+			s.Equal(target.expectedScheme, span.Attributes["http.scheme"].AsString())
+			s.Equal(target.expectedHostName, span.Attributes["net.host.name"].AsString())
+		})
+	}
+}
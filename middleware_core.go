@@ -11,11 +11,17 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"time"
 )
 
 var emptyListHash = hex.EncodeToString(sha256.New().Sum(nil))
 
+// ErrCountUnavailable may be returned by a ListCountFunc, to indicate that the total
+// amount of entities cannot be computed (cheaply). The CountHeaderMiddleware then
+// continues without an exact X-Total-Count header, instead of erroring.
+var ErrCountUnavailable = errors.New("total count unavailable")
+
 // ListHashFunc is a function for returning a calculated hash for a given subset of entities
 // via the given paging, for a list endpoint.
 // The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
@@ -24,34 +30,131 @@ type ListHashFunc func(ctx context.Context, paging Paging) (string, error)
 
 // ListCountFunc is a function for returning the total amount of entities for a list endpoint.
 // The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
-// elements, for easier handling.
+// elements, for easier handling. ErrCountUnavailable may be returned, if the total amount
+// cannot be computed.
 type ListCountFunc func(ctx context.Context) (uint, error)
 
+// ListLastModFunc is a function for returning the last modification date of a given subset
+// of entities via the given paging, for a list endpoint. This is usually the modification
+// date of the newest element.
+// The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
+// elements, for easier handling.
+type ListLastModFunc func(ctx context.Context, paging Paging) (time.Time, error)
+
 // ResourceLastModFunc is a function for returning the last modification data for a specific entity.
 // The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
 // elements, for easier handling.
 type ResourceLastModFunc func(ctx context.Context, entityUUID string) (time.Time, error)
 
+// ResourceVersionFunc is a function for returning an opaque version for a specific entity.
+// The version must change whenever the entity changes.
+// The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
+// elements, for easier handling.
+type ResourceVersionFunc func(ctx context.Context, entityUUID string) (string, error)
+
 // ErrorHandlerFunc is a function for handling arbitrary errors, that can happen during
 // and turtleware middleware.
 // If in doubt, use turtleware.DefaultErrorHandler, which handles many errors with meaningful
 // error output.
 type ErrorHandlerFunc func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error)
 
+// RecoverErrorHandler wraps the given ErrorHandlerFunc, so a panic while handling an error
+// is recovered. The panic is logged, and a minimal 500 response is written instead - unless
+// the error handler already started the response, which is then left as is.
+// A panic with http.ErrAbortHandler is not recovered, so the server still aborts the response.
+// All turtleware middlewares wrap their provided ErrorHandlerFunc this way.
+// If the error is deemed retryable by a RetryMiddleware further up the chain, the
+// ErrorHandlerFunc is not called at all, and the request is retried instead.
+func RecoverErrorHandler(errorHandler ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		if scheduleRetry(ctx, err) {
+			return
+		}
+
+		sw := &statusWriter{ResponseWriter: w}
+
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+
+				zerolog.Ctx(ctx).Error().
+					Err(err).
+					Interface("panic", rec).
+					Msg("Recovered from panic in error handler")
+
+				// Headers are already on their way, so there is nothing left to correct
+				if sw.status != 0 {
+					return
+				}
+
+				w.Header().Set("Cache-Control", "no-store")
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		}()
+
+		errorHandler(ctx, sw, r, err)
+	}
+}
+
 // CountHeaderMiddleware is a middleware for injecting an X-Total-Count header into the response,
 // by the provided ListCountFunc. If an error is encountered, the provided ErrorHandlerFunc is called.
+// The behavior of the middleware (e.g. the name of the header) can be adjusted via the provided options.
+// The names of the headers are passed down, so the list data handlers read the total count from the
+// same headers (see ListDataCountHeaderNames).
 func CountHeaderMiddleware(
 	countFetcher ListCountFunc,
 	errorHandler ErrorHandlerFunc,
+	opts ...CountOption,
 ) func(http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	// default
+	config := &countOptions{
+		approximateCountFetcher: nil,
+		unavailableValue:        "",
+		headerNames:             []string{"X-Total-Count"},
+		methods:                 nil,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r = r.WithContext(context.WithValue(r.Context(), ctxCountHeaderNames, config.headerNames))
+			if config.methods != nil && !slices.Contains(config.methods, r.Method) {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
 			countContext, cancel := context.WithCancel(r.Context())
 			defer cancel()
 
 			logger := zerolog.Ctx(countContext)
 
-			totalCount, err := countFetcher(countContext)
+			fetcher := countFetcher
+			approximate := r.Method == http.MethodHead && config.approximateCountFetcher != nil
+			if approximate {
+				fetcher = config.approximateCountFetcher
+			}
+
+			totalCount, err := fetcher(countContext)
+			if errors.Is(err, ErrCountUnavailable) {
+				logger.Debug().Msg("Total count unavailable")
+				if config.unavailableValue != "" {
+					setCountHeaders(w.Header(), config.headerNames, config.unavailableValue)
+				}
+
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
 			if err != nil {
 				if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
 					totalCount = 0
@@ -63,28 +166,46 @@ func CountHeaderMiddleware(
 				}
 			}
 
-			w.Header().Set("X-Total-Count", fmt.Sprintf("%d", totalCount))
+			setCountHeaders(w.Header(), config.headerNames, fmt.Sprintf("%d", totalCount))
+			if approximate {
+				w.Header().Set("X-Count-Approximate", "true")
+			}
 
 			h.ServeHTTP(w, r)
 		})
 	}
 }
 
+func setCountHeaders(header http.Header, headerNames []string, value string) {
+	for _, headerName := range headerNames {
+		header.Set(headerName, value)
+	}
+}
+
 // ListCacheMiddleware is a middleware for transparently handling caching via the provided
 // ListHashFunc. The next handler of the middleware is only called on a cache miss. That is,
-// if the If-None-Match header and the fetched hash differ.
+// if the If-None-Match header and the fetched hash differ. The hash is emitted as a strong Etag,
+// and compared to the If-None-Match header via EtagMatches.
 // If the ListHashFunc returns either sql.ErrNoRows or os.ErrNotExist, the sha256 hash of an
 // empty string is assumed as the hash.
+// The Vary and Cache-Control headers are set according to the given CacheOption values
+// (see CacheVaryAuthorization, CacheVary and CacheControl).
 // If an error is encountered, the provided ErrorHandlerFunc is called.
 func ListCacheMiddleware(
 	hashFetcher ListHashFunc,
 	errorHandler ErrorHandlerFunc,
+	opts ...CacheOption,
 ) func(h http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+	config := newCacheOptions(opts...)
+	vary := config.varyHeaders()
+	cacheControl := config.cacheControlDirectives(DefaultListCacheControl)
+
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			logger := zerolog.Ctx(r.Context())
-			w.Header().Set("Cache-Control", "must-revalidate")
-			w.Header().Add("Cache-Control", "max-age=0")
+			addVaryHeader(w.Header(), vary...)
+			setCacheControlHeader(w.Header(), cacheControl...)
 
 			logger.Trace().Msg("Handling preflight for resource list request")
 
@@ -116,9 +237,82 @@ func ListCacheMiddleware(
 				}
 			}
 
-			w.Header().Set("Etag", hash)
+			w.Header().Set("Etag", StrongEtag(hash))
 
-			cacheHit := etag == hash
+			cacheHit := EtagMatches(etag, hash)
+			if cacheHit {
+				logger.Debug().Msg("Successful cache hit")
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ListLastModCacheMiddleware is a middleware for transparently handling caching of a list via
+// the provided ListLastModFunc. The next handler of the middleware is only called when the
+// If-Modified-Since header and the fetched last modification date differ.
+// As If-None-Match takes precedence over If-Modified-Since, the If-Modified-Since header is
+// ignored if both are present. This allows pairing with the ListCacheMiddleware.
+// If the ListLastModFunc returns either sql.ErrNoRows or os.ErrNotExist, the cache check is skipped.
+// The Vary and Cache-Control headers are set according to the given CacheOption values
+// (see CacheVaryAuthorization, CacheVary and CacheControl).
+// If an error is encountered, the provided ErrorHandlerFunc is called.
+func ListLastModCacheMiddleware(
+	lastModFetcher ListLastModFunc,
+	errorHandler ErrorHandlerFunc,
+	opts ...CacheOption,
+) func(h http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+	config := newCacheOptions(opts...)
+	vary := config.varyHeaders()
+	cacheControl := config.cacheControlDirectives(DefaultListCacheControl)
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := zerolog.Ctx(r.Context())
+			addVaryHeader(w.Header(), vary...)
+			setCacheControlHeader(w.Header(), cacheControl...)
+
+			logger.Trace().Msg("Handling preflight for resource list request")
+
+			etag, lastModified := ExtractCacheHeader(r)
+
+			if !lastModified.IsZero() {
+				logger.Debug().Msgf("Received If-Modified-Since date %s", lastModified)
+			}
+
+			hashContext, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			paging, err := PagingFromRequestContext(hashContext)
+			if err != nil {
+				errorHandler(hashContext, w, r, err)
+
+				return
+			}
+
+			maxModDate, err := lastModFetcher(hashContext, paging)
+			if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
+				// Skip cache check
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to receive last-modification date")
+				errorHandler(hashContext, w, r, ErrReceivingMeta)
+
+				return
+			}
+
+			w.Header().Set("Last-Modified", maxModDate.Format(time.RFC1123))
+
+			cacheHit := etag == "" && !lastModified.IsZero() && maxModDate.Truncate(time.Second).Equal(lastModified.Truncate(time.Second))
 			if cacheHit {
 				logger.Debug().Msg("Successful cache hit")
 				w.WriteHeader(http.StatusNotModified)
@@ -134,18 +328,41 @@ func ListCacheMiddleware(
 // ResourceCacheMiddleware is a middleware for transparently handling caching of a single entity
 // (or resource) via the provided ResourceLastModFunc. The next handler of the middleware is only
 // called when the If-Modified-Since header and the fetched last modification date differ.
+// If the ResourceLastModFunc returns either sql.ErrNoRows or os.ErrNotExist, the cache check is
+// skipped. If it returns ErrResourceGone, the error is passed to the ErrorHandlerFunc right away.
+// If enabled via CacheLastModEtag, a strong Etag derived from the last modification date is emitted
+// as well. As If-None-Match takes precedence over If-Modified-Since, the If-Modified-Since header is
+// ignored if both are present.
+// If enabled via CacheIfUnmodifiedSince, requests for resources modified after the date of the
+// If-Unmodified-Since header are passed to the ErrorHandlerFunc with ErrPreconditionFailed.
+// The Vary and Cache-Control headers are set according to the given CacheOption values
+// (see CacheVaryAuthorization, CacheVary and CacheControl).
 // If an error is encountered, the provided ErrorHandlerFunc is called.
 func ResourceCacheMiddleware(
 	lastModFetcher ResourceLastModFunc,
 	errorHandler ErrorHandlerFunc,
+	opts ...CacheOption,
 ) func(h http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+	config := newCacheOptions(opts...)
+	vary := config.varyHeaders()
+	cacheControl := config.cacheControlDirectives(nil)
+	lastModEtag := config.lastModEtag
+	ifUnmodifiedSince := config.ifUnmodifiedSince
+
 	return func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			logger := zerolog.Ctx(r.Context())
+			addVaryHeader(w.Header(), vary...)
+			setCacheControlHeader(w.Header(), cacheControl...)
 
 			logger.Trace().Msg("Handling preflight for resource request")
 
-			_, lastModified := ExtractCacheHeader(r)
+			etag, lastModified := ExtractCacheHeader(r)
+
+			if lastModEtag && etag != "" {
+				logger.Debug().Msgf("Received If-None-Match tag %s", etag)
+			}
 
 			if !lastModified.IsZero() {
 				logger.Debug().Msgf("Received If-Modified-Since date %s", lastModified)
@@ -169,6 +386,12 @@ func ResourceCacheMiddleware(
 				return
 			}
 
+			if errors.Is(err, ErrResourceGone) {
+				errorHandler(hashContext, w, r, err)
+
+				return
+			}
+
 			if err != nil {
 				logger.Error().Err(err).Msg("Failed to receive last-modification date")
 				errorHandler(hashContext, w, r, ErrReceivingMeta)
@@ -178,7 +401,96 @@ func ResourceCacheMiddleware(
 
 			w.Header().Set("Last-Modified", maxModDate.Format(time.RFC1123))
 
+			if ifUnmodifiedSince && ModifiedSince(r, maxModDate) {
+				logger.Debug().Msg("Resource modified since If-Unmodified-Since date")
+				errorHandler(hashContext, w, r, ErrPreconditionFailed)
+
+				return
+			}
+
 			cacheHit := !lastModified.IsZero() && maxModDate.Truncate(time.Second).Equal(lastModified.Truncate(time.Second))
+			if lastModEtag {
+				versionTag := LastModEtag(entityUUID, maxModDate)
+				w.Header().Set("Etag", versionTag)
+
+				if etag != "" {
+					cacheHit = EtagMatches(etag, versionTag)
+				}
+			}
+			if cacheHit {
+				logger.Debug().Msg("Successful cache hit")
+				w.WriteHeader(http.StatusNotModified)
+
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ResourceVersionCacheMiddleware is a middleware for transparently handling caching of a single
+// entity (or resource) via the provided ResourceVersionFunc. The version is used as a strong Etag
+// (see StrongEtag).
+// The next handler of the middleware is only called when the If-None-Match header and the fetched
+// version differ.
+// If the ResourceVersionFunc returns either sql.ErrNoRows or os.ErrNotExist, the cache check is skipped.
+// The Vary and Cache-Control headers are set according to the given CacheOption values
+// (see CacheVaryAuthorization, CacheVary and CacheControl).
+// If an error is encountered, the provided ErrorHandlerFunc is called.
+func ResourceVersionCacheMiddleware(
+	versionFetcher ResourceVersionFunc,
+	errorHandler ErrorHandlerFunc,
+	opts ...CacheOption,
+) func(h http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+	config := newCacheOptions(opts...)
+	vary := config.varyHeaders()
+	cacheControl := config.cacheControlDirectives(nil)
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := zerolog.Ctx(r.Context())
+			addVaryHeader(w.Header(), vary...)
+			setCacheControlHeader(w.Header(), cacheControl...)
+
+			logger.Trace().Msg("Handling preflight for resource request")
+
+			etag, _ := ExtractCacheHeader(r)
+
+			if etag != "" {
+				logger.Debug().Msgf("Received If-None-Match tag %s", etag)
+			}
+
+			hashContext, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			entityUUID, err := EntityUUIDFromRequestContext(hashContext)
+			if err != nil {
+				errorHandler(hashContext, w, r, err)
+
+				return
+			}
+
+			version, err := versionFetcher(hashContext, entityUUID)
+			if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
+				// Skip cache check
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			if err != nil {
+				logger.Error().Err(err).Msg("Failed to receive version")
+				errorHandler(hashContext, w, r, ErrReceivingMeta)
+
+				return
+			}
+
+			versionTag := StrongEtag(version)
+			w.Header().Set("Etag", versionTag)
+
+			cacheHit := EtagMatches(etag, versionTag)
 			if cacheHit {
 				logger.Debug().Msg("Successful cache hit")
 				w.WriteHeader(http.StatusNotModified)
@@ -0,0 +1,52 @@
+package turtleware
+
+type countOptions struct {
+	approximateCountFetcher ListCountFunc
+	unavailableValue        string
+	headerNames             []string
+	methods                 []string
+}
+
+// CountOption represents an option for the CountHeaderMiddleware.
+type CountOption func(*countOptions)
+
+// CountApproximateOnHead sets a ListCountFunc, which is used instead of the exact
+// ListCountFunc for HEAD requests. This is useful if computing the exact count is
+// expensive, and clients probe the count via HEAD requests regularly.
+// Approximated counts are flagged via the X-Count-Approximate header.
+// The default is nil, which means the exact count is always computed.
+func CountApproximateOnHead(approximateCountFetcher ListCountFunc) CountOption {
+	return func(c *countOptions) {
+		c.approximateCountFetcher = approximateCountFetcher
+	}
+}
+
+// CountUnavailableValue sets the value of the count header, which is used
+// if the ListCountFunc returns ErrCountUnavailable (e.g. "unknown").
+// The default is empty, which means the header is omitted entirely.
+func CountUnavailableValue(unavailableValue string) CountOption {
+	return func(c *countOptions) {
+		c.unavailableValue = unavailableValue
+	}
+}
+
+// CountHeaderNames sets the names of the headers carrying the total count (e.g.
+// X-Pagination-Total), to match existing client contracts. If multiple names
+// are given, the total count is set for each of them.
+// The default is X-Total-Count.
+func CountHeaderNames(headerNames ...string) CountOption {
+	return func(c *countOptions) {
+		c.headerNames = headerNames
+	}
+}
+
+// CountOnlyForMethods restricts the count to requests with one of the given methods (e.g.
+// HEAD, if the list data handler derives the total count from the result rows, see
+// ListDataTotalCountColumn). Other requests are passed through without a count header,
+// but the names of the headers are still passed down.
+// The default is nil, which means the count is computed for all requests.
+func CountOnlyForMethods(methods ...string) CountOption {
+	return func(c *countOptions) {
+		c.methods = methods
+	}
+}
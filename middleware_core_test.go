@@ -3,8 +3,10 @@ package turtleware_test
 import (
 	"github.com/justinas/alice"
 	"github.com/kernle32dll/turtleware"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/suite"
 
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
@@ -118,6 +120,222 @@ func (s *MiddlewareCoreSuite) Test_CountHeaderMiddleware_Valid_Errors() {
 	}
 }
 
+func (s *MiddlewareCoreSuite) Test_CountHeaderMiddleware_Approximate() {
+	exactCountFetcher := func(
+		ctx context.Context,
+	) (uint, error) {
+		return uint(1337), nil
+	}
+
+	approximateCountFetcher := func(
+		ctx context.Context,
+	) (uint, error) {
+		return uint(1300), nil
+	}
+
+	s.Run("Head", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		errorCapture := &ErrorHandlerCapture{}
+
+		s.request.Method = http.MethodHead
+
+		testChain := alice.New(
+			turtleware.CountHeaderMiddleware(
+				exactCountFetcher,
+				errorCapture.Capture,
+				turtleware.CountApproximateOnHead(approximateCountFetcher),
+			),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal("1300", s.response.Header().Get("X-Total-Count"))
+		s.Equal("true", s.response.Header().Get("X-Count-Approximate"))
+		s.True(nextCapture.Called)
+		s.NoError(errorCapture.CapturedError)
+	})
+
+	s.Run("Get", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.CountHeaderMiddleware(
+				exactCountFetcher,
+				errorCapture.Capture,
+				turtleware.CountApproximateOnHead(approximateCountFetcher),
+			),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal("1337", s.response.Header().Get("X-Total-Count"))
+		s.Empty(s.response.Header().Get("X-Count-Approximate"))
+		s.True(nextCapture.Called)
+		s.NoError(errorCapture.CapturedError)
+	})
+}
+
+func (s *MiddlewareCoreSuite) Test_CountHeaderMiddleware_Unavailable() {
+	countFetcher := func(
+		ctx context.Context,
+	) (uint, error) {
+		return 0, turtleware.ErrCountUnavailable
+	}
+
+	s.Run("Omitted", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.CountHeaderMiddleware(countFetcher, errorCapture.Capture),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NotContains(s.response.Header(), "X-Total-Count")
+		s.True(nextCapture.Called)
+		s.NoError(errorCapture.CapturedError)
+	})
+
+	s.Run("Unknown", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.CountHeaderMiddleware(
+				countFetcher,
+				errorCapture.Capture,
+				turtleware.CountUnavailableValue("unknown"),
+			),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal("unknown", s.response.Header().Get("X-Total-Count"))
+		s.True(nextCapture.Called)
+		s.NoError(errorCapture.CapturedError)
+	})
+}
+
+func (s *MiddlewareCoreSuite) Test_CountHeaderMiddleware_HeaderNames() {
+	// given
+	countFetcher := func(ctx context.Context) (uint, error) {
+		return 1337, nil
+	}
+
+	s.Run("Single", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.CountHeaderMiddleware(
+				countFetcher,
+				errorCapture.Capture,
+				turtleware.CountHeaderNames("X-Pagination-Total"),
+			),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal("1337", s.response.Header().Get("X-Pagination-Total"))
+		s.NotContains(s.response.Header(), "X-Total-Count")
+		s.True(nextCapture.Called)
+		s.NoError(errorCapture.CapturedError)
+	})
+
+	s.Run("Multiple", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.CountHeaderMiddleware(
+				countFetcher,
+				errorCapture.Capture,
+				turtleware.CountHeaderNames("X-Total-Count", "X-Pagination-Total"),
+			),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal("1337", s.response.Header().Get("X-Total-Count"))
+		s.Equal("1337", s.response.Header().Get("X-Pagination-Total"))
+		s.True(nextCapture.Called)
+		s.NoError(errorCapture.CapturedError)
+	})
+}
+
+func (s *MiddlewareCoreSuite) Test_CountHeaderMiddleware_OnlyForMethods() {
+	// given
+	countFetcher := func(ctx context.Context) (uint, error) {
+		return 1337, nil
+	}
+
+	s.Run("Included", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		errorCapture := &ErrorHandlerCapture{}
+
+		s.request = httptest.NewRequest(http.MethodHead, "https://example.com/foo", http.NoBody)
+
+		testChain := alice.New(
+			turtleware.CountHeaderMiddleware(
+				countFetcher,
+				errorCapture.Capture,
+				turtleware.CountOnlyForMethods(http.MethodHead),
+			),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal("1337", s.response.Header().Get("X-Total-Count"))
+		s.True(nextCapture.Called)
+		s.NoError(errorCapture.CapturedError)
+	})
+
+	s.Run("Excluded", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.CountHeaderMiddleware(
+				countFetcher,
+				errorCapture.Capture,
+				turtleware.CountOnlyForMethods(http.MethodHead),
+			),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NotContains(s.response.Header(), "X-Total-Count")
+		s.True(nextCapture.Called)
+		s.NoError(errorCapture.CapturedError)
+	})
+}
+
 func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware_Success_CacheMiss() {
 	// given
 	nextCapture := &MiddlewareCapture{}
@@ -148,7 +366,7 @@ func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware_Success_CacheMiss() {
 		"max-age=0",
 	)
 	s.Equal(
-		"some-hash",
+		`"some-hash"`,
 		s.response.Header().Get("Etag"),
 	)
 	s.Equal(http.StatusOK, s.response.Code)
@@ -188,7 +406,7 @@ func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware_Success_CacheHit() {
 		"max-age=0",
 	)
 	s.Equal(
-		"some-hash",
+		`"some-hash"`,
 		s.response.Header().Get("Etag"),
 	)
 	s.Equal(http.StatusNotModified, s.response.Code)
@@ -196,6 +414,46 @@ func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware_Success_CacheHit() {
 	s.NoError(errorCapture.CapturedError)
 }
 
+func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware_Success_CacheHit_Normalized() {
+	cases := map[string]string{
+		"Quoted":   `"some-hash"`,
+		"Weak":     `W/"some-hash"`,
+		"Multiple": `"other-hash", "some-hash"`,
+		"Wildcard": "*",
+	}
+
+	for testName, ifNoneMatch := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			s.request.Header.Set("If-None-Match", ifNoneMatch)
+
+			hashFetcher := func(
+				ctx context.Context,
+				paging turtleware.Paging,
+			) (string, error) {
+				return "some-hash", nil
+			}
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+				turtleware.ListCacheMiddleware(hashFetcher, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(`"some-hash"`, s.response.Header().Get("Etag"))
+			s.Equal(http.StatusNotModified, s.response.Code)
+			s.False(nextCapture.Called)
+			s.NoError(errorCapture.CapturedError)
+		})
+	}
+}
+
 func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware_Error() {
 	// given
 	nextCapture := &MiddlewareCapture{}
@@ -294,7 +552,7 @@ func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware_Valid_Errors() {
 				"max-age=0",
 			)
 			s.Equal(
-				"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+				`"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"`,
 				s.response.Header().Get("Etag"),
 			)
 			s.True(nextCapture.Called)
@@ -303,6 +561,141 @@ func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware_Valid_Errors() {
 	}
 }
 
+func (s *MiddlewareCoreSuite) Test_ListLastModCacheMiddleware_Success_CacheMiss() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	lastModTime := time.Date(1991, 5, 23, 1, 2, 3, 4, time.UTC)
+	s.request.Header.Set("If-Modified-Since", lastModTime.Add(-time.Hour).Format(time.RFC1123))
+
+	lastModFetcher := func(
+		ctx context.Context,
+		paging turtleware.Paging,
+	) (time.Time, error) {
+		s.Equal(turtleware.Paging{Offset: 0, Limit: 100, DefaultLimit: 100, MaxLimit: 500}, paging)
+		return lastModTime, nil
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+		turtleware.ListLastModCacheMiddleware(lastModFetcher, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal("Thu, 23 May 1991 01:02:03 UTC", s.response.Header().Get("Last-Modified"))
+	s.Equal(http.StatusOK, s.response.Code)
+	s.True(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareCoreSuite) Test_ListLastModCacheMiddleware_Success_CacheHit() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	lastModTime := time.Date(1991, 5, 23, 1, 2, 3, 4, time.UTC)
+	s.request.Header.Set("If-Modified-Since", lastModTime.Format(time.RFC1123))
+
+	lastModFetcher := func(
+		ctx context.Context,
+		paging turtleware.Paging,
+	) (time.Time, error) {
+		return lastModTime, nil
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+		turtleware.ListLastModCacheMiddleware(lastModFetcher, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal("Thu, 23 May 1991 01:02:03 UTC", s.response.Header().Get("Last-Modified"))
+	s.Equal(http.StatusNotModified, s.response.Code)
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareCoreSuite) Test_ListLastModCacheMiddleware_Error() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	lastModFetcher := func(
+		ctx context.Context,
+		paging turtleware.Paging,
+	) (time.Time, error) {
+		return time.Time{}, errors.New("some-error")
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+		turtleware.ListLastModCacheMiddleware(lastModFetcher, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Header().Get("Last-Modified"))
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingMeta)
+}
+
+func (s *MiddlewareCoreSuite) Test_ListLastModCacheMiddleware_ErrContextMissingPaging() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	testChain := alice.New(
+		turtleware.ListLastModCacheMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Header().Get("Last-Modified"))
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrContextMissingPaging)
+}
+
+func (s *MiddlewareCoreSuite) Test_ListLastModCacheMiddleware_Valid_Errors() {
+	for testName, targetErr := range validErrors {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			lastModFetcher := func(
+				ctx context.Context,
+				paging turtleware.Paging,
+			) (time.Time, error) {
+				return time.Time{}, targetErr
+			}
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+				turtleware.ListLastModCacheMiddleware(lastModFetcher, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Empty(s.response.Header().Get("Last-Modified"))
+			s.True(nextCapture.Called)
+			s.NoError(errorCapture.CapturedError)
+		})
+	}
+}
+
 func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_Success_CacheMiss() {
 	// given
 	nextCapture := &MiddlewareCapture{}
@@ -364,6 +757,126 @@ func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_Success_CacheHit() {
 	s.NoError(errorCapture.CapturedError)
 }
 
+func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_LastModEtag() {
+	lastModTime := time.Date(1991, 5, 23, 1, 2, 3, 4, time.UTC)
+
+	cases := map[string]struct {
+		matchEtag       bool
+		ifNoneMatch     string
+		ifModifiedSince time.Time
+		statusCode      int
+	}{
+		"No_Conditional_Headers": {
+			statusCode: http.StatusOK,
+		},
+		"Etag_Hit": {
+			matchEtag:  true,
+			statusCode: http.StatusNotModified,
+		},
+		"Etag_Miss": {
+			ifNoneMatch: `"other-etag"`,
+			statusCode:  http.StatusOK,
+		},
+		"Last_Modified_Hit": {
+			ifModifiedSince: lastModTime,
+			statusCode:      http.StatusNotModified,
+		},
+		"Etag_Precedence": {
+			ifNoneMatch:     `"other-etag"`,
+			ifModifiedSince: lastModTime,
+			statusCode:      http.StatusOK,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			expectedEtag := turtleware.LastModEtag(s.entityUUID, lastModTime)
+			if target.matchEtag {
+				s.request.Header.Set("If-None-Match", expectedEtag)
+			}
+
+			if target.ifNoneMatch != "" {
+				s.request.Header.Set("If-None-Match", target.ifNoneMatch)
+			}
+
+			if !target.ifModifiedSince.IsZero() {
+				s.request.Header.Set("If-Modified-Since", target.ifModifiedSince.Format(time.RFC1123))
+			}
+
+			lastModFetcher := func(
+				ctx context.Context,
+				entityUUID string,
+			) (time.Time, error) {
+				return lastModTime, nil
+			}
+
+			testChain := alice.New(
+				s.buildEntityUUIDChain,
+				turtleware.ResourceCacheMiddleware(lastModFetcher, errorCapture.Capture, turtleware.CacheLastModEtag(true)),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(expectedEtag, s.response.Header().Get("Etag"))
+			s.Equal("Thu, 23 May 1991 01:02:03 UTC", s.response.Header().Get("Last-Modified"))
+			s.Equal(target.statusCode, s.response.Code)
+			s.Equal(target.statusCode == http.StatusOK, nextCapture.Called)
+			s.NoError(errorCapture.CapturedError)
+		})
+	}
+}
+
+func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_IfUnmodifiedSince() {
+	lastModTime := time.Date(1991, 5, 23, 1, 2, 3, 4, time.UTC)
+
+	cases := map[string]struct {
+		opts       []turtleware.CacheOption
+		statusCode int
+	}{
+		"Disabled": {
+			statusCode: http.StatusOK,
+		},
+		"Enabled": {
+			opts:       []turtleware.CacheOption{turtleware.CacheIfUnmodifiedSince(true)},
+			statusCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			s.request.Header.Set("If-Unmodified-Since", lastModTime.Add(-time.Hour).Format(time.RFC1123))
+
+			lastModFetcher := func(
+				ctx context.Context,
+				entityUUID string,
+			) (time.Time, error) {
+				return lastModTime, nil
+			}
+
+			testChain := alice.New(
+				s.buildEntityUUIDChain,
+				turtleware.ResourceCacheMiddleware(lastModFetcher, turtleware.DefaultErrorHandler, target.opts...),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.statusCode, s.response.Code)
+			s.Equal(target.statusCode == http.StatusOK, nextCapture.Called)
+		})
+	}
+}
+
 func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_Error() {
 	// given
 	nextCapture := &MiddlewareCapture{}
@@ -390,6 +903,31 @@ func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_Error() {
 	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingMeta)
 }
 
+func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_ErrResourceGone() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	lastModFetcher := func(
+		ctx context.Context,
+		entityUUID string,
+	) (time.Time, error) {
+		return time.Time{}, turtleware.ErrResourceGone
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCacheMiddleware(lastModFetcher, turtleware.DefaultErrorHandler),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusGone, s.response.Code)
+	s.Empty(s.response.Header().Get("Last-Modified"))
+	s.False(nextCapture.Called)
+}
+
 func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_ErrContextMissingEntityUUID() {
 	// given
 	nextCapture := &MiddlewareCapture{}
@@ -437,3 +975,338 @@ func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware_Valid_Errors() {
 		})
 	}
 }
+
+func (s *MiddlewareCoreSuite) Test_RecoverErrorHandler_Panic() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	logBuffer := &bytes.Buffer{}
+
+	logger := zerolog.New(logBuffer)
+	s.request = s.request.WithContext(logger.WithContext(s.request.Context()))
+
+	countFetcher := func(
+		ctx context.Context,
+	) (uint, error) {
+		return 0, errors.New("some-error")
+	}
+
+	panickingErrorHandler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		panic("error handler exploded")
+	}
+
+	testChain := alice.New(
+		turtleware.CountHeaderMiddleware(countFetcher, panickingErrorHandler),
+	).Then(nextCapture)
+
+	// when
+	s.NotPanics(func() {
+		testChain.ServeHTTP(s.response, s.request)
+	})
+
+	// then
+	s.Equal(http.StatusInternalServerError, s.response.Code)
+	s.False(nextCapture.Called)
+	s.Contains(logBuffer.String(), "Recovered from panic in error handler")
+	s.Contains(logBuffer.String(), "error handler exploded")
+}
+
+func (s *MiddlewareCoreSuite) Test_RecoverErrorHandler_Panic_After_Write() {
+	// given
+	logBuffer := &bytes.Buffer{}
+
+	logger := zerolog.New(logBuffer)
+	s.request = s.request.WithContext(logger.WithContext(s.request.Context()))
+
+	panickingErrorHandler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		w.WriteHeader(http.StatusBadRequest)
+		panic("error handler exploded")
+	}
+
+	// when
+	s.NotPanics(func() {
+		turtleware.RecoverErrorHandler(panickingErrorHandler)(
+			s.request.Context(), s.response, s.request, errors.New("some-error"),
+		)
+	})
+
+	// then
+	s.Equal(http.StatusBadRequest, s.response.Code)
+	s.Empty(s.response.Header().Get("Cache-Control"))
+	s.Contains(logBuffer.String(), "Recovered from panic in error handler")
+}
+
+func (s *MiddlewareCoreSuite) Test_RecoverErrorHandler_Panic_Abort() {
+	// given
+	abortingErrorHandler := func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		panic(http.ErrAbortHandler)
+	}
+
+	// when
+	// then
+	s.PanicsWithValue(http.ErrAbortHandler, func() {
+		turtleware.RecoverErrorHandler(abortingErrorHandler)(
+			s.request.Context(), s.response, s.request, errors.New("some-error"),
+		)
+	})
+}
+
+func (s *MiddlewareCoreSuite) Test_ResourceVersionCacheMiddleware_Success_CacheMiss() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Header.Set("If-None-Match", `"v1"`)
+
+	versionFetcher := func(
+		ctx context.Context,
+		entityUUID string,
+	) (string, error) {
+		s.Equal(s.entityUUID, entityUUID)
+		return "v2", nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.ResourceVersionCacheMiddleware(versionFetcher, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(`"v2"`, s.response.Header().Get("Etag"))
+	s.Equal(http.StatusOK, s.response.Code)
+	s.True(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareCoreSuite) Test_ResourceVersionCacheMiddleware_Success_CacheHit() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Header.Set("If-None-Match", `"v1"`)
+
+	versionFetcher := func(
+		ctx context.Context,
+		entityUUID string,
+	) (string, error) {
+		return "v1", nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.ResourceVersionCacheMiddleware(versionFetcher, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(`"v1"`, s.response.Header().Get("Etag"))
+	s.Equal(http.StatusNotModified, s.response.Code)
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareCoreSuite) Test_ResourceVersionCacheMiddleware_Quoted_Version() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	versionFetcher := func(
+		ctx context.Context,
+		entityUUID string,
+	) (string, error) {
+		return `"v1"`, nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.ResourceVersionCacheMiddleware(versionFetcher, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(`"v1"`, s.response.Header().Get("Etag"))
+	s.True(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareCoreSuite) Test_ResourceVersionCacheMiddleware_Error() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	versionFetcher := func(
+		ctx context.Context,
+		entityUUID string,
+	) (string, error) {
+		return "", errors.New("some-error")
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.ResourceVersionCacheMiddleware(versionFetcher, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Header().Get("Etag"))
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingMeta)
+}
+
+func (s *MiddlewareCoreSuite) Test_ResourceVersionCacheMiddleware_ErrContextMissingEntityUUID() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	testChain := alice.New(
+		turtleware.ResourceVersionCacheMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Header().Get("Etag"))
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrContextMissingEntityUUID)
+}
+
+func (s *MiddlewareCoreSuite) Test_ResourceVersionCacheMiddleware_Valid_Errors() {
+	for testName, targetErr := range validErrors {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			versionFetcher := func(
+				ctx context.Context,
+				entityUUID string,
+			) (string, error) {
+				return "", targetErr
+			}
+
+			testChain := alice.New(
+				s.buildEntityUUIDChain,
+				turtleware.ResourceVersionCacheMiddleware(versionFetcher, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Empty(s.response.Header().Get("Etag"))
+			s.True(nextCapture.Called)
+			s.NoError(errorCapture.CapturedError)
+		})
+	}
+}
+
+func (s *MiddlewareCoreSuite) Test_CacheMiddlewares_Vary() {
+	hashFetcher := func(ctx context.Context, paging turtleware.Paging) (string, error) {
+		return "some-hash", nil
+	}
+	listLastModFetcher := func(ctx context.Context, paging turtleware.Paging) (time.Time, error) {
+		return time.Now(), nil
+	}
+	resourceLastModFetcher := func(ctx context.Context, entityUUID string) (time.Time, error) {
+		return time.Now(), nil
+	}
+	versionFetcher := func(ctx context.Context, entityUUID string) (string, error) {
+		return "some-version", nil
+	}
+
+	cases := map[string]func(errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.CacheOption) alice.Chain{
+		"ListCacheMiddleware": func(errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.CacheOption) alice.Chain {
+			return alice.New(turtleware.PagingMiddleware, turtleware.ListCacheMiddleware(hashFetcher, errorHandler, opts...))
+		},
+		"ListLastModCacheMiddleware": func(errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.CacheOption) alice.Chain {
+			return alice.New(turtleware.PagingMiddleware, turtleware.ListLastModCacheMiddleware(listLastModFetcher, errorHandler, opts...))
+		},
+		"ResourceCacheMiddleware": func(errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.CacheOption) alice.Chain {
+			return alice.New(s.buildEntityUUIDChain, turtleware.ResourceCacheMiddleware(resourceLastModFetcher, errorHandler, opts...))
+		},
+		"ResourceVersionCacheMiddleware": func(errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.CacheOption) alice.Chain {
+			return alice.New(s.buildEntityUUIDChain, turtleware.ResourceVersionCacheMiddleware(versionFetcher, errorHandler, opts...))
+		},
+	}
+
+	for testName, buildChain := range cases {
+		s.Run(testName+"_Default", func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			testChain := buildChain(errorCapture.Capture).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal([]string{"Authorization"}, s.response.Header().Values("Vary"))
+			s.NoError(errorCapture.CapturedError)
+		})
+
+		s.Run(testName+"_Extras", func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			testChain := buildChain(
+				errorCapture.Capture,
+				turtleware.CacheVary("Accept", "accept-encoding"),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal([]string{"Authorization", "Accept", "Accept-Encoding"}, s.response.Header().Values("Vary"))
+			s.NoError(errorCapture.CapturedError)
+		})
+
+		s.Run(testName+"_CacheControl", func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			testChain := buildChain(
+				errorCapture.Capture,
+				turtleware.CacheControl("max-age=300", "public"),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal([]string{"max-age=300", "public"}, s.response.Header().Values("Cache-Control"))
+			s.NoError(errorCapture.CapturedError)
+		})
+
+		s.Run(testName+"_Without_Authorization", func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			testChain := buildChain(
+				errorCapture.Capture,
+				turtleware.CacheVaryAuthorization(false),
+				turtleware.CacheVary("Accept"),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal([]string{"Accept"}, s.response.Header().Values("Vary"))
+			s.NoError(errorCapture.CapturedError)
+		})
+	}
+}
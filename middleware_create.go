@@ -4,13 +4,24 @@ import (
 	"github.com/rs/zerolog"
 
 	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
+	"net/url"
+	"path"
+	"strings"
 )
 
 // CreateFunc is a function called for delegating the handling of the creation of a new resource.
 type CreateFunc[T CreateDTO] func(ctx context.Context, entityUUID, userUUID string, create T) error
 
+// CreateReturningFunc is a function called for delegating the handling of the creation of a new resource,
+// which returns the created resource (e.g. including server generated fields).
+type CreateReturningFunc[T CreateDTO, R any] func(ctx context.Context, entityUUID, userUUID string, create T) (R, error)
+
+// CreatedLocationFunc is a function for building the URL of a newly created resource,
+// which is used as the Location header of a 201 Created response.
+type CreatedLocationFunc func(r *http.Request, entityUUID string) string
+
 // CreateDTO defines the contract for validating a DTO used for creating a new resource.
 type CreateDTO interface {
 	Validate() []error
@@ -24,13 +35,57 @@ func IsHandledByDefaultCreateErrorHandler(err error) bool {
 
 // DefaultCreateErrorHandler is a default error handler, which sensibly handles errors known by turtleware.
 func DefaultCreateErrorHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	err = withErrorCode(err)
+
 	DefaultErrorHandler(ctx, w, r, err)
 }
 
 // ResourceCreateMiddleware is a middleware for creating a new resource.
 // It parses a turtleware.CreateDTO from the request body, validates it, and then calls the provided CreateFunc.
 // Errors encountered during the process are passed to the provided turtleware.ErrorHandlerFunc.
-func ResourceCreateMiddleware[T CreateDTO](createFunc CreateFunc[T], errorHandler ErrorHandlerFunc) func(http.Handler) http.Handler {
+// The request body is decoded via DecodeBody, using the provided options.
+// If the CreateFunc returns an AcceptedError (see Accepted), a 202 Accepted is written
+// instead of calling the next handler.
+func ResourceCreateMiddleware[T CreateDTO](createFunc CreateFunc[T], errorHandler ErrorHandlerFunc, opts ...BodyOption) func(http.Handler) http.Handler {
+	return resourceCreateMiddleware(
+		func(ctx context.Context, entityUUID, userUUID string, create T) (struct{}, error) {
+			return struct{}{}, createFunc(ctx, entityUUID, userUUID, create)
+		},
+		nil,
+		errorHandler,
+		opts...,
+	)
+}
+
+// ResourceCreateReturningMiddleware is a middleware for creating a new resource, which answers with
+// the created resource. It works like ResourceCreateMiddleware, but the resource returned by the
+// provided CreateReturningFunc is written via the provided ResponseSerializer with a 201 Created,
+// which completes the response. That is, the next handler is never called.
+// If no ResponseSerializer is provided, the EmissioneWriter is used.
+func ResourceCreateReturningMiddleware[T CreateDTO, R any](
+	createFunc CreateReturningFunc[T, R],
+	serializer ResponseSerializer,
+	errorHandler ErrorHandlerFunc,
+	opts ...BodyOption,
+) func(http.Handler) http.Handler {
+	return resourceCreateMiddleware(
+		createFunc,
+		func(w http.ResponseWriter, r *http.Request, created R) {
+			orEmissioneWriter(serializer).Write(w, r, http.StatusCreated, created)
+		},
+		errorHandler,
+		opts...,
+	)
+}
+
+func resourceCreateMiddleware[T CreateDTO, R any](
+	createFunc CreateReturningFunc[T, R],
+	writeFunc func(w http.ResponseWriter, r *http.Request, created R),
+	errorHandler ErrorHandlerFunc,
+	opts ...BodyOption,
+) func(http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			createContext, cancel := context.WithCancel(r.Context())
@@ -54,9 +109,9 @@ func ResourceCreateMiddleware[T CreateDTO](createFunc CreateFunc[T], errorHandle
 
 			// ----------------
 
-			var create T
-			if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
-				errorHandler(createContext, w, r, ErrMarshalling)
+			create, err := DecodeBody[T](r, opts...)
+			if err != nil {
+				errorHandler(createContext, w, r, err)
 
 				return
 			}
@@ -67,16 +122,124 @@ func ResourceCreateMiddleware[T CreateDTO](createFunc CreateFunc[T], errorHandle
 				return
 			}
 
-			if err := createFunc(createContext, entityUUID, userUUID, create); err != nil {
+			created, err := createFunc(createContext, entityUUID, userUUID, create)
+			if err != nil {
+				var acceptedError *AcceptedError
+				if errors.As(err, &acceptedError) {
+					WriteAccepted(createContext, w, acceptedError.StatusLocation)
+
+					return
+				}
+
 				logger.Error().Err(err).Msg("Create failed")
 				errorHandler(createContext, w, r, err)
 
 				return
 			}
 
+			if writeFunc != nil {
+				writeFunc(w, r, created)
+
+				return
+			}
+
 			if next != nil {
 				next.ServeHTTP(w, r)
 			}
 		})
 	}
 }
+
+// ResourceCreatedMiddleware is an opt-in middleware for answering a successful creation with
+// a 201 Created, and a Location header pointing at the new resource. It is meant to be placed
+// after the ResourceCreateMiddleware, so it is only reached if the creation succeeded.
+// The Location is built by the provided CreatedLocationFunc from the entity UUID, as extracted
+// by the EntityUUIDMiddleware. If no CreatedLocationFunc is provided, the entity UUID is appended
+// to the request path (unless the request path already ends with it, e.g. for a PUT).
+// If the next handler writes a body without writing a status, or explicitly writes a 200, the
+// status is changed to 201. Any other status written by the next handler is kept, and the
+// Location header is only set for a 201.
+// Errors encountered during the process are passed to the provided ErrorHandlerFunc.
+func ResourceCreatedMiddleware(locationFunc CreatedLocationFunc, errorHandler ErrorHandlerFunc) func(http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	if locationFunc == nil {
+		locationFunc = defaultCreatedLocation
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entityUUID, err := EntityUUIDFromRequestContext(r.Context())
+			if err != nil {
+				errorHandler(r.Context(), w, r, err)
+
+				return
+			}
+
+			cw := &createdWriter{ResponseWriter: w, location: locationFunc(r, entityUUID)}
+			if next != nil {
+				next.ServeHTTP(cw, r)
+			}
+
+			if !cw.wroteHeader {
+				cw.WriteHeader(http.StatusCreated)
+			}
+		})
+	}
+}
+
+func defaultCreatedLocation(r *http.Request, entityUUID string) string {
+	requestPath := strings.TrimSuffix(r.URL.Path, "/")
+	if path.Base(requestPath) == entityUUID {
+		return requestPath
+	}
+
+	return requestPath + "/" + url.PathEscape(entityUUID)
+}
+
+// createdWriter is a wrapper for a http.ResponseWriter, which turns an
+// (implicit) 200 OK into a 201 Created, and sets the Location header for it.
+type createdWriter struct {
+	http.ResponseWriter
+
+	location    string
+	wroteHeader bool
+}
+
+func (w *createdWriter) WriteHeader(statusCode int) {
+	if !w.wroteHeader && statusCode >= http.StatusOK {
+		w.wroteHeader = true
+
+		if statusCode == http.StatusOK {
+			statusCode = http.StatusCreated
+		}
+
+		if statusCode == http.StatusCreated {
+			w.Header().Set("Location", w.location)
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *createdWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *createdWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *createdWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -9,9 +9,11 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -214,6 +216,77 @@ func (s *MiddlewareCreateSuite) Test_ResourceCreateMiddleware_Handle_Err() {
 	s.ErrorIs(errorCapture.CapturedError, targetError)
 }
 
+func (s *MiddlewareCreateSuite) Test_ResourceCreateMiddleware_Accepted() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+	model := TestCreateModel{
+		SomeString: "test",
+	}
+
+	s.request.Body = s.createModelBodyReader(model)
+
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) error {
+		return turtleware.Accepted("/jobs/" + entityUUID)
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateMiddleware(createHandlerFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusAccepted, s.response.Code)
+	s.Equal("/jobs/"+s.entityUUID, s.response.Header().Get("Location"))
+	s.Equal("/jobs/"+s.entityUUID, s.response.Header().Get("Content-Location"))
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreateMiddleware_Accepted_Wrapped() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+	model := TestCreateModel{
+		SomeString: "test",
+	}
+
+	s.request.Body = s.createModelBodyReader(model)
+
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) error {
+		return fmt.Errorf("queued: %w", turtleware.Accepted("/jobs/"+entityUUID))
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateMiddleware(createHandlerFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusAccepted, s.response.Code)
+	s.Equal("/jobs/"+s.entityUUID, s.response.Header().Get("Location"))
+}
+
 func (s *MiddlewareCreateSuite) Test_ResourceCreateMiddleware_Success() {
 	// given
 	nextCapture := &MiddlewareCapture{}
@@ -250,6 +323,263 @@ func (s *MiddlewareCreateSuite) Test_ResourceCreateMiddleware_Success() {
 	s.NoError(errorCapture.CapturedError)
 }
 
+func (s *MiddlewareCreateSuite) Test_ResourceCreateReturningMiddleware_Success() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+	model := TestCreateModel{
+		SomeString: "test",
+	}
+
+	s.request.Body = s.createModelBodyReader(model)
+
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) (TestDataModel, error) {
+		s.Equal(s.entityUUID, entityUUID)
+		s.Equal(s.userUUID, userUUID)
+		s.Equal(model, create)
+		return TestDataModel{SomeString: create.SomeString, SomeInt: 42}, nil
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateReturningMiddleware(createHandlerFunc, nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusCreated, s.response.Code)
+	s.Equal("application/json;charset=utf-8", s.response.Header().Get("Content-Type"))
+	s.JSONEq(`{"SomeString": "test", "SomeInt": 42}`, s.response.Body.String())
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreateReturningMiddleware_Serializer() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = s.createModelBodyReader(TestCreateModel{SomeString: "test"})
+
+	created := TestDataModel{SomeString: "test", SomeInt: 42}
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) (TestDataModel, error) {
+		return created, nil
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateReturningMiddleware(createHandlerFunc, TestSerializer{}, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusCreated, s.response.Code)
+	s.Equal("text/plain", s.response.Header().Get("Content-Type"))
+	s.Equal(fmt.Sprintf("%v", created), s.response.Body.String())
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreateReturningMiddleware_Handle_Err() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = s.createModelBodyReader(TestCreateModel{SomeString: "test"})
+
+	targetError := errors.New("some-error")
+
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) (TestDataModel, error) {
+		return TestDataModel{}, targetError
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateReturningMiddleware(createHandlerFunc, nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, targetError)
+	s.Empty(s.response.Body.String())
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreateReturningMiddleware_Accepted() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = s.createModelBodyReader(TestCreateModel{SomeString: "test"})
+
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) (TestDataModel, error) {
+		return TestDataModel{}, turtleware.Accepted("/jobs/" + entityUUID)
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateReturningMiddleware(createHandlerFunc, nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusAccepted, s.response.Code)
+	s.Equal("/jobs/"+s.entityUUID, s.response.Header().Get("Location"))
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreateMiddleware_Sanitize() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = io.NopCloser(bytes.NewBufferString(`{"SomeString": "  te\u0007st\n  "}`))
+
+	var capturedCreate TestCreateModel
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) error {
+		capturedCreate = create
+		return nil
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateMiddleware(
+			createHandlerFunc,
+			errorCapture.Capture,
+			turtleware.BodyTrimStrings(true),
+			turtleware.BodyStripControlChars(true),
+		),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal("test", capturedCreate.SomeString)
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreateMiddleware_NoSanitize() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = io.NopCloser(bytes.NewBufferString(`{"SomeString": "  test  "}`))
+
+	var capturedCreate TestCreateModel
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) error {
+		capturedCreate = create
+		return nil
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateMiddleware(createHandlerFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal("  test  ", capturedCreate.SomeString)
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreateMiddleware_Strict() {
+	cases := map[string]struct {
+		body string
+		opt  turtleware.BodyOption
+	}{
+		"Unknown_Fields": {
+			body: `{"SomeString": "test", "Unknown": true}`,
+			opt:  turtleware.BodyDisallowUnknownFields(true),
+		},
+		"Max_Bytes": {
+			body: `{"SomeString": "test"}`,
+			opt:  turtleware.BodyMaxBytes(8),
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			s.request.Body = io.NopCloser(bytes.NewBufferString(target.body))
+
+			createHandlerFunc := func(
+				ctx context.Context,
+				entityUUID,
+				userUUID string,
+				create TestCreateModel,
+			) error {
+				s.Fail("create func must not be called")
+				return nil
+			}
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.ResourceCreateMiddleware(createHandlerFunc, turtleware.DefaultCreateErrorHandler, target.opt),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.Equal(http.StatusBadRequest, s.response.Code)
+		})
+	}
+}
+
 func (s *MiddlewareCreateSuite) createModelBodyReader(model turtleware.CreateDTO) io.ReadCloser {
 	pr, pw := io.Pipe()
 	encoder := json.NewEncoder(pw)
@@ -258,3 +588,131 @@ func (s *MiddlewareCreateSuite) createModelBodyReader(model turtleware.CreateDTO
 	}(encoder)
 	return pr
 }
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreatedMiddleware() {
+	cases := map[string]struct {
+		requestPath      string
+		locationFunc     turtleware.CreatedLocationFunc
+		next             http.Handler
+		expectedLocation func(entityUUID string) string
+		expectedStatus   int
+	}{
+		"Default_Location": {
+			requestPath:      "/foo",
+			expectedLocation: func(entityUUID string) string { return "/foo/" + entityUUID },
+			expectedStatus:   http.StatusCreated,
+		},
+		"Default_Location_Entity_Path": {
+			requestPath:      "/foo/%s",
+			expectedLocation: func(entityUUID string) string { return "/foo/" + entityUUID },
+			expectedStatus:   http.StatusCreated,
+		},
+		"Custom_Location": {
+			requestPath: "/foo",
+			locationFunc: func(r *http.Request, entityUUID string) string {
+				return "https://example.com/bar/" + entityUUID
+			},
+			expectedLocation: func(entityUUID string) string { return "https://example.com/bar/" + entityUUID },
+			expectedStatus:   http.StatusCreated,
+		},
+		"Next_Writes_Body": {
+			requestPath: "/foo",
+			next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte("{}"))
+			}),
+			expectedLocation: func(entityUUID string) string { return "/foo/" + entityUUID },
+			expectedStatus:   http.StatusCreated,
+		},
+		"Next_Writes_OK": {
+			requestPath: "/foo",
+			next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}),
+			expectedLocation: func(entityUUID string) string { return "/foo/" + entityUUID },
+			expectedStatus:   http.StatusCreated,
+		},
+		"Next_Writes_Own_Status": {
+			requestPath: "/foo",
+			next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}),
+			expectedLocation: func(entityUUID string) string { return "" },
+			expectedStatus:   http.StatusNoContent,
+		},
+		"Next_Writes_Error": {
+			requestPath: "/foo",
+			next: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				turtleware.WriteError(r.Context(), w, r, http.StatusConflict, errors.New("some-error"))
+			}),
+			expectedLocation: func(entityUUID string) string { return "" },
+			expectedStatus:   http.StatusConflict,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			next := target.next
+			if next == nil {
+				next = &MiddlewareCapture{}
+			}
+
+			s.request = httptest.NewRequest(http.MethodPost, "https://example.com"+strings.ReplaceAll(target.requestPath, "%s", s.entityUUID), http.NoBody)
+
+			testChain := alice.New(
+				s.buildEntityUUIDChain,
+				turtleware.ResourceCreatedMiddleware(target.locationFunc, turtleware.DefaultCreateErrorHandler),
+			).Then(next)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedStatus, s.response.Code)
+			s.Equal(target.expectedLocation(s.entityUUID), s.response.Header().Get("Location"))
+		})
+	}
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreatedMiddleware_ErrContextMissingEntityUUID() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	middleware := turtleware.ResourceCreatedMiddleware(nil, errorCapture.Capture)
+
+	// when
+	middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrContextMissingEntityUUID)
+	s.Empty(s.response.Header().Get("Location"))
+}
+
+func (s *MiddlewareCreateSuite) Test_ResourceCreatedMiddleware_CreateFailed() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = s.createModelBodyReader(TestCreateModel{SomeString: "test"})
+
+	createHandlerFunc := func(context.Context, string, string, TestCreateModel) error {
+		return errors.New("some-error")
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCreateMiddleware(createHandlerFunc, errorCapture.Capture),
+		turtleware.ResourceCreatedMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Error(errorCapture.CapturedError)
+	s.Empty(s.response.Header().Get("Location"))
+}
@@ -3,6 +3,7 @@ package turtleware
 import (
 	"github.com/jmoiron/sqlx"
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
 
 	"context"
 	"database/sql"
@@ -11,6 +12,9 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"reflect"
+	"strconv"
+	"time"
 )
 
 // ListStaticDataFunc is a function for retrieving a slice of data, scoped to the provided paging.
@@ -33,9 +37,19 @@ type SQLxResourceFunc[T any] func(ctx context.Context, r *sqlx.Rows) (T, error)
 
 // StaticListDataHandler is a handler for serving a list of resources from a static list.
 // Data is retrieved from the given ListStaticDataFunc, and then serialized to the http.ResponseWriter.
+// Only GET and HEAD requests are served, any other method is answered with a 405 (see RestrictMethods).
+// HEAD requests are answered without a body, and without retrieving any data. Headers set by
+// preceding middlewares (e.g. the CountHeaderMiddleware and the ListCacheMiddleware) are still
+// sent, so clients can probe the list cheaply.
 // Errors encountered during the process are passed to the provided ErrorHandlerFunc.
-func StaticListDataHandler[T any](dataFetcher ListStaticDataFunc[T], errorHandler ErrorHandlerFunc) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+// The behavior of the handler can be adjusted via the provided options, e.g. to serialize the
+// list differently (see ListDataSerializer).
+func StaticListDataHandler[T any](dataFetcher ListStaticDataFunc[T], errorHandler ErrorHandlerFunc, opts ...ListDataOption) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	config := newListDataOptions(opts...)
+
+	return RestrictMethods(http.MethodGet, http.MethodHead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := zerolog.Ctx(r.Context())
 
 		// Only proceed if we are working with an actual request
@@ -45,7 +59,7 @@ func StaticListDataHandler[T any](dataFetcher ListStaticDataFunc[T], errorHandle
 			return
 		}
 
-		dataContext, cancel := context.WithCancel(r.Context())
+		dataContext, cancel := newDataContext(r.Context(), config.timeout)
 		defer cancel()
 
 		paging, err := PagingFromRequestContext(dataContext)
@@ -56,30 +70,51 @@ func StaticListDataHandler[T any](dataFetcher ListStaticDataFunc[T], errorHandle
 		}
 
 		logger.Trace().Msgf("Handling request for resource list request")
-		rows, err := dataFetcher(dataContext, paging)
+		fetchContext, fetchSpan := startContextSpan(dataContext, "turtleware.FetchEntities")
+		rows, err := dataFetcher(fetchContext, paging)
 		if err != nil {
 			logger.Error().Err(err).Msg("Error while receiving rows")
-			errorHandler(dataContext, w, r, ErrReceivingResults)
+
+			// nolint errcheck: Returned error is not checked, as its just err as passed in
+			_ = TagContextSpanWithError(fetchContext, err)
+			fetchSpan.End()
+
+			errorHandler(dataContext, w, r, receivingError(dataContext, err))
 
 			return
 		}
 
+		fetchSpan.SetAttributes(attribute.Int("turtleware.row_count", len(rows)))
+		fetchSpan.End()
+
 		if rows == nil {
 			rows = make([]T, 0)
 		}
 
+		config.setPagingLinks(w.Header(), r, paging)
+
 		logger.Trace().Msg("Assembling response for resource list request")
-		EmissioneWriter.Write(w, r, http.StatusOK, rows)
-	})
+		serializeTraced(dataContext, config.responseSerializer(), w, r, FilterFields(dataContext, rows))
+	}))
 }
 
 // SQLListDataHandler is a handler for serving a list of resources from a SQL source.
 // Data is retrieved via a sql.Rows iterator retrieved from the given ListSQLDataFunc,
 // scanned into a struct via the SQLResourceFunc, and then serialized to the http.ResponseWriter.
 // Serialization is buffered, so the entire result set is read before writing the response.
+// The behavior of the handler can be adjusted via the provided options, e.g. to derive the
+// total count from the result rows (see ListDataTotalCountColumn).
+// Only GET and HEAD requests are served, any other method is answered with a 405 (see RestrictMethods).
+// HEAD requests are answered without a body, and without retrieving any data. Headers set by
+// preceding middlewares (e.g. the CountHeaderMiddleware and the ListCacheMiddleware) are still
+// sent, so clients can probe the list cheaply.
 // Errors encountered during the process are passed to the provided ErrorHandlerFunc.
-func SQLListDataHandler[T any](dataFetcher ListSQLDataFunc, dataTransformer SQLResourceFunc[T], errorHandler ErrorHandlerFunc) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+func SQLListDataHandler[T any](dataFetcher ListSQLDataFunc, dataTransformer SQLResourceFunc[T], errorHandler ErrorHandlerFunc, opts ...ListDataOption) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	config := newListDataOptions(opts...)
+
+	return RestrictMethods(http.MethodGet, http.MethodHead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := zerolog.Ctx(r.Context())
 
 		// Only proceed if we are working with an actual request
@@ -89,7 +124,7 @@ func SQLListDataHandler[T any](dataFetcher ListSQLDataFunc, dataTransformer SQLR
 			return
 		}
 
-		dataContext, cancel := context.WithCancel(r.Context())
+		dataContext, cancel := newDataContext(r.Context(), config.timeout)
 		defer cancel()
 
 		paging, err := PagingFromRequestContext(dataContext)
@@ -99,66 +134,115 @@ func SQLListDataHandler[T any](dataFetcher ListSQLDataFunc, dataTransformer SQLR
 			return
 		}
 
-		rows, err := dataFetcher(dataContext, paging)
+		results, totalCount, err := fetchSQLResults(dataContext, paging, dataFetcher, dataTransformer, config.totalCountColumn)
 		if err != nil {
-			logger.Error().Err(err).Msg("Error while receiving rows")
-			errorHandler(dataContext, w, r, ErrReceivingResults)
+			errorHandler(dataContext, w, r, err)
 
 			return
 		}
 
-		// Ensure row close, even on error
-		defer func() {
-			if err := rows.Close(); err != nil {
-				logger.Warn().Err(err).Msg("Failed to close row scanner")
-			}
-		}()
+		config.setTotalCount(dataContext, w.Header(), paging, totalCount)
+		config.setPagingLinks(w.Header(), r, paging)
 
-		results, err := bufferSQLResults(dataContext, rows, dataTransformer)
-		if err != nil {
-			errorHandler(dataContext, w, r, ErrReceivingResults)
+		serializeTraced(dataContext, config.responseSerializer(), w, r, FilterFields(dataContext, results))
+	}))
+}
 
-			return
+// fetchSQLResults retrieves the rows via the given ListSQLDataFunc, and buffers them via
+// bufferSQLResults. Both are traced via a turtleware.FetchRows span.
+func fetchSQLResults[T any](ctx context.Context, paging Paging, dataFetcher ListSQLDataFunc, dataTransformer SQLResourceFunc[T], totalCountColumn string) ([]T, int64, error) {
+	fetchContext, span := startContextSpan(ctx, "turtleware.FetchRows")
+	defer span.End()
+
+	logger := zerolog.Ctx(fetchContext)
+
+	rows, err := dataFetcher(fetchContext, paging)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error while receiving rows")
+
+		// nolint errcheck: Returned error is not checked, as its just err as passed in
+		_ = TagContextSpanWithError(fetchContext, err)
+
+		return nil, 0, receivingError(fetchContext, err)
+	}
+
+	// Ensure row close, even on error
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn().Err(err).Msg("Failed to close row scanner")
 		}
+	}()
+
+	results, totalCount, err := bufferSQLResults(fetchContext, rows, dataTransformer, totalCountColumn)
+	if err != nil {
+		return nil, 0, TagContextSpanWithError(fetchContext, err)
+	}
+
+	span.SetAttributes(attribute.Int("turtleware.row_count", len(results)))
 
-		EmissioneWriter.Write(w, r, http.StatusOK, results)
-	})
+	return results, totalCount, nil
 }
 
-func bufferSQLResults[T any](ctx context.Context, rows *sql.Rows, dataTransformer SQLResourceFunc[T]) ([]T, error) {
+func bufferSQLResults[T any](ctx context.Context, rows *sql.Rows, dataTransformer SQLResourceFunc[T], totalCountColumn string) ([]T, int64, error) {
 	dataContext, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	logger := zerolog.Ctx(dataContext)
 
 	results := make([]T, 0)
+	totalCount := int64(-1)
 
 	for rows.Next() {
+		if totalCountColumn != "" && totalCount < 0 {
+			count, err := scanTotalCount(rows, totalCountColumn)
+			if err != nil {
+				logger.Error().Err(err).Msg("Error while receiving total count")
+
+				return nil, 0, receivingError(dataContext, err)
+			}
+
+			totalCount = count
+		}
+
 		tempEntity, err := dataTransformer(dataContext, rows)
 		if err != nil {
 			logger.Error().Err(err).Msg("Error while receiving results")
 
-			return nil, ErrReceivingResults
+			return nil, 0, receivingError(dataContext, err)
 		}
 
 		results = append(results, tempEntity)
 	}
 
-	// Log, but don't act on the error
+	// Log, but don't act on the error - unless the results were cut off by a timeout
 	if err := rows.Err(); err != nil {
 		logger.Error().Err(err).Msg("Error while receiving results")
+
+		if isTimeout(dataContext, err) {
+			return nil, 0, causedError{sentinel: ErrRequestTimeout, cause: err}
+		}
 	}
 
-	return results, nil
+	return results, totalCount, nil
 }
 
 // SQLxListDataHandler is a handler for serving a list of resources from a SQL source via sqlx.
 // Data is retrieved via a sqlx.Rows iterator retrieved from the given ListSQLxDataFunc,
 // scanned into a struct via the SQLxResourceFunc, and then serialized to the http.ResponseWriter.
 // Serialization is buffered, so the entire result set is read before writing the response.
+// The behavior of the handler can be adjusted via the provided options, e.g. to derive the
+// total count from the result rows (see ListDataTotalCountColumn).
+// Only GET and HEAD requests are served, any other method is answered with a 405 (see RestrictMethods).
+// HEAD requests are answered without a body, and without retrieving any data. Headers set by
+// preceding middlewares (e.g. the CountHeaderMiddleware and the ListCacheMiddleware) are still
+// sent, so clients can probe the list cheaply.
 // Errors encountered during the process are passed to the provided ErrorHandlerFunc.
-func SQLxListDataHandler[T any](dataFetcher ListSQLxDataFunc, dataTransformer SQLxResourceFunc[T], errorHandler ErrorHandlerFunc) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+func SQLxListDataHandler[T any](dataFetcher ListSQLxDataFunc, dataTransformer SQLxResourceFunc[T], errorHandler ErrorHandlerFunc, opts ...ListDataOption) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	config := newListDataOptions(opts...)
+
+	return RestrictMethods(http.MethodGet, http.MethodHead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := zerolog.Ctx(r.Context())
 
 		// Only proceed if we are working with an actual request
@@ -168,7 +252,7 @@ func SQLxListDataHandler[T any](dataFetcher ListSQLxDataFunc, dataTransformer SQ
 			return
 		}
 
-		dataContext, cancel := context.WithCancel(r.Context())
+		dataContext, cancel := newDataContext(r.Context(), config.timeout)
 		defer cancel()
 
 		paging, err := PagingFromRequestContext(dataContext)
@@ -178,66 +262,275 @@ func SQLxListDataHandler[T any](dataFetcher ListSQLxDataFunc, dataTransformer SQ
 			return
 		}
 
-		rows, err := dataFetcher(dataContext, paging)
+		results, totalCount, err := fetchSQLxResults(dataContext, paging, dataFetcher, dataTransformer, config.totalCountColumn)
 		if err != nil {
-			logger.Error().Err(err).Msg("Error while receiving rows")
-			errorHandler(dataContext, w, r, ErrReceivingResults)
+			errorHandler(dataContext, w, r, err)
 
 			return
 		}
 
-		// Ensure row close, even on error
-		defer func() {
-			if err := rows.Close(); err != nil {
-				logger.Warn().Err(err).Msg("Failed to close row scanner")
-			}
-		}()
+		config.setTotalCount(dataContext, w.Header(), paging, totalCount)
+		config.setPagingLinks(w.Header(), r, paging)
 
-		results, err := bufferSQLxResults(dataContext, rows, dataTransformer)
-		if err != nil {
-			errorHandler(dataContext, w, r, ErrReceivingResults)
+		serializeTraced(dataContext, config.responseSerializer(), w, r, FilterFields(dataContext, results))
+	}))
+}
 
-			return
+// fetchSQLxResults retrieves the rows via the given ListSQLxDataFunc, and buffers them via
+// bufferSQLxResults. Both are traced via a turtleware.FetchRows span.
+func fetchSQLxResults[T any](ctx context.Context, paging Paging, dataFetcher ListSQLxDataFunc, dataTransformer SQLxResourceFunc[T], totalCountColumn string) ([]T, int64, error) {
+	fetchContext, span := startContextSpan(ctx, "turtleware.FetchRows")
+	defer span.End()
+
+	logger := zerolog.Ctx(fetchContext)
+
+	rows, err := dataFetcher(fetchContext, paging)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error while receiving rows")
+
+		// nolint errcheck: Returned error is not checked, as its just err as passed in
+		_ = TagContextSpanWithError(fetchContext, err)
+
+		return nil, 0, receivingError(fetchContext, err)
+	}
+
+	// Ensure row close, even on error
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Warn().Err(err).Msg("Failed to close row scanner")
 		}
+	}()
+
+	results, totalCount, err := bufferSQLxResults(fetchContext, rows, dataTransformer, totalCountColumn)
+	if err != nil {
+		return nil, 0, TagContextSpanWithError(fetchContext, err)
+	}
 
-		EmissioneWriter.Write(w, r, http.StatusOK, results)
-	})
+	span.SetAttributes(attribute.Int("turtleware.row_count", len(results)))
+
+	return results, totalCount, nil
 }
 
-func bufferSQLxResults[T any](ctx context.Context, rows *sqlx.Rows, dataTransformer SQLxResourceFunc[T]) ([]T, error) {
+func bufferSQLxResults[T any](ctx context.Context, rows *sqlx.Rows, dataTransformer SQLxResourceFunc[T], totalCountColumn string) ([]T, int64, error) {
 	dataContext, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	logger := zerolog.Ctx(dataContext)
 
 	results := make([]T, 0)
+	totalCount := int64(-1)
 
 	for rows.Next() {
+		if totalCountColumn != "" && totalCount < 0 {
+			count, err := scanTotalCount(rows.Rows, totalCountColumn)
+			if err != nil {
+				logger.Error().Err(err).Msg("Error while receiving total count")
+
+				return nil, 0, receivingError(dataContext, err)
+			}
+
+			totalCount = count
+		}
+
 		tempEntity, err := dataTransformer(dataContext, rows)
 		if err != nil {
 			logger.Error().Err(err).Msg("Error while receiving results")
 
-			return nil, ErrReceivingResults
+			return nil, 0, receivingError(dataContext, err)
 		}
 
 		results = append(results, tempEntity)
 	}
 
-	// Log, but don't act on the error
+	// Log, but don't act on the error - unless the results were cut off by a timeout
 	if err := rows.Err(); err != nil {
 		logger.Error().Err(err).Msg("Error while receiving results")
+
+		if isTimeout(dataContext, err) {
+			return nil, 0, causedError{sentinel: ErrRequestTimeout, cause: err}
+		}
 	}
 
-	return results, nil
+	return results, totalCount, nil
+}
+
+// newDataContext derives the context for retrieving data, which is cancelled after
+// the given timeout. A timeout of 0 means no timeout.
+func newDataContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(ctx, timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// isTimeout reports whether the given error was caused by exceeding the deadline of the
+// given context. Not all drivers wrap context.DeadlineExceeded, so the context is checked, too.
+func isTimeout(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// receivingError maps an error encountered while receiving results to ErrRequestTimeout,
+// if it was caused by a timeout, and to ErrReceivingResults otherwise. The original error
+// is kept as cause, so it can still be classified (e.g. by a RetryableFunc).
+func receivingError(ctx context.Context, err error) error {
+	if isTimeout(ctx, err) {
+		return causedError{sentinel: ErrRequestTimeout, cause: err}
+	}
+
+	return causedError{sentinel: ErrReceivingResults, cause: err}
+}
+
+// causedError is an error mapped to a sentinel error, which keeps the original error as
+// cause for errors.Is and errors.As - without exposing it via its message, as the message
+// is written to the client by the error handlers.
+type causedError struct {
+	sentinel error
+	cause    error
+}
+
+func (err causedError) Error() string {
+	return err.sentinel.Error()
+}
+
+func (err causedError) Unwrap() []error {
+	return []error{err.sentinel, err.cause}
+}
+
+func newListDataOptions(opts ...ListDataOption) *listDataOptions {
+	// default
+	config := &listDataOptions{
+		totalCountColumn: "",
+		countHeaderNames: nil,
+		serializer:       nil,
+		timeout:          0,
+		contentLength:    false,
+		jsonAPI:          false,
+		pagingLinks:      false,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return config
+}
+
+// resolveCountHeaderNames returns the given names of the count headers, or the names passed
+// down by the CountHeaderMiddleware if none are given, or X-Total-Count otherwise.
+func resolveCountHeaderNames(ctx context.Context, countHeaderNames []string) []string {
+	if countHeaderNames != nil {
+		return countHeaderNames
+	}
+
+	if contextNames, ok := ctx.Value(ctxCountHeaderNames).([]string); ok {
+		return contextNames
+	}
+
+	return []string{"X-Total-Count"}
+}
+
+// setTotalCount sets the count headers from a total count derived via ListDataTotalCountColumn.
+// A negative total count signals an empty page, for which the total count is only known
+// for the first page.
+func (c *listDataOptions) setTotalCount(ctx context.Context, header http.Header, paging Paging, totalCount int64) {
+	if c.totalCountColumn == "" {
+		return
+	}
+
+	if totalCount < 0 {
+		if paging.Offset > 0 || paging.Cursor != "" {
+			return
+		}
+
+		totalCount = 0
+	}
+
+	setCountHeaders(header, resolveCountHeaderNames(ctx, c.countHeaderNames), strconv.FormatInt(totalCount, 10))
+}
+
+// setPagingLinks adds the Link headers for navigating the list (see PagingLinks), if enabled
+// via ListDataPagingLinks. The total count is read from the count header, if it is exact.
+func (c *listDataOptions) setPagingLinks(header http.Header, r *http.Request, paging Paging) {
+	if !c.pagingLinks {
+		return
+	}
+
+	totalCount := int64(-1)
+	if countHeaderNames := resolveCountHeaderNames(r.Context(), c.countHeaderNames); len(countHeaderNames) > 0 {
+		if parsedCount, err := strconv.ParseInt(header.Get(countHeaderNames[0]), 10, 64); err == nil {
+			totalCount = parsedCount
+		}
+	}
+
+	for _, link := range PagingLinks(r.URL, paging, totalCount) {
+		header.Add("Link", link)
+	}
+}
+
+// scanTotalCount scans the given column of the current row as total count. As scanning
+// does not advance the rows iterator, the row can still be scanned afterward.
+func scanTotalCount(rows *sql.Rows, totalCountColumn string) (int64, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+
+	var totalCount int64
+
+	found := false
+	destinations := make([]interface{}, len(columns))
+	for i, column := range columns {
+		if column == totalCountColumn {
+			destinations[i] = &totalCount
+			found = true
+		} else {
+			destinations[i] = new(interface{})
+		}
+	}
+
+	if !found {
+		return 0, fmt.Errorf("total count column %q missing from result rows", totalCountColumn)
+	}
+
+	if err := rows.Scan(destinations...); err != nil {
+		return 0, err
+	}
+
+	return totalCount, nil
 }
 
 // ResourceDataHandler is a handler for serving a single resource. Data is retrieved from the
 // given ResourceDataFunc, and then serialized to the http.ResponseWriter.
 // If the response is an io.Reader, the response is streamed to the client via StreamResponse.
+// To skip content type detection for streamed responses, return a TypedReader.
 // Otherwise, the entire result set is read before writing the response.
+// The behavior of the handler can be adjusted via the provided options.
+// Only GET and HEAD requests are served, any other method is answered with a 405 (see RestrictMethods).
+// HEAD requests are answered without a body, and without retrieving any data. Headers set by
+// preceding middlewares (e.g. the Last-Modified header of the ResourceCacheMiddleware) are still sent.
+// If the ResourceDataFunc returns sql.ErrNoRows or os.ErrNotExist, ErrResourceNotFound is passed on
+// instead. If it returns ErrResourceGone (e.g. for soft-deleted resources), it is passed on as-is.
 // Errors encountered during the process are passed to the provided ErrorHandlerFunc.
-func ResourceDataHandler[T any](dataFetcher ResourceDataFunc[T], errorHandler ErrorHandlerFunc) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+func ResourceDataHandler[T any](dataFetcher ResourceDataFunc[T], errorHandler ErrorHandlerFunc, opts ...ResourceDataOption) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	// default
+	config := &resourceDataOptions{
+		emptyObjectOnNil: false,
+		serializer:       nil,
+		timeout:          0,
+		contentLength:    false,
+		jsonAPI:          false,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return RestrictMethods(http.MethodGet, http.MethodHead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := zerolog.Ctx(r.Context())
 
 		// Only proceed if we are working with an actual request
@@ -247,7 +540,7 @@ func ResourceDataHandler[T any](dataFetcher ResourceDataFunc[T], errorHandler Er
 			return
 		}
 
-		dataContext, cancel := context.WithCancel(r.Context())
+		dataContext, cancel := newDataContext(r.Context(), config.timeout)
 		defer cancel()
 
 		entityUUID, err := EntityUUIDFromRequestContext(dataContext)
@@ -257,33 +550,107 @@ func ResourceDataHandler[T any](dataFetcher ResourceDataFunc[T], errorHandler Er
 			return
 		}
 
-		tempEntity, err := dataFetcher(dataContext, entityUUID)
+		fetchContext, fetchSpan := startContextSpan(dataContext, "turtleware.FetchEntity")
+		tempEntity, err := dataFetcher(fetchContext, entityUUID)
 		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
+			fetchSpan.End()
 			errorHandler(dataContext, w, r, ErrResourceNotFound)
 
 			return
 		}
 
+		if errors.Is(err, ErrResourceGone) {
+			fetchSpan.End()
+			errorHandler(dataContext, w, r, err)
+
+			return
+		}
+
 		if err != nil {
 			logger.Error().Err(err).Msg("Error while receiving results")
-			errorHandler(dataContext, w, r, ErrReceivingResults)
+
+			// nolint errcheck: Returned error is not checked, as its just err as passed in
+			_ = TagContextSpanWithError(fetchContext, err)
+			fetchSpan.End()
+
+			errorHandler(dataContext, w, r, receivingError(dataContext, err))
 
 			return
 		}
 
+		fetchSpan.End()
+
 		if reader, ok := any(tempEntity).(io.Reader); ok {
 			logger.Trace().Msg("Streaming response for resource request")
 			StreamResponse(reader, w, r, errorHandler)
 		} else {
 			logger.Trace().Msg("Assembling response for resource request")
-			EmissioneWriter.Write(w, r, http.StatusOK, tempEntity)
+
+			if config.emptyObjectOnNil && isNilEntity(tempEntity) {
+				serializeTraced(dataContext, config.responseSerializer(), w, r, struct{}{})
+
+				return
+			}
+
+			serializeTraced(dataContext, config.responseSerializer(), w, r, FilterFields(dataContext, tempEntity))
 		}
-	})
+	}))
+}
+
+func isNilEntity(entity any) bool {
+	if entity == nil {
+		return true
+	}
+
+	value := reflect.ValueOf(entity)
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+// TypedReader is an io.Reader, which carries the content type of its content. If a
+// TypedReader is passed to StreamResponse (e.g. via a ResourceDataFunc), the content
+// type is used as-is, instead of sniffing it from the content.
+type TypedReader interface {
+	io.Reader
+	ContentType() string
+}
+
+type typedReader struct {
+	io.Reader
+	contentType string
+}
+
+// NewTypedReader wraps the given io.Reader into a TypedReader with the given content type.
+// If the io.Reader is an io.ReadCloser, it is closed by StreamResponse as usual.
+func NewTypedReader(reader io.Reader, contentType string) TypedReader {
+	return &typedReader{
+		Reader:      reader,
+		contentType: contentType,
+	}
+}
+
+func (typedReader *typedReader) ContentType() string {
+	return typedReader.contentType
+}
+
+func (typedReader *typedReader) Close() error {
+	if closer, ok := typedReader.Reader.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
 }
 
 // StreamResponse streams the provided io.Reader to the http.ResponseWriter. The function
 // tries to determine the content type of the stream by reading the first 512 bytes, and sets
-// the content-type HTTP header accordingly.
+// the content-type HTTP header accordingly. If the io.Reader is a TypedReader with a non-empty
+// content type, that content type is used instead.
+// An empty reader results in an empty body, with the content type application/octet-stream
+// (or the content type of the TypedReader).
 // Errors encountered during the process are passed to the provided ErrorHandlerFunc.
 func StreamResponse(reader io.Reader, w http.ResponseWriter, r *http.Request, errorHandler ErrorHandlerFunc) {
 	logger := zerolog.Ctx(r.Context())
@@ -296,11 +663,32 @@ func StreamResponse(reader io.Reader, w http.ResponseWriter, r *http.Request, er
 		}()
 	}
 
+	contentType := ""
+	if typedReader, ok := reader.(TypedReader); ok {
+		contentType = typedReader.ContentType()
+	}
+
+	buffer := streamBufferPool.Get().(*[]byte)
+	defer streamBufferPool.Put(buffer)
+
 	// Only the first 512 bytes are used to sniff the content type.
-	buffer := make([]byte, 512)
-	headerRead, err := reader.Read(buffer)
+	sniffBuffer := (*buffer)[:512]
+	headerRead, err := reader.Read(sniffBuffer)
 
-	if err != nil {
+	if errors.Is(err, io.EOF) && headerRead == 0 {
+		logger.Trace().Msg("Streaming empty response")
+
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+
+		return
+	}
+
+	if err != nil && !errors.Is(err, io.EOF) {
 		errorHandler(
 			r.Context(),
 			w, r,
@@ -310,10 +698,17 @@ func StreamResponse(reader io.Reader, w http.ResponseWriter, r *http.Request, er
 		return
 	}
 
-	w.Header().Set("Content-Type", http.DetectContentType(buffer))
+	if contentType == "" {
+		// Clear leftovers of previous usages of the pooled buffer
+		clear(sniffBuffer[headerRead:])
+
+		contentType = http.DetectContentType(sniffBuffer)
+	}
+
+	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(http.StatusOK)
 
-	if _, err := w.Write(buffer[:headerRead]); err != nil {
+	if _, err := w.Write(sniffBuffer[:headerRead]); err != nil {
 		// Worst-case - we already send the header and potentially
 		// some content, but something went wrong in between.
 		logger.Error().Err(err).Msg("Fatal error while streaming data")
@@ -322,7 +717,7 @@ func StreamResponse(reader io.Reader, w http.ResponseWriter, r *http.Request, er
 	}
 
 	// Copy all that is left in the pipe
-	if _, err := io.Copy(w, reader); err != nil {
+	if _, err := io.CopyBuffer(w, reader, *buffer); err != nil {
 		// Worst-case - we already send the header and potentially
 		// some content, but something went wrong in between.
 		logger.Error().Err(err).Msg("Fatal error while streaming data")
@@ -0,0 +1,180 @@
+package turtleware
+
+import (
+	"time"
+)
+
+type resourceDataOptions struct {
+	emptyObjectOnNil bool
+	serializer       ResponseSerializer
+	timeout          time.Duration
+	contentLength    bool
+	jsonAPI          bool
+}
+
+// ResourceDataOption represents an option for the ResourceDataHandler.
+type ResourceDataOption func(*resourceDataOptions)
+
+// ResourceDataEmptyObject sets whether a nil entity (e.g. a nil pointer or map) is
+// serialized as an empty object ({}) instead of null. This mirrors the behavior of
+// the list data handlers, which serialize nil lists as empty lists ([]).
+// The default is false.
+func ResourceDataEmptyObject(emptyObjectOnNil bool) ResourceDataOption {
+	return func(c *resourceDataOptions) {
+		c.emptyObjectOnNil = emptyObjectOnNil
+	}
+}
+
+// ResourceDataSerializer sets the ResponseSerializer used for serializing the resource
+// (e.g. for serving XML or CSV). Streamed responses are not affected.
+// The default is the EmissioneWriter.
+func ResourceDataSerializer(serializer ResponseSerializer) ResourceDataOption {
+	return func(c *resourceDataOptions) {
+		c.serializer = serializer
+	}
+}
+
+// ResourceDataContentLength sets whether the resource is serialized into a buffer first, so
+// the response carries a Content-Length header. This costs holding the entire body in memory.
+// Streamed responses are not affected.
+// The default is false.
+func ResourceDataContentLength(contentLength bool) ResourceDataOption {
+	return func(c *resourceDataOptions) {
+		c.contentLength = contentLength
+	}
+}
+
+// ResourceDataTimeout sets the timeout for retrieving the resource. The context passed to
+// the data function is cancelled after the timeout, and ErrRequestTimeout is passed to the
+// error handler (see DefaultErrorHandler). The timeout also covers streamed responses.
+// The default is 0, which means no timeout.
+func ResourceDataTimeout(timeout time.Duration) ResourceDataOption {
+	return func(c *resourceDataOptions) {
+		c.timeout = timeout
+	}
+}
+
+// ResourceDataJSONAPI sets whether the resource is wrapped into a JSON:API document, that is
+// {"data": {...}} (see JSONAPIDocument). Streamed responses are not affected.
+// The default is false, which means the resource is serialized as a bare object.
+func ResourceDataJSONAPI(jsonAPI bool) ResourceDataOption {
+	return func(c *resourceDataOptions) {
+		c.jsonAPI = jsonAPI
+	}
+}
+
+type listDataOptions struct {
+	totalCountColumn string
+	countHeaderNames []string
+	serializer       ResponseSerializer
+	timeout          time.Duration
+	contentLength    bool
+	jsonAPI          bool
+	pagingLinks      bool
+}
+
+// ListDataOption represents an option for the StaticListDataHandler, SQLListDataHandler
+// and SQLxListDataHandler.
+type ListDataOption func(*listDataOptions)
+
+// ListDataTotalCountColumn sets the name of a column of the result rows, which carries the
+// total count of the list (e.g. via count(*) OVER() in Postgres). If set, the total count is
+// read from the first row, and emitted via the count header. This saves the separate count
+// query of the CountHeaderMiddleware, which should be omitted in this case.
+// The column still has to be scanned by the SQLResourceFunc or SQLxResourceFunc.
+// This option has no effect for the StaticListDataHandler.
+// If the page is empty, the count header is only emitted for the first page (as 0), as the
+// total count cannot be derived otherwise.
+// The default is empty, which means no total count is derived.
+func ListDataTotalCountColumn(totalCountColumn string) ListDataOption {
+	return func(c *listDataOptions) {
+		c.totalCountColumn = totalCountColumn
+	}
+}
+
+// ListDataCountHeaderNames sets the names of the headers carrying the total count derived
+// via ListDataTotalCountColumn (see CountHeaderNames). The total count for JSON:API documents
+// and paging links is read from the first of these headers.
+// The default is the names used by a CountHeaderMiddleware further up the chain, or
+// X-Total-Count without one.
+func ListDataCountHeaderNames(countHeaderNames ...string) ListDataOption {
+	return func(c *listDataOptions) {
+		c.countHeaderNames = countHeaderNames
+	}
+}
+
+// ListDataSerializer sets the ResponseSerializer used for serializing the list
+// (e.g. for serving XML, or CSV via NewCSVSerializer).
+// The default is the EmissioneWriter.
+func ListDataSerializer(serializer ResponseSerializer) ListDataOption {
+	return func(c *listDataOptions) {
+		c.serializer = serializer
+	}
+}
+
+// ListDataContentLength sets whether the list is serialized into a buffer first, so the
+// response carries a Content-Length header. This costs holding the entire body in memory.
+// The default is false.
+func ListDataContentLength(contentLength bool) ListDataOption {
+	return func(c *listDataOptions) {
+		c.contentLength = contentLength
+	}
+}
+
+// ListDataTimeout sets the timeout for retrieving the list. The context passed to the
+// data functions is cancelled after the timeout, and ErrRequestTimeout is passed to the
+// error handler (see DefaultErrorHandler).
+// The default is 0, which means no timeout.
+func ListDataTimeout(timeout time.Duration) ListDataOption {
+	return func(c *listDataOptions) {
+		c.timeout = timeout
+	}
+}
+
+// ListDataJSONAPI sets whether the list is wrapped into a JSON:API document, that is
+// {"data": [...], "meta": {"total": N}} (see JSONAPIDocument). The total count is inlined from
+// the count header (see CountHeaderMiddleware and ListDataTotalCountColumn), if it is exact.
+// Requests preferring CSV are not affected.
+// The default is false, which means the list is serialized as a bare array.
+func ListDataJSONAPI(jsonAPI bool) ListDataOption {
+	return func(c *listDataOptions) {
+		c.jsonAPI = jsonAPI
+	}
+}
+
+// ListDataPagingLinks sets whether Link headers for navigating the list are emitted, with the
+// relations first, prev, next and last (see PagingLinks). The total count is read from the count
+// header (see CountHeaderMiddleware and ListDataTotalCountColumn). Without an exact total count,
+// the next and last links are omitted.
+// The default is false.
+func ListDataPagingLinks(pagingLinks bool) ListDataOption {
+	return func(c *listDataOptions) {
+		c.pagingLinks = pagingLinks
+	}
+}
+
+func (c *resourceDataOptions) responseSerializer() ResponseSerializer {
+	var serializer ResponseSerializer = orEmissioneWriter(c.serializer)
+	if c.contentLength {
+		serializer = contentLengthSerializer{serializer: serializer}
+	}
+
+	if c.jsonAPI {
+		return jsonAPISerializer{serializer: serializer}
+	}
+
+	return serializer
+}
+
+func (c *listDataOptions) responseSerializer() ResponseSerializer {
+	var serializer ResponseSerializer = orEmissioneWriter(c.serializer)
+	if c.contentLength {
+		serializer = contentLengthSerializer{serializer: serializer}
+	}
+
+	if c.jsonAPI {
+		return jsonAPISerializer{serializer: serializer, countHeaderNames: c.countHeaderNames}
+	}
+
+	return serializer
+}
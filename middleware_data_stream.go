@@ -0,0 +1,240 @@
+package turtleware
+
+import (
+	jsoniter "github.com/json-iterator/go"
+	"github.com/rs/zerolog"
+
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+)
+
+// ResourceSQLDataFunc is a function for retrieving a sql.Rows iterator for a single resource via its UUID.
+type ResourceSQLDataFunc func(ctx context.Context, entityUUID string) (*sql.Rows, error)
+
+// SQLResourceStreamFunc is a function for scanning a single row from a sql.Rows iterator,
+// and writing the contained data to the given ResourceStream.
+type SQLResourceStreamFunc func(ctx context.Context, r *sql.Rows, stream *ResourceStream) error
+
+// ResourceStream assembles a single JSON object, which is written to the client while
+// it is being assembled. Members are written in the order of the calls to Field and Item.
+// Items of the same array must be written consecutively, as an array is closed as soon
+// as any other member is written. The object is serialized with the JSON options of the
+// EmissioneWriter.
+type ResourceStream struct {
+	w      http.ResponseWriter
+	api    jsoniter.API
+	stream *jsoniter.Stream
+
+	started   bool
+	hasMember bool
+	openArray string
+	hasItem   bool
+}
+
+func newResourceStream(w http.ResponseWriter) *ResourceStream {
+	api := EmissioneWriter.JSONAPI()
+
+	return &ResourceStream{
+		w:      w,
+		api:    api,
+		stream: api.BorrowStream(w),
+	}
+}
+
+// Field writes the given value as a member of the JSON object.
+func (s *ResourceStream) Field(name string, value any) error {
+	s.closeArray()
+	s.writeMember(name)
+	s.stream.WriteVal(value)
+
+	return s.stream.Error
+}
+
+// Item appends the given value to the array member with the given name.
+// The array is opened with the first item written to it.
+func (s *ResourceStream) Item(array string, value any) error {
+	if !s.hasItem || s.openArray != array {
+		s.closeArray()
+		s.writeMember(array)
+		s.stream.WriteArrayStart()
+
+		s.openArray = array
+		s.hasItem = true
+	} else {
+		s.stream.WriteMore()
+	}
+
+	s.stream.WriteVal(value)
+
+	return s.stream.Error
+}
+
+func (s *ResourceStream) writeMember(name string) {
+	if !s.started {
+		s.start()
+	}
+
+	if s.hasMember {
+		s.stream.WriteMore()
+	}
+
+	s.hasMember = true
+	s.stream.WriteObjectField(name)
+}
+
+func (s *ResourceStream) start() {
+	if len(s.w.Header().Get("Content-Type")) == 0 {
+		s.w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	}
+
+	s.w.WriteHeader(http.StatusOK)
+
+	s.started = true
+	s.stream.WriteObjectStart()
+}
+
+func (s *ResourceStream) closeArray() {
+	if s.hasItem {
+		s.stream.WriteArrayEnd()
+
+		s.openArray = ""
+		s.hasItem = false
+	}
+}
+
+// flush writes everything assembled so far to the client.
+func (s *ResourceStream) flush() error {
+	if err := s.stream.Flush(); err != nil {
+		return err
+	}
+
+	if flusher, ok := s.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+// finish closes all open JSON structures, so the client always
+// receives a syntactically correct object.
+func (s *ResourceStream) finish() error {
+	s.closeArray()
+	s.stream.WriteObjectEnd()
+
+	return s.flush()
+}
+
+func (s *ResourceStream) release() {
+	s.api.ReturnStream(s.stream)
+}
+
+// SQLResourceStreamDataHandler is a handler for serving a single resource, which is assembled
+// from multiple rows of a SQL source (e.g. a document with its line items).
+// Data is retrieved via a sql.Rows iterator retrieved from the given ResourceSQLDataFunc, and
+// each row is written to a ResourceStream via the SQLResourceStreamFunc.
+// Serialization is not buffered, so the response is flushed to the client after every row.
+// If no row is returned at all, ErrResourceNotFound is passed to the provided ErrorHandlerFunc.
+// Only GET and HEAD requests are served, any other method is answered with a 405 (see RestrictMethods).
+// Errors encountered before the first write are passed to the provided ErrorHandlerFunc. Errors
+// encountered afterward can only be logged, and the JSON object is closed regardless.
+// Of the provided options, only ResourceDataTimeout applies, as the response is always streamed.
+func SQLResourceStreamDataHandler(dataFetcher ResourceSQLDataFunc, dataTransformer SQLResourceStreamFunc, errorHandler ErrorHandlerFunc, opts ...ResourceDataOption) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	// default
+	config := &resourceDataOptions{
+		timeout: 0,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return RestrictMethods(http.MethodGet, http.MethodHead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := zerolog.Ctx(r.Context())
+
+		// Only proceed if we are working with an actual request
+		if r.Method == http.MethodHead {
+			logger.Trace().Msg("Bailing out of resource request because of HEAD method")
+
+			return
+		}
+
+		dataContext, cancel := newDataContext(r.Context(), config.timeout)
+		defer cancel()
+
+		entityUUID, err := EntityUUIDFromRequestContext(dataContext)
+		if err != nil {
+			errorHandler(dataContext, w, r, err)
+
+			return
+		}
+
+		rows, err := dataFetcher(dataContext, entityUUID)
+		if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
+			errorHandler(dataContext, w, r, ErrResourceNotFound)
+
+			return
+		}
+
+		if err != nil {
+			logger.Error().Err(err).Msg("Error while receiving rows")
+			errorHandler(dataContext, w, r, receivingError(dataContext, err))
+
+			return
+		}
+
+		// Ensure row close, even on error
+		defer func() {
+			if err := rows.Close(); err != nil {
+				logger.Warn().Err(err).Msg("Failed to close row scanner")
+			}
+		}()
+
+		stream := newResourceStream(w)
+		defer stream.release()
+
+		logger.Trace().Msg("Streaming response for resource request")
+		var transformErr error
+		for rows.Next() {
+			if transformErr = dataTransformer(dataContext, rows, stream); transformErr != nil {
+				logger.Error().Err(transformErr).Msg("Error while receiving results")
+
+				break
+			}
+
+			if stream.started {
+				if err := stream.flush(); err != nil {
+					// Worst-case - we already send the header and potentially
+					// some content, but something went wrong in between.
+					logger.Error().Err(err).Msg("Fatal error while streaming data")
+
+					return
+				}
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			logger.Error().Err(err).Msg("Error while receiving results")
+			transformErr = err
+		}
+
+		if !stream.started {
+			if transformErr != nil {
+				errorHandler(dataContext, w, r, receivingError(dataContext, transformErr))
+			} else {
+				errorHandler(dataContext, w, r, ErrResourceNotFound)
+			}
+
+			return
+		}
+
+		if err := stream.finish(); err != nil {
+			logger.Error().Err(err).Msg("Fatal error while streaming data")
+		}
+	}))
+}
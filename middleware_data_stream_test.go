@@ -0,0 +1,446 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testRowsDriver is a minimal database/sql driver, which serves the
+// rows registered for a data source name, for testing sql.Rows based handlers.
+type testRowsDriver struct {
+	mu   sync.Mutex
+	rows map[string]*testRows
+}
+
+var rowsDriver = &testRowsDriver{rows: map[string]*testRows{}}
+
+func init() {
+	sql.Register("turtleware-test-rows", rowsDriver)
+}
+
+type testRows struct {
+	columns []string
+	values  [][]driver.Value
+	err     error
+	closed  bool
+}
+
+func (d *testRowsDriver) Open(name string) (driver.Conn, error) {
+	return &testRowsConn{driver: d, name: name}, nil
+}
+
+type testRowsConn struct {
+	driver *testRowsDriver
+	name   string
+}
+
+func (c *testRowsConn) Prepare(string) (driver.Stmt, error) {
+	return &testRowsStmt{conn: c}, nil
+}
+
+func (c *testRowsConn) Close() error {
+	return nil
+}
+
+func (c *testRowsConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type testRowsStmt struct {
+	conn *testRowsConn
+}
+
+func (s *testRowsStmt) Close() error {
+	return nil
+}
+
+func (s *testRowsStmt) NumInput() int {
+	return -1
+}
+
+func (s *testRowsStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s *testRowsStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+
+	return &testRowsIterator{rows: s.conn.driver.rows[s.conn.name]}, nil
+}
+
+type testRowsIterator struct {
+	rows  *testRows
+	index int
+}
+
+func (i *testRowsIterator) Columns() []string {
+	return i.rows.columns
+}
+
+func (i *testRowsIterator) Close() error {
+	i.rows.closed = true
+	return nil
+}
+
+func (i *testRowsIterator) Next(dest []driver.Value) error {
+	if i.index >= len(i.rows.values) {
+		if i.rows.err != nil {
+			return i.rows.err
+		}
+
+		return io.EOF
+	}
+
+	copy(dest, i.rows.values[i.index])
+	i.index++
+
+	return nil
+}
+
+type MiddlewareDataStreamSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestMiddlewareDataStreamSuite(t *testing.T) {
+	suite.Run(t, &MiddlewareDataStreamSuite{})
+}
+
+func (s *MiddlewareDataStreamSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *MiddlewareDataStreamSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+type testDocumentLine struct {
+	Position int
+	Item     string
+}
+
+type testDocument struct {
+	Title string
+	Lines []testDocumentLine
+}
+
+// queryTestRows registers the given rows, and returns a sql.Rows iterator over them.
+func (s *CommonSuite) queryTestRows(rows *testRows) *sql.Rows {
+	name := s.T().Name()
+
+	rowsDriver.mu.Lock()
+	rowsDriver.rows[name] = rows
+	rowsDriver.mu.Unlock()
+
+	db, err := sql.Open("turtleware-test-rows", name)
+	s.Require().NoError(err)
+	s.T().Cleanup(func() {
+		s.NoError(db.Close())
+	})
+
+	result, err := db.Query("SELECT")
+	s.Require().NoError(err)
+
+	return result
+}
+
+func (s *MiddlewareDataStreamSuite) documentRows() *testRows {
+	return &testRows{
+		columns: []string{"title", "position", "item"},
+		values: [][]driver.Value{
+			{"some document", int64(1), "first"},
+			{"some document", int64(2), "second"},
+			{"some document", int64(3), "third"},
+		},
+	}
+}
+
+func documentTransformer(ctx context.Context, r *sql.Rows, stream *turtleware.ResourceStream) error {
+	var title string
+	var line testDocumentLine
+	if err := r.Scan(&title, &line.Position, &line.Item); err != nil {
+		return err
+	}
+
+	if line.Position == 1 {
+		if err := stream.Field("Title", title); err != nil {
+			return err
+		}
+	}
+
+	return stream.Item("Lines", line)
+}
+
+func (s *MiddlewareDataStreamSuite) Test_SQLResourceStreamDataHandler_Head() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Method = http.MethodHead
+
+	testChain := turtleware.SQLResourceStreamDataHandler(nil, nil, errorCapture.Capture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Body.String())
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareDataStreamSuite) Test_SQLResourceStreamDataHandler_MethodNotAllowed() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Method = http.MethodPut
+
+	testChain := turtleware.SQLResourceStreamDataHandler(nil, nil, errorCapture.Capture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusMethodNotAllowed, s.response.Code)
+	s.Equal("GET, HEAD", s.response.Header().Get("Allow"))
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareDataStreamSuite) Test_SQLResourceStreamDataHandler_ErrContextMissingEntityUUID() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	testChain := turtleware.SQLResourceStreamDataHandler(nil, nil, errorCapture.Capture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Body.String())
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrContextMissingEntityUUID)
+}
+
+func (s *MiddlewareDataStreamSuite) Test_SQLResourceStreamDataHandler_ErrResourceNotFound() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	rows := &testRows{columns: []string{"title", "position", "item"}}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (*sql.Rows, error) {
+		return s.queryTestRows(rows), nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.SQLResourceStreamDataHandler(dataFetcherFunc, documentTransformer, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Body.String())
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrResourceNotFound)
+	s.True(rows.closed)
+}
+
+func (s *MiddlewareDataStreamSuite) Test_SQLResourceStreamDataHandler_Success() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	rows := s.documentRows()
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (*sql.Rows, error) {
+		s.Equal(s.entityUUID, entityUUID)
+
+		return s.queryTestRows(rows), nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.SQLResourceStreamDataHandler(dataFetcherFunc, documentTransformer, errorCapture.Capture))
+
+	buffered := httptest.NewRecorder()
+	turtleware.EmissioneWriter.Write(buffered, s.request, http.StatusOK, testDocument{
+		Title: "some document",
+		Lines: []testDocumentLine{
+			{Position: 1, Item: "first"},
+			{Position: 2, Item: "second"},
+			{Position: 3, Item: "third"},
+		},
+	})
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal(buffered.Header().Get("Content-Type"), s.response.Header().Get("Content-Type"))
+	s.Equal(buffered.Body.String(), s.response.Body.String())
+	s.True(s.response.Flushed)
+	s.NoError(errorCapture.CapturedError)
+	s.True(rows.closed)
+}
+
+func (s *MiddlewareDataStreamSuite) Test_SQLResourceStreamDataHandler_EmissioneWriter() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	emissioneWriter := turtleware.EmissioneWriter
+	turtleware.EmissioneWriter = turtleware.NewEmissioneWriter(turtleware.EmissioneIndent(0))
+	defer func() {
+		turtleware.EmissioneWriter = emissioneWriter
+	}()
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (*sql.Rows, error) {
+		return s.queryTestRows(s.documentRows()), nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.SQLResourceStreamDataHandler(dataFetcherFunc, documentTransformer, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal(
+		`{"Title":"some document","Lines":[{"Position":1,"Item":"first"},{"Position":2,"Item":"second"},{"Position":3,"Item":"third"}]}`,
+		s.response.Body.String(),
+	)
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareDataStreamSuite) Test_SQLResourceStreamDataHandler_Timeout() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	rows := s.documentRows()
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (*sql.Rows, error) {
+		return s.queryTestRows(rows), nil
+	}
+
+	dataTransformerFunc := func(ctx context.Context, r *sql.Rows, stream *turtleware.ResourceStream) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.SQLResourceStreamDataHandler(dataFetcherFunc, dataTransformerFunc, errorCapture.Capture, turtleware.ResourceDataTimeout(time.Millisecond)))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Body.String())
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrRequestTimeout)
+	s.True(rows.closed)
+}
+
+func (s *MiddlewareDataStreamSuite) Test_SQLResourceStreamDataHandler_TransformError() {
+	s.Run("Before_First_Write", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		rows := s.documentRows()
+
+		dataFetcherFunc := func(ctx context.Context, entityUUID string) (*sql.Rows, error) {
+			return s.queryTestRows(rows), nil
+		}
+
+		dataTransformerFunc := func(ctx context.Context, r *sql.Rows, stream *turtleware.ResourceStream) error {
+			return errors.New("some-error")
+		}
+
+		testChain := alice.New(
+			s.buildEntityUUIDChain,
+		).Then(turtleware.SQLResourceStreamDataHandler(dataFetcherFunc, dataTransformerFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Empty(s.response.Body.String())
+		s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingResults)
+		s.True(rows.closed)
+	})
+
+	s.Run("After_First_Write", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		rows := s.documentRows()
+
+		dataFetcherFunc := func(ctx context.Context, entityUUID string) (*sql.Rows, error) {
+			return s.queryTestRows(rows), nil
+		}
+
+		calls := 0
+		dataTransformerFunc := func(ctx context.Context, r *sql.Rows, stream *turtleware.ResourceStream) error {
+			calls++
+			if calls > 2 {
+				return errors.New("some-error")
+			}
+
+			return documentTransformer(ctx, r, stream)
+		}
+
+		testChain := alice.New(
+			s.buildEntityUUIDChain,
+		).Then(turtleware.SQLResourceStreamDataHandler(dataFetcherFunc, dataTransformerFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusOK, s.response.Code)
+		s.True(json.Valid(s.response.Body.Bytes()))
+		s.JSONEq(`{"Title":"some document","Lines":[{"Position":1,"Item":"first"},{"Position":2,"Item":"second"}]}`, s.response.Body.String())
+		s.NoError(errorCapture.CapturedError)
+		s.True(rows.closed)
+	})
+
+	s.Run("Rows_Error", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		rows := s.documentRows()
+		rows.err = errors.New("some-error")
+
+		dataFetcherFunc := func(ctx context.Context, entityUUID string) (*sql.Rows, error) {
+			return s.queryTestRows(rows), nil
+		}
+
+		testChain := alice.New(
+			s.buildEntityUUIDChain,
+		).Then(turtleware.SQLResourceStreamDataHandler(dataFetcherFunc, documentTransformer, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(http.StatusOK, s.response.Code)
+		s.True(json.Valid(s.response.Body.Bytes()))
+		s.NoError(errorCapture.CapturedError)
+		s.True(rows.closed)
+	})
+}
@@ -4,16 +4,22 @@ import (
 	"github.com/justinas/alice"
 	"github.com/kernle32dll/turtleware"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
 
 	"bytes"
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 type MiddlewareDataSuite struct {
@@ -41,6 +47,16 @@ func (t *TestCloser) Close() error {
 	return t.closerErr
 }
 
+// TestSerializer is a turtleware.ResponseSerializer, which writes
+// plain text, for testing serializer injection.
+type TestSerializer struct{}
+
+func (TestSerializer) Write(w http.ResponseWriter, r *http.Request, code int, i interface{}) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintf(w, "%v", i)
+}
+
 func TestMiddlewareDataSuite(t *testing.T) {
 	suite.Run(t, &MiddlewareDataSuite{})
 }
@@ -72,6 +88,23 @@ func (s *MiddlewareDataSuite) Test_StaticListDataHandler_Head() {
 	s.NoError(errorCapture.CapturedError)
 }
 
+func (s *MiddlewareDataSuite) Test_StaticListDataHandler_MethodNotAllowed() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Method = http.MethodPost
+
+	testChain := turtleware.StaticListDataHandler[TestDataModel](nil, errorCapture.Capture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusMethodNotAllowed, s.response.Code)
+	s.Equal("GET, HEAD", s.response.Header().Get("Allow"))
+	s.NoError(errorCapture.CapturedError)
+}
+
 func (s *MiddlewareDataSuite) Test_StaticListDataHandler_ErrContextMissingPaging() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
@@ -108,6 +141,52 @@ func (s *MiddlewareDataSuite) Test_StaticListDataHandler_Handle_Err() {
 	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingResults)
 }
 
+func (s *MiddlewareDataSuite) Test_StaticListDataHandler_Timeout() {
+	s.Run("Exceeded", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture, turtleware.ListDataTimeout(time.Millisecond)))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Empty(s.response.Body.String())
+		s.ErrorIs(errorCapture.CapturedError, turtleware.ErrRequestTimeout)
+	})
+
+	s.Run("Without_Option", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		hasDeadline := true
+		dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+			_, hasDeadline = ctx.Deadline()
+			return []TestDataModel{}, nil
+		}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(errorCapture.CapturedError)
+		s.Equal(http.StatusOK, s.response.Code)
+		s.False(hasDeadline)
+	})
+}
+
 func (s *MiddlewareDataSuite) Test_StaticListDataHandler_Success() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
@@ -118,8 +197,10 @@ func (s *MiddlewareDataSuite) Test_StaticListDataHandler_Success() {
 	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
 		dataFetcherFuncWasCalled = true
 		s.Equal(turtleware.Paging{
-			Limit:  23,
-			Offset: 5,
+			Limit:        23,
+			Offset:       5,
+			DefaultLimit: 100,
+			MaxLimit:     500,
 		}, paging)
 
 		return []TestDataModel{
@@ -147,6 +228,115 @@ func (s *MiddlewareDataSuite) Test_StaticListDataHandler_Success() {
 	s.True(dataFetcherFuncWasCalled)
 }
 
+func (s *MiddlewareDataSuite) Test_StaticListDataHandler_JSONAPI() {
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		return []TestDataModel{
+			{
+				SomeString: "test1",
+				SomeInt:    42,
+			},
+			{
+				SomeString: "test2",
+				SomeInt:    1337,
+			},
+		}, nil
+	}
+
+	s.Run("With_Count", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		countFetcher := func(ctx context.Context) (uint, error) {
+			return 2, nil
+		}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+			turtleware.CountHeaderMiddleware(countFetcher, errorCapture.Capture),
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture, turtleware.ListDataJSONAPI(true)))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.JSONEq(s.loadTestDataString("data/list_jsonapi.json"), s.response.Body.String())
+		s.Equal("2", s.response.Header().Get("X-Total-Count"))
+		s.NoError(errorCapture.CapturedError)
+	})
+
+	s.Run("Without_Count", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture, turtleware.ListDataJSONAPI(true)))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.JSONEq(`{"data":[{"SomeString":"test1","SomeInt":42},{"SomeString":"test2","SomeInt":1337}]}`, s.response.Body.String())
+		s.NoError(errorCapture.CapturedError)
+	})
+
+	s.Run("CSV", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		s.request.Header.Set("Accept", "text/csv")
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+		).Then(turtleware.StaticListDataHandler(
+			dataFetcherFunc,
+			errorCapture.Capture,
+			turtleware.ListDataJSONAPI(true),
+			turtleware.ListDataSerializer(turtleware.NewCSVSerializer()),
+		))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal("text/csv;charset=utf-8", s.response.Header().Get("Content-Type"))
+		s.NotContains(s.response.Body.String(), "data")
+		s.NoError(errorCapture.CapturedError)
+	})
+}
+
+func (s *MiddlewareDataSuite) Test_StaticListDataHandler_PagingLinks() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request = httptest.NewRequest(http.MethodGet, "/foo?offset=10&limit=10", http.NoBody)
+
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		return []TestDataModel{}, nil
+	}
+
+	countFetcher := func(ctx context.Context) (uint, error) {
+		return 25, nil
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+		turtleware.CountHeaderMiddleware(countFetcher, errorCapture.Capture),
+	).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture, turtleware.ListDataPagingLinks(true)))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal([]string{
+		`</foo?limit=10>; rel="first"`,
+		`</foo?limit=10>; rel="prev"`,
+		`</foo?offset=20&limit=10>; rel="next"`,
+		`</foo?offset=20&limit=10>; rel="last"`,
+	}, s.response.Header().Values("Link"))
+	s.NoError(errorCapture.CapturedError)
+}
+
 // Test_StaticListDataHandler_NilResult is an important test, that
 // verifies that turtleware.StaticListDataHandler writes out an empty result
 // array, if the returned array is nil.
@@ -161,8 +351,10 @@ func (s *MiddlewareDataSuite) Test_StaticListDataHandler_NilResult() {
 	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
 		dataFetcherFuncWasCalled = true
 		s.Equal(turtleware.Paging{
-			Limit:  100,
-			Offset: 0,
+			Limit:        100,
+			Offset:       0,
+			DefaultLimit: 100,
+			MaxLimit:     500,
 		}, paging)
 
 		return nil, nil
@@ -181,6 +373,536 @@ func (s *MiddlewareDataSuite) Test_StaticListDataHandler_NilResult() {
 	s.True(dataFetcherFuncWasCalled)
 }
 
+func (s *MiddlewareDataSuite) Test_SQLListDataHandler_TotalCountColumn() {
+	cases := map[string]struct {
+		query         string
+		rows          *testRows
+		opts          []turtleware.ListDataOption
+		expectedCount []string
+	}{
+		"Without_Option": {
+			rows: &testRows{
+				columns: []string{"some_string", "some_int", "total_count"},
+				values:  [][]driver.Value{{"first", int64(1), int64(42)}},
+			},
+			expectedCount: nil,
+		},
+		"Rows": {
+			rows: &testRows{
+				columns: []string{"some_string", "some_int", "total_count"},
+				values: [][]driver.Value{
+					{"first", int64(1), int64(42)},
+					{"second", int64(2), int64(42)},
+				},
+			},
+			opts:          []turtleware.ListDataOption{turtleware.ListDataTotalCountColumn("total_count")},
+			expectedCount: []string{"42"},
+		},
+		"Empty_First_Page": {
+			rows: &testRows{
+				columns: []string{"some_string", "some_int", "total_count"},
+			},
+			opts:          []turtleware.ListDataOption{turtleware.ListDataTotalCountColumn("total_count")},
+			expectedCount: []string{"0"},
+		},
+		"Empty_Later_Page": {
+			query: "?offset=100",
+			rows: &testRows{
+				columns: []string{"some_string", "some_int", "total_count"},
+			},
+			opts:          []turtleware.ListDataOption{turtleware.ListDataTotalCountColumn("total_count")},
+			expectedCount: nil,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			errorCapture := &ErrorHandlerCapture{}
+
+			s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo"+target.query, http.NoBody)
+
+			dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+				return s.queryTestRows(target.rows), nil
+			}
+
+			dataTransformerFunc := func(ctx context.Context, r *sql.Rows) (TestDataModel, error) {
+				var model TestDataModel
+				var totalCount int64
+				err := r.Scan(&model.SomeString, &model.SomeInt, &totalCount)
+				return model, err
+			}
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+			).Then(turtleware.SQLListDataHandler(dataFetcherFunc, dataTransformerFunc, errorCapture.Capture, target.opts...))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.NoError(errorCapture.CapturedError)
+			s.Equal(http.StatusOK, s.response.Code)
+			s.Equal(target.expectedCount, s.response.Header().Values("X-Total-Count"))
+			s.Len(s.decodeList(), len(target.rows.values))
+		})
+	}
+}
+
+func (s *MiddlewareDataSuite) Test_SQLListDataHandler_TotalCountColumn_CountHeaderNames() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	rows := &testRows{
+		columns: []string{"some_string", "some_int", "total_count"},
+		values:  [][]driver.Value{{"first", int64(1), int64(42)}},
+	}
+
+	countFetcher := func(ctx context.Context) (uint, error) {
+		return 1337, nil
+	}
+
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+		return s.queryTestRows(rows), nil
+	}
+
+	dataTransformerFunc := func(ctx context.Context, r *sql.Rows) (TestDataModel, error) {
+		var model TestDataModel
+		var totalCount int64
+		err := r.Scan(&model.SomeString, &model.SomeInt, &totalCount)
+		return model, err
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+		turtleware.CountHeaderMiddleware(
+			countFetcher,
+			errorCapture.Capture,
+			turtleware.CountHeaderNames("X-Pagination-Total"),
+			turtleware.CountOnlyForMethods(http.MethodHead),
+		),
+	).Then(turtleware.SQLListDataHandler(
+		dataFetcherFunc,
+		dataTransformerFunc,
+		errorCapture.Capture,
+		turtleware.ListDataTotalCountColumn("total_count"),
+	))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("42", s.response.Header().Get("X-Pagination-Total"))
+	s.NotContains(s.response.Header(), "X-Total-Count")
+}
+
+func (s *MiddlewareDataSuite) Test_SQLListDataHandler_Timeout() {
+	cases := map[string]struct {
+		fetchErr     bool
+		transformErr bool
+	}{
+		"Fetch":     {fetchErr: true},
+		"Transform": {transformErr: true},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			errorCapture := &ErrorHandlerCapture{}
+
+			rows := &testRows{
+				columns: []string{"some_string", "some_int"},
+				values:  [][]driver.Value{{"first", int64(1)}},
+			}
+
+			dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+				if target.fetchErr {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+
+				return s.queryTestRows(rows), nil
+			}
+
+			dataTransformerFunc := func(ctx context.Context, r *sql.Rows) (TestDataModel, error) {
+				if target.transformErr {
+					<-ctx.Done()
+					return TestDataModel{}, ctx.Err()
+				}
+
+				var model TestDataModel
+				err := r.Scan(&model.SomeString, &model.SomeInt)
+				return model, err
+			}
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+			).Then(turtleware.SQLListDataHandler(dataFetcherFunc, dataTransformerFunc, errorCapture.Capture, turtleware.ListDataTimeout(time.Millisecond)))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Empty(s.response.Body.String())
+			s.ErrorIs(errorCapture.CapturedError, turtleware.ErrRequestTimeout)
+		})
+	}
+}
+
+func (s *MiddlewareDataSuite) Test_SQLListDataHandler_TotalCountColumn_HeaderNames() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	rows := &testRows{
+		columns: []string{"some_string", "some_int", "total_count"},
+		values:  [][]driver.Value{{"first", int64(1), int64(42)}},
+	}
+
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+		return s.queryTestRows(rows), nil
+	}
+
+	dataTransformerFunc := func(ctx context.Context, r *sql.Rows) (TestDataModel, error) {
+		var model TestDataModel
+		var totalCount int64
+		err := r.Scan(&model.SomeString, &model.SomeInt, &totalCount)
+		return model, err
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+	).Then(turtleware.SQLListDataHandler(
+		dataFetcherFunc,
+		dataTransformerFunc,
+		errorCapture.Capture,
+		turtleware.ListDataTotalCountColumn("total_count"),
+		turtleware.ListDataCountHeaderNames("X-Pagination-Total", "X-Total-Count"),
+	))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.NoError(errorCapture.CapturedError)
+	s.Equal("42", s.response.Header().Get("X-Pagination-Total"))
+	s.Equal("42", s.response.Header().Get("X-Total-Count"))
+}
+
+func (s *MiddlewareDataSuite) Test_SQLListDataHandler_TotalCountColumn_Missing() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	rows := &testRows{
+		columns: []string{"some_string", "some_int"},
+		values:  [][]driver.Value{{"first", int64(1)}},
+	}
+
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+		return s.queryTestRows(rows), nil
+	}
+
+	dataTransformerFunc := func(ctx context.Context, r *sql.Rows) (TestDataModel, error) {
+		var model TestDataModel
+		err := r.Scan(&model.SomeString, &model.SomeInt)
+		return model, err
+	}
+
+	testChain := alice.New(
+		turtleware.PagingMiddleware,
+	).Then(turtleware.SQLListDataHandler(
+		dataFetcherFunc,
+		dataTransformerFunc,
+		errorCapture.Capture,
+		turtleware.ListDataTotalCountColumn("total_count"),
+	))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingResults)
+	s.Empty(s.response.Header().Get("X-Total-Count"))
+}
+
+func (s *MiddlewareDataSuite) Test_ListDataHandler_Serializer() {
+	data := []TestDataModel{{SomeString: "first", SomeInt: 1}}
+
+	staticFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		return data, nil
+	}
+
+	sqlFetcherFunc := func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+		return s.queryTestRows(&testRows{
+			columns: []string{"some_string", "some_int"},
+			values:  [][]driver.Value{{"first", int64(1)}},
+		}), nil
+	}
+
+	sqlTransformerFunc := func(ctx context.Context, r *sql.Rows) (TestDataModel, error) {
+		var model TestDataModel
+		err := r.Scan(&model.SomeString, &model.SomeInt)
+		return model, err
+	}
+
+	cases := map[string]func(errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.ListDataOption) http.Handler{
+		"Static": func(errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.ListDataOption) http.Handler {
+			return turtleware.StaticListDataHandler(staticFetcherFunc, errorHandler, opts...)
+		},
+		"SQL": func(errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.ListDataOption) http.Handler {
+			return turtleware.SQLListDataHandler(sqlFetcherFunc, sqlTransformerFunc, errorHandler, opts...)
+		},
+	}
+
+	for testName, handlerFunc := range cases {
+		s.Run(testName, func() {
+			// given
+			errorCapture := &ErrorHandlerCapture{}
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+			).Then(handlerFunc(errorCapture.Capture, turtleware.ListDataSerializer(TestSerializer{})))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.NoError(errorCapture.CapturedError)
+			s.Equal(http.StatusOK, s.response.Code)
+			s.Equal("text/plain", s.response.Header().Get("Content-Type"))
+			s.Equal(fmt.Sprintf("%v", data), s.response.Body.String())
+		})
+
+		s.Run(testName+"_Default", func() {
+			// given
+			errorCapture := &ErrorHandlerCapture{}
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+			).Then(handlerFunc(errorCapture.Capture))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.NoError(errorCapture.CapturedError)
+			s.Equal("application/json;charset=utf-8", s.response.Header().Get("Content-Type"))
+			s.Equal(data, s.decodeList())
+		})
+	}
+}
+
+func (s *MiddlewareDataSuite) Test_ListDataHandler_Head_Chain() {
+	handlers := map[string]func(called *bool, errorHandler turtleware.ErrorHandlerFunc) http.Handler{
+		"Static": func(called *bool, errorHandler turtleware.ErrorHandlerFunc) http.Handler {
+			return turtleware.StaticListDataHandler(func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+				*called = true
+				return nil, nil
+			}, errorHandler)
+		},
+		"SQL": func(called *bool, errorHandler turtleware.ErrorHandlerFunc) http.Handler {
+			return turtleware.SQLListDataHandler(func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+				*called = true
+				return nil, errors.New("unexpected call")
+			}, func(ctx context.Context, r *sql.Rows) (TestDataModel, error) {
+				return TestDataModel{}, nil
+			}, errorHandler)
+		},
+	}
+
+	hashFetcher := func(ctx context.Context, paging turtleware.Paging) (string, error) {
+		return "some-hash", nil
+	}
+
+	countFetcher := func(ctx context.Context) (uint, error) {
+		return 42, nil
+	}
+
+	for handlerName, handler := range handlers {
+		s.Run(handlerName+"_Cache_Miss", func() {
+			// given
+			errorCapture := &ErrorHandlerCapture{}
+			called := false
+
+			s.request.Method = http.MethodHead
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+				turtleware.ListCacheMiddleware(hashFetcher, errorCapture.Capture),
+				turtleware.CountHeaderMiddleware(countFetcher, errorCapture.Capture),
+			).Then(handler(&called, errorCapture.Capture))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.NoError(errorCapture.CapturedError)
+			s.Equal(http.StatusOK, s.response.Code)
+			s.Equal(`"some-hash"`, s.response.Header().Get("Etag"))
+			s.Equal("42", s.response.Header().Get("X-Total-Count"))
+			s.Empty(s.response.Body.String())
+			s.False(called)
+		})
+
+		s.Run(handlerName+"_Cache_Hit", func() {
+			// given
+			errorCapture := &ErrorHandlerCapture{}
+			called := false
+
+			s.request.Method = http.MethodHead
+			s.request.Header.Set("If-None-Match", `"some-hash"`)
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+				turtleware.ListCacheMiddleware(hashFetcher, errorCapture.Capture),
+				turtleware.CountHeaderMiddleware(countFetcher, errorCapture.Capture),
+			).Then(handler(&called, errorCapture.Capture))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.NoError(errorCapture.CapturedError)
+			s.Equal(http.StatusNotModified, s.response.Code)
+			s.Equal(`"some-hash"`, s.response.Header().Get("Etag"))
+			s.Empty(s.response.Body.String())
+			s.False(called)
+		})
+	}
+}
+
+func (s *MiddlewareDataSuite) Test_ListDataHandler_ContentLength() {
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		return []TestDataModel{{SomeString: "first", SomeInt: 1}}, nil
+	}
+
+	s.Run("Enabled", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture, turtleware.ListDataContentLength(true)))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(errorCapture.CapturedError)
+		s.Equal(http.StatusOK, s.response.Code)
+		s.Equal(fmt.Sprint(s.response.Body.Len()), s.response.Header().Get("Content-Length"))
+		s.Equal("application/json;charset=utf-8", s.response.Header().Get("Content-Type"))
+		s.Equal([]TestDataModel{{SomeString: "first", SomeInt: 1}}, s.decodeList())
+	})
+
+	s.Run("Disabled", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+
+		testChain := alice.New(
+			turtleware.PagingMiddleware,
+		).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(errorCapture.CapturedError)
+		s.Equal(http.StatusOK, s.response.Code)
+		s.Empty(s.response.Header().Get("Content-Length"))
+	})
+}
+
+func (s *MiddlewareDataSuite) Test_SQLListDataHandler_Tracing() {
+	dataTransformerFunc := func(ctx context.Context, r *sql.Rows) (TestDataModel, error) {
+		var model TestDataModel
+		err := r.Scan(&model.SomeString, &model.SomeInt)
+		return model, err
+	}
+
+	s.Run("Success", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+		recorder := &SpanRecorder{}
+
+		rows := &testRows{
+			columns: []string{"some_string", "some_int"},
+			values: [][]driver.Value{
+				{"first", int64(1)},
+				{"second", int64(2)},
+			},
+		}
+
+		dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+			return s.queryTestRows(rows), nil
+		}
+
+		testChain := alice.New(
+			turtleware.TracingMiddleware("test", recorder),
+			turtleware.PagingMiddleware,
+		).Then(turtleware.SQLListDataHandler(dataFetcherFunc, dataTransformerFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.NoError(errorCapture.CapturedError)
+
+		requestSpan := recorder.Span("test")
+		s.Require().NotNil(requestSpan)
+
+		fetchSpan := recorder.Span("turtleware.FetchRows")
+		s.Require().NotNil(fetchSpan)
+		s.True(fetchSpan.Ended)
+		s.Equal(requestSpan.SpanContext().SpanID(), fetchSpan.Parent.SpanID())
+		s.Equal(int64(2), fetchSpan.Attributes["turtleware.row_count"].AsInt64())
+		s.Equal(codes.Unset, fetchSpan.StatusCode)
+
+		serializeSpan := recorder.Span("turtleware.Serialize")
+		s.Require().NotNil(serializeSpan)
+		s.True(serializeSpan.Ended)
+		s.Equal(requestSpan.SpanContext().SpanID(), serializeSpan.Parent.SpanID())
+	})
+
+	s.Run("Error", func() {
+		// given
+		errorCapture := &ErrorHandlerCapture{}
+		recorder := &SpanRecorder{}
+
+		targetError := errors.New("some-error")
+
+		dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) (*sql.Rows, error) {
+			return nil, targetError
+		}
+
+		testChain := alice.New(
+			turtleware.TracingMiddleware("test", recorder),
+			turtleware.PagingMiddleware,
+		).Then(turtleware.SQLListDataHandler(dataFetcherFunc, dataTransformerFunc, errorCapture.Capture))
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingResults)
+
+		fetchSpan := recorder.Span("turtleware.FetchRows")
+		s.Require().NotNil(fetchSpan)
+		s.True(fetchSpan.Ended)
+		s.Equal(codes.Error, fetchSpan.StatusCode)
+		s.Equal([]error{targetError}, fetchSpan.Errors)
+		s.Nil(recorder.Span("turtleware.Serialize"))
+	})
+}
+
+func (s *MiddlewareDataSuite) decodeList() []TestDataModel {
+	var list []TestDataModel
+	s.Require().NoError(json.NewDecoder(s.response.Body).Decode(&list))
+
+	return list
+}
+
 func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Head() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
@@ -197,6 +919,56 @@ func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Head() {
 	s.NoError(errorCapture.CapturedError)
 }
 
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Head_Chain() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Method = http.MethodHead
+
+	lastModified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	lastModFetcher := func(ctx context.Context, entityUUID string) (time.Time, error) {
+		return lastModified, nil
+	}
+
+	called := false
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (TestDataModel, error) {
+		called = true
+		return TestDataModel{}, nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.ResourceCacheMiddleware(lastModFetcher, errorCapture.Capture),
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal(lastModified.Format(time.RFC1123), s.response.Header().Get("Last-Modified"))
+	s.Empty(s.response.Body.String())
+	s.False(called)
+}
+
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_MethodNotAllowed() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Method = http.MethodPut
+
+	testChain := turtleware.ResourceDataHandler[TestDataModel](nil, errorCapture.Capture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusMethodNotAllowed, s.response.Code)
+	s.Equal("GET, HEAD", s.response.Header().Get("Allow"))
+	s.NoError(errorCapture.CapturedError)
+}
+
 func (s *MiddlewareDataSuite) Test_ResourceDataHandler_ErrContextMissingEntityUUID() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
@@ -241,6 +1013,28 @@ func (s *MiddlewareDataSuite) Test_ResourceDataHandler_ErrResourceNotFound() {
 	}
 }
 
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_ErrResourceGone() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	targetError := fmt.Errorf("%w: soft-deleted", turtleware.ErrResourceGone)
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (TestDataModel, error) {
+		return TestDataModel{}, targetError
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Body.String())
+	s.Equal(targetError, errorCapture.CapturedError)
+}
+
 func (s *MiddlewareDataSuite) Test_ResourceDataHandler_ErrReceivingResults() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
@@ -263,6 +1057,27 @@ func (s *MiddlewareDataSuite) Test_ResourceDataHandler_ErrReceivingResults() {
 	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingResults)
 }
 
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Timeout() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (TestDataModel, error) {
+		<-ctx.Done()
+		return TestDataModel{}, fmt.Errorf("some-driver-error: %w", ctx.Err())
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture, turtleware.ResourceDataTimeout(time.Millisecond)))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Body.String())
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrRequestTimeout)
+}
+
 func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
@@ -291,6 +1106,172 @@ func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success() {
 	s.True(dataFetcherFuncWasCalled)
 }
 
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Serializer() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	entity := TestDataModel{
+		SomeString: "test1",
+		SomeInt:    42,
+	}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (TestDataModel, error) {
+		return entity, nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(
+		dataFetcherFunc,
+		errorCapture.Capture,
+		turtleware.ResourceDataSerializer(TestSerializer{}),
+	))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.NoError(errorCapture.CapturedError)
+	s.Equal("text/plain", s.response.Header().Get("Content-Type"))
+	s.Equal(fmt.Sprintf("%v", entity), s.response.Body.String())
+}
+
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_ContentLength() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (TestDataModel, error) {
+		return TestDataModel{SomeString: "first", SomeInt: 1}, nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(
+		dataFetcherFunc,
+		errorCapture.Capture,
+		turtleware.ResourceDataContentLength(true),
+		turtleware.ResourceDataSerializer(TestSerializer{}),
+	))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("text/plain", s.response.Header().Get("Content-Type"))
+	s.Equal("{first 1}", s.response.Body.String())
+	s.Equal("9", s.response.Header().Get("Content-Length"))
+}
+
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Tracing() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+	recorder := &SpanRecorder{}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (TestDataModel, error) {
+		return TestDataModel{SomeString: "first", SomeInt: 1}, nil
+	}
+
+	testChain := alice.New(
+		turtleware.TracingMiddleware("test", recorder),
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.NoError(errorCapture.CapturedError)
+
+	for _, spanName := range []string{"turtleware.FetchEntity", "turtleware.Serialize"} {
+		span := recorder.Span(spanName)
+		s.Require().NotNil(span, spanName)
+		s.True(span.Ended, spanName)
+		s.Equal(recorder.Span("test").SpanContext().SpanID(), span.Parent.SpanID(), spanName)
+	}
+}
+
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Nil() {
+	cases := map[string]struct {
+		opts     []turtleware.ResourceDataOption
+		expected string
+	}{
+		"Default":      {opts: nil, expected: "null"},
+		"Null":         {opts: []turtleware.ResourceDataOption{turtleware.ResourceDataEmptyObject(false)}, expected: "null"},
+		"Empty_Object": {opts: []turtleware.ResourceDataOption{turtleware.ResourceDataEmptyObject(true)}, expected: "{}"},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			errorCapture := &ErrorHandlerCapture{}
+
+			dataFetcherFunc := func(ctx context.Context, entityUUID string) (*TestDataModel, error) {
+				return nil, nil
+			}
+
+			testChain := alice.New(
+				s.buildEntityUUIDChain,
+			).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture, target.opts...))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(http.StatusOK, s.response.Code)
+			s.JSONEq(target.expected, s.response.Body.String())
+			s.NoError(errorCapture.CapturedError)
+		})
+	}
+}
+
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_EmptyObject_NonNil() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (*TestDataModel, error) {
+		return &TestDataModel{
+			SomeString: "test1",
+			SomeInt:    42,
+		}, nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture, turtleware.ResourceDataEmptyObject(true)))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.JSONEq(s.loadTestDataString("data/entity_success.json"), s.response.Body.String())
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_JSONAPI() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (TestDataModel, error) {
+		return TestDataModel{
+			SomeString: "test1",
+			SomeInt:    42,
+		}, nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture, turtleware.ResourceDataJSONAPI(true)))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.JSONEq(s.loadTestDataString("data/entity_jsonapi.json"), s.response.Body.String())
+	s.NoError(errorCapture.CapturedError)
+}
+
 func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success_Reader() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
@@ -318,6 +1299,48 @@ func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success_Reader() {
 	s.True(dataFetcherFuncWasCalled)
 }
 
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success_EmptyReader() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (io.Reader, error) {
+		return bytes.NewReader(nil), nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("application/octet-stream", s.response.Header().Get("Content-Type"))
+	s.Empty(s.response.Body.String())
+	s.NoError(errorCapture.CapturedError)
+}
+
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_ReaderError() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (io.Reader, error) {
+		return iotest.ErrReader(errors.New("some-error")), nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Empty(s.response.Body.String())
+	s.EqualError(errorCapture.CapturedError, "error while trying to read content type: some-error")
+}
+
 func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success_ReadCloser() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
@@ -343,6 +1366,55 @@ func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success_ReadCloser() {
 	s.True(testResponse.wasClosed)
 }
 
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success_TypedReader() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	testResponse := &TestCloser{
+		Buffer: bytes.NewBufferString("name,value\nfoo,1\n"),
+	}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (turtleware.TypedReader, error) {
+		return turtleware.NewTypedReader(testResponse, "text/csv"), nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("text/csv", s.response.Header().Get("Content-Type"))
+	s.Equal("name,value\nfoo,1\n", s.response.Body.String())
+	s.NoError(errorCapture.CapturedError)
+	s.True(testResponse.wasClosed)
+}
+
+func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success_TypedReader_Empty() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	dataFetcherFunc := func(ctx context.Context, entityUUID string) (turtleware.TypedReader, error) {
+		return turtleware.NewTypedReader(bytes.NewReader(nil), "text/csv"), nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+	).Then(turtleware.ResourceDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("text/csv", s.response.Header().Get("Content-Type"))
+	s.Empty(s.response.Body.String())
+	s.NoError(errorCapture.CapturedError)
+}
+
 func (s *MiddlewareDataSuite) Test_ResourceDataHandler_Success_ReadCloser_CloseError() {
 	// given
 	errorCapture := &ErrorHandlerCapture{}
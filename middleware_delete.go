@@ -0,0 +1,216 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+)
+
+var (
+	// ErrNoBulkIDs is returned when the bulk request did not contain any ids.
+	ErrNoBulkIDs = errors.New("bulk request did not contain any ids")
+)
+
+// BulkResultStatus describes the outcome of a bulk operation for a single id.
+type BulkResultStatus string
+
+const (
+	// BulkResultDeleted indicates that the resource was deleted.
+	BulkResultDeleted BulkResultStatus = "deleted"
+
+	// BulkResultNotFound indicates that the resource did not exist.
+	BulkResultNotFound BulkResultStatus = "not-found"
+
+	// BulkResultError indicates that an error occurred while handling the resource.
+	BulkResultError BulkResultStatus = "error"
+)
+
+// BulkResult is the outcome of a bulk operation for a single id.
+type BulkResult struct {
+	ID     string           `json:"id" xml:"ID"`
+	Status BulkResultStatus `json:"status" xml:"Status"`
+	Error  string           `json:"error,omitempty" xml:"Error,omitempty"`
+}
+
+// DeleteFunc is a function called for delegating the actual deletion of an existing resource.
+// If no If-Unmodified-Since header was provided, ifUnmodifiedSince is the zero time.
+type DeleteFunc func(ctx context.Context, entityUUID, userUUID string, ifUnmodifiedSince time.Time) error
+
+// BulkDeleteFunc is a function called for delegating the actual deletion of multiple resources.
+// It is expected to return a BulkResult per provided id. An error should only be returned if
+// the bulk operation as a whole failed.
+type BulkDeleteFunc func(ctx context.Context, userUUID string, ids []string) ([]BulkResult, error)
+
+// IsHandledByDefaultDeleteErrorHandler indicates if the DefaultDeleteErrorHandler has any special
+// handling for the given error, or if it defaults to handing it out as-is.
+func IsHandledByDefaultDeleteErrorHandler(err error) bool {
+	return errors.Is(err, ErrUnmodifiedSinceHeaderInvalid) ||
+		IsHandledByDefaultErrorHandler(err)
+}
+
+// DefaultDeleteErrorHandler is a default error handler, which sensibly handles errors known by turtleware.
+func DefaultDeleteErrorHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	err = withErrorCode(err)
+
+	if errors.Is(err, ErrUnmodifiedSinceHeaderInvalid) {
+		WriteError(ctx, w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	DefaultErrorHandler(ctx, w, r, err)
+}
+
+// IsHandledByDefaultBulkDeleteErrorHandler indicates if the DefaultBulkDeleteErrorHandler has any special
+// handling for the given error, or if it defaults to handing it out as-is.
+func IsHandledByDefaultBulkDeleteErrorHandler(err error) bool {
+	return errors.Is(err, ErrNoBulkIDs) ||
+		IsHandledByDefaultErrorHandler(err)
+}
+
+// DefaultBulkDeleteErrorHandler is a default error handler, which sensibly handles errors known by turtleware.
+func DefaultBulkDeleteErrorHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	err = withErrorCode(err)
+
+	if errors.Is(err, ErrNoBulkIDs) {
+		WriteError(ctx, w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	DefaultErrorHandler(ctx, w, r, err)
+}
+
+// ResourceDeleteMiddleware is a middleware for deleting an existing resource.
+// It calls the provided DeleteFunc, and writes a 204 No Content on success, after which the
+// next handler is called. An If-Unmodified-Since header is optional, but is parsed via
+// GetIfUnmodifiedSince if provided.
+// If the DeleteFunc returns sql.ErrNoRows or os.ErrNotExist, ErrResourceNotFound is passed on instead.
+// If the DeleteFunc returns an AcceptedError (see Accepted), a 202 Accepted is written
+// instead of calling the next handler.
+// Errors encountered during the process are passed to the provided ErrorHandlerFunc.
+func ResourceDeleteMiddleware(deleteFunc DeleteFunc, errorHandler ErrorHandlerFunc) func(http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deleteContext, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			logger := zerolog.Ctx(deleteContext)
+
+			userUUID, err := UserUUIDFromRequestContext(deleteContext)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+
+				return
+			}
+
+			entityUUID, err := EntityUUIDFromRequestContext(deleteContext)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+
+				return
+			}
+
+			// ----------------
+
+			ifUnmodifiedSince, err := GetIfUnmodifiedSince(r)
+			if err != nil && !errors.Is(err, ErrUnmodifiedSinceHeaderMissing) {
+				errorHandler(deleteContext, w, r, err)
+
+				return
+			}
+
+			if err := deleteFunc(deleteContext, entityUUID, userUUID, ifUnmodifiedSince); err != nil {
+				var acceptedError *AcceptedError
+				if errors.As(err, &acceptedError) {
+					WriteAccepted(deleteContext, w, acceptedError.StatusLocation)
+
+					return
+				}
+
+				if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
+					errorHandler(deleteContext, w, r, ErrResourceNotFound)
+
+					return
+				}
+
+				logger.Error().Err(err).Msg("Delete failed")
+				errorHandler(deleteContext, w, r, err)
+
+				return
+			}
+
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusNoContent)
+
+			if next != nil {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// ResourceBulkDeleteMiddleware is a middleware for deleting multiple resources at once.
+// It parses a JSON array of ids from the request body, and then calls the provided BulkDeleteFunc.
+// The per-id outcomes are written with a 207 Multi-Status, which completes the response. That is,
+// the next handler is never called.
+// Errors encountered during the process are passed to the provided ErrorHandlerFunc.
+func ResourceBulkDeleteMiddleware(bulkDeleteFunc BulkDeleteFunc, errorHandler ErrorHandlerFunc) func(http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
+	return func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deleteContext, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			logger := zerolog.Ctx(deleteContext)
+
+			userUUID, err := UserUUIDFromRequestContext(deleteContext)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+
+				return
+			}
+
+			// ----------------
+
+			ids, err := DecodeBody[[]string](r)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+
+				return
+			}
+
+			if len(ids) == 0 {
+				errorHandler(deleteContext, w, r, ErrNoBulkIDs)
+
+				return
+			}
+
+			results, err := bulkDeleteFunc(deleteContext, userUUID, ids)
+			if err != nil {
+				logger.Error().Err(err).Msg("Bulk delete failed")
+				errorHandler(deleteContext, w, r, err)
+
+				return
+			}
+
+			WriteBulkResults(w, r, results)
+		})
+	}
+}
+
+// WriteBulkResults writes the given BulkResult slice with a 207 Multi-Status.
+func WriteBulkResults(w http.ResponseWriter, r *http.Request, results []BulkResult) {
+	if results == nil {
+		results = make([]BulkResult, 0)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	EmissioneWriter.Write(w, r, http.StatusMultiStatus, results)
+}
@@ -0,0 +1,445 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+type MiddlewareDeleteSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestMiddlewareDeleteSuite(t *testing.T) {
+	suite.Run(t, &MiddlewareDeleteSuite{})
+}
+
+func (s *MiddlewareDeleteSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodDelete, "https://example.com/foo", http.NoBody)
+}
+
+func (s *MiddlewareDeleteSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *MiddlewareDeleteSuite) Test_DefaultDeleteErrorHandler_Handled() {
+	// given
+	cases := map[string]struct {
+		err        error
+		goldenFile string
+		statusCode int
+	}{
+		"ErrUnmodifiedSinceHeaderInvalid": {
+			err:        turtleware.ErrUnmodifiedSinceHeaderInvalid,
+			goldenFile: "error_errunmodifiedsinceheaderinvalid.json",
+			statusCode: http.StatusBadRequest,
+		},
+		"ErrMarshalling": {
+			// handled via DefaultErrorHandler
+			err:        turtleware.ErrMarshalling,
+			goldenFile: "error_errmarshalling.json",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			targetError := target.err
+
+			// when
+			turtleware.DefaultDeleteErrorHandler(context.Background(), s.response, s.request, targetError)
+
+			// then
+			s.Equal(target.statusCode, s.response.Code)
+			s.JSONEq(s.loadTestDataString("errorhandler/delete/"+target.goldenFile), s.response.Body.String())
+			s.True(turtleware.IsHandledByDefaultDeleteErrorHandler(targetError))
+		})
+	}
+}
+
+func (s *MiddlewareDeleteSuite) Test_DefaultDeleteErrorHandler_NotHandled() {
+	// given
+	targetError := errors.New("some-error")
+
+	// when
+	turtleware.DefaultDeleteErrorHandler(context.Background(), s.response, s.request, targetError)
+
+	// then
+	s.JSONEq(s.loadTestDataString("errors/some_error.json"), s.response.Body.String())
+	s.False(turtleware.IsHandledByDefaultDeleteErrorHandler(targetError))
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceDeleteMiddleware_ErrContextMissingAuthClaims() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.ResourceDeleteMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrContextMissingAuthClaims)
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceDeleteMiddleware_ErrContextMissingEntityUUID() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		turtleware.ResourceDeleteMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrContextMissingEntityUUID)
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceDeleteMiddleware_UnmodifiedSinceError() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Header.Set("If-Unmodified-Since", "trash")
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceDeleteMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrUnmodifiedSinceHeaderInvalid)
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceDeleteMiddleware_Handle_Err() {
+	targetError := errors.New("some-error")
+
+	cases := map[string]struct {
+		err         error
+		expectedErr error
+	}{
+		"Some_Error": {
+			err:         targetError,
+			expectedErr: targetError,
+		},
+		"SQL_No_Rows": {
+			err:         sql.ErrNoRows,
+			expectedErr: turtleware.ErrResourceNotFound,
+		},
+		"Not_Exist": {
+			err:         fmt.Errorf("wrapped: %w", os.ErrNotExist),
+			expectedErr: turtleware.ErrResourceNotFound,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			deleteFunc := func(context.Context, string, string, time.Time) error {
+				return target.err
+			}
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.ResourceDeleteMiddleware(deleteFunc, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.ErrorIs(errorCapture.CapturedError, target.expectedErr)
+		})
+	}
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceDeleteMiddleware_Accepted() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	deleteFunc := func(context.Context, string, string, time.Time) error {
+		return turtleware.Accepted("/jobs/some-job")
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceDeleteMiddleware(deleteFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusAccepted, s.response.Code)
+	s.Equal("/jobs/some-job", s.response.Header().Get("Location"))
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceDeleteMiddleware_Success() {
+	testTime := time.Now().UTC()
+
+	cases := map[string]struct {
+		ifUnmodifiedSince string
+		expected          time.Time
+	}{
+		"Without_If_Unmodified_Since": {
+			expected: time.Time{},
+		},
+		"With_If_Unmodified_Since": {
+			ifUnmodifiedSince: testTime.Format(time.RFC3339Nano),
+			expected:          testTime,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			if target.ifUnmodifiedSince != "" {
+				s.request.Header.Set("If-Unmodified-Since", target.ifUnmodifiedSince)
+			}
+
+			deleteFuncWasCalled := false
+			deleteFunc := func(ctx context.Context, entityUUID, userUUID string, ifUnmodifiedSince time.Time) error {
+				deleteFuncWasCalled = true
+				s.Equal(s.entityUUID, entityUUID)
+				s.Equal(s.userUUID, userUUID)
+				s.Equal(target.expected, ifUnmodifiedSince)
+				return nil
+			}
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.ResourceDeleteMiddleware(deleteFunc, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.True(nextCapture.Called)
+			s.NoError(errorCapture.CapturedError)
+			s.True(deleteFuncWasCalled)
+			s.Equal(http.StatusNoContent, s.response.Code)
+			s.Empty(s.response.Body.String())
+		})
+	}
+}
+
+func (s *MiddlewareDeleteSuite) Test_DefaultBulkDeleteErrorHandler_Handled() {
+	// given
+	cases := map[string]struct {
+		err        error
+		goldenFile string
+		statusCode int
+	}{
+		"ErrNoBulkIDs": {
+			err:        turtleware.ErrNoBulkIDs,
+			goldenFile: "error_errnobulkids.json",
+			statusCode: http.StatusBadRequest,
+		},
+		"ErrMarshalling": {
+			// handled via DefaultErrorHandler
+			err:        turtleware.ErrMarshalling,
+			goldenFile: "error_errmarshalling.json",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			targetError := target.err
+
+			// when
+			turtleware.DefaultBulkDeleteErrorHandler(context.Background(), s.response, s.request, targetError)
+
+			// then
+			s.Equal(target.statusCode, s.response.Code)
+			s.JSONEq(s.loadTestDataString("errorhandler/delete/"+target.goldenFile), s.response.Body.String())
+			s.True(turtleware.IsHandledByDefaultBulkDeleteErrorHandler(targetError))
+		})
+	}
+}
+
+func (s *MiddlewareDeleteSuite) Test_DefaultBulkDeleteErrorHandler_NotHandled() {
+	// given
+	targetError := errors.New("some-error")
+
+	// when
+	turtleware.DefaultBulkDeleteErrorHandler(context.Background(), s.response, s.request, targetError)
+
+	// then
+	s.JSONEq(s.loadTestDataString("errors/some_error.json"), s.response.Body.String())
+	s.False(turtleware.IsHandledByDefaultBulkDeleteErrorHandler(targetError))
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceBulkDeleteMiddleware_ErrContextMissingAuthClaims() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	testChain := alice.New(
+		turtleware.ResourceBulkDeleteMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrContextMissingAuthClaims)
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceBulkDeleteMiddleware_TrashBody() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = io.NopCloser(bytes.NewBufferString("trash"))
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		turtleware.ResourceBulkDeleteMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrMarshalling)
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceBulkDeleteMiddleware_ErrNoBulkIDs() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = io.NopCloser(bytes.NewBufferString("[]"))
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		turtleware.ResourceBulkDeleteMiddleware(nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrNoBulkIDs)
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceBulkDeleteMiddleware_Handle_Err() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = io.NopCloser(bytes.NewBufferString(`["existing"]`))
+
+	targetError := errors.New("some-error")
+
+	bulkDeleteFunc := func(ctx context.Context, userUUID string, ids []string) ([]turtleware.BulkResult, error) {
+		return nil, targetError
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		turtleware.ResourceBulkDeleteMiddleware(bulkDeleteFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, targetError)
+}
+
+func (s *MiddlewareDeleteSuite) Test_ResourceBulkDeleteMiddleware_Success() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = io.NopCloser(bytes.NewBufferString(`["existing", "missing", "broken"]`))
+
+	existing := map[string]bool{"existing": true, "broken": true}
+
+	bulkDeleteFunc := func(ctx context.Context, userUUID string, ids []string) ([]turtleware.BulkResult, error) {
+		s.Equal(s.userUUID, userUUID)
+
+		results := make([]turtleware.BulkResult, len(ids))
+		for i, id := range ids {
+			switch {
+			case !existing[id]:
+				results[i] = turtleware.BulkResult{ID: id, Status: turtleware.BulkResultNotFound}
+			case id == "broken":
+				results[i] = turtleware.BulkResult{ID: id, Status: turtleware.BulkResultError, Error: "some-error"}
+			default:
+				results[i] = turtleware.BulkResult{ID: id, Status: turtleware.BulkResultDeleted}
+			}
+		}
+
+		return results, nil
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		turtleware.ResourceBulkDeleteMiddleware(bulkDeleteFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusMultiStatus, s.response.Code)
+	s.JSONEq(s.loadTestDataString("delete/bulk_results.json"), s.response.Body.String())
+}
@@ -5,27 +5,55 @@ import (
 
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
 	"net/http"
+	"path/filepath"
+	"strings"
 )
 
+// ErrTooManyParams signals that a multipart form contains more non-file fields than allowed.
+var ErrTooManyParams = errors.New("multipart form contains too many fields")
+
+// ErrDisallowedFileType signals that a multipart form contains a file with a content type or
+// extension, which is not allowed.
+var ErrDisallowedFileType = errors.New("file type is not allowed")
+
 // FileHandleFunc is a function that handles a single file upload.
 type FileHandleFunc func(ctx context.Context, entityUUID, userUUID string, fileName string, file multipart.File) error
 
+// FileHandleFuncExt is a function that handles a single file upload. In contrast to FileHandleFunc,
+// it receives the complete multipart.FileHeader of the file, which carries the file name, the
+// headers of the part (such as the declared Content-Type), and the size of the file.
+type FileHandleFuncExt func(ctx context.Context, entityUUID, userUUID string, fileHeader *multipart.FileHeader, file multipart.File) error
+
+func (fn FileHandleFunc) ext() FileHandleFuncExt {
+	return func(ctx context.Context, entityUUID, userUUID string, fileHeader *multipart.FileHeader, file multipart.File) error {
+		return fn(ctx, entityUUID, userUUID, fileHeader.Filename, file)
+	}
+}
+
 // IsHandledByDefaultFileUploadErrorHandler indicates if the DefaultFileUploadErrorHandler has any special
 // handling for the given error, or if it defaults to handing it out as-is.
 func IsHandledByDefaultFileUploadErrorHandler(err error) bool {
 	return errors.Is(err, http.ErrNotMultipart) ||
 		errors.Is(err, http.ErrMissingBoundary) ||
 		errors.Is(err, multipart.ErrMessageTooLarge) ||
+		errors.Is(err, ErrTooManyParams) ||
+		errors.Is(err, ErrDisallowedFileType) ||
 		IsHandledByDefaultErrorHandler(err)
 }
 
 // DefaultFileUploadErrorHandler is a default error handler, which sensibly handles errors known by turtleware.
 func DefaultFileUploadErrorHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	err = withErrorCode(err)
+
 	if errors.Is(err, http.ErrNotMultipart) ||
 		errors.Is(err, http.ErrMissingBoundary) ||
-		errors.Is(err, multipart.ErrMessageTooLarge) {
+		errors.Is(err, multipart.ErrMessageTooLarge) ||
+		errors.Is(err, ErrTooManyParams) ||
+		errors.Is(err, ErrDisallowedFileType) {
 		WriteError(ctx, w, r, http.StatusBadRequest, err)
 		return
 	}
@@ -35,14 +63,24 @@ func DefaultFileUploadErrorHandler(ctx context.Context, w http.ResponseWriter, r
 
 // FileUploadMiddleware is a middleware that handles uploads of one or multiple files.
 // Uploads are parsed from the request via HandleFileUpload, and then passed to the provided FileHandleFunc.
+// The behavior of the middleware can be adjusted via the provided options.
 // Errors encountered during the process are passed to the provided ErrorHandlerFunc.
-func FileUploadMiddleware(fileHandleFunc FileHandleFunc, errorHandler ErrorHandlerFunc) func(http.Handler) http.Handler {
+func FileUploadMiddleware(fileHandleFunc FileHandleFunc, errorHandler ErrorHandlerFunc, opts ...FileUploadOption) func(http.Handler) http.Handler {
+	return FileUploadMiddlewareExt(fileHandleFunc.ext(), errorHandler, opts...)
+}
+
+// FileUploadMiddlewareExt is the same as FileUploadMiddleware, but passes the complete
+// multipart.FileHeader of each file (e.g. for its content type and size) to the provided
+// FileHandleFuncExt.
+func FileUploadMiddlewareExt(fileHandleFunc FileHandleFuncExt, errorHandler ErrorHandlerFunc, opts ...FileUploadOption) func(http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			uploadContext, cancel := context.WithCancel(r.Context())
 			defer cancel()
 
-			if err := HandleFileUpload(uploadContext, r, fileHandleFunc); err != nil {
+			if err := HandleFileUploadExt(uploadContext, r, fileHandleFunc, opts...); err != nil {
 				errorHandler(uploadContext, w, r, err)
 
 				return
@@ -57,10 +95,35 @@ func FileUploadMiddleware(fileHandleFunc FileHandleFunc, errorHandler ErrorHandl
 
 // HandleFileUpload is a helper function for handling file uploads.
 // It parses upload metadata from the request, and then calls the provided FileHandleFunc for each file part.
+// The size of the upload, the non-file fields, and the types of the files of the form are
+// validated against the limits provided via the options, before any file is handled.
+// The limits for non-file fields are enforced while the form is streamed from the request.
 // Errors encountered during the process are passed to the caller.
-func HandleFileUpload(ctx context.Context, r *http.Request, fileHandleFunc FileHandleFunc) error {
+func HandleFileUpload(ctx context.Context, r *http.Request, fileHandleFunc FileHandleFunc, opts ...FileUploadOption) error {
+	return HandleFileUploadExt(ctx, r, fileHandleFunc.ext(), opts...)
+}
+
+// HandleFileUploadExt is the same as HandleFileUpload, but passes the complete
+// multipart.FileHeader of each file to the provided FileHandleFuncExt.
+func HandleFileUploadExt(ctx context.Context, r *http.Request, fileHandleFunc FileHandleFuncExt, opts ...FileUploadOption) error {
 	logger := zerolog.Ctx(ctx)
 
+	// default
+	config := &fileUploadOptions{
+		maxMemory:      int64(5 << 20),
+		maxSize:        0,
+		maxValueFields: 0,
+		maxValueBytes:  0,
+
+		allowedContentTypes: nil,
+		allowedExtensions:   nil,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
 	userUUID, err := UserUUIDFromRequestContext(ctx)
 	if err != nil {
 		return err
@@ -73,13 +136,42 @@ func HandleFileUpload(ctx context.Context, r *http.Request, fileHandleFunc FileH
 
 	// ----------------
 
+	if config.maxSize > 0 {
+		if r.ContentLength > config.maxSize {
+			return fmt.Errorf("%w: %d bytes exceed limit of %d", multipart.ErrMessageTooLarge, r.ContentLength, config.maxSize)
+		}
+
+		r.Body = http.MaxBytesReader(nil, r.Body, config.maxSize)
+	}
+
 	mr, err := r.MultipartReader()
 	if err != nil {
 		return err
 	}
 
-	form, err := mr.ReadForm(int64(5 << 20))
+	if config.maxValueFields > 0 || config.maxValueBytes > 0 {
+		var closeReader func()
+		mr, closeReader = limitFormValues(mr, config)
+		defer closeReader()
+	}
+
+	form, err := mr.ReadForm(config.maxMemory)
 	if err != nil {
+		if maxBytesErr := (*http.MaxBytesError)(nil); errors.As(err, &maxBytesErr) {
+			return fmt.Errorf("%w: %w", multipart.ErrMessageTooLarge, err)
+		}
+
+		return err
+	}
+
+	// Ensure removal of temporary files, even on error
+	defer func() {
+		if err := form.RemoveAll(); err != nil {
+			logger.Warn().Err(err).Msg("Failed to remove temporary files of multipart form")
+		}
+	}()
+
+	if err := validateFormFiles(form, config); err != nil {
 		return err
 	}
 
@@ -98,7 +190,7 @@ func HandleFileUpload(ctx context.Context, r *http.Request, fileHandleFunc FileH
 				return err
 			}
 
-			if err := fileHandleFunc(ctx, entityUUID, userUUID, fileName, f); err != nil {
+			if err := fileHandleFunc(ctx, entityUUID, userUUID, file, f); err != nil {
 				logEntry.Error().Err(err).Msg("Multipart handling failed")
 
 				if err := f.Close(); err != nil {
@@ -116,3 +208,160 @@ func HandleFileUpload(ctx context.Context, r *http.Request, fileHandleFunc FileH
 
 	return nil
 }
+
+// limitFormValues pipes the parts of the given reader into a new multipart.Reader, while
+// enforcing the limits for non-file fields. As the limits are checked while streaming,
+// a form exceeding them is rejected before its values are buffered by ReadForm.
+// The returned function must be called after reading, to stop the piping.
+func limitFormValues(mr *multipart.Reader, config *fileUploadOptions) (*multipart.Reader, func()) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		pw.CloseWithError(copyFormParts(mr, mw, config))
+	}()
+
+	return multipart.NewReader(pr, mw.Boundary()), func() {
+		// nolint errcheck: Closing the reader side of a pipe never fails
+		_ = pr.Close()
+	}
+}
+
+func copyFormParts(mr *multipart.Reader, mw *multipart.Writer, config *fileUploadOptions) error {
+	valueFields := 0
+	valueBytes := int64(0)
+
+	for {
+		part, err := mr.NextPart()
+		if errors.Is(err, io.EOF) {
+			return mw.Close()
+		}
+
+		if err != nil {
+			return err
+		}
+
+		dst, err := mw.CreatePart(part.Header)
+		if err != nil {
+			return err
+		}
+
+		if part.FileName() != "" {
+			if _, err := io.Copy(dst, part); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		valueFields++
+		if config.maxValueFields > 0 && valueFields > config.maxValueFields {
+			return fmt.Errorf("%w: fields exceed limit of %d", ErrTooManyParams, config.maxValueFields)
+		}
+
+		src := io.Reader(part)
+		if config.maxValueBytes > 0 {
+			src = io.LimitReader(part, config.maxValueBytes-valueBytes+1)
+		}
+
+		n, err := io.Copy(dst, src)
+		if err != nil {
+			return err
+		}
+
+		valueBytes += n
+		if config.maxValueBytes > 0 && valueBytes > config.maxValueBytes {
+			return fmt.Errorf("%w: field values exceed limit of %d bytes", multipart.ErrMessageTooLarge, config.maxValueBytes)
+		}
+	}
+}
+
+func validateFormFiles(form *multipart.Form, config *fileUploadOptions) error {
+	if len(config.allowedContentTypes) == 0 && len(config.allowedExtensions) == 0 {
+		return nil
+	}
+
+	for _, files := range form.File {
+		for _, file := range files {
+			if err := validateFileType(file, config); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateFileType(file *multipart.FileHeader, config *fileUploadOptions) error {
+	if len(config.allowedExtensions) > 0 {
+		extension := filepath.Ext(file.Filename)
+
+		allowed := false
+		for _, allowedExtension := range config.allowedExtensions {
+			if strings.EqualFold(extension, "."+strings.TrimPrefix(allowedExtension, ".")) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return fmt.Errorf("%w: extension of %s", ErrDisallowedFileType, file.Filename)
+		}
+	}
+
+	if len(config.allowedContentTypes) > 0 {
+		contentType, err := sniffContentType(file)
+		if err != nil {
+			return err
+		}
+
+		allowed := false
+		for _, allowedContentType := range config.allowedContentTypes {
+			if contentTypeMatches(contentType, allowedContentType) {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return fmt.Errorf("%w: content type %s of %s", ErrDisallowedFileType, contentType, file.Filename)
+		}
+	}
+
+	return nil
+}
+
+// sniffContentType detects the content type of the given file from its first bytes,
+// without any parameters (such as the charset).
+func sniffContentType(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+
+	// nolint errcheck: The file was only read
+	defer func() { _ = f.Close() }()
+
+	buffer := make([]byte, 512)
+
+	n, err := io.ReadFull(f, buffer)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(buffer[:n]), ";")
+
+	return strings.TrimSpace(contentType), nil
+}
+
+func contentTypeMatches(contentType, allowedContentType string) bool {
+	allowedContentType = strings.TrimSpace(allowedContentType)
+
+	if prefix, found := strings.CutSuffix(allowedContentType, "/*"); found {
+		mainType, _, _ := strings.Cut(contentType, "/")
+
+		return prefix == "*" || strings.EqualFold(mainType, prefix)
+	}
+
+	return strings.EqualFold(contentType, allowedContentType)
+}
@@ -0,0 +1,78 @@
+package turtleware
+
+type fileUploadOptions struct {
+	maxMemory           int64
+	maxSize             int64
+	maxValueFields      int
+	maxValueBytes       int64
+	allowedContentTypes []string
+	allowedExtensions   []string
+}
+
+// FileUploadOption represents an option for the FileUploadMiddleware and HandleFileUpload.
+type FileUploadOption func(*fileUploadOptions)
+
+// FileUploadMaxMemory sets the maximum number of bytes of a multipart form, which are buffered
+// in memory. File parts exceeding the limit are buffered in temporary files instead, which are
+// removed after the upload was handled.
+// The default is 5 MiB.
+func FileUploadMaxMemory(maxMemory int64) FileUploadOption {
+	return func(c *fileUploadOptions) {
+		c.maxMemory = maxMemory
+	}
+}
+
+// FileUploadMaxSize sets the maximum number of bytes of the whole request body, enforced
+// via http.MaxBytesReader. Uploads exceeding the limit are rejected with
+// multipart.ErrMessageTooLarge.
+// A limit of zero disables the check.
+// The default is zero.
+func FileUploadMaxSize(maxSize int64) FileUploadOption {
+	return func(c *fileUploadOptions) {
+		c.maxSize = maxSize
+	}
+}
+
+// FileUploadMaxValueFields sets the maximum number of non-file fields a multipart form may
+// contain. Forms exceeding the limit are rejected with ErrTooManyParams.
+// A limit of zero disables the check.
+// The default is zero.
+func FileUploadMaxValueFields(maxValueFields int) FileUploadOption {
+	return func(c *fileUploadOptions) {
+		c.maxValueFields = maxValueFields
+	}
+}
+
+// FileUploadMaxValueBytes sets the maximum number of bytes all non-file field values of a
+// multipart form may sum up to. Forms exceeding the limit are rejected with
+// multipart.ErrMessageTooLarge.
+// A limit of zero disables the check.
+// The default is zero.
+func FileUploadMaxValueBytes(maxValueBytes int64) FileUploadOption {
+	return func(c *fileUploadOptions) {
+		c.maxValueBytes = maxValueBytes
+	}
+}
+
+// FileUploadAllowedContentTypes sets the content types files of a multipart form may have.
+// The content type is sniffed from the first bytes of each file via http.DetectContentType,
+// instead of trusting the header sent by the client. Wildcards for subtypes (e.g. "image/*")
+// are supported. Files not matching are rejected with ErrDisallowedFileType.
+// If empty, all content types are allowed.
+// The default is empty.
+func FileUploadAllowedContentTypes(contentTypes ...string) FileUploadOption {
+	return func(c *fileUploadOptions) {
+		c.allowedContentTypes = contentTypes
+	}
+}
+
+// FileUploadAllowedExtensions sets the file name extensions (e.g. ".png") files of a multipart
+// form may have. Extensions are compared case-insensitive. Files not matching are rejected
+// with ErrDisallowedFileType.
+// If empty, all extensions are allowed.
+// The default is empty.
+func FileUploadAllowedExtensions(extensions ...string) FileUploadOption {
+	return func(c *fileUploadOptions) {
+		c.allowedExtensions = extensions
+	}
+}
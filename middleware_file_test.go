@@ -56,6 +56,14 @@ func (s *MiddlewareFileSuite) Test_DefaultFileUploadErrorHandler_Handled() {
 			err:        multipart.ErrMessageTooLarge,
 			goldenFile: "error_errmessagetoolarge.json",
 		},
+		"ErrTooManyParams": {
+			err:        turtleware.ErrTooManyParams,
+			goldenFile: "error_errtoomanyparams.json",
+		},
+		"ErrDisallowedFileType": {
+			err:        turtleware.ErrDisallowedFileType,
+			goldenFile: "error_errdisallowedfiletype.json",
+		},
 		"ErrMarshalling": {
 			// handled via DefaultErrorHandler
 			err:        turtleware.ErrMarshalling,
@@ -221,6 +229,372 @@ func (s *MiddlewareFileSuite) Test_FileUploadMiddleware_Success() {
 	s.NoError(errorCapture.CapturedError)
 }
 
+func (s *MiddlewareFileSuite) Test_FileUploadMiddlewareExt_Success() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	part, contentType := s.CreateMultipart()
+	s.request.Body = io.NopCloser(bytes.NewBuffer(part))
+	s.request.Header.Set("Content-Type", contentType)
+
+	fileCounter := 1
+	fileHandlerFunc := func(
+		ctx context.Context,
+		entityUUID, userUUID string,
+		fileHeader *multipart.FileHeader,
+		file multipart.File,
+	) error {
+		content, err := io.ReadAll(file)
+		s.Require().NoError(err)
+
+		s.Equal(s.entityUUID, entityUUID)
+		s.Equal(s.userUUID, userUUID)
+		s.Equal(fmt.Sprintf("test%d.txt", fileCounter), fileHeader.Filename)
+		s.Equal("application/octet-stream", fileHeader.Header.Get("Content-Type"))
+		s.Equal(int64(len(content)), fileHeader.Size)
+		s.Equal(fmt.Sprintf("works%d", fileCounter), string(content))
+		fileCounter++
+
+		return nil
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.FileUploadMiddlewareExt(fileHandlerFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(3, fileCounter)
+}
+
+func (s *MiddlewareFileSuite) Test_FileUploadMiddleware_ValueLimits() {
+	cases := map[string]struct {
+		option      turtleware.FileUploadOption
+		expectedErr error
+	}{
+		"Fields_Exceeded": {
+			option:      turtleware.FileUploadMaxValueFields(2),
+			expectedErr: turtleware.ErrTooManyParams,
+		},
+		"Fields_Within": {
+			option: turtleware.FileUploadMaxValueFields(3),
+		},
+		"Bytes_Exceeded": {
+			option:      turtleware.FileUploadMaxValueBytes(8),
+			expectedErr: multipart.ErrMessageTooLarge,
+		},
+		"Bytes_Within": {
+			option: turtleware.FileUploadMaxValueBytes(9),
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			s.Require().NoError(writer.WriteField("first", "aaa"))
+			s.Require().NoError(writer.WriteField("second", "bbb"))
+			s.Require().NoError(writer.WriteField("second", "ccc"))
+			s.attachMultipartFile(writer, "test1.txt", []byte("works1"))
+			s.Require().NoError(writer.Close())
+
+			s.request.Body = io.NopCloser(body)
+			s.request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			fileHandlerFunc := func(
+				ctx context.Context,
+				entityUUID, userUUID string,
+				fileName string,
+				file multipart.File,
+			) error {
+				return nil
+			}
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.FileUploadMiddleware(fileHandlerFunc, errorCapture.Capture, target.option),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			if target.expectedErr != nil {
+				s.False(nextCapture.Called)
+				s.ErrorIs(errorCapture.CapturedError, target.expectedErr)
+			} else {
+				s.True(nextCapture.Called)
+				s.NoError(errorCapture.CapturedError)
+			}
+		})
+	}
+}
+
+func (s *MiddlewareFileSuite) Test_FileUploadMiddleware_ValueLimits_Streaming() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	s.Require().NoError(writer.WriteField("first", "aaa"))
+	_, err := writer.CreateFormField("second")
+	s.Require().NoError(err)
+
+	// The value of the second field never ends - so the form must be
+	// rejected before the value is read.
+	endless := &endlessReader{}
+
+	s.request.Body = io.NopCloser(io.MultiReader(body, io.LimitReader(endless, 64<<20)))
+	s.request.Header.Set("Content-Type", writer.FormDataContentType())
+
+	fileHandlerFunc := func(
+		ctx context.Context,
+		entityUUID, userUUID string,
+		fileName string,
+		file multipart.File,
+	) error {
+		return nil
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.FileUploadMiddleware(fileHandlerFunc, errorCapture.Capture, turtleware.FileUploadMaxValueFields(1)),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrTooManyParams)
+	s.Less(endless.read, int64(1<<20))
+}
+
+// endlessReader is an io.Reader, which endlessly returns bytes, and counts the bytes read.
+type endlessReader struct {
+	read int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'a'
+	}
+
+	r.read += int64(len(p))
+
+	return len(p), nil
+}
+
+func (s *MiddlewareFileSuite) Test_FileUploadMiddleware_SizeLimits() {
+	part, contentType := s.CreateMultipart()
+
+	cases := map[string]struct {
+		option        turtleware.FileUploadOption
+		contentLength int64
+		expectedErr   error
+	}{
+		"Size_Exceeded": {
+			option:      turtleware.FileUploadMaxSize(int64(len(part) - 1)),
+			expectedErr: multipart.ErrMessageTooLarge,
+		},
+		"Size_Exceeded_Content_Length": {
+			option:        turtleware.FileUploadMaxSize(int64(len(part) - 1)),
+			contentLength: int64(len(part)),
+			expectedErr:   multipart.ErrMessageTooLarge,
+		},
+		"Size_Within": {
+			option:        turtleware.FileUploadMaxSize(int64(len(part))),
+			contentLength: int64(len(part)),
+		},
+		"Memory_Exceeded": {
+			// files exceeding the memory limit are buffered on disk instead
+			option: turtleware.FileUploadMaxMemory(1),
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			s.request.Body = io.NopCloser(bytes.NewBuffer(part))
+			s.request.ContentLength = target.contentLength
+			s.request.Header.Set("Content-Type", contentType)
+
+			fileCounter := 1
+			fileHandlerFunc := func(
+				ctx context.Context,
+				entityUUID, userUUID string,
+				fileName string,
+				file multipart.File,
+			) error {
+				content, err := io.ReadAll(file)
+				s.Require().NoError(err)
+
+				s.Equal(fmt.Sprintf("works%d", fileCounter), string(content))
+				fileCounter++
+
+				return nil
+			}
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.FileUploadMiddleware(fileHandlerFunc, errorCapture.Capture, target.option),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			if target.expectedErr != nil {
+				s.False(nextCapture.Called)
+				s.ErrorIs(errorCapture.CapturedError, target.expectedErr)
+				s.True(turtleware.IsHandledByDefaultFileUploadErrorHandler(errorCapture.CapturedError))
+			} else {
+				s.True(nextCapture.Called)
+				s.NoError(errorCapture.CapturedError)
+				s.Equal(3, fileCounter)
+			}
+		})
+	}
+}
+
+func (s *MiddlewareFileSuite) Test_FileUploadMiddleware_FileTypes() {
+	png := []byte("\x89PNG\r\n\x1a\nsome-image-data")
+
+	cases := map[string]struct {
+		fileName    string
+		data        []byte
+		options     []turtleware.FileUploadOption
+		expectedErr error
+	}{
+		"Content_Type_Allowed": {
+			fileName: "image.png",
+			data:     png,
+			options:  []turtleware.FileUploadOption{turtleware.FileUploadAllowedContentTypes("image/png")},
+		},
+		"Content_Type_Wildcard": {
+			fileName: "image.png",
+			data:     png,
+			options:  []turtleware.FileUploadOption{turtleware.FileUploadAllowedContentTypes("image/*")},
+		},
+		"Content_Type_Disallowed": {
+			// the extension does not matter for sniffing
+			fileName:    "image.png",
+			data:        []byte("MZ\x90\x00some-executable"),
+			options:     []turtleware.FileUploadOption{turtleware.FileUploadAllowedContentTypes("image/*")},
+			expectedErr: turtleware.ErrDisallowedFileType,
+		},
+		"Content_Type_Text": {
+			fileName: "test.txt",
+			data:     []byte("works"),
+			options:  []turtleware.FileUploadOption{turtleware.FileUploadAllowedContentTypes("text/plain")},
+		},
+		"Extension_Allowed": {
+			fileName: "IMAGE.PNG",
+			data:     png,
+			options:  []turtleware.FileUploadOption{turtleware.FileUploadAllowedExtensions("png", ".jpg")},
+		},
+		"Extension_Disallowed": {
+			fileName:    "image.exe",
+			data:        png,
+			options:     []turtleware.FileUploadOption{turtleware.FileUploadAllowedExtensions(".png")},
+			expectedErr: turtleware.ErrDisallowedFileType,
+		},
+		"Extension_Missing": {
+			fileName:    "image",
+			data:        png,
+			options:     []turtleware.FileUploadOption{turtleware.FileUploadAllowedExtensions(".png")},
+			expectedErr: turtleware.ErrDisallowedFileType,
+		},
+		"Both_Allowed": {
+			fileName: "image.png",
+			data:     png,
+			options: []turtleware.FileUploadOption{
+				turtleware.FileUploadAllowedContentTypes("image/png"),
+				turtleware.FileUploadAllowedExtensions(".png"),
+			},
+		},
+		"Both_Extension_Disallowed": {
+			fileName: "image.gif",
+			data:     png,
+			options: []turtleware.FileUploadOption{
+				turtleware.FileUploadAllowedContentTypes("image/png"),
+				turtleware.FileUploadAllowedExtensions(".png"),
+			},
+			expectedErr: turtleware.ErrDisallowedFileType,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			s.attachMultipartFile(writer, target.fileName, target.data)
+			s.Require().NoError(writer.Close())
+
+			s.request.Body = io.NopCloser(body)
+			s.request.Header.Set("Content-Type", writer.FormDataContentType())
+
+			handled := false
+			fileHandlerFunc := func(
+				ctx context.Context,
+				entityUUID, userUUID string,
+				fileName string,
+				file multipart.File,
+			) error {
+				content, err := io.ReadAll(file)
+				s.Require().NoError(err)
+
+				s.Equal(target.data, content)
+				handled = true
+
+				return nil
+			}
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.FileUploadMiddleware(fileHandlerFunc, errorCapture.Capture, target.options...),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			if target.expectedErr != nil {
+				s.False(nextCapture.Called)
+				s.False(handled)
+				s.ErrorIs(errorCapture.CapturedError, target.expectedErr)
+			} else {
+				s.True(nextCapture.Called)
+				s.True(handled)
+				s.NoError(errorCapture.CapturedError)
+			}
+		})
+	}
+}
+
 func (s *MiddlewareFileSuite) CreateMultipart() ([]byte, string) {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
@@ -0,0 +1,75 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"context"
+	"net/http"
+)
+
+// AfterResponseHook is a function called after a response was written, with the final
+// status code of the response. It is intended for side effects, such as warming or
+// invalidating caches, or emitting events.
+type AfterResponseHook func(ctx context.Context, r *http.Request, status int)
+
+type afterResponseOptions struct {
+	detached bool
+}
+
+// AfterResponseOption represents an option for the AfterResponseMiddleware.
+type AfterResponseOption func(*afterResponseOptions)
+
+// AfterResponseDetached sets whether the AfterResponseHook is run in a detached goroutine,
+// instead of the request goroutine. If detached, the response is not delayed by the hook,
+// and the context passed to the hook is not canceled with the request.
+// The default is false.
+func AfterResponseDetached(detached bool) AfterResponseOption {
+	return func(c *afterResponseOptions) {
+		c.detached = detached
+	}
+}
+
+// AfterResponseMiddleware is a http middleware for calling the given AfterResponseHook, after
+// the next handler completed. The status code written by the next handler is captured, and
+// passed to the hook. If the next handler did not write anything, 200 is assumed, as the
+// http server does.
+// Panics of a detached hook are recovered and logged.
+func AfterResponseMiddleware(hook AfterResponseHook, opts ...AfterResponseOption) func(http.Handler) http.Handler {
+	// default
+	config := &afterResponseOptions{
+		detached: false,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			sw := &statusWriter{ResponseWriter: w}
+			next.ServeHTTP(sw, r)
+
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			if !config.detached {
+				hook(r.Context(), r, status)
+				return
+			}
+
+			hookContext := context.WithoutCancel(r.Context())
+			go func() {
+				defer func() {
+					if rec := recover(); rec != nil {
+						zerolog.Ctx(hookContext).Error().Interface("panic", rec).Msg("Recovered from panic in after response hook")
+					}
+				}()
+
+				hook(hookContext, r, status)
+			}()
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MiddlewareHookSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestMiddlewareHookSuite(t *testing.T) {
+	suite.Run(t, &MiddlewareHookSuite{})
+}
+
+func (s *MiddlewareHookSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *MiddlewareHookSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+type AfterResponseHookCapture struct {
+	Called  chan int
+	Context context.Context
+}
+
+func (c *AfterResponseHookCapture) Hook(ctx context.Context, r *http.Request, status int) {
+	c.Context = ctx
+	c.Called <- status
+}
+
+func (s *MiddlewareHookSuite) Test_AfterResponseMiddleware_Create() {
+	cases := map[string]struct {
+		body           string
+		createErr      error
+		expectedStatus int
+	}{
+		"Success": {
+			body:           `{"SomeString":"test"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		"Trash_Body": {
+			body:           "trash",
+			expectedStatus: http.StatusBadRequest,
+		},
+		"Create_Error": {
+			body:           `{"SomeString":"test"}`,
+			createErr:      errors.New("some-error"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			hookCapture := &AfterResponseHookCapture{Called: make(chan int, 1)}
+
+			s.request.Method = http.MethodPost
+			s.request.Body = io.NopCloser(bytes.NewBufferString(target.body))
+
+			createFunc := func(context.Context, string, string, TestCreateModel) error {
+				return target.createErr
+			}
+
+			testChain := alice.New(
+				turtleware.AfterResponseMiddleware(hookCapture.Hook),
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.ResourceCreateMiddleware(createFunc, turtleware.DefaultCreateErrorHandler),
+			).Then(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusCreated)
+			}))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedStatus, s.response.Code)
+			s.Require().Len(hookCapture.Called, 1)
+			s.Equal(target.expectedStatus, <-hookCapture.Called)
+		})
+	}
+}
+
+func (s *MiddlewareHookSuite) Test_AfterResponseMiddleware_Read() {
+	cases := map[string]struct {
+		fetchErr       error
+		expectedStatus int
+	}{
+		"Success": {
+			expectedStatus: http.StatusOK,
+		},
+		"Not_Found": {
+			fetchErr:       sql.ErrNoRows,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			hookCapture := &AfterResponseHookCapture{Called: make(chan int, 1)}
+
+			dataFetcherFunc := func(context.Context, string) (TestDataModel, error) {
+				return TestDataModel{}, target.fetchErr
+			}
+
+			testChain := alice.New(
+				turtleware.AfterResponseMiddleware(hookCapture.Hook),
+				s.buildEntityUUIDChain,
+			).Then(turtleware.ResourceDataHandler(dataFetcherFunc, turtleware.DefaultErrorHandler))
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedStatus, s.response.Code)
+			s.Require().Len(hookCapture.Called, 1)
+			s.Equal(target.expectedStatus, <-hookCapture.Called)
+		})
+	}
+}
+
+func (s *MiddlewareHookSuite) Test_AfterResponseMiddleware_ImplicitStatus() {
+	// given
+	hookCapture := &AfterResponseHookCapture{Called: make(chan int, 1)}
+
+	middleware := turtleware.AfterResponseMiddleware(hookCapture.Hook)
+
+	// when
+	middleware(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Require().Len(hookCapture.Called, 1)
+	s.Equal(http.StatusOK, <-hookCapture.Called)
+}
+
+func (s *MiddlewareHookSuite) Test_AfterResponseMiddleware_Detached() {
+	// given
+	hookCapture := &AfterResponseHookCapture{Called: make(chan int, 1)}
+
+	requestContext, cancel := context.WithCancel(s.request.Context())
+	s.request = s.request.WithContext(requestContext)
+
+	middleware := turtleware.AfterResponseMiddleware(hookCapture.Hook, turtleware.AfterResponseDetached(true))
+
+	// when
+	middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})).ServeHTTP(s.response, s.request)
+	cancel()
+
+	// then
+	select {
+	case status := <-hookCapture.Called:
+		s.Equal(http.StatusAccepted, status)
+		s.NoError(hookCapture.Context.Err())
+	case <-time.After(time.Second):
+		s.Fail("after response hook was not called")
+	}
+}
+
+func (s *MiddlewareHookSuite) Test_AfterResponseMiddleware_Detached_Panic() {
+	// given
+	called := make(chan struct{})
+
+	middleware := turtleware.AfterResponseMiddleware(func(context.Context, *http.Request, int) {
+		defer close(called)
+		panic("some-panic")
+	}, turtleware.AfterResponseDetached(true))
+
+	// when
+	middleware(&MiddlewareCapture{}).ServeHTTP(s.response, s.request)
+
+	// then
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		s.Fail("after response hook was not called")
+	}
+}
@@ -0,0 +1,93 @@
+package turtleware
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+
+	"context"
+	"net/http"
+	"sync/atomic"
+)
+
+type inFlightOptions struct {
+	meterProvider metric.MeterProvider
+	metricName    string
+}
+
+// InFlightOption represents an option for the InFlightGauge.
+type InFlightOption func(*inFlightOptions)
+
+// InFlightMeterProvider sets the MeterProvider used for exporting the in-flight metric.
+// The default is nil, which means otel.GetMeterProvider()
+func InFlightMeterProvider(meterProvider metric.MeterProvider) InFlightOption {
+	return func(c *inFlightOptions) {
+		c.meterProvider = meterProvider
+	}
+}
+
+// InFlightMetricName sets the name of the exported in-flight metric.
+// The default is "http.server.active_requests".
+func InFlightMetricName(metricName string) InFlightOption {
+	return func(c *inFlightOptions) {
+		c.metricName = metricName
+	}
+}
+
+// InFlightGauge keeps track of the number of requests currently in-flight,
+// and exports it as an otel gauge. This is useful as a signal for autoscaling.
+type InFlightGauge struct {
+	inFlight atomic.Int64
+}
+
+// NewInFlightGauge creates a new InFlightGauge, and registers its metric with
+// the configured MeterProvider.
+func NewInFlightGauge(opts ...InFlightOption) (*InFlightGauge, error) {
+	// default
+	config := &inFlightOptions{
+		meterProvider: nil,
+		metricName:    "http.server.active_requests",
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	meterProvider := config.meterProvider
+	if meterProvider == nil {
+		meterProvider = otel.GetMeterProvider()
+	}
+
+	gauge := &InFlightGauge{}
+
+	if _, err := meterProvider.Meter(TracerName).Int64ObservableGauge(
+		config.metricName,
+		metric.WithDescription("Number of requests currently in-flight."),
+		metric.WithUnit("{request}"),
+		metric.WithInt64Callback(func(_ context.Context, observer metric.Int64Observer) error {
+			observer.Observe(gauge.InFlight())
+
+			return nil
+		}),
+	); err != nil {
+		return nil, err
+	}
+
+	return gauge, nil
+}
+
+// InFlight returns the number of requests currently in-flight.
+func (gauge *InFlightGauge) InFlight() int64 {
+	return gauge.inFlight.Load()
+}
+
+// Middleware is a middleware for counting requests as in-flight, until the next
+// handler returns (or panics).
+func (gauge *InFlightGauge) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gauge.inFlight.Add(1)
+		defer gauge.inFlight.Add(-1)
+
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,64 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MiddlewareInFlightSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestMiddlewareInFlightSuite(t *testing.T) {
+	suite.Run(t, &MiddlewareInFlightSuite{})
+}
+
+func (s *MiddlewareInFlightSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *MiddlewareInFlightSuite) Test_InFlightGauge() {
+	// given
+	gauge, err := turtleware.NewInFlightGauge()
+	s.Require().NoError(err)
+
+	inFlightDuringRequest := int64(-1)
+	handler := gauge.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlightDuringRequest = gauge.InFlight()
+	}))
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(int64(1), inFlightDuringRequest)
+	s.Equal(int64(0), gauge.InFlight())
+}
+
+func (s *MiddlewareInFlightSuite) Test_InFlightGauge_Panic() {
+	// given
+	gauge, err := turtleware.NewInFlightGauge(turtleware.InFlightMetricName("some.metric"))
+	s.Require().NoError(err)
+
+	handler := gauge.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("some-panic")
+	}))
+
+	// when
+	s.Panics(func() {
+		handler.ServeHTTP(s.response, s.request)
+	})
+
+	// then
+	s.Equal(int64(0), gauge.InFlight())
+}
@@ -79,6 +79,20 @@ func (w *statusWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.status = 200
+	}
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 // RequestLoggerMiddleware is a http middleware for logging non-sensitive properties about the request.
 func RequestLoggerMiddleware(opts ...LoggingOption) func(next http.Handler) http.Handler {
 	// default
@@ -4,9 +4,10 @@ import (
 	"github.com/rs/zerolog"
 
 	"context"
-	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -22,11 +23,37 @@ var (
 
 	// ErrNoDateTimeLayoutMatched is returned when the If-Unmodified-Since header does not match any known date time layout.
 	ErrNoDateTimeLayoutMatched = errors.New("no date time layout matched")
+
+	// ErrMatchHeaderInvalid is returned when the If-Match header is not a single strong entity tag.
+	ErrMatchHeaderInvalid = errors.New("received If-Match header in invalid format")
 )
 
 // PatchFunc is a function called for delegating the actual updating of an existing resource.
 type PatchFunc[T PatchDTO] func(ctx context.Context, entityUUID, userUUID string, patch T, ifUnmodifiedSince time.Time) error
 
+// MergePatchFunc is a function called for delegating the actual updating of an existing resource
+// via a JSON Merge Patch (RFC 7386). The fields present in the merge patch document are passed
+// alongside the decoded patch, so fields explicitly set to null can be removed, and absent fields
+// can be left untouched.
+type MergePatchFunc[T PatchDTO] func(ctx context.Context, entityUUID, userUUID string, patch T, fields MergePatchFields, ifUnmodifiedSince time.Time) error
+
+// PatchPreconditions contains the preconditions of a patch request, which must be checked when
+// updating the resource. At least one of both is set.
+type PatchPreconditions struct {
+	// IfMatch is the entity tag of the If-Match header, without surrounding quotes, or "*".
+	// It is empty if the header is absent.
+	IfMatch string
+
+	// IfUnmodifiedSince is the time of the If-Unmodified-Since header. It is the zero time
+	// if the header is absent.
+	IfUnmodifiedSince time.Time
+}
+
+// ConditionalPatchFunc is a function called for delegating the actual updating of an existing resource.
+// In contrast to PatchFunc, it receives the If-Match entity tag alongside the If-Unmodified-Since time,
+// which allows a hash based compare-and-swap of the resource.
+type ConditionalPatchFunc[T PatchDTO] func(ctx context.Context, entityUUID, userUUID string, patch T, preconditions PatchPreconditions) error
+
 // PatchDTO defines the contract for validating a DTO used for patching a new resource.
 type PatchDTO interface {
 	HasChanges() bool
@@ -37,6 +64,7 @@ type PatchDTO interface {
 // handling for the given error, or if it defaults to handing it out as-is.
 func IsHandledByDefaultPatchErrorHandler(err error) bool {
 	return errors.Is(err, ErrUnmodifiedSinceHeaderInvalid) ||
+		errors.Is(err, ErrMatchHeaderInvalid) ||
 		errors.Is(err, ErrNoChanges) ||
 		errors.Is(err, ErrUnmodifiedSinceHeaderMissing) ||
 		IsHandledByDefaultErrorHandler(err)
@@ -44,7 +72,11 @@ func IsHandledByDefaultPatchErrorHandler(err error) bool {
 
 // DefaultPatchErrorHandler is a default error handler, which sensibly handles errors known by turtleware.
 func DefaultPatchErrorHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
-	if errors.Is(err, ErrUnmodifiedSinceHeaderInvalid) || errors.Is(err, ErrNoChanges) {
+	err = withErrorCode(err)
+
+	if errors.Is(err, ErrUnmodifiedSinceHeaderInvalid) ||
+		errors.Is(err, ErrMatchHeaderInvalid) ||
+		errors.Is(err, ErrNoChanges) {
 		WriteError(ctx, w, r, http.StatusBadRequest, err)
 		return
 	}
@@ -60,7 +92,70 @@ func DefaultPatchErrorHandler(ctx context.Context, w http.ResponseWriter, r *htt
 // ResourcePatchMiddleware is a middleware for patching or updating an existing resource.
 // It parses a PatchDTO from the request body, validates it, and then calls the provided PatchFunc.
 // Errors encountered during the process are passed to the provided ErrorHandlerFunc.
-func ResourcePatchMiddleware[T PatchDTO](patchFunc PatchFunc[T], errorHandler ErrorHandlerFunc) func(http.Handler) http.Handler {
+// The request body is decoded via DecodeBody, using the provided options.
+// If the PatchFunc returns an AcceptedError (see Accepted), a 202 Accepted is written
+// instead of calling the next handler.
+func ResourcePatchMiddleware[T PatchDTO](patchFunc PatchFunc[T], errorHandler ErrorHandlerFunc, opts ...BodyOption) func(http.Handler) http.Handler {
+	return resourcePatchMiddleware(
+		func(r *http.Request) (T, MergePatchFields, error) {
+			patch, err := DecodeBody[T](r, opts...)
+			return patch, nil, err
+		},
+		getIfUnmodifiedSincePreconditions,
+		func(ctx context.Context, entityUUID, userUUID string, patch T, _ MergePatchFields, preconditions PatchPreconditions) error {
+			return patchFunc(ctx, entityUUID, userUUID, patch, preconditions.IfUnmodifiedSince)
+		},
+		errorHandler,
+	)
+}
+
+// ResourceConditionalPatchMiddleware is a middleware for patching or updating an existing resource.
+// It works like ResourcePatchMiddleware, but additionally accepts an If-Match header with an
+// entity tag as precondition (see GetPatchPreconditions), which is passed to the provided
+// ConditionalPatchFunc. Unlike If-Unmodified-Since, entity tags are not limited to a resolution
+// of one second.
+func ResourceConditionalPatchMiddleware[T PatchDTO](patchFunc ConditionalPatchFunc[T], errorHandler ErrorHandlerFunc, opts ...BodyOption) func(http.Handler) http.Handler {
+	return resourcePatchMiddleware(
+		func(r *http.Request) (T, MergePatchFields, error) {
+			patch, err := DecodeBody[T](r, opts...)
+			return patch, nil, err
+		},
+		GetPatchPreconditions,
+		func(ctx context.Context, entityUUID, userUUID string, patch T, _ MergePatchFields, preconditions PatchPreconditions) error {
+			return patchFunc(ctx, entityUUID, userUUID, patch, preconditions)
+		},
+		errorHandler,
+	)
+}
+
+// ResourceMergePatchMiddleware is a middleware for patching an existing resource via a
+// JSON Merge Patch (RFC 7386). It works like ResourcePatchMiddleware, but decodes the request
+// body via DecodeMergePatchBody, and passes the fields present in the body to the provided
+// MergePatchFunc. This allows clients to explicitly null out fields, which is not possible
+// with ResourcePatchMiddleware.
+// Fields explicitly set to null are considered changes, even if the HasChanges method of the
+// PatchDTO reports otherwise. A body without any fields results in ErrNoChanges.
+func ResourceMergePatchMiddleware[T PatchDTO](patchFunc MergePatchFunc[T], errorHandler ErrorHandlerFunc, opts ...BodyOption) func(http.Handler) http.Handler {
+	return resourcePatchMiddleware(
+		func(r *http.Request) (T, MergePatchFields, error) {
+			return DecodeMergePatchBody[T](r, opts...)
+		},
+		getIfUnmodifiedSincePreconditions,
+		func(ctx context.Context, entityUUID, userUUID string, patch T, fields MergePatchFields, preconditions PatchPreconditions) error {
+			return patchFunc(ctx, entityUUID, userUUID, patch, fields, preconditions.IfUnmodifiedSince)
+		},
+		errorHandler,
+	)
+}
+
+func resourcePatchMiddleware[T PatchDTO](
+	decodeFunc func(r *http.Request) (T, MergePatchFields, error),
+	preconditionsFunc func(r *http.Request) (PatchPreconditions, error),
+	patchFunc func(ctx context.Context, entityUUID, userUUID string, patch T, fields MergePatchFields, preconditions PatchPreconditions) error,
+	errorHandler ErrorHandlerFunc,
+) func(http.Handler) http.Handler {
+	errorHandler = RecoverErrorHandler(errorHandler)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			patchContext, cancel := context.WithCancel(r.Context())
@@ -82,13 +177,13 @@ func ResourcePatchMiddleware[T PatchDTO](patchFunc PatchFunc[T], errorHandler Er
 
 			// ----------------
 
-			var patch T
-			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
-				errorHandler(patchContext, w, r, ErrMarshalling)
+			patch, fields, err := decodeFunc(r)
+			if err != nil {
+				errorHandler(patchContext, w, r, err)
 				return
 			}
 
-			if !patch.HasChanges() {
+			if !HasPatchChanges(patch, fields) {
 				errorHandler(patchContext, w, r, ErrNoChanges)
 				return
 			}
@@ -98,13 +193,19 @@ func ResourcePatchMiddleware[T PatchDTO](patchFunc PatchFunc[T], errorHandler Er
 				return
 			}
 
-			ifUnmodifiedSince, err := GetIfUnmodifiedSince(r)
+			preconditions, err := preconditionsFunc(r)
 			if err != nil {
 				errorHandler(patchContext, w, r, err)
 				return
 			}
 
-			if err := patchFunc(patchContext, entityUUID, userUUID, patch, ifUnmodifiedSince); err != nil {
+			if err := patchFunc(patchContext, entityUUID, userUUID, patch, fields, preconditions); err != nil {
+				var acceptedError *AcceptedError
+				if errors.As(err, &acceptedError) {
+					WriteAccepted(patchContext, w, acceptedError.StatusLocation)
+					return
+				}
+
 				logger.Error().Err(err).Msg("Patch failed")
 				errorHandler(patchContext, w, r, err)
 				return
@@ -117,6 +218,22 @@ func ResourcePatchMiddleware[T PatchDTO](patchFunc PatchFunc[T], errorHandler Er
 	}
 }
 
+// HasPatchChanges reports whether the given patch contains any changes. If merge patch fields
+// are given (see DecodeMergePatchBody), fields explicitly set to null are considered changes,
+// and an empty merge patch document never contains changes. Otherwise, the HasChanges method
+// of the patch decides.
+func HasPatchChanges[T PatchDTO](patch T, fields MergePatchFields) bool {
+	if fields == nil {
+		return patch.HasChanges()
+	}
+
+	if len(fields) == 0 {
+		return false
+	}
+
+	return fields.HasNulls() || patch.HasChanges()
+}
+
 // GetIfUnmodifiedSince tries to parse a time.Time from the If-Unmodified-Since header of
 // a given request. It tries the following formats (in that order):
 //
@@ -137,6 +254,57 @@ func GetIfUnmodifiedSince(r *http.Request) (time.Time, error) {
 	return ifUnmodifiedSince, nil
 }
 
+// GetIfMatch parses the If-Match header of a given request. The entity tag is returned without
+// its surrounding quotes, or as "*" for the wildcard. If the header is absent, an empty string is
+// returned. As If-Match requires a strong comparison (see RFC 7232, section 3.1), weak entity tags
+// are rejected with ErrMatchHeaderInvalid, as well as lists of multiple entity tags.
+func GetIfMatch(r *http.Request) (string, error) {
+	ifMatch := strings.TrimSpace(strings.Join(r.Header.Values("If-Match"), ", "))
+	if ifMatch == "" || ifMatch == "*" {
+		return ifMatch, nil
+	}
+
+	if len(ifMatch) < 2 || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		return "", fmt.Errorf("%w: %s is no single strong entity tag", ErrMatchHeaderInvalid, ifMatch)
+	}
+
+	etag := ifMatch[1 : len(ifMatch)-1]
+	if strings.Contains(etag, `"`) {
+		return "", fmt.Errorf("%w: %s is no single strong entity tag", ErrMatchHeaderInvalid, ifMatch)
+	}
+
+	return etag, nil
+}
+
+// GetPatchPreconditions parses the If-Match (see GetIfMatch) and If-Unmodified-Since (see
+// GetIfUnmodifiedSince) headers of a given request. If neither header is present,
+// ErrUnmodifiedSinceHeaderMissing is returned.
+func GetPatchPreconditions(r *http.Request) (PatchPreconditions, error) {
+	ifMatch, err := GetIfMatch(r)
+	if err != nil {
+		return PatchPreconditions{}, err
+	}
+
+	ifUnmodifiedSince, err := GetIfUnmodifiedSince(r)
+	if err != nil && (ifMatch == "" || !errors.Is(err, ErrUnmodifiedSinceHeaderMissing)) {
+		return PatchPreconditions{}, err
+	}
+
+	return PatchPreconditions{
+		IfMatch:           ifMatch,
+		IfUnmodifiedSince: ifUnmodifiedSince,
+	}, nil
+}
+
+func getIfUnmodifiedSincePreconditions(r *http.Request) (PatchPreconditions, error) {
+	ifUnmodifiedSince, err := GetIfUnmodifiedSince(r)
+	if err != nil {
+		return PatchPreconditions{}, err
+	}
+
+	return PatchPreconditions{IfUnmodifiedSince: ifUnmodifiedSince}, nil
+}
+
 func parseTimeByFormats(value string, layouts ...string) (time.Time, error) {
 	for _, layout := range layouts {
 		if parsedValue, err := time.Parse(layout, value); err == nil {
@@ -70,6 +70,11 @@ func (s *MiddlewarePatchSuite) Test_DefaultPatchErrorHandler_Handled() {
 			goldenFile: "error_errunmodifiedsinceheaderinvalid.json",
 			statusCode: http.StatusBadRequest,
 		},
+		"ErrMatchHeaderInvalid": {
+			err:        turtleware.ErrMatchHeaderInvalid,
+			goldenFile: "error_errmatchheaderinvalid.json",
+			statusCode: http.StatusBadRequest,
+		},
 		"ErrNoChanges": {
 			err:        turtleware.ErrNoChanges,
 			goldenFile: "error_errnochanges.json",
@@ -285,6 +290,40 @@ func (s *MiddlewarePatchSuite) Test_ResourcePatchMiddleware_Handle_Err() {
 	s.ErrorIs(errorCapture.CapturedError, targetError)
 }
 
+func (s *MiddlewarePatchSuite) Test_ResourcePatchMiddleware_Accepted() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+	testTime := time.Now().UTC()
+	model := TestPatchModel{
+		SomeString:     "test",
+		HasSomeChanges: true,
+	}
+
+	s.request.Body = s.patchModelBodyReader(model)
+	s.request.Header.Set("If-Unmodified-Since", testTime.Format(time.RFC3339Nano))
+
+	patchHandlerFunc := func(context.Context, string, string, TestPatchModel, time.Time) error {
+		return turtleware.Accepted("/jobs/some-job")
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourcePatchMiddleware(patchHandlerFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusAccepted, s.response.Code)
+	s.Equal("/jobs/some-job", s.response.Header().Get("Location"))
+	s.Equal("/jobs/some-job", s.response.Header().Get("Content-Location"))
+}
+
 func (s *MiddlewarePatchSuite) Test_ResourcePatchMiddleware_Success() {
 	// given
 	nextCapture := &MiddlewareCapture{}
@@ -323,6 +362,118 @@ func (s *MiddlewarePatchSuite) Test_ResourcePatchMiddleware_Success() {
 	s.True(patchHandlerFuncWasCalled)
 }
 
+func (s *MiddlewarePatchSuite) Test_ResourceConditionalPatchMiddleware() {
+	testTime := time.Now().UTC()
+
+	cases := map[string]struct {
+		ifMatch               string
+		ifUnmodifiedSince     string
+		expectedPreconditions turtleware.PatchPreconditions
+		expectedErr           error
+	}{
+		"If_Match": {
+			ifMatch:               `"some-hash"`,
+			expectedPreconditions: turtleware.PatchPreconditions{IfMatch: "some-hash"},
+		},
+		"If_Unmodified_Since": {
+			ifUnmodifiedSince:     testTime.Format(time.RFC3339Nano),
+			expectedPreconditions: turtleware.PatchPreconditions{IfUnmodifiedSince: testTime},
+		},
+		"Both": {
+			ifMatch:           `"some-hash"`,
+			ifUnmodifiedSince: testTime.Format(time.RFC3339Nano),
+			expectedPreconditions: turtleware.PatchPreconditions{
+				IfMatch:           "some-hash",
+				IfUnmodifiedSince: testTime,
+			},
+		},
+		"Neither": {
+			expectedErr: turtleware.ErrUnmodifiedSinceHeaderMissing,
+		},
+		"If_Match_Invalid": {
+			ifMatch:     "some-hash",
+			expectedErr: turtleware.ErrMatchHeaderInvalid,
+		},
+		"If_Unmodified_Since_Invalid": {
+			ifMatch:           `"some-hash"`,
+			ifUnmodifiedSince: "trash",
+			expectedErr:       turtleware.ErrUnmodifiedSinceHeaderInvalid,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+			model := TestPatchModel{
+				SomeString:     "test",
+				HasSomeChanges: true,
+			}
+
+			s.request.Body = s.patchModelBodyReader(model)
+			if target.ifMatch != "" {
+				s.request.Header.Set("If-Match", target.ifMatch)
+			}
+			if target.ifUnmodifiedSince != "" {
+				s.request.Header.Set("If-Unmodified-Since", target.ifUnmodifiedSince)
+			}
+
+			patchHandlerFuncWasCalled := false
+			patchHandlerFunc := func(ctx context.Context, entityUUID, userUUID string, patch TestPatchModel, preconditions turtleware.PatchPreconditions) error {
+				patchHandlerFuncWasCalled = true
+				s.Equal(s.entityUUID, entityUUID)
+				s.Equal(s.userUUID, userUUID)
+				s.Equal(model, patch)
+				s.Equal(target.expectedPreconditions, preconditions)
+				return nil
+			}
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.ResourceConditionalPatchMiddleware(patchHandlerFunc, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			if target.expectedErr != nil {
+				s.False(nextCapture.Called)
+				s.False(patchHandlerFuncWasCalled)
+				s.ErrorIs(errorCapture.CapturedError, target.expectedErr)
+			} else {
+				s.True(nextCapture.Called)
+				s.True(patchHandlerFuncWasCalled)
+				s.NoError(errorCapture.CapturedError)
+			}
+		})
+	}
+}
+
+func (s *MiddlewarePatchSuite) Test_ResourcePatchMiddleware_IfMatchOnly() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = s.patchModelBodyReader(TestPatchModel{HasSomeChanges: true})
+	s.request.Header.Set("If-Match", `"some-hash"`)
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourcePatchMiddleware[TestPatchModel](nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrUnmodifiedSinceHeaderMissing)
+}
+
 func (s *MiddlewarePatchSuite) patchModelBodyReader(model turtleware.PatchDTO) io.ReadCloser {
 	pr, pw := io.Pipe()
 	encoder := json.NewEncoder(pw)
@@ -332,6 +483,58 @@ func (s *MiddlewarePatchSuite) patchModelBodyReader(model turtleware.PatchDTO) i
 	return pr
 }
 
+func (s *MiddlewarePatchSuite) Test_GetIfMatch() {
+	cases := map[string]struct {
+		ifMatch  []string
+		expected string
+	}{
+		"No_Header": {},
+		"Strong":    {ifMatch: []string{`"some-hash"`}, expected: "some-hash"},
+		"Spaced":    {ifMatch: []string{` "some-hash" `}, expected: "some-hash"},
+		"Wildcard":  {ifMatch: []string{"*"}, expected: "*"},
+		"Encoded":   {ifMatch: []string{`"some-hash-deflate"`}, expected: "some-hash-deflate"},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			for _, ifMatch := range target.ifMatch {
+				s.request.Header.Add("If-Match", ifMatch)
+			}
+
+			// when
+			etag, err := turtleware.GetIfMatch(s.request)
+
+			// then
+			s.NoError(err)
+			s.Equal(target.expected, etag)
+		})
+	}
+
+	invalid := map[string][]string{
+		"Unquoted":          {"some-hash"},
+		"Weak":              {`W/"some-hash"`},
+		"List":              {`"some-hash", "other-hash"`},
+		"Multiple_Headers":  {`"some-hash"`, `"other-hash"`},
+		"Single_Quote_Only": {`"`},
+	}
+
+	for testName, ifMatches := range invalid {
+		s.Run("Invalid_"+testName, func() {
+			// given
+			for _, ifMatch := range ifMatches {
+				s.request.Header.Add("If-Match", ifMatch)
+			}
+
+			// when
+			_, err := turtleware.GetIfMatch(s.request)
+
+			// then
+			s.ErrorIs(err, turtleware.ErrMatchHeaderInvalid)
+		})
+	}
+}
+
 func (s *MiddlewarePatchSuite) Test_GetIfUnmodifiedSince_NoHeader() {
 	// given
 	// -
@@ -389,3 +592,163 @@ func (s *MiddlewarePatchSuite) Test_GetIfUnmodifiedSince_Matches() {
 		})
 	}
 }
+
+func (s *MiddlewarePatchSuite) Test_ResourceMergePatchMiddleware_TrashBody() {
+	cases := map[string]string{
+		"Trash":  "trash",
+		"Array":  `[{"SomeString":"test"}]`,
+		"Null":   "null",
+		"Scalar": `"test"`,
+	}
+
+	for testName, body := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			s.request.Body = io.NopCloser(bytes.NewBufferString(body))
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.ResourceMergePatchMiddleware[TestPatchModel](nil, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.ErrorIs(errorCapture.CapturedError, turtleware.ErrMarshalling)
+		})
+	}
+}
+
+func (s *MiddlewarePatchSuite) Test_ResourceMergePatchMiddleware_NoChanges() {
+	cases := map[string]string{
+		"Empty":      `{}`,
+		"No_Changes": `{"SomeString":"test"}`,
+	}
+
+	for testName, body := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+
+			s.request.Body = io.NopCloser(bytes.NewBufferString(body))
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.ResourceMergePatchMiddleware[TestPatchModel](nil, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.ErrorIs(errorCapture.CapturedError, turtleware.ErrNoChanges)
+		})
+	}
+}
+
+func (s *MiddlewarePatchSuite) Test_ResourceMergePatchMiddleware_ValidationError() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	s.request.Body = io.NopCloser(bytes.NewBufferString(`{"HasSomeChanges":true,"ValidationError":true}`))
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.ResourceMergePatchMiddleware[TestPatchModel](nil, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorAs(errorCapture.CapturedError, &turtleware.ValidationWrapperError{})
+	s.ErrorIs(errorCapture.CapturedError, ErrTestPatchModelTest)
+}
+
+func (s *MiddlewarePatchSuite) Test_ResourceMergePatchMiddleware_Success() {
+	cases := map[string]struct {
+		body           string
+		expectedPatch  TestPatchModel
+		expectedFields turtleware.MergePatchFields
+	}{
+		"Changes": {
+			body:           `{"SomeString":"test","HasSomeChanges":true}`,
+			expectedPatch:  TestPatchModel{SomeString: "test", HasSomeChanges: true},
+			expectedFields: turtleware.MergePatchFields{"SomeString": false, "HasSomeChanges": false},
+		},
+		"Explicit_Null": {
+			body:           `{"SomeString":null}`,
+			expectedPatch:  TestPatchModel{},
+			expectedFields: turtleware.MergePatchFields{"SomeString": true},
+		},
+		"Zero_Value": {
+			body:           `{"SomeString":"","HasSomeChanges":true}`,
+			expectedPatch:  TestPatchModel{HasSomeChanges: true},
+			expectedFields: turtleware.MergePatchFields{"SomeString": false, "HasSomeChanges": false},
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			errorCapture := &ErrorHandlerCapture{}
+			testTime := time.Now().UTC()
+
+			s.request.Body = io.NopCloser(bytes.NewBufferString(target.body))
+			s.request.Header.Set("If-Unmodified-Since", testTime.Format(time.RFC3339Nano))
+
+			patchHandlerFuncWasCalled := false
+			patchHandlerFunc := func(ctx context.Context, entityUUID, userUUID string, patch TestPatchModel, fields turtleware.MergePatchFields, ifUnmodifiedSince time.Time) error {
+				patchHandlerFuncWasCalled = true
+				s.Equal(s.entityUUID, entityUUID)
+				s.Equal(s.userUUID, userUUID)
+				s.Equal(target.expectedPatch, patch)
+				s.Equal(target.expectedFields, fields)
+				s.Equal(testTime, ifUnmodifiedSince)
+				return nil
+			}
+
+			testChain := alice.New(
+				s.buildAuthChain,
+				s.buildEntityUUIDChain,
+				turtleware.ResourceMergePatchMiddleware(patchHandlerFunc, errorCapture.Capture),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.True(nextCapture.Called)
+			s.NoError(errorCapture.CapturedError)
+			s.True(patchHandlerFuncWasCalled)
+		})
+	}
+}
+
+func (s *MiddlewarePatchSuite) Test_MergePatchFields() {
+	// given
+	fields := turtleware.MergePatchFields{"present": false, "removed": true}
+
+	// then
+	s.True(fields.Has("present"))
+	s.True(fields.Has("removed"))
+	s.False(fields.Has("absent"))
+	s.False(fields.IsNull("present"))
+	s.True(fields.IsNull("removed"))
+	s.False(fields.IsNull("absent"))
+	s.True(fields.HasNulls())
+	s.False(turtleware.MergePatchFields{"present": false}.HasNulls())
+}
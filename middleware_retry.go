@@ -0,0 +1,157 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// RetryableFunc is a function for classifying if a given error is retryable.
+// A typical example are serialization failures of serializable transactions.
+type RetryableFunc func(err error) bool
+
+type retryOptions struct {
+	maxBodyBytes int64
+}
+
+// RetryOption represents an option for the RetryMiddleware.
+type RetryOption func(*retryOptions)
+
+// RetryMaxBodyBytes sets the maximum size of request bodies in bytes, enforced via
+// http.MaxBytesReader, as the body is buffered completely for retries. Larger bodies
+// are rejected with ErrMarshalling.
+// The default is 1 MiB.
+func RetryMaxBodyBytes(maxBodyBytes int64) RetryOption {
+	return func(c *retryOptions) {
+		c.maxBodyBytes = maxBodyBytes
+	}
+}
+
+type retryState struct {
+	retryable RetryableFunc
+	remaining uint
+	retry     bool
+	err       error
+	writer    *retryWriter
+}
+
+// RetryMiddleware is a middleware for retrying the next handler up to maxRetries times,
+// if an error deemed retryable by the provided RetryableFunc is encountered.
+// The error is detected via the ErrorHandlerFunc of the turtleware middlewares
+// further down the chain, and is classified as it is passed to the ErrorHandlerFunc. Errors
+// mapped by the data handlers (e.g. to ErrReceivingResults) keep the original error, so
+// errors.Is and errors.As based classification works for them, too.
+// Attempts which already wrote (parts of) the response are never retried, and their error
+// is handled as usual.
+// The request body is buffered (up to the limit of RetryMaxBodyBytes), and provided anew
+// for each attempt.
+func RetryMiddleware(maxRetries uint, retryable RetryableFunc, opts ...RetryOption) func(http.Handler) http.Handler {
+	// default
+	config := &retryOptions{
+		maxBodyBytes: 1 << 20,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := zerolog.Ctx(r.Context())
+
+			var body []byte
+			if r.Body != nil && r.Body != http.NoBody {
+				var err error
+				body, err = io.ReadAll(http.MaxBytesReader(nil, r.Body, config.maxBodyBytes))
+				if err != nil {
+					logger.Error().Err(err).Msg("Failed to buffer request body for retries")
+					WriteError(r.Context(), w, r, http.StatusBadRequest, bodyError(err))
+
+					return
+				}
+			}
+
+			writer := &retryWriter{ResponseWriter: w}
+
+			state := &retryState{
+				retryable: retryable,
+				remaining: maxRetries,
+				writer:    writer,
+			}
+
+			retryContext := context.WithValue(r.Context(), ctxRetryState, state)
+
+			for attempt := uint(1); ; attempt++ {
+				state.retry = false
+
+				attemptRequest := r.WithContext(retryContext)
+				if body != nil {
+					attemptRequest.Body = io.NopCloser(bytes.NewReader(body))
+				}
+
+				next.ServeHTTP(writer, attemptRequest)
+
+				if !state.retry {
+					return
+				}
+
+				logger.Warn().Err(state.err).Msgf("Retrying request after failed attempt %d", attempt)
+			}
+		})
+	}
+}
+
+// scheduleRetry checks if a RetryMiddleware is present in the given context, if nothing
+// of the response was written yet, and if the given error is retryable. If so, a retry
+// is scheduled, and true is returned.
+func scheduleRetry(ctx context.Context, err error) bool {
+	state, ok := ctx.Value(ctxRetryState).(*retryState)
+	if !ok || state.remaining == 0 || state.writer.written || !state.retryable(err) {
+		return false
+	}
+
+	state.remaining--
+	state.retry = true
+	state.err = err
+
+	return true
+}
+
+// retryWriter is a wrapper for a http.ResponseWriter, which tracks if anything of the
+// response was written, so attempts with partially written responses are not retried.
+type retryWriter struct {
+	http.ResponseWriter
+
+	written bool
+}
+
+func (w *retryWriter) WriteHeader(statusCode int) {
+	// Informational responses are followed by the actual response
+	if statusCode >= http.StatusOK {
+		w.written = true
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *retryWriter) Write(b []byte) (int, error) {
+	w.written = true
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *retryWriter) Flush() {
+	w.written = true
+
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *retryWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,247 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type MiddlewareRetrySuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+var ErrTestSerializationFailure = errors.New("serialization failure")
+
+func TestMiddlewareRetrySuite(t *testing.T) {
+	suite.Run(t, &MiddlewareRetrySuite{})
+}
+
+func (s *MiddlewareRetrySuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(
+		http.MethodPost,
+		"https://example.com/foo",
+		bytes.NewBufferString(`{"SomeString":"test"}`),
+	)
+}
+
+func (s *MiddlewareRetrySuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func isTestSerializationFailure(err error) bool {
+	return errors.Is(err, ErrTestSerializationFailure)
+}
+
+func (s *MiddlewareRetrySuite) Test_RetryMiddleware_Success_After_Retries() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	var received []TestCreateModel
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) error {
+		received = append(received, create)
+		if len(received) <= 2 {
+			return ErrTestSerializationFailure
+		}
+
+		return nil
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.RetryMiddleware(3, isTestSerializationFailure),
+		turtleware.ResourceCreateMiddleware(createHandlerFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.NoError(errorCapture.CapturedError)
+
+	s.Require().Len(received, 3)
+	for _, create := range received {
+		s.Equal("test", create.SomeString)
+	}
+}
+
+func (s *MiddlewareRetrySuite) Test_RetryMiddleware_Retries_Exhausted() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	attempts := 0
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) error {
+		attempts++
+
+		return ErrTestSerializationFailure
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.RetryMiddleware(2, isTestSerializationFailure),
+		turtleware.ResourceCreateMiddleware(createHandlerFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, ErrTestSerializationFailure)
+	s.Equal(3, attempts)
+}
+
+func (s *MiddlewareRetrySuite) Test_RetryMiddleware_Not_Retryable() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+	errorCapture := &ErrorHandlerCapture{}
+
+	targetError := errors.New("some-error")
+
+	attempts := 0
+	createHandlerFunc := func(
+		ctx context.Context,
+		entityUUID,
+		userUUID string,
+		create TestCreateModel,
+	) error {
+		attempts++
+
+		return targetError
+	}
+
+	testChain := alice.New(
+		s.buildAuthChain,
+		s.buildEntityUUIDChain,
+		turtleware.RetryMiddleware(2, isTestSerializationFailure),
+		turtleware.ResourceCreateMiddleware(createHandlerFunc, errorCapture.Capture),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.ErrorIs(errorCapture.CapturedError, targetError)
+	s.Equal(1, attempts)
+}
+
+func (s *MiddlewareRetrySuite) Test_RetryMiddleware_DataHandler() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	attempts := 0
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		attempts++
+		if attempts <= 2 {
+			return nil, ErrTestSerializationFailure
+		}
+
+		return []TestDataModel{{SomeString: "test"}}, nil
+	}
+
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+
+	testChain := alice.New(
+		turtleware.RetryMiddleware(3, isTestSerializationFailure),
+		turtleware.PagingMiddleware,
+	).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(3, attempts)
+	s.Equal(http.StatusOK, s.response.Code)
+}
+
+func (s *MiddlewareRetrySuite) Test_RetryMiddleware_DataHandler_Exhausted() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	dataFetcherFunc := func(ctx context.Context, paging turtleware.Paging) ([]TestDataModel, error) {
+		return nil, ErrTestSerializationFailure
+	}
+
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+
+	testChain := alice.New(
+		turtleware.RetryMiddleware(1, isTestSerializationFailure),
+		turtleware.PagingMiddleware,
+	).Then(turtleware.StaticListDataHandler(dataFetcherFunc, errorCapture.Capture))
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.ErrorIs(errorCapture.CapturedError, turtleware.ErrReceivingResults)
+	s.ErrorIs(errorCapture.CapturedError, ErrTestSerializationFailure)
+	s.Equal(turtleware.ErrReceivingResults.Error(), errorCapture.CapturedError.Error())
+}
+
+func (s *MiddlewareRetrySuite) Test_RetryMiddleware_Partially_Written() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	attempts := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		_, err := w.Write([]byte("partial;"))
+		s.Require().NoError(err)
+
+		turtleware.RecoverErrorHandler(errorCapture.Capture)(r.Context(), w, r, ErrTestSerializationFailure)
+	})
+
+	// when
+	turtleware.RetryMiddleware(2, isTestSerializationFailure)(handler).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(1, attempts)
+	s.ErrorIs(errorCapture.CapturedError, ErrTestSerializationFailure)
+	s.Equal("partial;", s.response.Body.String())
+}
+
+func (s *MiddlewareRetrySuite) Test_RetryMiddleware_MaxBodyBytes() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	// when
+	turtleware.RetryMiddleware(
+		2,
+		isTestSerializationFailure,
+		turtleware.RetryMaxBodyBytes(4),
+	)(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusBadRequest, s.response.Code)
+}
@@ -0,0 +1,101 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var (
+	// ErrInvalidClientTimeout indicates that the client supplied timeout
+	// header was in an invalid format (e.g. non-numeric).
+	ErrInvalidClientTimeout = errors.New("invalid client timeout")
+
+	// ErrClientTimeoutExceeded indicates that the client supplied timeout
+	// exceeds the maximum timeout allowed by the server.
+	ErrClientTimeoutExceeded = errors.New("client timeout exceeds maximum timeout")
+)
+
+type clientTimeoutOptions struct {
+	headerName      string
+	rejectExceeding bool
+}
+
+// ClientTimeoutOption represents an option for the ClientTimeoutMiddleware.
+type ClientTimeoutOption func(*clientTimeoutOptions)
+
+// ClientTimeoutHeader sets the name of the header, which carries the client supplied
+// timeout in milliseconds.
+// The default is X-Timeout-Ms.
+func ClientTimeoutHeader(headerName string) ClientTimeoutOption {
+	return func(c *clientTimeoutOptions) {
+		c.headerName = headerName
+	}
+}
+
+// ClientTimeoutRejectExceeding sets whether client supplied timeouts exceeding the maximum
+// timeout are rejected with ErrClientTimeoutExceeded, instead of being clamped to the maximum.
+// The default is false.
+func ClientTimeoutRejectExceeding(rejectExceeding bool) ClientTimeoutOption {
+	return func(c *clientTimeoutOptions) {
+		c.rejectExceeding = rejectExceeding
+	}
+}
+
+// ClientTimeoutMiddleware is a http middleware for applying a client supplied timeout
+// (in milliseconds, via the X-Timeout-Ms header by default) as the deadline of the request
+// context. This allows clients to opt into shorter timeouts. The timeout is bounded by the
+// given maxTimeout. If the client does not supply a timeout, the request context is left as-is.
+func ClientTimeoutMiddleware(maxTimeout time.Duration, opts ...ClientTimeoutOption) func(http.Handler) http.Handler {
+	// default
+	config := &clientTimeoutOptions{
+		headerName:      "X-Timeout-Ms",
+		rejectExceeding: false,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(config.headerName)
+			if header == "" {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			millis, err := strconv.ParseUint(header, 10, 32)
+			if err != nil || millis == 0 {
+				WriteError(r.Context(), w, r, http.StatusBadRequest, fmt.Errorf("%w: %s", ErrInvalidClientTimeout, header))
+
+				return
+			}
+
+			timeout := time.Duration(millis) * time.Millisecond
+			if timeout > maxTimeout {
+				if config.rejectExceeding {
+					WriteError(r.Context(), w, r, http.StatusBadRequest, fmt.Errorf("%w: %s", ErrClientTimeoutExceeded, maxTimeout))
+
+					return
+				}
+
+				timeout = maxTimeout
+			}
+
+			zerolog.Ctx(r.Context()).Trace().Msgf("Applying client timeout of %s", timeout)
+
+			timeoutContext, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			h.ServeHTTP(w, r.WithContext(timeoutContext))
+		})
+	}
+}
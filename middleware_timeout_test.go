@@ -0,0 +1,127 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MiddlewareTimeoutSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestMiddlewareTimeoutSuite(t *testing.T) {
+	suite.Run(t, &MiddlewareTimeoutSuite{})
+}
+
+func (s *MiddlewareTimeoutSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *MiddlewareTimeoutSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *MiddlewareTimeoutSuite) recordDeadline(deadline *time.Time, hasDeadline *bool) http.Handler {
+	return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		*deadline, *hasDeadline = r.Context().Deadline()
+	})
+}
+
+func (s *MiddlewareTimeoutSuite) Test_ClientTimeoutMiddleware_Applied() {
+	// given
+	var (
+		deadline    time.Time
+		hasDeadline bool
+	)
+
+	s.request.Header.Set("X-Timeout-Ms", "500")
+
+	// when
+	start := time.Now()
+	turtleware.ClientTimeoutMiddleware(5*time.Second)(s.recordDeadline(&deadline, &hasDeadline)).ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(hasDeadline)
+	s.WithinDuration(start.Add(500*time.Millisecond), deadline, 100*time.Millisecond)
+}
+
+func (s *MiddlewareTimeoutSuite) Test_ClientTimeoutMiddleware_Clamped() {
+	// given
+	var (
+		deadline    time.Time
+		hasDeadline bool
+	)
+
+	s.request.Header.Set("X-Timeout-Ms", "60000")
+
+	// when
+	start := time.Now()
+	turtleware.ClientTimeoutMiddleware(time.Second)(s.recordDeadline(&deadline, &hasDeadline)).ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(hasDeadline)
+	s.WithinDuration(start.Add(time.Second), deadline, 100*time.Millisecond)
+}
+
+func (s *MiddlewareTimeoutSuite) Test_ClientTimeoutMiddleware_Rejected() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	s.request.Header.Set("X-Timeout-Ms", "60000")
+
+	middleware := turtleware.ClientTimeoutMiddleware(
+		time.Second,
+		turtleware.ClientTimeoutRejectExceeding(true),
+	)
+
+	// when
+	middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusBadRequest, s.response.Code)
+}
+
+func (s *MiddlewareTimeoutSuite) Test_ClientTimeoutMiddleware_Invalid() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	s.request.Header.Set("X-Request-Timeout", "kaese")
+
+	middleware := turtleware.ClientTimeoutMiddleware(
+		time.Second,
+		turtleware.ClientTimeoutHeader("X-Request-Timeout"),
+	)
+
+	// when
+	middleware(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusBadRequest, s.response.Code)
+}
+
+func (s *MiddlewareTimeoutSuite) Test_ClientTimeoutMiddleware_Missing() {
+	// given
+	var (
+		deadline    time.Time
+		hasDeadline bool
+	)
+
+	// when
+	turtleware.ClientTimeoutMiddleware(time.Second)(s.recordDeadline(&deadline, &hasDeadline)).ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(hasDeadline)
+}
@@ -0,0 +1,128 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type openAPIOptions struct {
+	contentType string
+	maxAge      time.Duration
+}
+
+// OpenAPIOption represents an option for the OpenAPI document handler.
+type OpenAPIOption func(*openAPIOptions)
+
+// OpenAPIContentType sets the content type the document is served with.
+// The default is empty, which means the content type is guessed from the
+// document (application/json for JSON documents, application/yaml otherwise).
+func OpenAPIContentType(contentType string) OpenAPIOption {
+	return func(c *openAPIOptions) {
+		c.contentType = contentType
+	}
+}
+
+// OpenAPIMaxAge sets the max-age of the Cache-Control header.
+// The default is five minutes.
+func OpenAPIMaxAge(maxAge time.Duration) OpenAPIOption {
+	return func(c *openAPIOptions) {
+		c.maxAge = maxAge
+	}
+}
+
+// OpenAPIHandler is a http handler for serving a static OpenAPI (or Swagger) document.
+// The handler does not include any authentication, and is intended to be mounted
+// outside the auth chain, at a path of your choosing.
+// The document is served with a strong Etag, so clients can revalidate cheaply.
+func OpenAPIHandler(document []byte, opts ...OpenAPIOption) http.Handler {
+	// default
+	config := &openAPIOptions{
+		contentType: "",
+		maxAge:      5 * time.Minute,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	contentType := config.contentType
+	if contentType == "" {
+		contentType = guessOpenAPIContentType(document)
+	}
+
+	hash := sha256.Sum256(document)
+	etag := StrongEtag(hex.EncodeToString(hash[:]))
+	cacheControl := fmt.Sprintf("public, max-age=%d", int(config.maxAge.Seconds()))
+
+	return RestrictMethods(http.MethodGet, http.MethodHead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("Etag", etag)
+
+		if EtagMatches(strings.Join(r.Header.Values("If-None-Match"), ", "), etag) {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(document)))
+		w.WriteHeader(http.StatusOK)
+
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		if _, err := w.Write(document); err != nil {
+			zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to write OpenAPI document")
+		}
+	}))
+}
+
+func guessOpenAPIContentType(document []byte) string {
+	trimmed := bytes.TrimSpace(document)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		return "application/json"
+	}
+
+	return "application/yaml"
+}
+
+var swaggerUITemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>API documentation</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+<script>
+  window.onload = () => {
+    window.ui = SwaggerUIBundle({url: {{.}}, dom_id: '#swagger-ui'});
+  };
+</script>
+</body>
+</html>
+`))
+
+// SwaggerUIHandler is a http handler for serving a minimal Swagger UI page, which
+// renders the OpenAPI document served at the given URL (e.g. via OpenAPIHandler).
+// Like OpenAPIHandler, it does not include any authentication.
+func SwaggerUIHandler(documentURL string) http.Handler {
+	page := &bytes.Buffer{}
+	if err := swaggerUITemplate.Execute(page, documentURL); err != nil {
+		panic(err)
+	}
+
+	return OpenAPIHandler(page.Bytes(), OpenAPIContentType("text/html; charset=utf-8"))
+}
@@ -0,0 +1,117 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type OpenAPISuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestOpenAPISuite(t *testing.T) {
+	suite.Run(t, &OpenAPISuite{})
+}
+
+func (s *OpenAPISuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/openapi", http.NoBody)
+}
+
+func (s *OpenAPISuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *OpenAPISuite) Test_OpenAPIHandler_JSON() {
+	// given
+	document := `{"openapi": "3.0.0"}`
+
+	// when
+	turtleware.OpenAPIHandler([]byte(document)).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("application/json", s.response.Header().Get("Content-Type"))
+	s.Equal("public, max-age=300", s.response.Header().Get("Cache-Control"))
+	s.NotEmpty(s.response.Header().Get("Etag"))
+	s.Equal(document, s.response.Body.String())
+}
+
+func (s *OpenAPISuite) Test_OpenAPIHandler_YAML() {
+	// given
+	document := "openapi: 3.0.0\n"
+
+	// when
+	turtleware.OpenAPIHandler([]byte(document)).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("application/yaml", s.response.Header().Get("Content-Type"))
+	s.Equal(document, s.response.Body.String())
+}
+
+func (s *OpenAPISuite) Test_OpenAPIHandler_NoAuthRequired() {
+	// given
+	s.request.Header.Del("Authorization")
+
+	handler := turtleware.OpenAPIHandler(
+		[]byte(`{"openapi": "3.0.0"}`),
+		turtleware.OpenAPIContentType("application/vnd.oai.openapi+json"),
+	)
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Empty(s.response.Header().Get("WWW-Authenticate"))
+	s.Equal("application/vnd.oai.openapi+json", s.response.Header().Get("Content-Type"))
+}
+
+func (s *OpenAPISuite) Test_OpenAPIHandler_CacheHit() {
+	// given
+	handler := turtleware.OpenAPIHandler([]byte(`{"openapi": "3.0.0"}`))
+	handler.ServeHTTP(s.response, s.request)
+
+	etag := s.response.Header().Get("Etag")
+	s.request.Header.Set("If-None-Match", etag)
+	s.response = httptest.NewRecorder()
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusNotModified, s.response.Code)
+	s.Empty(s.response.Body.String())
+}
+
+func (s *OpenAPISuite) Test_OpenAPIHandler_MethodNotAllowed() {
+	// given
+	s.request.Method = http.MethodPost
+
+	// when
+	turtleware.OpenAPIHandler([]byte(`{}`)).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusMethodNotAllowed, s.response.Code)
+	s.Equal("GET, HEAD", s.response.Header().Get("Allow"))
+}
+
+func (s *OpenAPISuite) Test_SwaggerUIHandler() {
+	// when
+	turtleware.SwaggerUIHandler("/openapi.json").ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("text/html; charset=utf-8", s.response.Header().Get("Content-Type"))
+	s.Contains(s.response.Body.String(), `"/openapi.json"`)
+}
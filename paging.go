@@ -4,13 +4,37 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 )
 
+// PagingMode distinguishes the kinds of paging requested by clients.
+type PagingMode int
+
+const (
+	// PagingModeOffset indicates offset based paging, via the Offset and Limit
+	// fields of Paging. This is the default.
+	PagingModeOffset PagingMode = iota
+
+	// PagingModeCursor indicates cursor based paging, via the Cursor and Limit
+	// fields of Paging.
+	PagingModeCursor
+)
+
 // Paging is a simple holder for applying offsets and limits.
+// If the client requested cursor based paging, Mode is PagingModeCursor, and
+// Cursor contains the opaque cursor. It is up to the data fetchers to opt into
+// cursor semantics.
+// A Limit of zero means no limit. DefaultLimit and MaxLimit reflect the limits
+// the paging information was parsed with.
 type Paging struct {
 	Offset uint32
 	Limit  uint16
+	Cursor string
+	Mode   PagingMode
+
+	DefaultLimit uint16
+	MaxLimit     uint16
 }
 
 var (
@@ -21,13 +45,81 @@ var (
 	// ErrInvalidLimit indicates that the query contained an invalid
 	// limit parameter (e.g. non-numeric).
 	ErrInvalidLimit = errors.New("invalid limit parameter")
+
+	// ErrUnalignedOffset indicates that the query contained an offset
+	// parameter, which is not a multiple of the limit parameter.
+	ErrUnalignedOffset = errors.New("offset parameter must be a multiple of limit parameter")
+
+	// ErrCursorOffsetConflict indicates that the query contained both a cursor
+	// and an offset parameter, which are mutually exclusive.
+	ErrCursorOffsetConflict = errors.New("cursor and offset parameters are mutually exclusive")
 )
 
+const (
+	// defaultPagingLimit is the limit applied if the client does not provide one.
+	defaultPagingLimit uint16 = 100
+
+	// maxPagingLimit is the maximum limit a client may request.
+	maxPagingLimit uint16 = 500
+)
+
+type pagingOptions struct {
+	alignedOffset bool
+	defaultLimit  uint16
+	maxLimit      uint16
+}
+
+// PagingOption represents an option for the PagingMiddlewareWithOptions.
+type PagingOption func(*pagingOptions)
+
+// PagingAlignedOffset sets whether the offset must be a multiple of the limit.
+// This keeps requested pages consistent, for caching layers requiring page-aligned
+// offsets. Unaligned offsets are rejected with ErrUnalignedOffset.
+// The default is false.
+func PagingAlignedOffset(alignedOffset bool) PagingOption {
+	return func(c *pagingOptions) {
+		c.alignedOffset = alignedOffset
+	}
+}
+
+// PagingDefaultLimit sets the limit applied if the client does not provide one.
+// If set to zero, no limit is applied by default, and the limits requested by
+// clients are not clamped to the maximum limit.
+// The default is 100.
+func PagingDefaultLimit(defaultLimit uint16) PagingOption {
+	return func(c *pagingOptions) {
+		c.defaultLimit = defaultLimit
+	}
+}
+
+// PagingMaxLimit sets the maximum limit a client may request. Larger limits
+// are silently clamped to the maximum limit. If set to zero, limits are not
+// clamped at all.
+// The default is 500.
+func PagingMaxLimit(maxLimit uint16) PagingOption {
+	return func(c *pagingOptions) {
+		c.maxLimit = maxLimit
+	}
+}
+
 // ParsePagingFromRequest parses Paging information from a given
-// request.
+// request. If a non-empty cursor parameter is provided, cursor based
+// paging is assumed, and providing an offset parameter in addition
+// results in ErrCursorOffsetConflict.
+// A default limit of 100 is applied, and larger limits than 500 are
+// clamped to 500. Use PagingMiddlewareWithOptions for different limits.
 func ParsePagingFromRequest(r *http.Request) (Paging, error) {
+	return parsePagingFromRequest(r, defaultPagingLimit, maxPagingLimit)
+}
+
+func parsePagingFromRequest(r *http.Request, defaultLimit uint16, maxLimit uint16) (Paging, error) {
 	query := r.URL.Query()
 
+	cursor := query.Get("cursor")
+	if cursor != "" && query.Get("offset") != "" {
+		return Paging{}, ErrCursorOffsetConflict
+	}
+
 	var (
 		offset uint32
 		limit  uint16
@@ -54,25 +146,107 @@ func ParsePagingFromRequest(r *http.Request) (Paging, error) {
 
 		limit = uint16(val)
 
-		if limit > 500 {
-			limit = 500
+		if defaultLimit > 0 && maxLimit > 0 && limit > maxLimit {
+			limit = maxLimit
 		}
 	} else {
-		limit = 100
+		limit = defaultLimit
+	}
+
+	if cursor != "" {
+		return Paging{
+			Limit:        limit,
+			Cursor:       cursor,
+			Mode:         PagingModeCursor,
+			DefaultLimit: defaultLimit,
+			MaxLimit:     maxLimit,
+		}, nil
 	}
 
 	return Paging{
-		Offset: offset,
-		Limit:  limit,
+		Offset:       offset,
+		Limit:        limit,
+		DefaultLimit: defaultLimit,
+		MaxLimit:     maxLimit,
 	}, nil
 }
 
 // String provides a simple way of stringifying paging information for
 // requests.
 func (paging Paging) String() string {
+	if paging.Mode == PagingModeCursor {
+		return fmt.Sprintf("cursor=%s&limit=%d", url.QueryEscape(paging.Cursor), paging.Limit)
+	}
+
 	if paging.Offset > 0 {
 		return fmt.Sprintf("offset=%d&limit=%d", paging.Offset, paging.Limit)
 	}
 
 	return fmt.Sprintf("limit=%d", paging.Limit)
 }
+
+// PagingLinks returns the values of RFC 8288 Link headers for navigating the list at the
+// given URL, with the relations first, prev, next and last. The paging query parameters of
+// the URL are replaced via Paging.String(), while any other query parameters are retained.
+// A negative total count signals an unknown total count, in which case only the first and
+// prev links are returned. The next link is omitted for the last page, as is the prev link
+// for the first page. For cursor based paging, only the first link is returned, as the
+// neighboring cursors are unknown. If no limit is applied, no links are returned at all.
+func PagingLinks(u *url.URL, paging Paging, totalCount int64) []string {
+	if paging.Limit == 0 {
+		return nil
+	}
+
+	link := func(offset uint32, rel string) string {
+		linkPaging := Paging{
+			Offset: offset,
+			Limit:  paging.Limit,
+		}
+
+		query := u.Query()
+		query.Del("offset")
+		query.Del("limit")
+		query.Del("cursor")
+
+		rawQuery := linkPaging.String()
+		if encoded := query.Encode(); encoded != "" {
+			rawQuery = encoded + "&" + rawQuery
+		}
+
+		linkURL := *u
+		linkURL.RawQuery = rawQuery
+
+		return "<" + linkURL.String() + `>; rel="` + rel + `"`
+	}
+
+	links := []string{link(0, "first")}
+	if paging.Mode == PagingModeCursor {
+		return links
+	}
+
+	limit := uint32(paging.Limit)
+
+	if paging.Offset > 0 {
+		prevOffset := uint32(0)
+		if paging.Offset > limit {
+			prevOffset = paging.Offset - limit
+		}
+
+		links = append(links, link(prevOffset, "prev"))
+	}
+
+	if totalCount < 0 {
+		return links
+	}
+
+	if int64(paging.Offset)+int64(limit) < totalCount {
+		links = append(links, link(paging.Offset+limit, "next"))
+	}
+
+	lastOffset := uint32(0)
+	if totalCount > 0 {
+		lastOffset = uint32((totalCount - 1) / int64(limit) * int64(limit))
+	}
+
+	return append(links, link(lastOffset, "last"))
+}
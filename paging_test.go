@@ -48,8 +48,10 @@ func (s *PagingSuite) Test_ParsePagingFromRequest() {
 		// then
 		s.NoError(err)
 		s.Equal(turtleware.Paging{
-			Offset: 30,
-			Limit:  10,
+			Offset:       30,
+			Limit:        10,
+			DefaultLimit: 100,
+			MaxLimit:     500,
 		}, paging)
 	})
 
@@ -66,8 +68,10 @@ func (s *PagingSuite) Test_ParsePagingFromRequest() {
 			// then
 			s.NoError(err)
 			s.Equal(turtleware.Paging{
-				Offset: 0,
-				Limit:  10,
+				Offset:       0,
+				Limit:        10,
+				DefaultLimit: 100,
+				MaxLimit:     500,
 			}, paging)
 		})
 
@@ -83,8 +87,10 @@ func (s *PagingSuite) Test_ParsePagingFromRequest() {
 			// then
 			s.NoError(err)
 			s.Equal(turtleware.Paging{
-				Offset: 0,
-				Limit:  500,
+				Offset:       0,
+				Limit:        500,
+				DefaultLimit: 100,
+				MaxLimit:     500,
 			}, paging)
 		})
 
@@ -132,8 +138,10 @@ func (s *PagingSuite) Test_ParsePagingFromRequest() {
 			// then
 			s.NoError(err)
 			s.Equal(turtleware.Paging{
-				Offset: 30,
-				Limit:  100,
+				Offset:       30,
+				Limit:        100,
+				DefaultLimit: 100,
+				MaxLimit:     500,
 			}, paging)
 		})
 
@@ -168,6 +176,65 @@ func (s *PagingSuite) Test_ParsePagingFromRequest() {
 		})
 	})
 
+	s.Run("Cursor", func() {
+		s.Run("Success", func() {
+			// given
+			r := buildTestRequest(map[string]string{
+				"cursor": "abc=/+",
+				"limit":  "10",
+			})
+
+			// when
+			paging, err := turtleware.ParsePagingFromRequest(r)
+
+			// then
+			s.NoError(err)
+			s.Equal(turtleware.Paging{
+				Limit:        10,
+				Cursor:       "abc=/+",
+				Mode:         turtleware.PagingModeCursor,
+				DefaultLimit: 100,
+				MaxLimit:     500,
+			}, paging)
+		})
+
+		s.Run("Empty", func() {
+			// given
+			r := buildTestRequest(map[string]string{
+				"cursor": "",
+				"offset": "30",
+			})
+
+			// when
+			paging, err := turtleware.ParsePagingFromRequest(r)
+
+			// then
+			s.NoError(err)
+			s.Equal(turtleware.Paging{
+				Offset:       30,
+				Limit:        100,
+				Mode:         turtleware.PagingModeOffset,
+				DefaultLimit: 100,
+				MaxLimit:     500,
+			}, paging)
+		})
+
+		s.Run("Offset_Conflict", func() {
+			// given
+			r := buildTestRequest(map[string]string{
+				"cursor": "abc",
+				"offset": "30",
+			})
+
+			// when
+			paging, err := turtleware.ParsePagingFromRequest(r)
+
+			// then
+			s.ErrorIs(err, turtleware.ErrCursorOffsetConflict)
+			s.Equal(turtleware.Paging{}, paging)
+		})
+	})
+
 	s.Run("No_Parameters", func() {
 		// given
 		r := buildTestRequest(nil)
@@ -178,8 +245,10 @@ func (s *PagingSuite) Test_ParsePagingFromRequest() {
 		// then
 		s.NoError(err)
 		s.Equal(turtleware.Paging{
-			Offset: 0,
-			Limit:  100,
+			Offset:       0,
+			Limit:        100,
+			DefaultLimit: 100,
+			MaxLimit:     500,
 		}, paging)
 	})
 }
@@ -219,6 +288,23 @@ func (s *PagingSuite) Test_ParsePagingFromRequest_String() {
 
 	})
 
+	s.Run("Cursor", func() {
+		// given
+		paging := turtleware.Paging{Limit: 10, Cursor: "abc=/+", Mode: turtleware.PagingModeCursor, DefaultLimit: 100, MaxLimit: 500}
+
+		// when
+		stringVal := paging.String()
+
+		// then
+		s.Equal("cursor=abc%3D%2F%2B&limit=10", stringVal)
+
+		roundTripped, err := turtleware.ParsePagingFromRequest(
+			httptest.NewRequest(http.MethodGet, "https://example.com?"+stringVal, http.NoBody),
+		)
+		s.NoError(err)
+		s.Equal(paging, roundTripped)
+	})
+
 	s.Run("EmptyPaging", func() {
 		// given
 		paging := turtleware.Paging{}
@@ -230,3 +316,90 @@ func (s *PagingSuite) Test_ParsePagingFromRequest_String() {
 		s.Equal("limit=0", stringVal)
 	})
 }
+
+func (s *PagingSuite) Test_PagingLinks() {
+	listURL, err := url.Parse("https://example.com/things?filter=abc&offset=20&limit=10")
+	s.Require().NoError(err)
+
+	cases := map[string]struct {
+		paging     turtleware.Paging
+		totalCount int64
+		expected   []string
+	}{
+		"Middle_Page": {
+			paging:     turtleware.Paging{Offset: 20, Limit: 10},
+			totalCount: 45,
+			expected: []string{
+				`<https://example.com/things?filter=abc&limit=10>; rel="first"`,
+				`<https://example.com/things?filter=abc&offset=10&limit=10>; rel="prev"`,
+				`<https://example.com/things?filter=abc&offset=30&limit=10>; rel="next"`,
+				`<https://example.com/things?filter=abc&offset=40&limit=10>; rel="last"`,
+			},
+		},
+		"First_Page": {
+			paging:     turtleware.Paging{Offset: 0, Limit: 10},
+			totalCount: 45,
+			expected: []string{
+				`<https://example.com/things?filter=abc&limit=10>; rel="first"`,
+				`<https://example.com/things?filter=abc&offset=10&limit=10>; rel="next"`,
+				`<https://example.com/things?filter=abc&offset=40&limit=10>; rel="last"`,
+			},
+		},
+		"Last_Page": {
+			paging:     turtleware.Paging{Offset: 40, Limit: 10},
+			totalCount: 45,
+			expected: []string{
+				`<https://example.com/things?filter=abc&limit=10>; rel="first"`,
+				`<https://example.com/things?filter=abc&offset=30&limit=10>; rel="prev"`,
+				`<https://example.com/things?filter=abc&offset=40&limit=10>; rel="last"`,
+			},
+		},
+		"Unaligned_Offset": {
+			paging:     turtleware.Paging{Offset: 5, Limit: 10},
+			totalCount: 10,
+			expected: []string{
+				`<https://example.com/things?filter=abc&limit=10>; rel="first"`,
+				`<https://example.com/things?filter=abc&limit=10>; rel="prev"`,
+				`<https://example.com/things?filter=abc&limit=10>; rel="last"`,
+			},
+		},
+		"Empty_List": {
+			paging:     turtleware.Paging{Offset: 0, Limit: 10},
+			totalCount: 0,
+			expected: []string{
+				`<https://example.com/things?filter=abc&limit=10>; rel="first"`,
+				`<https://example.com/things?filter=abc&limit=10>; rel="last"`,
+			},
+		},
+		"Unknown_Total_Count": {
+			paging:     turtleware.Paging{Offset: 20, Limit: 10},
+			totalCount: -1,
+			expected: []string{
+				`<https://example.com/things?filter=abc&limit=10>; rel="first"`,
+				`<https://example.com/things?filter=abc&offset=10&limit=10>; rel="prev"`,
+			},
+		},
+		"Cursor": {
+			paging:     turtleware.Paging{Limit: 10, Cursor: "abc", Mode: turtleware.PagingModeCursor},
+			totalCount: 45,
+			expected: []string{
+				`<https://example.com/things?filter=abc&limit=10>; rel="first"`,
+			},
+		},
+		"No_Limit": {
+			paging:     turtleware.Paging{Offset: 0, Limit: 0},
+			totalCount: 45,
+			expected:   nil,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			links := turtleware.PagingLinks(listURL, target.paging, target.totalCount)
+
+			// then
+			s.Equal(target.expected, links)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"context"
+	"errors"
+	"net/http"
+)
+
+// ResourceRelatedFunc is a function for returning the paths of resources related
+// to a given entity (or resource), which clients are likely to request next.
+type ResourceRelatedFunc func(ctx context.Context, entityUUID string) ([]string, error)
+
+// RelatedResources may optionally be implemented by a GetEndpoint, to declare related
+// resources. If implemented, the ResourceHandler composition includes the PreloadMiddleware.
+type RelatedResources interface {
+	RelatedResources(ctx context.Context, entityUUID string) ([]string, error)
+}
+
+type preloadOptions struct {
+	push bool
+	as   string
+}
+
+// PreloadOption represents an option for the PreloadMiddleware.
+type PreloadOption func(*preloadOptions)
+
+// PreloadPush sets whether related resources are pushed via HTTP/2 server push,
+// if the connection supports it. Resources which cannot be pushed are still hinted
+// via a Link header.
+// The default is false.
+func PreloadPush(push bool) PreloadOption {
+	return func(c *preloadOptions) {
+		c.push = push
+	}
+}
+
+// PreloadAs sets the "as" parameter of the emitted Link headers (e.g. "fetch").
+// The default is empty, which means the parameter is omitted.
+func PreloadAs(as string) PreloadOption {
+	return func(c *preloadOptions) {
+		c.as = as
+	}
+}
+
+// PreloadMiddleware is a middleware for hinting related resources of a single entity (or
+// resource) to clients, via "Link: </related>; rel=preload" headers. The related resources
+// are retrieved via the provided ResourceRelatedFunc.
+// As the hints are purely an optimization, errors of the ResourceRelatedFunc are only logged,
+// and the next handler of the middleware is called regardless.
+func PreloadMiddleware(
+	relatedFetcher ResourceRelatedFunc,
+	opts ...PreloadOption,
+) func(h http.Handler) http.Handler {
+	// default
+	config := &preloadOptions{
+		push: false,
+		as:   "",
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	linkSuffix := ">; rel=preload"
+	if config.as != "" {
+		linkSuffix += "; as=" + config.as
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := zerolog.Ctx(r.Context())
+
+			entityUUID, err := EntityUUIDFromRequestContext(r.Context())
+			if err != nil {
+				logger.Warn().Err(err).Msg("Skipping preload hints")
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			related, err := relatedFetcher(r.Context(), entityUUID)
+			if err != nil {
+				logger.Warn().Err(err).Msg("Failed to receive related resources")
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			pusher, canPush := w.(http.Pusher)
+
+			for _, target := range related {
+				if config.push && canPush {
+					err := pusher.Push(target, &http.PushOptions{Header: preloadPushHeader(r)})
+					if err == nil {
+						continue
+					}
+
+					if !errors.Is(err, http.ErrNotSupported) {
+						logger.Debug().Err(err).Msgf("Failed to push %s", target)
+					}
+				}
+
+				w.Header().Add("Link", "<"+target+linkSuffix)
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func preloadPushHeader(r *http.Request) http.Header {
+	header := http.Header{}
+
+	for _, name := range []string{"Authorization", "Accept", "Accept-Encoding"} {
+		if value := r.Header.Get(name); value != "" {
+			header.Set(name, value)
+		}
+	}
+
+	return header
+}
@@ -0,0 +1,181 @@
+package turtleware_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type PreloadSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestPreloadSuite(t *testing.T) {
+	suite.Run(t, &PreloadSuite{})
+}
+
+func (s *PreloadSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *PreloadSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+
+	pushed []string
+	err    error
+}
+
+func (p *pushRecorder) Push(target string, _ *http.PushOptions) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	p.pushed = append(p.pushed, target)
+
+	return nil
+}
+
+func (s *PreloadSuite) relatedFetcher(ctx context.Context, entityUUID string) ([]string, error) {
+	return []string{"/owners/" + entityUUID, "/tags"}, nil
+}
+
+func (s *PreloadSuite) Test_PreloadMiddleware() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.PreloadMiddleware(s.relatedFetcher),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Equal(
+		[]string{"</owners/" + s.entityUUID + ">; rel=preload", "</tags>; rel=preload"},
+		s.response.Header().Values("Link"),
+	)
+}
+
+func (s *PreloadSuite) Test_PreloadMiddleware_As() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.PreloadMiddleware(s.relatedFetcher, turtleware.PreloadAs("fetch")),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal("</tags>; rel=preload; as=fetch", s.response.Header().Values("Link")[1])
+}
+
+func (s *PreloadSuite) Test_PreloadMiddleware_Push() {
+	s.Run("Supported", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		recorder := &pushRecorder{ResponseRecorder: s.response}
+
+		testChain := alice.New(
+			s.buildEntityUUIDChain,
+			turtleware.PreloadMiddleware(s.relatedFetcher, turtleware.PreloadPush(true)),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(recorder, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Equal([]string{"/owners/" + s.entityUUID, "/tags"}, recorder.pushed)
+		s.Empty(s.response.Header().Values("Link"))
+	})
+
+	s.Run("Not_Supported", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+		recorder := &pushRecorder{ResponseRecorder: s.response, err: http.ErrNotSupported}
+
+		testChain := alice.New(
+			s.buildEntityUUIDChain,
+			turtleware.PreloadMiddleware(s.relatedFetcher, turtleware.PreloadPush(true)),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(recorder, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Len(s.response.Header().Values("Link"), 2)
+	})
+
+	s.Run("Without_HTTP2", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		testChain := alice.New(
+			s.buildEntityUUIDChain,
+			turtleware.PreloadMiddleware(s.relatedFetcher, turtleware.PreloadPush(true)),
+		).Then(nextCapture)
+
+		// when
+		testChain.ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+		s.Len(s.response.Header().Values("Link"), 2)
+	})
+}
+
+func (s *PreloadSuite) Test_PreloadMiddleware_Error() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	relatedFetcher := func(ctx context.Context, entityUUID string) ([]string, error) {
+		return nil, errors.New("some-error")
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		turtleware.PreloadMiddleware(relatedFetcher),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Empty(s.response.Header().Values("Link"))
+}
+
+func (s *PreloadSuite) Test_PreloadMiddleware_Missing_EntityUUID() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	// when
+	turtleware.PreloadMiddleware(s.relatedFetcher)(nextCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Empty(s.response.Header().Values("Link"))
+}
@@ -0,0 +1,256 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ProblemContentType is the content type of problem documents, as defined by RFC 7807.
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is a problem document, as defined by RFC 7807.
+type ProblemDetails struct {
+	Type     string              `json:"type,omitempty"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Errors   []ProblemFieldError `json:"errors,omitempty"`
+}
+
+// ProblemFieldError is a single validation failure of a ProblemDetails document.
+// Field is a JSON Pointer to the offending field, and empty if the failure
+// does not relate to a specific field.
+type ProblemFieldError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// WriteProblem writes the given problem document with its status code, and the
+// application/problem+json content type - if the request type is not HEAD.
+// The document is serialized with the JSON options of the EmissioneWriter.
+func WriteProblem(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	problem ProblemDetails,
+) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", ProblemContentType)
+
+	if r.Method == http.MethodHead {
+		// No body, but we still require the status code
+		w.WriteHeader(problem.Status)
+
+		return
+	}
+
+	body, err := EmissioneWriter.JSONAPI().Marshal(problem)
+	if err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Error while marshalling problem document")
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(problem.Status)
+
+	if _, err := w.Write(body); err != nil {
+		zerolog.Ctx(ctx).Error().Err(err).Msg("Error while writing problem document")
+	}
+}
+
+// problemTitles maps the sentinel errors of turtleware to the titles of their problem
+// documents. Errors not contained are titled by the status text of their status code.
+var problemTitles = []struct {
+	err   error
+	title string
+}{
+	{err: ErrResourceNotFound, title: "Resource not found"},
+	{err: ErrResourceGone, title: "Resource gone"},
+	{err: ErrPreconditionFailed, title: "Precondition failed"},
+	{err: ErrNoChanges, title: "No changes"},
+	{err: ErrConflict, title: "Conflict with current state"},
+	{err: ErrUnsupportedMediaType, title: "Unsupported media type"},
+	{err: ErrMarshalling, title: "Malformed request body"},
+	{err: ErrMissingUserUUID, title: "Missing user UUID"},
+	{err: ErrInvalidEntityUUID, title: "Invalid entity UUID"},
+	{err: ErrUnmodifiedSinceHeaderMissing, title: "Missing If-Unmodified-Since header"},
+	{err: ErrUnmodifiedSinceHeaderInvalid, title: "Invalid If-Unmodified-Since header"},
+	{err: ErrUnavailableForLegalReasons, title: "Unavailable for legal reasons"},
+	{err: ErrReceivingResults, title: "Failed to receive results"},
+	{err: ErrRequestTimeout, title: "Timeout while receiving results"},
+	{err: ErrReceivingMeta, title: "Failed to receive metadata"},
+}
+
+func problemTitle(code int, errs []error) string {
+	for _, problemTitle := range problemTitles {
+		for _, err := range errs {
+			if errors.Is(err, problemTitle.err) {
+				return problemTitle.title
+			}
+		}
+	}
+
+	return http.StatusText(code)
+}
+
+// WriteProblemError is the RFC 7807 equivalent of WriteError. It sets the given status code,
+// and writes a problem document (see WriteProblem) for the given errors, with the content type
+// application/problem+json. The title is derived from the turtleware sentinel errors (e.g.
+// ErrResourceNotFound), falling back to the status text. The error messages are joined into
+// the detail.
+func WriteProblemError(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	code int,
+	errs ...error,
+) {
+	for _, err := range errs {
+		// nolint errcheck: Returned error is not checked, as its just err as passed in
+		_ = TagContextSpanWithError(ctx, err)
+	}
+
+	if r.Method != http.MethodHead {
+		zerolog.Ctx(ctx).Warn().
+			Errs("errors", errs).
+			Int("error_code", code).
+			Msg("Writing problem")
+	}
+
+	details := make([]string, len(errs))
+	for i, err := range errs {
+		details[i] = err.Error()
+	}
+
+	WriteProblem(ctx, w, r, ProblemDetails{
+		Title:  problemTitle(code, errs),
+		Status: code,
+		Detail: strings.Join(details, "; "),
+	})
+}
+
+// DefaultProblemErrorHandler is the RFC 7807 equivalent of DefaultErrorHandler. It maps the
+// errors known by turtleware to the same status codes as DefaultErrorHandler, including the
+// ones of DefaultPatchErrorHandler, but writes them via WriteProblemError. Validation failures
+// are written via WriteValidationProblem.
+func DefaultProblemErrorHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrResourceNotFound) {
+		WriteProblemError(ctx, w, r, http.StatusNotFound, err)
+		return
+	}
+
+	if errors.Is(err, ErrResourceGone) {
+		WriteProblemError(ctx, w, r, http.StatusGone, err)
+		return
+	}
+
+	if errors.Is(err, ErrPreconditionFailed) {
+		WriteProblemError(ctx, w, r, http.StatusPreconditionFailed, err)
+		return
+	}
+
+	if errors.Is(err, ErrMissingUserUUID) ||
+		errors.Is(err, ErrInvalidEntityUUID) ||
+		errors.Is(err, ErrMarshalling) ||
+		errors.Is(err, ErrNoChanges) ||
+		errors.Is(err, ErrUnmodifiedSinceHeaderInvalid) {
+		WriteProblemError(ctx, w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if errors.Is(err, ErrUnmodifiedSinceHeaderMissing) {
+		WriteProblemError(ctx, w, r, http.StatusPreconditionRequired, err)
+		return
+	}
+
+	if errors.Is(err, ErrConflict) {
+		WriteProblemError(ctx, w, r, http.StatusConflict, err)
+		return
+	}
+
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		WriteProblemError(ctx, w, r, http.StatusUnsupportedMediaType, err)
+		return
+	}
+
+	if errors.Is(err, ErrUnavailableForLegalReasons) {
+		addBlockedByLink(w.Header(), err)
+		WriteProblemError(ctx, w, r, http.StatusUnavailableForLegalReasons, err)
+		return
+	}
+
+	if errors.Is(err, ErrRequestTimeout) {
+		WriteProblemError(ctx, w, r, http.StatusGatewayTimeout, err)
+		return
+	}
+
+	validationErr := &ValidationWrapperError{}
+	if errors.As(err, validationErr) {
+		WriteValidationProblem(ctx, w, r, *validationErr)
+		return
+	}
+
+	WriteProblemError(ctx, w, r, http.StatusInternalServerError, err)
+}
+
+// WriteValidationProblem writes the errors of the given ValidationWrapperError as a problem
+// document with status code 400. Errors which are (or wrap) a FieldError reference their
+// field via a JSON Pointer, so clients (e.g. form libraries) can map them to their fields.
+func WriteValidationProblem(
+	ctx context.Context,
+	w http.ResponseWriter,
+	r *http.Request,
+	validationErr ValidationWrapperError,
+) {
+	for _, err := range validationErr.Errors {
+		// nolint errcheck: Returned error is not checked, as its just err as passed in
+		_ = TagContextSpanWithError(ctx, err)
+	}
+
+	fieldErrors := make([]ProblemFieldError, len(validationErr.Errors))
+	for i, err := range validationErr.Errors {
+		fieldErrors[i] = problemFieldError(err)
+	}
+
+	WriteProblem(ctx, w, r, ProblemDetails{
+		Title:  "Validation failed",
+		Status: http.StatusBadRequest,
+		Errors: fieldErrors,
+	})
+}
+
+// ValidationProblemErrorHandler wraps the given ErrorHandlerFunc, so validation failures
+// (ValidationWrapperError) are written as problem documents via WriteValidationProblem.
+// Any other error is passed to the given ErrorHandlerFunc.
+func ValidationProblemErrorHandler(errorHandler ErrorHandlerFunc) ErrorHandlerFunc {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+		validationErr := &ValidationWrapperError{}
+		if errors.As(err, validationErr) {
+			WriteValidationProblem(ctx, w, r, *validationErr)
+
+			return
+		}
+
+		errorHandler(ctx, w, r, err)
+	}
+}
+
+func problemFieldError(err error) ProblemFieldError {
+	fieldErrorPointer := &FieldError{}
+	if errors.As(err, &fieldErrorPointer) {
+		return ProblemFieldError{Field: fieldErrorPointer.Pointer, Message: fieldErrorPointer.Message}
+	}
+
+	fieldError := FieldError{}
+	if errors.As(err, &fieldError) {
+		return ProblemFieldError{Field: fieldError.Pointer, Message: fieldError.Message}
+	}
+
+	return ProblemFieldError{Message: err.Error()}
+}
@@ -0,0 +1,235 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ProblemSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestProblemSuite(t *testing.T) {
+	suite.Run(t, &ProblemSuite{})
+}
+
+func (s *ProblemSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodPost, "https://example.com/foo", http.NoBody)
+}
+
+func (s *ProblemSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *ProblemSuite) validationError() error {
+	return &turtleware.ValidationWrapperError{
+		Errors: []error{
+			turtleware.NewFieldError("must not be empty", "name"),
+			turtleware.FieldError{Pointer: "/address/street", Message: "must not be longer than 64 characters"},
+			fmt.Errorf("wrapped: %w", turtleware.NewFieldError("unknown tag", "tags", "0")),
+			errors.New("some general validation error"),
+		},
+	}
+}
+
+func (s *ProblemSuite) Test_ValidationProblemErrorHandler_Validation() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+
+	// when
+	turtleware.ValidationProblemErrorHandler(errorCapture.Capture)(context.Background(), s.response, s.request, s.validationError())
+
+	// then
+	s.NoError(errorCapture.CapturedError)
+	s.Equal(http.StatusBadRequest, s.response.Code)
+	s.Equal("application/problem+json", s.response.Header().Get("Content-Type"))
+	s.JSONEq(s.loadTestDataString("problem/validation.json"), s.response.Body.String())
+}
+
+func (s *ProblemSuite) Test_ValidationProblemErrorHandler_Other() {
+	// given
+	errorCapture := &ErrorHandlerCapture{}
+	targetErr := errors.New("some-error")
+
+	// when
+	turtleware.ValidationProblemErrorHandler(errorCapture.Capture)(context.Background(), s.response, s.request, targetErr)
+
+	// then
+	s.ErrorIs(errorCapture.CapturedError, targetErr)
+	s.Empty(s.response.Body.String())
+}
+
+func (s *ProblemSuite) Test_WriteProblem_Head() {
+	// given
+	s.request.Method = http.MethodHead
+
+	// when
+	turtleware.WriteProblem(context.Background(), s.response, s.request, turtleware.ProblemDetails{
+		Title:  "Conflict",
+		Status: http.StatusConflict,
+	})
+
+	// then
+	s.Equal(http.StatusConflict, s.response.Code)
+	s.Equal("application/problem+json", s.response.Header().Get("Content-Type"))
+	s.Empty(s.response.Body.String())
+}
+
+func (s *ProblemSuite) Test_WriteProblemError() {
+	// when
+	turtleware.WriteProblemError(
+		context.Background(), s.response, s.request, http.StatusNotFound,
+		fmt.Errorf("some-context: %w", turtleware.ErrResourceNotFound),
+	)
+
+	// then
+	s.Equal(http.StatusNotFound, s.response.Code)
+	s.Equal("application/problem+json", s.response.Header().Get("Content-Type"))
+	s.Equal("no-store", s.response.Header().Get("Cache-Control"))
+	s.JSONEq(s.loadTestDataString("problem/resource_not_found.json"), s.response.Body.String())
+}
+
+func (s *ProblemSuite) Test_WriteProblemError_Multiple() {
+	// when
+	turtleware.WriteProblemError(
+		context.Background(), s.response, s.request, http.StatusTeapot,
+		errors.New("first-error"), errors.New("second-error"),
+	)
+
+	// then
+	s.Equal(http.StatusTeapot, s.response.Code)
+	s.JSONEq(`{
+		"title": "I'm a teapot",
+		"status": 418,
+		"detail": "first-error; second-error"
+	}`, s.response.Body.String())
+}
+
+func (s *ProblemSuite) Test_DefaultProblemErrorHandler() {
+	cases := map[string]struct {
+		err        error
+		statusCode int
+		title      string
+	}{
+		"ErrResourceNotFound": {
+			err:        turtleware.ErrResourceNotFound,
+			statusCode: http.StatusNotFound,
+			title:      "Resource not found",
+		},
+		"ErrResourceGone": {
+			err:        turtleware.ErrResourceGone,
+			statusCode: http.StatusGone,
+			title:      "Resource gone",
+		},
+		"ErrPreconditionFailed": {
+			err:        turtleware.ErrPreconditionFailed,
+			statusCode: http.StatusPreconditionFailed,
+			title:      "Precondition failed",
+		},
+		"ErrMissingUserUUID": {
+			err:        turtleware.ErrMissingUserUUID,
+			statusCode: http.StatusBadRequest,
+			title:      "Missing user UUID",
+		},
+		"ErrInvalidEntityUUID": {
+			err:        turtleware.ErrInvalidEntityUUID,
+			statusCode: http.StatusBadRequest,
+			title:      "Invalid entity UUID",
+		},
+		"ErrMarshalling": {
+			err:        turtleware.ErrMarshalling,
+			statusCode: http.StatusBadRequest,
+			title:      "Malformed request body",
+		},
+		"ErrNoChanges": {
+			err:        turtleware.ErrNoChanges,
+			statusCode: http.StatusBadRequest,
+			title:      "No changes",
+		},
+		"ErrUnmodifiedSinceHeaderInvalid": {
+			err:        turtleware.ErrUnmodifiedSinceHeaderInvalid,
+			statusCode: http.StatusBadRequest,
+			title:      "Invalid If-Unmodified-Since header",
+		},
+		"ErrUnmodifiedSinceHeaderMissing": {
+			err:        turtleware.ErrUnmodifiedSinceHeaderMissing,
+			statusCode: http.StatusPreconditionRequired,
+			title:      "Missing If-Unmodified-Since header",
+		},
+		"ErrConflict": {
+			err:        turtleware.ErrConflict,
+			statusCode: http.StatusConflict,
+			title:      "Conflict with current state",
+		},
+		"ErrUnsupportedMediaType": {
+			err:        turtleware.ErrUnsupportedMediaType,
+			statusCode: http.StatusUnsupportedMediaType,
+			title:      "Unsupported media type",
+		},
+		"ErrUnavailableForLegalReasons": {
+			err:        turtleware.ErrUnavailableForLegalReasons,
+			statusCode: http.StatusUnavailableForLegalReasons,
+			title:      "Unavailable for legal reasons",
+		},
+		"ErrRequestTimeout": {
+			err:        turtleware.ErrRequestTimeout,
+			statusCode: http.StatusGatewayTimeout,
+			title:      "Timeout while receiving results",
+		},
+		"Unknown": {
+			err:        errors.New("some-error"),
+			statusCode: http.StatusInternalServerError,
+			title:      "Internal Server Error",
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			turtleware.DefaultProblemErrorHandler(context.Background(), s.response, s.request, target.err)
+
+			// then
+			s.Equal(target.statusCode, s.response.Code)
+			s.Equal("application/problem+json", s.response.Header().Get("Content-Type"))
+
+			problem := turtleware.ProblemDetails{}
+			s.Require().NoError(json.Unmarshal(s.response.Body.Bytes(), &problem))
+			s.Equal(turtleware.ProblemDetails{
+				Title:  target.title,
+				Status: target.statusCode,
+				Detail: target.err.Error(),
+			}, problem)
+		})
+	}
+
+	s.Run("ValidationWrapperError", func() {
+		// when
+		turtleware.DefaultProblemErrorHandler(context.Background(), s.response, s.request, s.validationError())
+
+		// then
+		s.Equal(http.StatusBadRequest, s.response.Code)
+		s.JSONEq(s.loadTestDataString("problem/validation.json"), s.response.Body.String())
+	})
+}
+
+func (s *ProblemSuite) Test_JSONPointer() {
+	// when
+	pointer := turtleware.JSONPointer("a/b", "m~n", "0")
+
+	// then
+	s.Equal("/a~1b/m~0n/0", pointer)
+}
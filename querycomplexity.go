@@ -0,0 +1,44 @@
+package turtleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrQueryTooComplex indicates that the combined sparse fieldsets, includes, sorts
+// and filters of the query exceed the complexity budget of the requested endpoint.
+var ErrQueryTooComplex = errors.New("query too complex")
+
+// QueryComplexity computes the complexity of the query of a given request.
+// The complexity is the count of all comma separated values of the fields
+// (including fields[type]), include, sort and filter (including filter[name])
+// query parameters. Empty values are not counted.
+func QueryComplexity(r *http.Request) uint {
+	complexity := uint(0)
+
+	for key, values := range r.URL.Query() {
+		if !isComplexityParameter(key) {
+			continue
+		}
+
+		for _, value := range values {
+			for _, part := range strings.Split(value, ",") {
+				if strings.TrimSpace(part) != "" {
+					complexity++
+				}
+			}
+		}
+	}
+
+	return complexity
+}
+
+func isComplexityParameter(key string) bool {
+	switch key {
+	case "fields", "include", "sort", "filter":
+		return true
+	default:
+		return (strings.HasPrefix(key, "fields[") || strings.HasPrefix(key, "filter[")) && strings.HasSuffix(key, "]")
+	}
+}
@@ -0,0 +1,47 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type QueryComplexitySuite struct {
+	CommonSuite
+}
+
+func TestQueryComplexitySuite(t *testing.T) {
+	suite.Run(t, &QueryComplexitySuite{})
+}
+
+func (s *QueryComplexitySuite) Test_QueryComplexity() {
+	cases := map[string]struct {
+		query    string
+		expected uint
+	}{
+		"Empty":            {query: "", expected: 0},
+		"Unrelated":        {query: "offset=10&limit=5&q=foo", expected: 0},
+		"Includes":         {query: "include=author,comments", expected: 2},
+		"Sparse_Fieldsets": {query: "fields[articles]=title,body&fields[people]=name", expected: 3},
+		"Sorts":            {query: "sort=-created,title", expected: 2},
+		"Filters":          {query: "filter[author]=bob&filter[tag]=go,rust", expected: 3},
+		"Empty_Values":     {query: "include=,author,&sort=", expected: 1},
+		"Combined":         {query: "fields=title&include=author&sort=title&filter=published", expected: 4},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			r := httptest.NewRequest(http.MethodGet, "https://example.com/articles?"+target.query, http.NoBody)
+
+			// when
+			complexity := turtleware.QueryComplexity(r)
+
+			// then
+			s.Equal(target.expected, complexity)
+		})
+	}
+}
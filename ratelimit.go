@@ -0,0 +1,223 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRateLimitExceeded indicates that the client exceeded its rate limit
+// (see RateLimitMiddleware).
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
+// RateLimitStore is the contract for token bucket stores used by the RateLimitMiddleware.
+// Implementations must be safe for concurrent use, and may be shared between multiple
+// instances of a service (e.g. via Redis).
+type RateLimitStore interface {
+	// Take takes a token from the bucket of the given key. The bucket holds at most burst
+	// tokens, and is refilled with the given rate of tokens per second. If no token is
+	// available, false is returned, alongside the duration until the next token is available.
+	Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+}
+
+// RateLimitKeyFunc is a function for deriving the rate limit key of a request.
+// If an empty key is returned, the request is not rate limited.
+type RateLimitKeyFunc func(r *http.Request) string
+
+type rateLimitOptions struct {
+	rate    float64
+	burst   int
+	store   RateLimitStore
+	keyFunc RateLimitKeyFunc
+}
+
+// RateLimitOption represents an option for the RateLimitMiddleware.
+type RateLimitOption func(*rateLimitOptions)
+
+// RateLimitRate sets the number of requests per second allowed for each key, in the long run.
+// The default is 10.
+func RateLimitRate(rate float64) RateLimitOption {
+	return func(c *rateLimitOptions) {
+		c.rate = rate
+	}
+}
+
+// RateLimitBurst sets the number of requests allowed for each key in a burst, before
+// requests are limited to the rate (see RateLimitRate).
+// The default is 20.
+func RateLimitBurst(burst int) RateLimitOption {
+	return func(c *rateLimitOptions) {
+		c.burst = burst
+	}
+}
+
+// RateLimitStorage sets the RateLimitStore used for keeping the token buckets. Middlewares
+// sharing a store also share the bucket of a key, so the key is limited across all of them.
+// The default is a new MemoryRateLimitStore per middleware.
+func RateLimitStorage(store RateLimitStore) RateLimitOption {
+	return func(c *rateLimitOptions) {
+		c.store = store
+	}
+}
+
+// RateLimitKey sets the function for deriving the rate limit key of a request.
+// The default is DefaultRateLimitKey.
+func RateLimitKey(keyFunc RateLimitKeyFunc) RateLimitOption {
+	return func(c *rateLimitOptions) {
+		c.keyFunc = keyFunc
+	}
+}
+
+// DefaultRateLimitKey keys requests by the user UUID of the auth claims (see UserUUIDFromRequestContext),
+// and falls back to the remote IP of the request, if no auth claims are present.
+func DefaultRateLimitKey(r *http.Request) string {
+	if userUUID, err := UserUUIDFromRequestContext(r.Context()); err == nil {
+		return "user:" + userUUID
+	}
+
+	return "ip:" + remoteIP(r)
+}
+
+// remoteIP returns the IP of the remote address of the given request, without the port.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// RateLimitMiddleware is an opt-in http middleware for throttling requests per principal, via
+// token buckets of the configured RateLimitStore. Requests exceeding the limit are answered with
+// a 429 Too Many Requests, with a Retry-After header announcing when the next request is allowed.
+// Requests are keyed via DefaultRateLimitKey by default, which requires the auth claims - so the
+// middleware belongs after the AuthClaimsMiddleware in the chain.
+// Errors of the RateLimitStore are only logged, and the request is allowed.
+func RateLimitMiddleware(opts ...RateLimitOption) func(http.Handler) http.Handler {
+	// default
+	config := &rateLimitOptions{
+		rate:    10,
+		burst:   20,
+		store:   nil,
+		keyFunc: DefaultRateLimitKey,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	store := config.store
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := config.keyFunc(r)
+			if key == "" {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			allowed, retryAfter, err := store.Take(r.Context(), key, config.rate, config.burst)
+			if err != nil {
+				zerolog.Ctx(r.Context()).Warn().Err(err).Msg("Failed to check rate limit")
+			} else if !allowed {
+				zerolog.Ctx(r.Context()).Debug().Msgf("Rate limit exceeded for %s", key)
+
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(max(retryAfter, time.Second).Seconds()))))
+				WriteError(r.Context(), w, r, http.StatusTooManyRequests, ErrRateLimitExceeded)
+
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MemoryRateLimitStore is an in-memory implementation of RateLimitStore.
+// Buckets which are completely refilled are evicted lazily, at most once a minute. Each bucket
+// is refilled with the rate and burst it was last taken from, so buckets of middlewares with
+// different limits sharing the store are evicted correctly.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryRateLimitBucket
+	lastSweep time.Time
+}
+
+type memoryRateLimitBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	rate      float64
+	burst     int
+}
+
+// NewMemoryRateLimitStore creates a new, empty MemoryRateLimitStore.
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets:   map[string]*memoryRateLimitBucket{},
+		lastSweep: time.Now(),
+	}
+}
+
+// Take takes a token from the bucket of the given key, refilling the bucket with the given
+// rate (in tokens per second) since it was last accessed, up to the given burst.
+func (store *MemoryRateLimitStore) Take(_ context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	now := time.Now()
+	store.sweep(now)
+
+	bucket, found := store.buckets[key]
+	if !found {
+		bucket = &memoryRateLimitBucket{tokens: float64(burst), updatedAt: now}
+		store.buckets[key] = bucket
+	}
+
+	bucket.tokens = refilledTokens(bucket, now, rate, burst)
+	bucket.updatedAt = now
+	bucket.rate = rate
+	bucket.burst = burst
+
+	if bucket.tokens < 1 {
+		if rate <= 0 {
+			return false, time.Duration(math.MaxInt64), nil
+		}
+
+		return false, time.Duration((1 - bucket.tokens) / rate * float64(time.Second)), nil
+	}
+
+	bucket.tokens--
+
+	return true, 0, nil
+}
+
+func (store *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < time.Minute {
+		return
+	}
+
+	store.lastSweep = now
+
+	for key, bucket := range store.buckets {
+		if refilledTokens(bucket, now, bucket.rate, bucket.burst) >= float64(bucket.burst) {
+			delete(store.buckets, key)
+		}
+	}
+}
+
+func refilledTokens(bucket *memoryRateLimitBucket, now time.Time, rate float64, burst int) float64 {
+	return math.Min(float64(burst), bucket.tokens+now.Sub(bucket.updatedAt).Seconds()*rate)
+}
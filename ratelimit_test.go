@@ -0,0 +1,167 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type RateLimitSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestRateLimitSuite(t *testing.T) {
+	suite.Run(t, &RateLimitSuite{})
+}
+
+func (s *RateLimitSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+	s.request.RemoteAddr = "192.0.2.1:1234"
+}
+
+func (s *RateLimitSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, float64, int) (bool, time.Duration, error) {
+	return false, 0, errors.New("some-error")
+}
+
+func (s *RateLimitSuite) Test_RateLimitMiddleware_Exceeded() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	middleware := turtleware.RateLimitMiddleware(
+		turtleware.RateLimitRate(0.5),
+		turtleware.RateLimitBurst(2),
+	)(nextCapture)
+
+	for i := 0; i < 2; i++ {
+		middleware.ServeHTTP(httptest.NewRecorder(), s.request)
+	}
+
+	s.Require().True(nextCapture.Called)
+	nextCapture.Called = false
+
+	// when
+	middleware.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusTooManyRequests, s.response.Code)
+	s.Equal("2", s.response.Header().Get("Retry-After"))
+	s.Contains(s.response.Body.String(), turtleware.ErrRateLimitExceeded.Error())
+}
+
+func (s *RateLimitSuite) Test_RateLimitMiddleware_Keys() {
+	s.Run("User", func() {
+		// given
+		var recordedKey string
+
+		keyCapture := func(r *http.Request) string {
+			recordedKey = turtleware.DefaultRateLimitKey(r)
+			return recordedKey
+		}
+
+		// when
+		s.buildAuthChain(turtleware.RateLimitMiddleware(turtleware.RateLimitKey(keyCapture))(&MiddlewareCapture{})).ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal("user:"+s.userUUID, recordedKey)
+	})
+
+	s.Run("Remote_IP", func() {
+		// when
+		key := turtleware.DefaultRateLimitKey(s.request)
+
+		// then
+		s.Equal("ip:192.0.2.1", key)
+	})
+
+	s.Run("Separate_Buckets", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		middleware := turtleware.RateLimitMiddleware(turtleware.RateLimitBurst(1))(nextCapture)
+		middleware.ServeHTTP(httptest.NewRecorder(), s.request)
+
+		otherRequest := s.request.Clone(s.request.Context())
+		otherRequest.RemoteAddr = "192.0.2.2:1234"
+
+		// when
+		middleware.ServeHTTP(s.response, otherRequest)
+
+		// then
+		s.Equal(http.StatusOK, s.response.Code)
+	})
+
+	s.Run("Empty_Key", func() {
+		// given
+		nextCapture := &MiddlewareCapture{}
+
+		middleware := turtleware.RateLimitMiddleware(
+			turtleware.RateLimitBurst(0),
+			turtleware.RateLimitKey(func(r *http.Request) string { return "" }),
+		)(nextCapture)
+
+		// when
+		middleware.ServeHTTP(s.response, s.request)
+
+		// then
+		s.True(nextCapture.Called)
+	})
+}
+
+func (s *RateLimitSuite) Test_RateLimitMiddleware_StoreError() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	middleware := turtleware.RateLimitMiddleware(
+		turtleware.RateLimitStorage(failingRateLimitStore{}),
+	)(nextCapture)
+
+	// when
+	middleware.ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Empty(s.response.Header().Get("Retry-After"))
+}
+
+func (s *RateLimitSuite) Test_MemoryRateLimitStore() {
+	// given
+	store := turtleware.NewMemoryRateLimitStore()
+	ctx := context.Background()
+
+	// when
+	first, _, firstErr := store.Take(ctx, "some-key", 10, 1)
+	second, retryAfter, secondErr := store.Take(ctx, "some-key", 10, 1)
+
+	time.Sleep(150 * time.Millisecond)
+	third, _, thirdErr := store.Take(ctx, "some-key", 10, 1)
+
+	// then
+	s.NoError(firstErr)
+	s.True(first)
+
+	s.NoError(secondErr)
+	s.False(second)
+	s.InDelta(100*time.Millisecond, retryAfter, float64(10*time.Millisecond))
+
+	s.NoError(thirdErr)
+	s.True(third)
+}
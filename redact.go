@@ -0,0 +1,256 @@
+package turtleware
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// RedactedValue is the value that redacted fields are replaced with by Redact.
+const RedactedValue = "***"
+
+// Redact masks all struct fields of the given data, which are not supposed to show up
+// in logs (e.g. passwords or tokens). Fields are marked for redaction via a struct tag,
+// such as `turtleware:"redact"`, and their values are replaced with RedactedValue.
+// If the data does not contain any redacted fields, the data is returned as-is.
+// Otherwise, structs containing redacted fields are converted to maps, keyed by their
+// JSON field names. The given data itself is never modified.
+func Redact(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+
+	value := reflect.ValueOf(data)
+	if !needsRedaction(value) {
+		return data
+	}
+
+	return redactValue(value)
+}
+
+func redactValue(v reflect.Value) interface{} {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		return redactValue(v.Elem())
+	}
+
+	if !needsRedaction(v) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+
+		return redactValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+
+		result := make([]interface{}, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			result[i] = redactValue(v.Index(i))
+		}
+
+		return result
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+
+		result := make(map[string]interface{}, v.Len())
+
+		iter := v.MapRange()
+		for iter.Next() {
+			result[fmt.Sprint(iter.Key().Interface())] = redactValue(iter.Value())
+		}
+
+		return result
+	case reflect.Struct:
+		result := make(map[string]interface{}, v.NumField())
+		redactStruct(v, result)
+
+		return result
+	default:
+		return v.Interface()
+	}
+}
+
+func redactStruct(v reflect.Value, result map[string]interface{}) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, omitEmpty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		fieldValue := v.Field(i)
+
+		if field.Anonymous && name == "" {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				redactStruct(embedded, result)
+
+				continue
+			}
+		}
+
+		if omitEmpty && isEmptyValue(fieldValue) {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if isFieldRedacted(field) {
+			result[name] = RedactedValue
+
+			continue
+		}
+
+		result[name] = redactValue(fieldValue)
+	}
+}
+
+func isFieldRedacted(field reflect.StructField) bool {
+	for _, option := range strings.Split(field.Tag.Get("turtleware"), ",") {
+		if strings.TrimSpace(option) == "redact" {
+			return true
+		}
+	}
+
+	return false
+}
+
+type redaction int
+
+const (
+	// redactionNone marks types without any fields marked for redaction.
+	redactionNone redaction = iota
+
+	// redactionDynamic marks types, which may contain fields marked for redaction
+	// in the dynamic values of interface types (e.g. map[string]interface{}).
+	redactionDynamic
+
+	// redactionStatic marks types, which contain fields marked for redaction.
+	redactionStatic
+)
+
+// needsRedaction reports if the given value contains fields marked for redaction.
+// Values of interface types are inspected at runtime.
+func needsRedaction(v reflect.Value) bool {
+	switch typeRedaction(v.Type()) {
+	case redactionNone:
+		return false
+	case redactionStatic:
+		return true
+	case redactionDynamic:
+		// Inspect the value below
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		return !v.IsNil() && needsRedaction(v.Elem())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if needsRedaction(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if needsRedaction(iter.Value()) {
+				return true
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() && needsRedaction(v.Field(i)) {
+				return true
+			}
+		}
+	default:
+		// Nothing to redact
+	}
+
+	return false
+}
+
+var redactedTypes sync.Map
+
+// typeRedaction reports if the given type (or any type contained in it) has fields
+// marked for redaction, or may have them via interface types. The result is cached per type.
+func typeRedaction(t reflect.Type) redaction {
+	if cached, ok := redactedTypes.Load(t); ok {
+		return cached.(redaction)
+	}
+
+	result := inspectTypeRedaction(t, map[reflect.Type]struct{}{})
+
+	// Only the result of the inspected type itself is cached, as the results of the types
+	// contained in it are incomplete, if they refer back to the inspected type.
+	redactedTypes.Store(t, result)
+
+	return result
+}
+
+func inspectTypeRedaction(t reflect.Type, visiting map[reflect.Type]struct{}) redaction {
+	if cached, ok := redactedTypes.Load(t); ok {
+		return cached.(redaction)
+	}
+
+	// Guard against recursive types - the result is accounted for by the outer inspection
+	if _, found := visiting[t]; found {
+		return redactionNone
+	}
+
+	visiting[t] = struct{}{}
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return redactionDynamic
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return inspectTypeRedaction(t.Elem(), visiting)
+	case reflect.Struct:
+		result := redactionNone
+
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			if isFieldRedacted(field) {
+				return redactionStatic
+			}
+
+			result = max(result, inspectTypeRedaction(field.Type, visiting))
+		}
+
+		return result
+	default:
+		return redactionNone
+	}
+}
@@ -0,0 +1,146 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"testing"
+	"time"
+)
+
+type RedactSuite struct {
+	CommonSuite
+}
+
+func TestRedactSuite(t *testing.T) {
+	suite.Run(t, &RedactSuite{})
+}
+
+type testRedactCredentials struct {
+	Token string `json:"token" turtleware:"redact"`
+}
+
+type TestRedactEmbedded struct {
+	Secret string `turtleware:"redact"`
+}
+
+type testRedactModel struct {
+	TestRedactEmbedded
+
+	Username    string                           `json:"username"`
+	Password    string                           `json:"password" turtleware:"redact"`
+	Ignored     string                           `json:"-"`
+	Created     time.Time                        `json:"created"`
+	Credentials *testRedactCredentials           `json:"credentials"`
+	History     []testRedactCredentials          `json:"history"`
+	Keyed       map[string]testRedactCredentials `json:"keyed"`
+}
+
+type testRedactWrapper struct {
+	Payload interface{} `json:"payload"`
+}
+
+type testRedactNode struct {
+	Name     string           `json:"name"`
+	Children []testRedactNode `json:"children,omitempty"`
+	Password string           `json:"password" turtleware:"redact"`
+}
+
+func (s *RedactSuite) Test_Redact() {
+	s.Run("Struct", func() {
+		// given
+		created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		model := testRedactModel{
+			TestRedactEmbedded: TestRedactEmbedded{Secret: "embedded-secret"},
+			Username:           "some-user",
+			Password:           "some-password",
+			Ignored:            "ignored",
+			Created:            created,
+			Credentials:        &testRedactCredentials{Token: "some-token"},
+			History:            []testRedactCredentials{{Token: "old-token"}},
+			Keyed:              map[string]testRedactCredentials{"key": {Token: "keyed-token"}},
+		}
+
+		// when
+		redacted := turtleware.Redact(model)
+
+		// then
+		s.Equal(map[string]any{
+			"Secret":      turtleware.RedactedValue,
+			"username":    "some-user",
+			"password":    turtleware.RedactedValue,
+			"created":     created,
+			"credentials": map[string]any{"token": turtleware.RedactedValue},
+			"history":     []any{map[string]any{"token": turtleware.RedactedValue}},
+			"keyed":       map[string]any{"key": map[string]any{"token": turtleware.RedactedValue}},
+		}, redacted)
+		s.Equal("some-password", model.Password)
+	})
+
+	s.Run("Interface_Field", func() {
+		// when
+		redacted := turtleware.Redact(testRedactWrapper{Payload: testRedactCredentials{Token: "some-token"}})
+
+		// then
+		s.Equal(map[string]any{
+			"payload": map[string]any{"token": turtleware.RedactedValue},
+		}, redacted)
+	})
+
+	s.Run("Interface_Map", func() {
+		// when
+		redacted := turtleware.Redact(map[string]interface{}{
+			"credentials": testRedactCredentials{Token: "some-token"},
+			"plain":       "plain",
+		})
+
+		// then
+		s.Equal(map[string]any{
+			"credentials": map[string]any{"token": turtleware.RedactedValue},
+			"plain":       "plain",
+		}, redacted)
+	})
+
+	s.Run("Interface_Plain", func() {
+		// given
+		data := testRedactWrapper{Payload: "plain"}
+
+		// when
+		redacted := turtleware.Redact(data)
+
+		// then
+		s.Equal(data, redacted)
+	})
+
+	s.Run("Recursive", func() {
+		// when
+		redacted := turtleware.Redact([]testRedactNode{{
+			Name:     "parent",
+			Children: []testRedactNode{{Name: "child", Password: "child-password"}},
+			Password: "parent-password",
+		}})
+
+		// then
+		s.Equal([]any{map[string]any{
+			"name":     "parent",
+			"children": []any{map[string]any{"name": "child", "password": turtleware.RedactedValue}},
+			"password": turtleware.RedactedValue,
+		}}, redacted)
+	})
+
+	s.Run("Nil", func() {
+		// when
+		redacted := turtleware.Redact(nil)
+
+		// then
+		s.Nil(redacted)
+	})
+
+	s.Run("Plain", func() {
+		// when
+		redacted := turtleware.Redact("plain")
+
+		// then
+		s.Equal("plain", redacted)
+	})
+}
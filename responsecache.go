@@ -0,0 +1,422 @@
+package turtleware
+
+import (
+	"github.com/rs/zerolog"
+
+	"bytes"
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a response stored by the ResponseCacheMiddleware.
+// RequestHeader holds the values of the request headers named in the Vary header
+// of the response, so the response is only served to matching requests.
+type CachedResponse struct {
+	StatusCode    int
+	Header        http.Header
+	Body          []byte
+	StoredAt      time.Time
+	RequestHeader http.Header
+}
+
+// ResponseCache is the contract for stores used by the ResponseCacheMiddleware.
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the response stored for the given key, and whether one was found.
+	Get(ctx context.Context, key string) (CachedResponse, bool, error)
+
+	// Set stores the response for the given key, for at most the given TTL.
+	Set(ctx context.Context, key string, response CachedResponse, ttl time.Duration) error
+}
+
+// ResponseCacheKeyFunc is a function for deriving the cache key of a request.
+// If an empty key is returned, the request is not cached.
+type ResponseCacheKeyFunc func(r *http.Request) (string, error)
+
+type responseCacheOptions struct {
+	ttl time.Duration
+}
+
+// ResponseCacheOption represents an option for the ResponseCacheMiddleware.
+type ResponseCacheOption func(*responseCacheOptions)
+
+// ResponseCacheTTL sets the duration responses are served from the cache.
+// The default is one minute.
+func ResponseCacheTTL(ttl time.Duration) ResponseCacheOption {
+	return func(c *responseCacheOptions) {
+		c.ttl = ttl
+	}
+}
+
+// ResponseCacheMiddleware is an opt-in middleware for caching complete responses (status, headers,
+// and body) of successful GET requests in the provided ResponseCache, keyed by the provided
+// ResponseCacheKeyFunc. Cached responses are served without calling the next handler, until the
+// TTL elapses.
+// The middleware follows the semantics of a shared cache: Responses with Cache-Control no-store,
+// no-cache, private or max-age=0 (or s-maxage=0) are never stored, and responses to requests with
+// an Authorization header are only stored if explicitly allowed via Cache-Control public or s-maxage.
+// Responses are only served to requests matching the headers named in their Vary header, and
+// responses with Vary * or Set-Cookie are never stored. Requests with Cache-Control no-store or
+// no-cache bypass the cache. Headers belonging to a single request (such as X-Request-Id or the
+// trace context) are neither stored nor replayed.
+// Errors of the ResponseCache are only logged, and the request is handled as a cache miss.
+func ResponseCacheMiddleware(
+	store ResponseCache,
+	keyFunc ResponseCacheKeyFunc,
+	opts ...ResponseCacheOption,
+) func(h http.Handler) http.Handler {
+	// default
+	config := &responseCacheOptions{
+		ttl: time.Minute,
+	}
+
+	// apply opts
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger := zerolog.Ctx(r.Context())
+
+			requestDirectives := cacheControlDirectives(r.Header)
+			if r.Method != http.MethodGet || hasDirective(requestDirectives, "no-store", "no-cache") {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			key, err := keyFunc(r)
+			if err != nil || key == "" {
+				h.ServeHTTP(w, r)
+
+				return
+			}
+
+			cached, found, err := store.Get(r.Context(), key)
+			if err != nil {
+				logger.Warn().Err(err).Msg("Failed to receive cached response")
+			} else if found && varyMatches(r, cached) {
+				logger.Debug().Msg("Serving cached response")
+				writeCachedResponse(w, cached)
+
+				return
+			}
+
+			writer := &cachingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+			h.ServeHTTP(writer, r)
+
+			if writer.statusCode != http.StatusOK || !isStorableResponse(r, writer.Header()) {
+				return
+			}
+
+			if err := store.Set(r.Context(), key, CachedResponse{
+				StatusCode:    writer.statusCode,
+				Header:        replayableHeader(writer.Header()),
+				Body:          writer.body.Bytes(),
+				StoredAt:      time.Now(),
+				RequestHeader: varyRequestHeader(r, writer.Header()),
+			}, config.ttl); err != nil {
+				logger.Warn().Err(err).Msg("Failed to store cached response")
+			}
+		})
+	}
+}
+
+// perRequestHeaders are response headers, which belong to a single request, and thus
+// must never be replayed for another request.
+var perRequestHeaders = []string{"X-Request-Id", "Traceparent", "Tracestate", "Set-Cookie"}
+
+// replayableHeader returns a copy of the given header, without the perRequestHeaders.
+func replayableHeader(header http.Header) http.Header {
+	replayable := header.Clone()
+
+	for _, name := range perRequestHeaders {
+		replayable.Del(name)
+	}
+
+	return replayable
+}
+
+// writeReplayedHeader writes the given stored header to the response, keeping the
+// perRequestHeaders already present in the response.
+func writeReplayedHeader(w http.ResponseWriter, header http.Header) {
+	for name, values := range header {
+		if slices.Contains(perRequestHeaders, http.CanonicalHeaderKey(name)) {
+			continue
+		}
+
+		w.Header()[name] = append([]string(nil), values...)
+	}
+}
+
+func writeCachedResponse(w http.ResponseWriter, cached CachedResponse) {
+	writeReplayedHeader(w, cached.Header)
+
+	w.Header().Set("Age", strconv.Itoa(int(time.Since(cached.StoredAt).Seconds())))
+	w.WriteHeader(cached.StatusCode)
+
+	_, _ = w.Write(cached.Body)
+}
+
+func isStorableResponse(r *http.Request, header http.Header) bool {
+	responseDirectives := cacheControlDirectives(header)
+	if hasDirective(responseDirectives, "no-store", "no-cache", "private") {
+		return false
+	}
+
+	// s-maxage takes precedence over max-age for shared caches
+	if sharedMaxAge, found := responseDirectives["s-maxage"]; found {
+		if sharedMaxAge == "0" {
+			return false
+		}
+	} else if responseDirectives["max-age"] == "0" {
+		return false
+	}
+
+	if slices.Contains(varyHeaderNames(header), "*") {
+		return false
+	}
+
+	// Cookies are specific to the client
+	if header.Get("Set-Cookie") != "" {
+		return false
+	}
+
+	if r.Header.Get("Authorization") != "" {
+		return hasDirective(responseDirectives, "public", "s-maxage")
+	}
+
+	return true
+}
+
+// varyHeaderNames returns the canonical header names listed in the Vary header.
+func varyHeaderNames(header http.Header) []string {
+	var names []string
+
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	return names
+}
+
+// varyRequestHeader returns the values of the request headers named in the Vary header of the response.
+func varyRequestHeader(r *http.Request, responseHeader http.Header) http.Header {
+	requestHeader := http.Header{}
+
+	for _, name := range varyHeaderNames(responseHeader) {
+		requestHeader[name] = append([]string(nil), r.Header.Values(name)...)
+	}
+
+	return requestHeader
+}
+
+// varyMatches checks whether the request matches the request headers of the cached response.
+func varyMatches(r *http.Request, cached CachedResponse) bool {
+	for _, name := range varyHeaderNames(cached.Header) {
+		if !slices.Equal(r.Header.Values(name), cached.RequestHeader.Values(name)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func cacheControlDirectives(header http.Header) map[string]string {
+	directives := map[string]string{}
+
+	for _, value := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(value, ",") {
+			name, argument, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			directives[strings.ToLower(name)] = strings.Trim(argument, `"`)
+		}
+	}
+
+	return directives
+}
+
+func hasDirective(directives map[string]string, names ...string) bool {
+	for _, name := range names {
+		if _, found := directives[name]; found {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cachingWriter is a wrapper for a http.ResponseWriter for capturing
+// the http status code and body, while writing through.
+type cachingWriter struct {
+	http.ResponseWriter
+
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (w *cachingWriter) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *cachingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+// MemoryResponseCache is an in-memory implementation of ResponseCache.
+// Expired entries are evicted lazily, when accessed, and swept once the cache is full.
+type MemoryResponseCache struct {
+	entries *expiringMap[CachedResponse]
+}
+
+// NewMemoryResponseCache creates a new, empty MemoryResponseCache, which holds at
+// most DefaultMemoryMaxEntries entries.
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return NewMemoryResponseCacheWithMaxEntries(DefaultMemoryMaxEntries)
+}
+
+// NewMemoryResponseCacheWithMaxEntries creates a new, empty MemoryResponseCache, which holds
+// at most the given number of entries. If the cache is full, and no entry is expired yet, the
+// entry expiring the soonest is evicted.
+func NewMemoryResponseCacheWithMaxEntries(maxEntries int) *MemoryResponseCache {
+	return &MemoryResponseCache{
+		entries: newExpiringMap[CachedResponse](maxEntries),
+	}
+}
+
+// Get returns the response stored for the given key, if it is not expired yet.
+func (cache *MemoryResponseCache) Get(_ context.Context, key string) (CachedResponse, bool, error) {
+	response, found := cache.entries.get(key)
+
+	return response, found, nil
+}
+
+// Set stores the response for the given key, for at most the given TTL.
+func (cache *MemoryResponseCache) Set(_ context.Context, key string, response CachedResponse, ttl time.Duration) error {
+	cache.entries.set(key, response, ttl)
+
+	return nil
+}
+
+// DefaultMemoryMaxEntries is the default number of entries held by the in-memory stores
+// (see NewMemoryResponseCache).
+const DefaultMemoryMaxEntries = 10000
+
+// expiringMap is a size bounded map of entries with an expiry, for the in-memory stores.
+type expiringMap[T any] struct {
+	mu         sync.Mutex
+	entries    map[string]expiringEntry[T]
+	maxEntries int
+}
+
+type expiringEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func newExpiringMap[T any](maxEntries int) *expiringMap[T] {
+	if maxEntries < 1 {
+		maxEntries = DefaultMemoryMaxEntries
+	}
+
+	return &expiringMap[T]{
+		entries:    map[string]expiringEntry[T]{},
+		maxEntries: maxEntries,
+	}
+}
+
+func (m *expiringMap[T]) get(key string) (T, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, found := m.entries[key]
+	if !found {
+		var empty T
+		return empty, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(m.entries, key)
+
+		var empty T
+		return empty, false
+	}
+
+	return entry.value, true
+}
+
+func (m *expiringMap[T]) set(key string, value T, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.store(key, value, ttl)
+}
+
+// add sets the value for the given key, unless a not yet expired value exists for it,
+// and reports whether the value was set.
+func (m *expiringMap[T]) add(key string, value T, ttl time.Duration) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry, found := m.entries[key]; found && !time.Now().After(entry.expiresAt) {
+		return false
+	}
+
+	m.store(key, value, ttl)
+
+	return true
+}
+
+func (m *expiringMap[T]) delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+}
+
+func (m *expiringMap[T]) store(key string, value T, ttl time.Duration) {
+	if _, found := m.entries[key]; !found && len(m.entries) >= m.maxEntries {
+		m.evict()
+	}
+
+	m.entries[key] = expiringEntry[T]{
+		value:     value,
+		expiresAt: time.Now().Add(ttl),
+	}
+}
+
+// evict sweeps all expired entries. If none expired, the entry expiring the soonest is evicted.
+func (m *expiringMap[T]) evict() {
+	now := time.Now()
+
+	soonestKey := ""
+	soonestExpiry := time.Time{}
+
+	for key, entry := range m.entries {
+		if now.After(entry.expiresAt) {
+			delete(m.entries, key)
+
+			continue
+		}
+
+		if soonestKey == "" || entry.expiresAt.Before(soonestExpiry) {
+			soonestKey, soonestExpiry = key, entry.expiresAt
+		}
+	}
+
+	if len(m.entries) >= m.maxEntries {
+		delete(m.entries, soonestKey)
+	}
+}
@@ -0,0 +1,293 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type ResponseCacheSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+	store    *turtleware.MemoryResponseCache
+}
+
+func TestResponseCacheSuite(t *testing.T) {
+	suite.Run(t, &ResponseCacheSuite{})
+}
+
+func (s *ResponseCacheSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+	s.store = turtleware.NewMemoryResponseCache()
+}
+
+func (s *ResponseCacheSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *ResponseCacheSuite) keyFunc(r *http.Request) (string, error) {
+	return r.URL.String(), nil
+}
+
+// countingHandler returns a handler, which counts its calls, and responds
+// with the given Cache-Control header.
+func (s *ResponseCacheSuite) countingHandler(calls *int, cacheControl string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"some":"data"}`))
+	})
+}
+
+func (s *ResponseCacheSuite) Test_ResponseCacheMiddleware_Hit() {
+	// given
+	calls := 0
+	handler := turtleware.ResponseCacheMiddleware(s.store, s.keyFunc)(s.countingHandler(&calls, ""))
+	handler.ServeHTTP(httptest.NewRecorder(), s.request)
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(1, calls)
+	s.Equal(http.StatusOK, s.response.Code)
+	s.Equal("application/json", s.response.Header().Get("Content-Type"))
+	s.Equal("0", s.response.Header().Get("Age"))
+	s.Equal(`{"some":"data"}`, s.response.Body.String())
+}
+
+func (s *ResponseCacheSuite) Test_ResponseCacheMiddleware_Expired() {
+	// given
+	calls := 0
+	handler := turtleware.ResponseCacheMiddleware(
+		s.store,
+		s.keyFunc,
+		turtleware.ResponseCacheTTL(-time.Second),
+	)(s.countingHandler(&calls, ""))
+	handler.ServeHTTP(httptest.NewRecorder(), s.request)
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(2, calls)
+	s.Empty(s.response.Header().Get("Age"))
+}
+
+func (s *ResponseCacheSuite) Test_ResponseCacheMiddleware_Bypass() {
+	cases := map[string]struct {
+		cacheControl string
+		prepare      func(r *http.Request)
+	}{
+		"Response_No_Store":   {cacheControl: "no-store"},
+		"Response_Private":    {cacheControl: "private, max-age=60"},
+		"Response_No_Cache":   {cacheControl: "no-cache"},
+		"Response_Max_Age_0":  {cacheControl: "must-revalidate, max-age=0"},
+		"Response_S_Maxage_0": {cacheControl: "max-age=60, s-maxage=0"},
+		"Request_No_Cache": {prepare: func(r *http.Request) {
+			r.Header.Set("Cache-Control", "no-cache")
+		}},
+		"Authorization": {prepare: func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer some-token")
+		}},
+		"Authorization_Must_Revalidate": {cacheControl: "must-revalidate, max-age=60", prepare: func(r *http.Request) {
+			r.Header.Set("Authorization", "Bearer some-token")
+		}},
+		"Non_GET": {prepare: func(r *http.Request) {
+			r.Method = http.MethodPost
+		}},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			if target.prepare != nil {
+				target.prepare(s.request)
+			}
+
+			calls := 0
+			handler := turtleware.ResponseCacheMiddleware(s.store, s.keyFunc)(s.countingHandler(&calls, target.cacheControl))
+			handler.ServeHTTP(httptest.NewRecorder(), s.request)
+
+			// when
+			handler.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(2, calls)
+			s.Equal(`{"some":"data"}`, s.response.Body.String())
+		})
+	}
+}
+
+func (s *ResponseCacheSuite) Test_ResponseCacheMiddleware_Authorization_Public() {
+	// given
+	s.request.Header.Set("Authorization", "Bearer some-token")
+
+	calls := 0
+	handler := turtleware.ResponseCacheMiddleware(s.store, s.keyFunc)(s.countingHandler(&calls, "public, max-age=60"))
+	handler.ServeHTTP(httptest.NewRecorder(), s.request)
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(1, calls)
+}
+
+func (s *ResponseCacheSuite) Test_ResponseCacheMiddleware_Set_Cookie() {
+	// given
+	calls := 0
+	handler := turtleware.ResponseCacheMiddleware(s.store, s.keyFunc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.Header().Set("Set-Cookie", "session=some-session")
+		_, _ = w.Write([]byte(`{"some":"data"}`))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), s.request)
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(2, calls)
+	s.Empty(s.response.Header().Get("Age"))
+}
+
+func (s *ResponseCacheSuite) Test_ResponseCacheMiddleware_Per_Request_Headers() {
+	// given
+	calls := 0
+	handler := turtleware.ErrorIdentifiersMiddleware(
+		turtleware.ResponseCacheMiddleware(s.store, s.keyFunc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+
+			w.Header().Set("Traceparent", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+			_, _ = w.Write([]byte(`{"some":"data"}`))
+		})),
+	)
+
+	firstRequest := s.request.Clone(s.request.Context())
+	firstRequest.Header.Set("X-Request-Id", "first-request")
+	handler.ServeHTTP(httptest.NewRecorder(), firstRequest)
+
+	s.request.Header.Set("X-Request-Id", "second-request")
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(1, calls)
+	s.Equal("0", s.response.Header().Get("Age"))
+	s.Equal("second-request", s.response.Header().Get("X-Request-Id"))
+	s.Empty(s.response.Header().Get("Traceparent"))
+	s.Equal(`{"some":"data"}`, s.response.Body.String())
+}
+
+func (s *ResponseCacheSuite) Test_ResponseCacheMiddleware_Vary() {
+	// given
+	calls := 0
+	handler := turtleware.ResponseCacheMiddleware(s.store, s.keyFunc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.Header().Set("Vary", "Accept")
+		_, _ = w.Write([]byte(r.Header.Get("Accept")))
+	}))
+
+	s.request.Header.Set("Accept", "application/json")
+	handler.ServeHTTP(httptest.NewRecorder(), s.request)
+
+	s.Run("Matching", func() {
+		// given
+		s.request.Header.Set("Accept", "application/json")
+
+		// when
+		handler.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(1, calls)
+		s.Equal("application/json", s.response.Body.String())
+	})
+
+	s.Run("Not_Matching", func() {
+		// given
+		s.request.Header.Set("Accept", "text/csv")
+
+		// when
+		handler.ServeHTTP(s.response, s.request)
+
+		// then
+		s.Equal(2, calls)
+		s.Equal("text/csv", s.response.Body.String())
+	})
+}
+
+func (s *ResponseCacheSuite) Test_ResponseCacheMiddleware_Vary_Wildcard() {
+	// given
+	calls := 0
+	handler := turtleware.ResponseCacheMiddleware(s.store, s.keyFunc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		w.Header().Set("Vary", "*")
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), s.request)
+
+	// when
+	handler.ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal(2, calls)
+}
+
+func (s *ResponseCacheSuite) Test_MemoryResponseCache_MaxEntries() {
+	// given
+	ctx := context.Background()
+	store := turtleware.NewMemoryResponseCacheWithMaxEntries(2)
+
+	s.Require().NoError(store.Set(ctx, "expired", turtleware.CachedResponse{}, -time.Second))
+	s.Require().NoError(store.Set(ctx, "soonest", turtleware.CachedResponse{}, time.Minute))
+
+	// when
+	s.Require().NoError(store.Set(ctx, "first", turtleware.CachedResponse{}, time.Hour))
+	s.Require().NoError(store.Set(ctx, "second", turtleware.CachedResponse{}, time.Hour))
+
+	// then
+	_, foundSoonest, _ := store.Get(ctx, "soonest")
+	_, foundFirst, _ := store.Get(ctx, "first")
+	_, foundSecond, _ := store.Get(ctx, "second")
+
+	s.False(foundSoonest)
+	s.True(foundFirst)
+	s.True(foundSecond)
+}
+
+func (s *ResponseCacheSuite) Test_MemoryResponseCache() {
+	// given
+	ctx := context.Background()
+	s.Require().NoError(s.store.Set(ctx, "key", turtleware.CachedResponse{StatusCode: http.StatusOK}, time.Minute))
+
+	// when
+	response, found, err := s.store.Get(ctx, "key")
+	_, foundOther, errOther := s.store.Get(ctx, "other-key")
+
+	// then
+	s.NoError(err)
+	s.True(found)
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.NoError(errOther)
+	s.False(foundOther)
+}
@@ -0,0 +1,93 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type SignedURLSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	secret   []byte
+}
+
+func TestSignedURLSuite(t *testing.T) {
+	suite.Run(t, &SignedURLSuite{})
+}
+
+func (s *SignedURLSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.secret = []byte("some-secret")
+}
+
+func (s *SignedURLSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *SignedURLSuite) Test_VerifySignedURLMiddleware_Valid() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	signedURL := turtleware.SignedURL(s.secret, "/files/"+s.entityUUID, time.Now().Add(time.Minute))
+	request := httptest.NewRequest(http.MethodGet, "https://example.com"+signedURL, http.NoBody)
+
+	// when
+	turtleware.VerifySignedURLMiddleware(s.secret)(nextCapture).ServeHTTP(s.response, request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Equal(http.StatusOK, s.response.Code)
+}
+
+func (s *SignedURLSuite) Test_VerifySignedURLMiddleware_Tampered() {
+	signedURL := turtleware.SignedURL(s.secret, "/files/"+s.entityUUID, time.Now().Add(time.Minute))
+
+	cases := map[string]string{
+		"Path":              strings.Replace(signedURL, "/files/", "/other/", 1),
+		"Expiry":            strings.Replace(signedURL, "expires=", "expires=9", 1),
+		"Signature":         strings.Replace(signedURL, "signature=", "signature=00", 1),
+		"Missing_Signature": "/files/" + s.entityUUID + "?expires=9999999999",
+		"Wrong_Secret":      turtleware.SignedURL([]byte("other-secret"), "/files/"+s.entityUUID, time.Now().Add(time.Minute)),
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+			request := httptest.NewRequest(http.MethodGet, "https://example.com"+target, http.NoBody)
+
+			// when
+			turtleware.VerifySignedURLMiddleware(s.secret)(nextCapture).ServeHTTP(s.response, request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.Equal(http.StatusForbidden, s.response.Code)
+			s.JSONEq(s.loadTestDataString("signedurl/invalid_signature.json"), s.response.Body.String())
+		})
+	}
+}
+
+func (s *SignedURLSuite) Test_VerifySignedURLMiddleware_Expired() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	signedURL := turtleware.SignedURL(s.secret, "/files/"+s.entityUUID, time.Now().Add(-time.Minute))
+	request := httptest.NewRequest(http.MethodGet, "https://example.com"+signedURL, http.NoBody)
+
+	// when
+	turtleware.VerifySignedURLMiddleware(s.secret)(nextCapture).ServeHTTP(s.response, request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusForbidden, s.response.Code)
+	s.JSONEq(s.loadTestDataString("signedurl/expired.json"), s.response.Body.String())
+}
@@ -0,0 +1,182 @@
+package turtleware
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"reflect"
+)
+
+var (
+	// ErrConflict indicates that the request conflicts with the current state of
+	// the resource, such as a unique or foreign key constraint violation.
+	ErrConflict = errors.New("conflict with current state of the resource")
+
+	// ErrSerializationFailure indicates that a transaction could not be serialized
+	// with concurrent transactions (including deadlocks), and may be retried.
+	ErrSerializationFailure = errors.New("transaction serialization failure")
+)
+
+// classifiedError is an error classified as a turtleware sentinel, retaining
+// the original error as its cause. Only the sentinel is exposed via Error, so
+// driver specific details do not leak into error responses.
+type classifiedError struct {
+	sentinel error
+	cause    error
+}
+
+func (classifiedError classifiedError) Error() string {
+	return classifiedError.sentinel.Error()
+}
+
+func (classifiedError classifiedError) Unwrap() []error {
+	return []error{classifiedError.sentinel, classifiedError.cause}
+}
+
+// ClassifySQLError translates common database driver errors into turtleware sentinels.
+// The following errors are recognized:
+//
+// - sql.ErrNoRows and os.ErrNotExist as ErrResourceNotFound
+// - unique and foreign key violations as ErrConflict
+// - serialization failures and deadlocks as ErrSerializationFailure
+//
+// Driver errors are recognized by their shape (SQLSTATE for PostgreSQL drivers such as
+// lib/pq and pgx, error numbers for MySQL, and extended result codes for SQLite), so no
+// driver is imported. Driver errors are found anywhere in the error tree, including errors
+// joined via errors.Join or wrapped via multiple %w verbs.
+// The original error remains accessible via errors.Is and errors.As.
+// Unknown errors are returned untouched.
+func ClassifySQLError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
+		return classifiedError{sentinel: ErrResourceNotFound, cause: err}
+	}
+
+	if sentinel := classifyDriverError(err); sentinel != nil {
+		return classifiedError{sentinel: sentinel, cause: err}
+	}
+
+	return err
+}
+
+// sqlStateError is implemented by PostgreSQL driver errors (lib/pq, pgx).
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+// codeError is implemented by SQLite driver errors (modernc.org/sqlite).
+type codeError interface {
+	error
+	Code() int
+}
+
+func classifyDriverError(err error) error {
+	// PostgreSQL (lib/pq, pgx)
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		return classifySQLState(stateErr.SQLState())
+	}
+
+	// SQLite (modernc.org/sqlite)
+	var codeErr codeError
+	if errors.As(err, &codeErr) {
+		return classifySQLiteCode(int64(codeErr.Code()))
+	}
+
+	// MySQL (go-sql-driver/mysql) and SQLite (mattn/go-sqlite3) errors expose their
+	// codes as fields only, so they are recognized by their fields instead
+	var sentinel error
+	walkErrorTree(err, func(current error) bool {
+		sentinel = classifyDriverErrorFields(current)
+		return sentinel != nil
+	})
+
+	return sentinel
+}
+
+func classifyDriverErrorFields(err error) error {
+	value := reflect.Indirect(reflect.ValueOf(err))
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// MySQL (go-sql-driver/mysql)
+	if number := value.FieldByName("Number"); number.IsValid() && number.CanUint() {
+		return classifyMySQLNumber(number.Uint())
+	}
+
+	// SQLite (mattn/go-sqlite3)
+	if code := value.FieldByName("ExtendedCode"); code.IsValid() && code.CanInt() {
+		return classifySQLiteCode(code.Int())
+	}
+
+	return nil
+}
+
+// walkErrorTree calls visit for the given error and all errors wrapped by it (via Unwrap() error
+// and Unwrap() []error) in the same depth-first order as errors.As, until visit returns true.
+func walkErrorTree(err error, visit func(error) bool) bool {
+	if err == nil {
+		return false
+	}
+
+	if visit(err) {
+		return true
+	}
+
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() error }:
+		return walkErrorTree(wrapper.Unwrap(), visit)
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range wrapper.Unwrap() {
+			if walkErrorTree(wrapped, visit) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func classifySQLState(sqlState string) error {
+	switch sqlState {
+	case "23505", "23503":
+		// unique_violation, foreign_key_violation
+		return ErrConflict
+	case "40001", "40P01":
+		// serialization_failure, deadlock_detected
+		return ErrSerializationFailure
+	default:
+		return nil
+	}
+}
+
+func classifyMySQLNumber(number uint64) error {
+	switch number {
+	case 1062, 1586, 1451, 1452:
+		// ER_DUP_ENTRY, ER_DUP_ENTRY_WITH_KEY_NAME, ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2
+		return ErrConflict
+	case 1213:
+		// ER_LOCK_DEADLOCK
+		return ErrSerializationFailure
+	default:
+		return nil
+	}
+}
+
+func classifySQLiteCode(code int64) error {
+	switch code {
+	case 2067, 1555, 787:
+		// SQLITE_CONSTRAINT_UNIQUE, SQLITE_CONSTRAINT_PRIMARYKEY, SQLITE_CONSTRAINT_FOREIGNKEY
+		return ErrConflict
+	case 5, 517:
+		// SQLITE_BUSY, SQLITE_BUSY_SNAPSHOT
+		return ErrSerializationFailure
+	default:
+		return nil
+	}
+}
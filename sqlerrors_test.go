@@ -0,0 +1,135 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+type SQLErrorsSuite struct {
+	CommonSuite
+}
+
+func TestSQLErrorsSuite(t *testing.T) {
+	suite.Run(t, &SQLErrorsSuite{})
+}
+
+// testPostgresError mimics the shape of lib/pq and pgx errors.
+type testPostgresError struct {
+	code string
+}
+
+func (e *testPostgresError) Error() string {
+	return "pq: " + e.code
+}
+
+func (e *testPostgresError) SQLState() string {
+	return e.code
+}
+
+// testMySQLError mimics the shape of go-sql-driver/mysql errors.
+type testMySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *testMySQLError) Error() string {
+	return fmt.Sprintf("Error %d: %s", e.Number, e.Message)
+}
+
+type testSQLiteErrNo int
+
+// testSQLiteError mimics the shape of mattn/go-sqlite3 errors.
+type testSQLiteError struct {
+	Code         testSQLiteErrNo
+	ExtendedCode testSQLiteErrNo
+}
+
+func (e testSQLiteError) Error() string {
+	return fmt.Sprintf("sqlite error %d", e.ExtendedCode)
+}
+
+// testModerncSQLiteError mimics the shape of modernc.org/sqlite errors.
+type testModerncSQLiteError struct {
+	code int
+}
+
+func (e *testModerncSQLiteError) Error() string {
+	return fmt.Sprintf("sqlite error %d", e.code)
+}
+
+func (e *testModerncSQLiteError) Code() int {
+	return e.code
+}
+
+func (s *SQLErrorsSuite) Test_ClassifySQLError() {
+	cases := map[string]struct {
+		err      error
+		expected error
+	}{
+		"NoRows":                  {err: sql.ErrNoRows, expected: turtleware.ErrResourceNotFound},
+		"NotExist":                {err: os.ErrNotExist, expected: turtleware.ErrResourceNotFound},
+		"Postgres_Unique":         {err: &testPostgresError{code: "23505"}, expected: turtleware.ErrConflict},
+		"Postgres_ForeignKey":     {err: &testPostgresError{code: "23503"}, expected: turtleware.ErrConflict},
+		"Postgres_Serialization":  {err: &testPostgresError{code: "40001"}, expected: turtleware.ErrSerializationFailure},
+		"Postgres_Deadlock":       {err: &testPostgresError{code: "40P01"}, expected: turtleware.ErrSerializationFailure},
+		"MySQL_Duplicate":         {err: &testMySQLError{Number: 1062}, expected: turtleware.ErrConflict},
+		"MySQL_ForeignKey":        {err: &testMySQLError{Number: 1452}, expected: turtleware.ErrConflict},
+		"MySQL_Deadlock":          {err: &testMySQLError{Number: 1213}, expected: turtleware.ErrSerializationFailure},
+		"SQLite_Unique":           {err: testSQLiteError{Code: 19, ExtendedCode: 2067}, expected: turtleware.ErrConflict},
+		"SQLite_PrimaryKey":       {err: testSQLiteError{Code: 19, ExtendedCode: 1555}, expected: turtleware.ErrConflict},
+		"SQLite_ForeignKey":       {err: testSQLiteError{Code: 19, ExtendedCode: 787}, expected: turtleware.ErrConflict},
+		"SQLite_Busy":             {err: testSQLiteError{Code: 5, ExtendedCode: 5}, expected: turtleware.ErrSerializationFailure},
+		"ModerncSQLite_Unique":    {err: &testModerncSQLiteError{code: 2067}, expected: turtleware.ErrConflict},
+		"Wrapped_Postgres_Unique": {err: fmt.Errorf("insert failed: %w", &testPostgresError{code: "23505"}), expected: turtleware.ErrConflict},
+		"Joined_Postgres_Unique":  {err: errors.Join(errors.New("some-error"), &testPostgresError{code: "23505"}), expected: turtleware.ErrConflict},
+		"Joined_MySQL_Deadlock":   {err: errors.Join(errors.New("some-error"), &testMySQLError{Number: 1213}), expected: turtleware.ErrSerializationFailure},
+		"Multi_Wrapped_SQLite":    {err: fmt.Errorf("%w: %w", errors.New("some-error"), testSQLiteError{Code: 5, ExtendedCode: 5}), expected: turtleware.ErrSerializationFailure},
+		"Multi_Wrapped_Modernc":   {err: fmt.Errorf("%w: %w", errors.New("some-error"), &testModerncSQLiteError{code: 787}), expected: turtleware.ErrConflict},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// when
+			err := turtleware.ClassifySQLError(target.err)
+
+			// then
+			s.ErrorIs(err, target.expected)
+			s.ErrorIs(err, target.err)
+			s.Equal(target.expected.Error(), err.Error())
+		})
+	}
+}
+
+func (s *SQLErrorsSuite) Test_ClassifySQLError_Unknown() {
+	cases := map[string]error{
+		"Plain":          errors.New("some-error"),
+		"Postgres_Other": &testPostgresError{code: "42P01"},
+		"MySQL_Other":    &testMySQLError{Number: 1146},
+		"SQLite_Other":   testSQLiteError{Code: 1, ExtendedCode: 1},
+	}
+
+	for testName, targetError := range cases {
+		s.Run(testName, func() {
+			// when
+			err := turtleware.ClassifySQLError(targetError)
+
+			// then
+			s.Equal(targetError, err)
+		})
+	}
+}
+
+func (s *SQLErrorsSuite) Test_ClassifySQLError_Nil() {
+	// when
+	err := turtleware.ClassifySQLError(nil)
+
+	// then
+	s.NoError(err)
+}
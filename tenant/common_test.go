@@ -0,0 +1,95 @@
+package tenant_test
+
+import (
+	"github.com/google/uuid"
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/kernle32dll/turtleware/tenant"
+	"github.com/lestrrat-go/jwx/v2/jwa"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/lestrrat-go/jwx/v2/jws"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"github.com/stretchr/testify/suite"
+
+	"fmt"
+	"net/http"
+)
+
+type CommonSuite struct {
+	suite.Suite
+
+	entityUUID string
+	tenantUUID string
+	userUUID   string
+
+	privateKey jwk.Key
+	keySet     jwk.Set
+}
+
+func (s *CommonSuite) SetupTest() {
+	s.entityUUID = uuid.NewString()
+	s.tenantUUID = uuid.NewString()
+	s.userUUID = uuid.NewString()
+
+	privateKey, err := jwk.FromRaw([]byte("secret-passphrase"))
+	s.Require().NoError(err)
+	s.Require().NoError(privateKey.Set(jwk.KeyIDKey, "super-key"))
+	s.Require().NoError(privateKey.Set(jwk.AlgorithmKey, jwa.HS512))
+
+	s.privateKey = privateKey
+	s.keySet = jwk.NewSet()
+	s.Require().NoError(s.keySet.AddKey(privateKey))
+}
+
+// authorize sets a signed bearer token with the given claims as the Authorization header.
+func (s *CommonSuite) authorize(r *http.Request, claims map[string]interface{}) {
+	t := jwt.New()
+
+	for k, v := range claims {
+		s.Require().NoError(t.Set(k, v))
+	}
+
+	hdr := jws.NewHeaders()
+	s.Require().NoError(hdr.Set(jwk.KeyIDKey, s.privateKey.KeyID()))
+
+	signedT, err := jwt.Sign(t, jwt.WithKey(jwa.HS512, s.privateKey, jws.WithProtectedHeaders(hdr)))
+	s.Require().NoError(err)
+
+	r.Header.Set("Authorization", fmt.Sprintf("Bearer %s", signedT))
+}
+
+// tenantClaims returns the claims of a token of the suite user, for the suite tenant.
+func (s *CommonSuite) tenantClaims() map[string]interface{} {
+	return map[string]interface{}{"uuid": s.userUUID, "tenant_uuid": s.tenantUUID}
+}
+
+// buildTenantChain authorizes the request with a token of the suite tenant, and resolves
+// the tenant UUID via tenant.UUIDMiddleware.
+func (s *CommonSuite) buildTenantChain(h http.Handler) http.Handler {
+	return alice.New(
+		func(handler http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				s.authorize(r, s.tenantClaims())
+				handler.ServeHTTP(w, r)
+			})
+		},
+		turtleware.AuthBearerHeaderMiddleware,
+		turtleware.AuthClaimsMiddleware(s.keySet),
+		tenant.UUIDMiddleware,
+	).Then(h)
+}
+
+func (s *CommonSuite) buildEntityUUIDChain(h http.Handler) http.Handler {
+	return turtleware.EntityUUIDMiddleware(func(r *http.Request) (string, error) {
+		return s.entityUUID, nil
+	})(h)
+}
+
+// MiddlewareCapture is a helper struct to capture a middleware calling the next handler.
+type MiddlewareCapture struct {
+	Called bool
+}
+
+func (m *MiddlewareCapture) ServeHTTP(http.ResponseWriter, *http.Request) {
+	m.Called = true
+}
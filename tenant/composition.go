@@ -22,18 +22,22 @@ type GetEndpoint[T any] interface {
 
 // ResourceHandler composes a full http.Handler for retrieving a single tenant scoped resource.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements turtleware.DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements turtleware.JSONAPIEndpoint, the resource may be wrapped into a JSON:API document.
+// If the endpoint implements turtleware.CORSEndpoint, OPTIONS requests are answered via the turtleware.OptionsMiddleware.
 func ResourceHandler[T any](
 	keySet jwk.Set,
 	getEndpoint GetEndpoint[T],
 ) http.Handler {
 	entityMiddleware := turtleware.EntityUUIDMiddleware(getEndpoint.EntityUUID)
 	cacheMiddleware := ResourceCacheMiddleware(getEndpoint.LastModification, getEndpoint.HandleError)
-	dataMiddleware := ResourceDataHandler(getEndpoint.FetchEntity, getEndpoint.HandleError)
+	dataOpts := append(resourceTimeoutOptions(getEndpoint), resourceJSONAPIOptions(getEndpoint)...)
+	dataMiddleware := ResourceDataHandler(getEndpoint.FetchEntity, getEndpoint.HandleError, dataOpts...)
 
-	return resourcePreHandler(keySet).Append(
+	return optionsPreHandler(readOnlyPreHandler(resourcePreHandler(keySet)).Append(
 		entityMiddleware,
 		cacheMiddleware,
-	).Then(
+	), getEndpoint, http.MethodGet, http.MethodHead).Then(
 		dataMiddleware,
 	)
 }
@@ -51,18 +55,30 @@ type GetSQLListEndpoint[T any] interface {
 
 // ListSQLHandler composes a full http.Handler for retrieving a list of resources via SQL.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements turtleware.TotalCountColumnEndpoint, the total count is derived from the result rows
+// (except for HEAD requests).
+// If the endpoint implements turtleware.DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements turtleware.JSONAPIEndpoint, the list may be wrapped into a JSON:API document.
+// If the endpoint implements turtleware.PagingLinksEndpoint, Link headers for navigating the list are emitted.
+// If the endpoint implements turtleware.CSVEndpoint, the list may be served as CSV.
+// If the endpoint implements turtleware.CORSEndpoint, OPTIONS requests are answered via the turtleware.OptionsMiddleware.
 func ListSQLHandler[T any](
 	keySet jwk.Set,
 	listEndpoint GetSQLListEndpoint[T],
 ) http.Handler {
 	cacheMiddleware := ListCacheMiddleware(listEndpoint.ListHash, listEndpoint.HandleError)
-	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError)
-	dataMiddleware := SQLListDataHandler(listEndpoint.FetchRows, listEndpoint.TransformEntity, listEndpoint.HandleError)
+	countOpts, dataOpts := countPreHandler(listEndpoint)
+	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError, countOpts...)
+	dataOpts = append(dataOpts, listTimeoutOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listJSONAPIOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listPagingLinksOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listCSVOptions(listEndpoint)...)
+	dataMiddleware := SQLListDataHandler(listEndpoint.FetchRows, listEndpoint.TransformEntity, listEndpoint.HandleError, dataOpts...)
 
-	return listPreHandler(keySet).Append(
+	return optionsPreHandler(csvPreHandler(listPreHandler(keySet), listEndpoint).Append(
 		cacheMiddleware,
 		countMiddleware,
-	).Then(
+	), listEndpoint, http.MethodGet, http.MethodHead).Then(
 		dataMiddleware,
 	)
 }
@@ -80,18 +96,30 @@ type GetSQLxListEndpoint[T any] interface {
 
 // ListSQLxHandler composes a full http.Handler for retrieving a list of resources via SQLx.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements turtleware.TotalCountColumnEndpoint, the total count is derived from the result rows
+// (except for HEAD requests).
+// If the endpoint implements turtleware.DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements turtleware.JSONAPIEndpoint, the list may be wrapped into a JSON:API document.
+// If the endpoint implements turtleware.PagingLinksEndpoint, Link headers for navigating the list are emitted.
+// If the endpoint implements turtleware.CSVEndpoint, the list may be served as CSV.
+// If the endpoint implements turtleware.CORSEndpoint, OPTIONS requests are answered via the turtleware.OptionsMiddleware.
 func ListSQLxHandler[T any](
 	keySet jwk.Set,
 	listEndpoint GetSQLxListEndpoint[T],
 ) http.Handler {
 	cacheMiddleware := ListCacheMiddleware(listEndpoint.ListHash, listEndpoint.HandleError)
-	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError)
-	dataMiddleware := SQLxListDataHandler(listEndpoint.FetchRows, listEndpoint.TransformEntity, listEndpoint.HandleError)
+	countOpts, dataOpts := countPreHandler(listEndpoint)
+	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError, countOpts...)
+	dataOpts = append(dataOpts, listTimeoutOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listJSONAPIOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listPagingLinksOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listCSVOptions(listEndpoint)...)
+	dataMiddleware := SQLxListDataHandler(listEndpoint.FetchRows, listEndpoint.TransformEntity, listEndpoint.HandleError, dataOpts...)
 
-	return listPreHandler(keySet).Append(
+	return optionsPreHandler(csvPreHandler(listPreHandler(keySet), listEndpoint).Append(
 		cacheMiddleware,
 		countMiddleware,
-	).Then(
+	), listEndpoint, http.MethodGet, http.MethodHead).Then(
 		dataMiddleware,
 	)
 }
@@ -108,18 +136,27 @@ type GetStaticListEndpoint[T any] interface {
 
 // StaticListHandler composes a full http.Handler for retrieving a list of resources via a static list.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements turtleware.DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements turtleware.JSONAPIEndpoint, the list may be wrapped into a JSON:API document.
+// If the endpoint implements turtleware.PagingLinksEndpoint, Link headers for navigating the list are emitted.
+// If the endpoint implements turtleware.CSVEndpoint, the list may be served as CSV.
+// If the endpoint implements turtleware.CORSEndpoint, OPTIONS requests are answered via the turtleware.OptionsMiddleware.
 func StaticListHandler[T any](
 	keySet jwk.Set,
 	listEndpoint GetStaticListEndpoint[T],
 ) http.Handler {
 	cacheMiddleware := ListCacheMiddleware(listEndpoint.ListHash, listEndpoint.HandleError)
 	countMiddleware := CountHeaderMiddleware(listEndpoint.TotalCount, listEndpoint.HandleError)
-	dataMiddleware := StaticListDataHandler(listEndpoint.FetchEntities, listEndpoint.HandleError)
+	dataOpts := listTimeoutOptions(listEndpoint)
+	dataOpts = append(dataOpts, listJSONAPIOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listPagingLinksOptions(listEndpoint)...)
+	dataOpts = append(dataOpts, listCSVOptions(listEndpoint)...)
+	dataMiddleware := StaticListDataHandler(listEndpoint.FetchEntities, listEndpoint.HandleError, dataOpts...)
 
-	return listPreHandler(keySet).Append(
+	return optionsPreHandler(csvPreHandler(listPreHandler(keySet), listEndpoint).Append(
 		cacheMiddleware,
 		countMiddleware,
-	).Then(
+	), listEndpoint, http.MethodGet, http.MethodHead).Then(
 		dataMiddleware,
 	)
 }
@@ -135,6 +172,9 @@ type CreateEndpoint[T turtleware.CreateDTO] interface {
 
 // ResourceCreateHandler composes a full http.Handler for creating a new tenant scoped resource.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements turtleware.CreatedEndpoint, a 201 Created with a Location header is written.
+// If the endpoint implements turtleware.JSONContentTypeEndpoint, non-JSON request bodies are rejected early.
+// If the endpoint implements turtleware.CORSEndpoint, OPTIONS requests are answered via the turtleware.OptionsMiddleware.
 func ResourceCreateHandler[T turtleware.CreateDTO](
 	keySet jwk.Set,
 	createEndpoint CreateEndpoint[T],
@@ -143,10 +183,10 @@ func ResourceCreateHandler[T turtleware.CreateDTO](
 	entityMiddleware := turtleware.EntityUUIDMiddleware(createEndpoint.EntityUUID)
 	createMiddleware := ResourceCreateMiddleware(createEndpoint.CreateEntity, createEndpoint.HandleError)
 
-	return resourcePreHandler(keySet).Append(
+	return optionsPreHandler(createdPostHandler(jsonContentTypePreHandler(resourcePreHandler(keySet), createEndpoint).Append(
 		entityMiddleware,
 		createMiddleware,
-	).Then(
+	), createEndpoint, createEndpoint.HandleError), createEndpoint, http.MethodPost).Then(
 		nextHandler,
 	)
 }
@@ -162,6 +202,8 @@ type PatchEndpoint[T turtleware.PatchDTO] interface {
 
 // ResourcePatchHandler composes a full http.Handler for updating a tenant scoped resource.
 // This includes authentication, caching, and data retrieval.
+// If the endpoint implements turtleware.JSONContentTypeEndpoint, non-JSON request bodies are rejected early.
+// If the endpoint implements turtleware.CORSEndpoint, OPTIONS requests are answered via the turtleware.OptionsMiddleware.
 func ResourcePatchHandler[T turtleware.PatchDTO](
 	keySet jwk.Set,
 	patchEndpoint PatchEndpoint[T],
@@ -170,22 +212,224 @@ func ResourcePatchHandler[T turtleware.PatchDTO](
 	entityMiddleware := turtleware.EntityUUIDMiddleware(patchEndpoint.EntityUUID)
 	patchMiddleware := ResourcePatchMiddleware(patchEndpoint.UpdateEntity, patchEndpoint.HandleError)
 
-	return resourcePreHandler(keySet).Append(
+	return optionsPreHandler(jsonContentTypePreHandler(resourcePreHandler(keySet), patchEndpoint).Append(
 		entityMiddleware,
 		patchMiddleware,
-	).Then(
+	), patchEndpoint, http.MethodPatch).Then(
 		nextHandler,
 	)
 }
 
 // --------------------------
 
+// DeleteEndpoint defines the contract for a ResourceDeleteHandler composition.
+type DeleteEndpoint interface {
+	EntityUUID(r *http.Request) (string, error)
+	DeleteEntity(ctx context.Context, tenantUUID, entityUUID, userUUID string, ifUnmodifiedSince time.Time) error
+	HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error)
+}
+
+// ResourceDeleteHandler composes a full http.Handler for deleting an existing tenant scoped resource.
+// This includes authentication, and delegation of resource deletion.
+// On success, a 204 No Content is written before the nextHandler is called.
+// If the endpoint implements turtleware.CORSEndpoint, OPTIONS requests are answered via the turtleware.OptionsMiddleware.
+func ResourceDeleteHandler(
+	keySet jwk.Set,
+	deleteEndpoint DeleteEndpoint,
+	nextHandler http.Handler,
+) http.Handler {
+	entityMiddleware := turtleware.EntityUUIDMiddleware(deleteEndpoint.EntityUUID)
+	deleteMiddleware := ResourceDeleteMiddleware(deleteEndpoint.DeleteEntity, deleteEndpoint.HandleError)
+
+	return optionsPreHandler(resourcePreHandler(keySet).Append(
+		entityMiddleware,
+		deleteMiddleware,
+	), deleteEndpoint, http.MethodDelete).Then(
+		nextHandler,
+	)
+}
+
+// --------------------------
+
+// Scoped may optionally be implemented by an endpoint, to declare if it is tenant scoped.
+// Tenant scoped endpoints can retrieve the tenant UUID via UUIDFromRequestContext.
+type Scoped interface {
+	TenantScoped() bool
+}
+
+// BuildResourceHandler composes a full http.Handler for retrieving a single resource,
+// for both tenant scoped and non-tenant scoped endpoints. If the endpoint implements
+// Scoped and declares itself tenant scoped, the UUIDMiddleware is wired into the chain.
+// Otherwise, the composition is equal to turtleware.ResourceHandler.
+// This includes authentication, caching, and data retrieval.
+// If the endpoint implements turtleware.RelatedResources, preload hints are emitted for related resources.
+// If the endpoint implements turtleware.DataTimeoutEndpoint, data retrieval is cancelled after the timeout.
+// If the endpoint implements turtleware.JSONAPIEndpoint, the resource may be wrapped into a JSON:API document.
+// If the endpoint implements turtleware.CORSEndpoint, OPTIONS requests are answered via the turtleware.OptionsMiddleware.
+func BuildResourceHandler[T any](
+	keySet jwk.Set,
+	getEndpoint turtleware.GetEndpoint[T],
+) http.Handler {
+	entityMiddleware := turtleware.EntityUUIDMiddleware(getEndpoint.EntityUUID)
+	cacheMiddleware := turtleware.ResourceCacheMiddleware(getEndpoint.LastModification, getEndpoint.HandleError)
+	dataOpts := append(resourceTimeoutOptions(getEndpoint), resourceJSONAPIOptions(getEndpoint)...)
+	dataMiddleware := turtleware.ResourceDataHandler(getEndpoint.FetchEntity, getEndpoint.HandleError, dataOpts...)
+
+	return optionsPreHandler(relatedPreHandler(readOnlyPreHandler(scopedPreHandler(keySet, getEndpoint)).Append(
+		entityMiddleware,
+		cacheMiddleware,
+	), getEndpoint), getEndpoint, http.MethodGet, http.MethodHead).Then(
+		dataMiddleware,
+	)
+}
+
+// --------------------------
+
 func listPreHandler(
 	keySet jwk.Set,
 ) alice.Chain {
 	pagingMiddleware := turtleware.PagingMiddleware
 
-	return resourcePreHandler(keySet).Append(pagingMiddleware)
+	return readOnlyPreHandler(resourcePreHandler(keySet)).Append(pagingMiddleware)
+}
+
+func optionsPreHandler(
+	chain alice.Chain,
+	endpoint any,
+	allowed ...string,
+) alice.Chain {
+	if cors, ok := endpoint.(turtleware.CORSEndpoint); ok {
+		return alice.New(turtleware.OptionsMiddleware(allowed, cors.CORSOptions()...)).Extend(chain)
+	}
+
+	return chain
+}
+
+func createdPostHandler(
+	chain alice.Chain,
+	endpoint any,
+	errorHandler turtleware.ErrorHandlerFunc,
+) alice.Chain {
+	if created, ok := endpoint.(turtleware.CreatedEndpoint); ok {
+		return chain.Append(turtleware.ResourceCreatedMiddleware(created.CreatedLocation, errorHandler))
+	}
+
+	return chain
+}
+
+func countPreHandler(
+	endpoint any,
+) ([]turtleware.CountOption, []turtleware.ListDataOption) {
+	if totalCount, ok := endpoint.(turtleware.TotalCountColumnEndpoint); ok {
+		// HEAD requests bail out before any rows are fetched, so the count is still queried for them
+		return []turtleware.CountOption{turtleware.CountOnlyForMethods(http.MethodHead)}, []turtleware.ListDataOption{
+			turtleware.ListDataTotalCountColumn(totalCount.TotalCountColumn()),
+		}
+	}
+
+	return nil, nil
+}
+
+func listTimeoutOptions(
+	endpoint any,
+) []turtleware.ListDataOption {
+	if timeout, ok := endpoint.(turtleware.DataTimeoutEndpoint); ok {
+		return []turtleware.ListDataOption{turtleware.ListDataTimeout(timeout.DataTimeout())}
+	}
+
+	return nil
+}
+
+func resourceTimeoutOptions(
+	endpoint any,
+) []turtleware.ResourceDataOption {
+	if timeout, ok := endpoint.(turtleware.DataTimeoutEndpoint); ok {
+		return []turtleware.ResourceDataOption{turtleware.ResourceDataTimeout(timeout.DataTimeout())}
+	}
+
+	return nil
+}
+
+func listJSONAPIOptions(
+	endpoint any,
+) []turtleware.ListDataOption {
+	if jsonAPI, ok := endpoint.(turtleware.JSONAPIEndpoint); ok {
+		return []turtleware.ListDataOption{turtleware.ListDataJSONAPI(jsonAPI.JSONAPI())}
+	}
+
+	return nil
+}
+
+func listPagingLinksOptions(
+	endpoint any,
+) []turtleware.ListDataOption {
+	if pagingLinks, ok := endpoint.(turtleware.PagingLinksEndpoint); ok {
+		return []turtleware.ListDataOption{turtleware.ListDataPagingLinks(pagingLinks.PagingLinks())}
+	}
+
+	return nil
+}
+
+func listCSVOptions(
+	endpoint any,
+) []turtleware.ListDataOption {
+	if csv, ok := endpoint.(turtleware.CSVEndpoint); ok && csv.CSV() {
+		return []turtleware.ListDataOption{turtleware.ListDataSerializer(turtleware.NewCSVSerializer())}
+	}
+
+	return nil
+}
+
+// csvPreHandler appends the turtleware.CSVMiddleware, which has to be placed before the cache middleware.
+func csvPreHandler(
+	chain alice.Chain,
+	endpoint any,
+) alice.Chain {
+	if csv, ok := endpoint.(turtleware.CSVEndpoint); ok && csv.CSV() {
+		return chain.Append(turtleware.CSVMiddleware)
+	}
+
+	return chain
+}
+
+func resourceJSONAPIOptions(
+	endpoint any,
+) []turtleware.ResourceDataOption {
+	if jsonAPI, ok := endpoint.(turtleware.JSONAPIEndpoint); ok {
+		return []turtleware.ResourceDataOption{turtleware.ResourceDataJSONAPI(jsonAPI.JSONAPI())}
+	}
+
+	return nil
+}
+
+func jsonContentTypePreHandler(
+	chain alice.Chain,
+	endpoint any,
+) alice.Chain {
+	if jsonContentType, ok := endpoint.(turtleware.JSONContentTypeEndpoint); ok && jsonContentType.RequireJSONContentType() {
+		return chain.Append(turtleware.RequireJSONContentTypeMiddleware)
+	}
+
+	return chain
+}
+
+func readOnlyPreHandler(
+	chain alice.Chain,
+) alice.Chain {
+	methodMiddleware := turtleware.RestrictMethods(http.MethodGet, http.MethodHead)
+
+	return alice.New(methodMiddleware).Extend(chain)
+}
+
+func relatedPreHandler(
+	chain alice.Chain,
+	endpoint any,
+) alice.Chain {
+	if related, ok := endpoint.(turtleware.RelatedResources); ok {
+		return chain.Append(turtleware.PreloadMiddleware(related.RelatedResources))
+	}
+
+	return chain
 }
 
 func resourcePreHandler(
@@ -201,3 +445,20 @@ func resourcePreHandler(
 		tenantUUIDMiddleware,
 	)
 }
+
+func scopedPreHandler(
+	keySet jwk.Set,
+	endpoint any,
+) alice.Chain {
+	if scoped, ok := endpoint.(Scoped); ok && scoped.TenantScoped() {
+		return resourcePreHandler(keySet)
+	}
+
+	authHeaderMiddleware := turtleware.AuthBearerHeaderMiddleware
+	authMiddleware := turtleware.AuthClaimsMiddleware(keySet)
+
+	return alice.New(
+		authHeaderMiddleware,
+		authMiddleware,
+	)
+}
@@ -0,0 +1,156 @@
+package tenant_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/kernle32dll/turtleware/tenant"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type CompositionSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestCompositionSuite(t *testing.T) {
+	suite.Run(t, &CompositionSuite{})
+}
+
+func (s *CompositionSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *CompositionSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+type testEntity struct {
+	EntityUUID string `json:"entity_uuid"`
+	TenantUUID string `json:"tenant_uuid"`
+}
+
+// getEndpoint is a turtleware.GetEndpoint, which returns the tenant UUID of the
+// request context (if any) alongside the entity UUID.
+type getEndpoint struct {
+	entityUUID string
+}
+
+func (e getEndpoint) EntityUUID(*http.Request) (string, error) {
+	return e.entityUUID, nil
+}
+
+func (getEndpoint) LastModification(context.Context, string) (time.Time, error) {
+	return time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), nil
+}
+
+func (getEndpoint) FetchEntity(ctx context.Context, entityUUID string) (testEntity, error) {
+	// nolint errcheck: Non-tenant scoped endpoints have no tenant UUID
+	tenantUUID, _ := tenant.UUIDFromRequestContext(ctx)
+
+	return testEntity{EntityUUID: entityUUID, TenantUUID: tenantUUID}, nil
+}
+
+func (getEndpoint) HandleError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	turtleware.DefaultErrorHandler(ctx, w, r, err)
+}
+
+type scopedGetEndpoint struct {
+	getEndpoint
+
+	scoped bool
+}
+
+func (e scopedGetEndpoint) TenantScoped() bool {
+	return e.scoped
+}
+
+func (s *CompositionSuite) Test_BuildResourceHandler() {
+	cases := map[string]struct {
+		endpoint           func(entityUUID string) turtleware.GetEndpoint[testEntity]
+		tenantClaim        bool
+		expectedStatus     int
+		expectedTenantUUID bool
+	}{
+		"Scoped": {
+			endpoint: func(entityUUID string) turtleware.GetEndpoint[testEntity] {
+				return scopedGetEndpoint{getEndpoint: getEndpoint{entityUUID: entityUUID}, scoped: true}
+			},
+			tenantClaim:        true,
+			expectedStatus:     http.StatusOK,
+			expectedTenantUUID: true,
+		},
+		"Scoped_Missing_Tenant": {
+			endpoint: func(entityUUID string) turtleware.GetEndpoint[testEntity] {
+				return scopedGetEndpoint{getEndpoint: getEndpoint{entityUUID: entityUUID}, scoped: true}
+			},
+			tenantClaim:    false,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"Not_Scoped": {
+			endpoint: func(entityUUID string) turtleware.GetEndpoint[testEntity] {
+				return scopedGetEndpoint{getEndpoint: getEndpoint{entityUUID: entityUUID}, scoped: false}
+			},
+			tenantClaim:    true,
+			expectedStatus: http.StatusOK,
+		},
+		"Not_Scoped_Missing_Tenant": {
+			endpoint: func(entityUUID string) turtleware.GetEndpoint[testEntity] {
+				return scopedGetEndpoint{getEndpoint: getEndpoint{entityUUID: entityUUID}, scoped: false}
+			},
+			tenantClaim:    false,
+			expectedStatus: http.StatusOK,
+		},
+		"Not_Implemented": {
+			endpoint: func(entityUUID string) turtleware.GetEndpoint[testEntity] {
+				return getEndpoint{entityUUID: entityUUID}
+			},
+			tenantClaim:    false,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			claims := map[string]interface{}{"uuid": s.userUUID}
+			if target.tenantClaim {
+				claims = s.tenantClaims()
+			}
+
+			s.authorize(s.request, claims)
+
+			handler := tenant.BuildResourceHandler[testEntity](s.keySet, target.endpoint(s.entityUUID))
+
+			// when
+			handler.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedStatus, s.response.Code)
+
+			if target.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var entity testEntity
+			s.Require().NoError(json.Unmarshal(s.response.Body.Bytes(), &entity))
+			s.Equal(s.entityUUID, entity.EntityUUID)
+
+			if target.expectedTenantUUID {
+				s.Equal(s.tenantUUID, entity.TenantUUID)
+			} else {
+				s.Empty(entity.TenantUUID)
+			}
+		})
+	}
+}
@@ -1,11 +1,14 @@
 package tenant
 
 import (
+	"github.com/google/uuid"
 	"github.com/kernle32dll/turtleware"
+	"github.com/rs/zerolog"
 
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 )
 
 type ctxKey int
@@ -13,6 +16,9 @@ type ctxKey int
 const (
 	// ctxTenantUUID is the context key used to pass down the tenant UUID.
 	ctxTenantUUID ctxKey = iota
+
+	// ctxTenantUUIDParsed is the context key used to pass down the parsed tenant UUID.
+	ctxTenantUUIDParsed
 )
 
 var (
@@ -23,10 +29,21 @@ var (
 	// ErrTokenMissingTenantUUID indicates that a requested was
 	// missing the tenant UUID.
 	ErrTokenMissingTenantUUID = errors.New("token does not include tenant UUID")
+
+	// ErrTenantUUIDInvalid indicates that the tenant UUID of a token
+	// is not a valid UUID.
+	ErrTenantUUIDInvalid = errors.New("tenant UUID of token is invalid")
+
+	// ErrTenantMismatch indicates that the tenant of a request route differs
+	// from the tenant of the token (see RequirePathTenantMiddleware).
+	ErrTenantMismatch = errors.New("tenant of route does not match tenant of token")
 )
 
 // UUIDMiddleware is a http middleware for checking tenant authentication details, and
 // passing down the tenant UUID if existing, or bailing out otherwise.
+// The tenant UUID is parsed once, and passed down both as-is (see UUIDFromRequestContext)
+// and as uuid.UUID (see UUIDFromRequestContextParsed). Malformed tenant UUIDs are answered
+// with a 400 and ErrTenantUUIDInvalid.
 func UUIDMiddleware(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		claims, err := turtleware.AuthClaimsFromRequestContext(r.Context())
@@ -41,10 +58,16 @@ func UUIDMiddleware(h http.Handler) http.Handler {
 			return
 		}
 
-		h.ServeHTTP(
-			w,
-			r.WithContext(context.WithValue(r.Context(), ctxTenantUUID, tenantUUID)),
-		)
+		parsedTenantUUID, err := uuid.Parse(tenantUUID)
+		if err != nil {
+			turtleware.WriteError(r.Context(), w, r, http.StatusBadRequest, ErrTenantUUIDInvalid)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), ctxTenantUUID, tenantUUID)
+		ctx = context.WithValue(ctx, ctxTenantUUIDParsed, parsedTenantUUID)
+
+		h.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
@@ -58,3 +81,111 @@ func UUIDFromRequestContext(ctx context.Context) (string, error) {
 
 	return tenantUUID, nil
 }
+
+// UUIDFromRequestContextParsed extracts the parsed tenant UUID from the request context.
+// Returns ErrContextMissingTenantUUID if the tenant UUID is missing from the context.
+func UUIDFromRequestContextParsed(ctx context.Context) (uuid.UUID, error) {
+	tenantUUID, ok := ctx.Value(ctxTenantUUIDParsed).(uuid.UUID)
+	if !ok {
+		return uuid.Nil, ErrContextMissingTenantUUID
+	}
+
+	return tenantUUID, nil
+}
+
+// RequirePathTenantMiddleware is a http middleware for checking that the tenant of the request
+// route (e.g. a path segment), as returned by the given extractor, matches the tenant of the token.
+// Requests for other tenants are answered with a 403 and ErrTenantMismatch, preventing cross-tenant
+// access when routing by URL. The tenants are compared case-insensitively. If the extractor
+// fails, the request is answered with a 400, as the route carries no usable tenant.
+// The middleware must run after the tenant UUID has been resolved (e.g. via UUIDMiddleware).
+func RequirePathTenantMiddleware(extractor func(*http.Request) (string, error)) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantUUID, err := UUIDFromRequestContext(r.Context())
+			if err != nil {
+				turtleware.WriteError(r.Context(), w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			pathTenantUUID, err := extractor(r)
+			if err != nil {
+				turtleware.WriteError(r.Context(), w, r, http.StatusBadRequest, err)
+				return
+			}
+
+			if !strings.EqualFold(tenantUUID, pathTenantUUID) {
+				zerolog.Ctx(r.Context()).Warn().Msgf("Tenant %s of route does not match tenant %s of token", pathTenantUUID, tenantUUID)
+				turtleware.WriteError(r.Context(), w, r, http.StatusForbidden, ErrTenantMismatch)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimitKeyByTenant keys requests by the tenant UUID (see UUIDFromRequestContext), for throttling
+// all users of a tenant together via turtleware.RateLimitMiddleware (see turtleware.RateLimitKey).
+// If no tenant UUID is present, it falls back to turtleware.DefaultRateLimitKey.
+func RateLimitKeyByTenant(r *http.Request) string {
+	if tenantUUID, err := UUIDFromRequestContext(r.Context()); err == nil {
+		return "tenant:" + tenantUUID
+	}
+
+	return turtleware.DefaultRateLimitKey(r)
+}
+
+// IdempotencyScopeByTenant scopes idempotency keys by the tenant UUID (see UUIDFromRequestContext), in
+// addition to the user scope of turtleware.DefaultIdempotencyScope. Use it via turtleware.IdempotencyScope
+// for the turtleware.IdempotencyMiddleware.
+func IdempotencyScopeByTenant(r *http.Request) string {
+	userScope := turtleware.DefaultIdempotencyScope(r)
+
+	tenantUUID, err := UUIDFromRequestContext(r.Context())
+	if err != nil || userScope == "" {
+		return userScope
+	}
+
+	return "tenant:" + tenantUUID + ":" + userScope
+}
+
+// EntityOwnershipFunc is a function for checking if a specific entity belongs to a given tenant.
+type EntityOwnershipFunc func(ctx context.Context, tenantUUID string, entityUUID string) (bool, error)
+
+// EntityOwnershipMiddleware is a http middleware for checking that the requested entity belongs
+// to the tenant of the token, before any data is fetched. Entities not owned by the tenant are
+// answered with a 404, so the existence of entities of other tenants is not leaked.
+// The middleware must run after both the tenant UUID and the entity UUID have been resolved
+// (e.g. via UUIDMiddleware and turtleware.EntityUUIDMiddleware).
+func EntityOwnershipMiddleware(checker EntityOwnershipFunc) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			tenantUUID, err := UUIDFromRequestContext(r.Context())
+			if err != nil {
+				turtleware.WriteError(r.Context(), w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			entityUUID, err := turtleware.EntityUUIDFromRequestContext(r.Context())
+			if err != nil {
+				turtleware.WriteError(r.Context(), w, r, http.StatusInternalServerError, err)
+				return
+			}
+
+			owned, err := checker(r.Context(), tenantUUID, entityUUID)
+			if err != nil {
+				zerolog.Ctx(r.Context()).Error().Err(err).Msg("Failed to check entity ownership")
+				turtleware.WriteError(r.Context(), w, r, http.StatusInternalServerError, turtleware.ErrReceivingMeta)
+				return
+			}
+
+			if !owned {
+				turtleware.WriteError(r.Context(), w, r, http.StatusNotFound, turtleware.ErrResourceNotFound)
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
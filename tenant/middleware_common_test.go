@@ -0,0 +1,213 @@
+package tenant_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/kernle32dll/turtleware/tenant"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type MiddlewareCommonSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestMiddlewareCommonSuite(t *testing.T) {
+	suite.Run(t, &MiddlewareCommonSuite{})
+}
+
+func (s *MiddlewareCommonSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *MiddlewareCommonSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *MiddlewareCommonSuite) Test_EntityOwnershipMiddleware() {
+	cases := map[string]struct {
+		owned          bool
+		err            error
+		expectedCalled bool
+		expectedStatus int
+		expectedError  error
+	}{
+		"Owned": {
+			owned:          true,
+			expectedCalled: true,
+			expectedStatus: http.StatusOK,
+		},
+		"Not_Owned": {
+			owned:          false,
+			expectedCalled: false,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  turtleware.ErrResourceNotFound,
+		},
+		"Error": {
+			err:            errors.New("some-error"),
+			expectedCalled: false,
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  turtleware.ErrReceivingMeta,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			checker := func(ctx context.Context, tenantUUID string, entityUUID string) (bool, error) {
+				s.Equal(s.tenantUUID, tenantUUID)
+				s.Equal(s.entityUUID, entityUUID)
+
+				return target.owned, target.err
+			}
+
+			testChain := alice.New(
+				s.buildTenantChain,
+				s.buildEntityUUIDChain,
+				tenant.EntityOwnershipMiddleware(checker),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedCalled, nextCapture.Called)
+			s.Equal(target.expectedStatus, s.response.Code)
+
+			if target.expectedError != nil {
+				s.Contains(s.response.Body.String(), target.expectedError.Error())
+			}
+		})
+	}
+}
+
+func (s *MiddlewareCommonSuite) Test_EntityOwnershipMiddleware_ErrContextMissingTenantUUID() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	checker := func(ctx context.Context, tenantUUID string, entityUUID string) (bool, error) {
+		return true, nil
+	}
+
+	testChain := alice.New(
+		s.buildEntityUUIDChain,
+		tenant.EntityOwnershipMiddleware(checker),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusInternalServerError, s.response.Code)
+	s.Contains(s.response.Body.String(), tenant.ErrContextMissingTenantUUID.Error())
+}
+
+func (s *MiddlewareCommonSuite) Test_RateLimitKeyByTenant() {
+	// given
+	var recordedKey string
+
+	keyCapture := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recordedKey = tenant.RateLimitKeyByTenant(r)
+	})
+
+	// when
+	s.buildTenantChain(keyCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.Equal("tenant:"+s.tenantUUID, recordedKey)
+}
+
+func (s *MiddlewareCommonSuite) Test_IdempotencyScopeByTenant() {
+	// given
+	var recordedScope, userScope string
+
+	scopeCapture := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recordedScope = tenant.IdempotencyScopeByTenant(r)
+		userScope = turtleware.DefaultIdempotencyScope(r)
+	})
+
+	// when
+	s.buildTenantChain(scopeCapture).ServeHTTP(s.response, s.request)
+
+	// then
+	s.NotEmpty(userScope)
+	s.Equal("tenant:"+s.tenantUUID+":"+userScope, recordedScope)
+}
+
+func (s *MiddlewareCommonSuite) Test_RequirePathTenantMiddleware() {
+	errNoPathTenant := errors.New("no tenant in path")
+
+	cases := map[string]struct {
+		pathTenantUUID func() string
+		err            error
+		expectedCalled bool
+		expectedStatus int
+		expectedError  error
+	}{
+		"Match": {
+			pathTenantUUID: func() string { return s.tenantUUID },
+			expectedCalled: true,
+			expectedStatus: http.StatusOK,
+		},
+		"Match_Case_Insensitive": {
+			pathTenantUUID: func() string { return strings.ToUpper(s.tenantUUID) },
+			expectedCalled: true,
+			expectedStatus: http.StatusOK,
+		},
+		"Mismatch": {
+			pathTenantUUID: func() string { return s.entityUUID },
+			expectedCalled: false,
+			expectedStatus: http.StatusForbidden,
+			expectedError:  tenant.ErrTenantMismatch,
+		},
+		"Extractor_Error": {
+			pathTenantUUID: func() string { return "" },
+			err:            errNoPathTenant,
+			expectedCalled: false,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  errNoPathTenant,
+		},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			extractor := func(r *http.Request) (string, error) {
+				return target.pathTenantUUID(), target.err
+			}
+
+			testChain := alice.New(
+				s.buildTenantChain,
+				tenant.RequirePathTenantMiddleware(extractor),
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.Equal(target.expectedCalled, nextCapture.Called)
+			s.Equal(target.expectedStatus, s.response.Code)
+
+			if target.expectedError != nil {
+				s.Contains(s.response.Body.String(), target.expectedError.Error())
+			}
+		})
+	}
+}
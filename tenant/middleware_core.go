@@ -2,21 +2,12 @@ package tenant
 
 import (
 	"github.com/kernle32dll/turtleware"
-	"github.com/rs/zerolog"
 
 	"context"
-	"crypto/sha256"
-	"database/sql"
-	"encoding/hex"
-	"errors"
-	"fmt"
 	"net/http"
-	"os"
 	"time"
 )
 
-var emptyListHash = hex.EncodeToString(sha256.New().Sum(nil))
-
 // ListHashFunc is a function for returning a calculated hash for a given subset of entities
 // of a given tenant, via the given paging, for a list endpoint.
 // The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
@@ -26,9 +17,17 @@ type ListHashFunc func(ctx context.Context, tenantUUID string, paging turtleware
 // ListCountFunc is a function for returning the total amount of entities of a given tenant
 // for a list endpoint.
 // The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
-// elements, for easier handling.
+// elements, for easier handling. turtleware.ErrCountUnavailable may be returned, if the total
+// amount cannot be computed.
 type ListCountFunc func(ctx context.Context, tenantUUID string) (uint, error)
 
+// ListLastModFunc is a function for returning the last modification date of a given subset
+// of entities of a given tenant via the given paging, for a list endpoint. This is usually the
+// modification date of the newest element.
+// The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
+// elements, for easier handling.
+type ListLastModFunc func(ctx context.Context, tenantUUID string, paging turtleware.Paging) (time.Time, error)
+
 // ResourceLastModFunc is a function for returning the last modification data for a specific
 // entity of a given tenant.
 // The function may return sql.ErrNoRows or os.ErrNotExist to indicate that there are not
@@ -37,172 +36,141 @@ type ResourceLastModFunc func(ctx context.Context, tenantUUID string, entityUUID
 
 // CountHeaderMiddleware is a middleware for injecting an X-Total-Count header into the response,
 // by the provided ListCountFunc. If an error is encountered, the provided turtleware.ErrorHandlerFunc is called.
+// The behavior of the middleware can be adjusted via the provided options, the same as for
+// turtleware.CountHeaderMiddleware.
 func CountHeaderMiddleware(
 	countFetcher ListCountFunc,
 	errorHandler turtleware.ErrorHandlerFunc,
+	opts ...turtleware.CountOption,
 ) func(http.Handler) http.Handler {
-	return func(h http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			countContext, cancel := context.WithCancel(r.Context())
-			defer cancel()
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
 
-			logger := zerolog.Ctx(countContext)
-
-			tenantUUID, err := UUIDFromRequestContext(countContext)
-			if err != nil {
-				errorHandler(countContext, w, r, err)
-				return
-			}
-
-			totalCount, err := countFetcher(countContext, tenantUUID)
-			if err != nil {
-				if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
-					totalCount = 0
-				} else {
-					logger.Error().Err(err).Msg("Failed to receive count")
-					errorHandler(countContext, w, r, turtleware.ErrReceivingMeta)
+	return requireTenant(
+		turtleware.CountHeaderMiddleware(tenantCountFetcher(countFetcher), errorHandler, opts...),
+		errorHandler,
+	)
+}
 
-					return
-				}
-			}
+// CountApproximateOnHead is the tenant scoped equivalent of turtleware.CountApproximateOnHead.
+func CountApproximateOnHead(approximateCountFetcher ListCountFunc) turtleware.CountOption {
+	return turtleware.CountApproximateOnHead(tenantCountFetcher(approximateCountFetcher))
+}
 
-			w.Header().Set("X-Total-Count", fmt.Sprintf("%d", totalCount))
+func tenantCountFetcher(countFetcher ListCountFunc) turtleware.ListCountFunc {
+	return func(ctx context.Context) (uint, error) {
+		tenantUUID, err := UUIDFromRequestContext(ctx)
+		if err != nil {
+			return 0, err
+		}
 
-			h.ServeHTTP(w, r)
-		})
+		return countFetcher(ctx, tenantUUID)
 	}
 }
 
 // ListCacheMiddleware is a middleware for transparently handling caching via the provided
-// ListHashFunc. The next handler of the middleware is only called on a cache miss. That is,
-// if the If-None-Match header and the fetched hash differ.
-// If the ListHashFunc returns either sql.ErrNoRows or os.ErrNotExist, the sha256 hash of an
-// empty string is assumed as the hash.
+// ListHashFunc. It works the same as turtleware.ListCacheMiddleware, but scoped to the tenant.
+// The behavior of the middleware can be adjusted via the provided options, the same as for
+// turtleware.ListCacheMiddleware.
 // If an error is encountered, the provided turtleware.ErrorHandlerFunc is called.
 func ListCacheMiddleware(
 	hashFetcher ListHashFunc,
 	errorHandler turtleware.ErrorHandlerFunc,
+	opts ...turtleware.CacheOption,
 ) func(h http.Handler) http.Handler {
-	return func(h http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logger := zerolog.Ctx(r.Context())
-			w.Header().Set("Cache-Control", "must-revalidate")
-			w.Header().Add("Cache-Control", "max-age=0")
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
 
-			logger.Trace().Msg("Handling preflight for tenant based resource list request")
-
-			etag, _ := turtleware.ExtractCacheHeader(r)
-
-			if etag != "" {
-				logger.Debug().Msgf("Received If-None-Match tag %s", etag)
-			}
-
-			hashContext, cancel := context.WithCancel(r.Context())
-			defer cancel()
-
-			tenantUUID, err := UUIDFromRequestContext(hashContext)
-			if err != nil {
-				errorHandler(hashContext, w, r, err)
-
-				return
-			}
-
-			paging, err := turtleware.PagingFromRequestContext(hashContext)
-			if err != nil {
-				errorHandler(hashContext, w, r, err)
-
-				return
-			}
+	return requireTenant(
+		turtleware.ListCacheMiddleware(tenantListHashFetcher(hashFetcher), errorHandler, opts...),
+		errorHandler,
+	)
+}
 
-			hash, err := hashFetcher(hashContext, tenantUUID, paging)
-			if err != nil {
-				if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
-					hash = emptyListHash
-				} else {
-					logger.Error().Err(err).Msg("Failed to receive hash")
-					errorHandler(hashContext, w, r, turtleware.ErrReceivingMeta)
+func tenantListHashFetcher(hashFetcher ListHashFunc) turtleware.ListHashFunc {
+	return func(ctx context.Context, paging turtleware.Paging) (string, error) {
+		tenantUUID, err := UUIDFromRequestContext(ctx)
+		if err != nil {
+			return "", err
+		}
 
-					return
-				}
-			}
+		return hashFetcher(ctx, tenantUUID, paging)
+	}
+}
 
-			w.Header().Set("Etag", hash)
+// ListLastModCacheMiddleware is a middleware for transparently handling caching of a list of a
+// tenant via the provided ListLastModFunc. It works the same as turtleware.ListLastModCacheMiddleware,
+// but scoped to the tenant. The behavior of the middleware can be adjusted via the provided options,
+// the same as for turtleware.ListLastModCacheMiddleware.
+// If an error is encountered, the provided turtleware.ErrorHandlerFunc is called.
+func ListLastModCacheMiddleware(
+	lastModFetcher ListLastModFunc,
+	errorHandler turtleware.ErrorHandlerFunc,
+	opts ...turtleware.CacheOption,
+) func(h http.Handler) http.Handler {
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
 
-			cacheHit := etag == hash
-			if cacheHit {
-				logger.Debug().Msg("Successful cache hit")
-				w.WriteHeader(http.StatusNotModified)
+	return requireTenant(
+		turtleware.ListLastModCacheMiddleware(tenantListLastModFetcher(lastModFetcher), errorHandler, opts...),
+		errorHandler,
+	)
+}
 
-				return
-			}
+func tenantListLastModFetcher(lastModFetcher ListLastModFunc) turtleware.ListLastModFunc {
+	return func(ctx context.Context, paging turtleware.Paging) (time.Time, error) {
+		tenantUUID, err := UUIDFromRequestContext(ctx)
+		if err != nil {
+			return time.Time{}, err
+		}
 
-			h.ServeHTTP(w, r)
-		})
+		return lastModFetcher(ctx, tenantUUID, paging)
 	}
 }
 
 // ResourceCacheMiddleware is a middleware for transparently handling caching of a single entity
-// (or resource) of a tenant via the provided ResourceLastModFunc. The next handler of the middleware
-// is only called when the If-Modified-Since header and the fetched last modification date differ.
+// (or resource) of a tenant via the provided ResourceLastModFunc. It works the same as
+// turtleware.ResourceCacheMiddleware, but scoped to the tenant. The behavior of the middleware
+// can be adjusted via the provided options, the same as for turtleware.ResourceCacheMiddleware.
 // If an error is encountered, the provided turtleware.ErrorHandlerFunc is called.
 func ResourceCacheMiddleware(
 	lastModFetcher ResourceLastModFunc,
 	errorHandler turtleware.ErrorHandlerFunc,
+	opts ...turtleware.CacheOption,
 ) func(h http.Handler) http.Handler {
-	return func(h http.Handler) http.Handler {
-		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			logger := zerolog.Ctx(r.Context())
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
 
-			logger.Trace().Msg("Handling preflight for tenant based resource request")
-
-			_, lastModified := turtleware.ExtractCacheHeader(r)
-
-			if !lastModified.IsZero() {
-				logger.Debug().Msgf("Received If-Modified-Since date %s", lastModified)
-			}
-
-			hashContext, cancel := context.WithCancel(r.Context())
-			defer cancel()
-
-			tenantUUID, err := UUIDFromRequestContext(hashContext)
-			if err != nil {
-				errorHandler(hashContext, w, r, err)
-
-				return
-			}
-
-			entityUUID, err := turtleware.EntityUUIDFromRequestContext(hashContext)
-			if err != nil {
-				errorHandler(hashContext, w, r, err)
-				return
-			}
-
-			maxModDate, err := lastModFetcher(hashContext, tenantUUID, entityUUID)
-			if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
-				// Skip cache check
-				h.ServeHTTP(w, r)
-
-				return
-			}
+	return requireTenant(
+		turtleware.ResourceCacheMiddleware(tenantResourceLastModFetcher(lastModFetcher), errorHandler, opts...),
+		errorHandler,
+	)
+}
 
-			if err != nil {
-				logger.Error().Err(err).Msg("Failed to receive last-modification date")
-				errorHandler(hashContext, w, r, turtleware.ErrReceivingMeta)
+func tenantResourceLastModFetcher(lastModFetcher ResourceLastModFunc) turtleware.ResourceLastModFunc {
+	return func(ctx context.Context, entityUUID string) (time.Time, error) {
+		tenantUUID, err := UUIDFromRequestContext(ctx)
+		if err != nil {
+			return time.Time{}, err
+		}
 
-				return
-			}
+		return lastModFetcher(ctx, tenantUUID, entityUUID)
+	}
+}
 
-			w.Header().Set("Last-Modified", maxModDate.Format(time.RFC1123))
+// requireTenant wraps the given middleware, so requests without a tenant UUID in the request
+// context are passed to the given turtleware.ErrorHandlerFunc right away.
+func requireTenant(
+	middleware func(http.Handler) http.Handler,
+	errorHandler turtleware.ErrorHandlerFunc,
+) func(http.Handler) http.Handler {
+	return func(h http.Handler) http.Handler {
+		next := middleware(h)
 
-			cacheHit := !lastModified.IsZero() && maxModDate.Truncate(time.Second).Equal(lastModified.Truncate(time.Second))
-			if cacheHit {
-				logger.Debug().Msg("Successful cache hit")
-				w.WriteHeader(http.StatusNotModified)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, err := UUIDFromRequestContext(r.Context()); err != nil {
+				errorHandler(r.Context(), w, r, err)
 
 				return
 			}
 
-			h.ServeHTTP(w, r)
+			next.ServeHTTP(w, r)
 		})
 	}
 }
@@ -0,0 +1,158 @@
+package tenant_test
+
+import (
+	"github.com/justinas/alice"
+	"github.com/kernle32dll/turtleware"
+	"github.com/kernle32dll/turtleware/tenant"
+	"github.com/stretchr/testify/suite"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type MiddlewareCoreSuite struct {
+	CommonSuite
+
+	response *httptest.ResponseRecorder
+	request  *http.Request
+}
+
+func TestMiddlewareCoreSuite(t *testing.T) {
+	suite.Run(t, &MiddlewareCoreSuite{})
+}
+
+func (s *MiddlewareCoreSuite) SetupTest() {
+	s.CommonSuite.SetupTest()
+
+	s.response = httptest.NewRecorder()
+	s.request = httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+}
+
+func (s *MiddlewareCoreSuite) SetupSubTest() {
+	s.SetupTest()
+}
+
+func (s *MiddlewareCoreSuite) Test_ListCacheMiddleware() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	s.request.Header.Set("If-None-Match", `"some-hash"`)
+
+	hashFetcher := func(ctx context.Context, tenantUUID string, paging turtleware.Paging) (string, error) {
+		s.Equal(s.tenantUUID, tenantUUID)
+
+		return "some-hash", nil
+	}
+
+	testChain := alice.New(
+		s.buildTenantChain,
+		turtleware.PagingMiddleware,
+		tenant.ListCacheMiddleware(hashFetcher, turtleware.DefaultErrorHandler, turtleware.CacheVary("Accept")),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusNotModified, s.response.Code)
+	s.Equal(`"some-hash"`, s.response.Header().Get("Etag"))
+	s.Equal([]string{"Authorization", "Accept"}, s.response.Header().Values("Vary"))
+}
+
+func (s *MiddlewareCoreSuite) Test_ListLastModCacheMiddleware() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	lastModTime := time.Date(1991, 5, 23, 1, 2, 3, 0, time.UTC)
+	s.request.Header.Set("If-Modified-Since", lastModTime.Format(time.RFC1123))
+
+	lastModFetcher := func(ctx context.Context, tenantUUID string, paging turtleware.Paging) (time.Time, error) {
+		s.Equal(s.tenantUUID, tenantUUID)
+
+		return lastModTime, nil
+	}
+
+	testChain := alice.New(
+		s.buildTenantChain,
+		turtleware.PagingMiddleware,
+		tenant.ListLastModCacheMiddleware(lastModFetcher, turtleware.DefaultErrorHandler),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.False(nextCapture.Called)
+	s.Equal(http.StatusNotModified, s.response.Code)
+}
+
+func (s *MiddlewareCoreSuite) Test_ResourceCacheMiddleware() {
+	// given
+	nextCapture := &MiddlewareCapture{}
+
+	lastModTime := time.Date(1991, 5, 23, 1, 2, 3, 0, time.UTC)
+	s.request.Header.Set("If-Modified-Since", lastModTime.Add(-time.Hour).Format(time.RFC1123))
+
+	lastModFetcher := func(ctx context.Context, tenantUUID string, entityUUID string) (time.Time, error) {
+		s.Equal(s.tenantUUID, tenantUUID)
+		s.Equal(s.entityUUID, entityUUID)
+
+		return lastModTime, nil
+	}
+
+	testChain := alice.New(
+		s.buildTenantChain,
+		s.buildEntityUUIDChain,
+		tenant.ResourceCacheMiddleware(lastModFetcher, turtleware.DefaultErrorHandler, turtleware.CacheLastModEtag(true)),
+	).Then(nextCapture)
+
+	// when
+	testChain.ServeHTTP(s.response, s.request)
+
+	// then
+	s.True(nextCapture.Called)
+	s.Equal(lastModTime.Format(time.RFC1123), s.response.Header().Get("Last-Modified"))
+	s.Equal(turtleware.LastModEtag(s.entityUUID, lastModTime), s.response.Header().Get("Etag"))
+}
+
+func (s *MiddlewareCoreSuite) Test_ErrContextMissingTenantUUID() {
+	hashFetcher := func(ctx context.Context, tenantUUID string, paging turtleware.Paging) (string, error) {
+		return "some-hash", nil
+	}
+
+	lastModFetcher := func(ctx context.Context, tenantUUID string, entityUUID string) (time.Time, error) {
+		return time.Now(), nil
+	}
+
+	cases := map[string]struct {
+		middleware func(http.Handler) http.Handler
+	}{
+		"ListCacheMiddleware":     {middleware: tenant.ListCacheMiddleware(hashFetcher, turtleware.DefaultErrorHandler)},
+		"ResourceCacheMiddleware": {middleware: tenant.ResourceCacheMiddleware(lastModFetcher, turtleware.DefaultErrorHandler)},
+	}
+
+	for testName, target := range cases {
+		s.Run(testName, func() {
+			// given
+			nextCapture := &MiddlewareCapture{}
+
+			testChain := alice.New(
+				turtleware.PagingMiddleware,
+				s.buildEntityUUIDChain,
+				target.middleware,
+			).Then(nextCapture)
+
+			// when
+			testChain.ServeHTTP(s.response, s.request)
+
+			// then
+			s.False(nextCapture.Called)
+			s.Equal(http.StatusInternalServerError, s.response.Code)
+			s.Contains(s.response.Body.String(), tenant.ErrContextMissingTenantUUID.Error())
+		})
+	}
+}
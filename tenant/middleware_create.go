@@ -5,17 +5,69 @@ import (
 	"github.com/rs/zerolog"
 
 	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
 )
 
 // CreateFunc is a function called for delegating the actual creating of a new tenant scoped resource.
 type CreateFunc[T turtleware.CreateDTO] func(ctx context.Context, tenantUUID, entityUUID, userUUID string, create T) error
 
+// CreateReturningFunc is a function called for delegating the actual creating of a new tenant scoped
+// resource, which returns the created resource (e.g. including server generated fields).
+type CreateReturningFunc[T turtleware.CreateDTO, R any] func(ctx context.Context, tenantUUID, entityUUID, userUUID string, create T) (R, error)
+
 // ResourceCreateMiddleware is a middleware for creating a new tenant scoped resource.
 // It parses a turtleware.CreateDTO from the request body, validates it, and then calls the provided CreateFunc.
 // Errors encountered during the process are passed to the provided turtleware.ErrorHandlerFunc.
-func ResourceCreateMiddleware[T turtleware.CreateDTO](createFunc CreateFunc[T], errorHandler turtleware.ErrorHandlerFunc) func(http.Handler) http.Handler {
+// The request body is decoded via turtleware.DecodeBody, using the provided options.
+// If the CreateFunc returns an turtleware.AcceptedError (see turtleware.Accepted), a 202 Accepted is written
+// instead of calling the next handler.
+func ResourceCreateMiddleware[T turtleware.CreateDTO](createFunc CreateFunc[T], errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.BodyOption) func(http.Handler) http.Handler {
+	return resourceCreateMiddleware(
+		func(ctx context.Context, tenantUUID, entityUUID, userUUID string, create T) (struct{}, error) {
+			return struct{}{}, createFunc(ctx, tenantUUID, entityUUID, userUUID, create)
+		},
+		nil,
+		errorHandler,
+		opts...,
+	)
+}
+
+// ResourceCreateReturningMiddleware is a middleware for creating a new tenant scoped resource, which
+// answers with the created resource. It works like ResourceCreateMiddleware, but the resource returned
+// by the provided CreateReturningFunc is written via the provided turtleware.ResponseSerializer with a
+// 201 Created, which completes the response. That is, the next handler is never called.
+// If no turtleware.ResponseSerializer is provided, the turtleware.EmissioneWriter is used.
+func ResourceCreateReturningMiddleware[T turtleware.CreateDTO, R any](
+	createFunc CreateReturningFunc[T, R],
+	serializer turtleware.ResponseSerializer,
+	errorHandler turtleware.ErrorHandlerFunc,
+	opts ...turtleware.BodyOption,
+) func(http.Handler) http.Handler {
+	return resourceCreateMiddleware(
+		createFunc,
+		func(w http.ResponseWriter, r *http.Request, created R) {
+			if serializer == nil {
+				turtleware.EmissioneWriter.Write(w, r, http.StatusCreated, created)
+
+				return
+			}
+
+			serializer.Write(w, r, http.StatusCreated, created)
+		},
+		errorHandler,
+		opts...,
+	)
+}
+
+func resourceCreateMiddleware[T turtleware.CreateDTO, R any](
+	createFunc CreateReturningFunc[T, R],
+	writeFunc func(w http.ResponseWriter, r *http.Request, created R),
+	errorHandler turtleware.ErrorHandlerFunc,
+	opts ...turtleware.BodyOption,
+) func(http.Handler) http.Handler {
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			createContext, cancel := context.WithCancel(r.Context())
@@ -43,9 +95,9 @@ func ResourceCreateMiddleware[T turtleware.CreateDTO](createFunc CreateFunc[T],
 
 			// ----------------
 
-			var create T
-			if err := json.NewDecoder(r.Body).Decode(&create); err != nil {
-				errorHandler(createContext, w, r, turtleware.ErrMarshalling)
+			create, err := turtleware.DecodeBody[T](r, opts...)
+			if err != nil {
+				errorHandler(createContext, w, r, err)
 				return
 			}
 
@@ -54,12 +106,25 @@ func ResourceCreateMiddleware[T turtleware.CreateDTO](createFunc CreateFunc[T],
 				return
 			}
 
-			if err := createFunc(createContext, tenantUUID, entityUUID, userUUID, create); err != nil {
+			created, err := createFunc(createContext, tenantUUID, entityUUID, userUUID, create)
+			if err != nil {
+				var acceptedError *turtleware.AcceptedError
+				if errors.As(err, &acceptedError) {
+					turtleware.WriteAccepted(createContext, w, acceptedError.StatusLocation)
+					return
+				}
+
 				logger.Error().Err(err).Msg("Create failed")
 				errorHandler(createContext, w, r, err)
 				return
 			}
 
+			if writeFunc != nil {
+				writeFunc(w, r, created)
+
+				return
+			}
+
 			if next != nil {
 				next.ServeHTTP(w, r)
 			}
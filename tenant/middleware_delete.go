@@ -0,0 +1,145 @@
+package tenant
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/rs/zerolog"
+
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+)
+
+// DeleteFunc is a function called for delegating the actual deletion of an existing tenant scoped resource.
+// If no If-Unmodified-Since header was provided, ifUnmodifiedSince is the zero time.
+type DeleteFunc func(ctx context.Context, tenantUUID, entityUUID, userUUID string, ifUnmodifiedSince time.Time) error
+
+// BulkDeleteFunc is a function called for delegating the actual deletion of multiple tenant scoped resources.
+// It is expected to return a turtleware.BulkResult per provided id. An error should only be returned if
+// the bulk operation as a whole failed.
+type BulkDeleteFunc func(ctx context.Context, tenantUUID, userUUID string, ids []string) ([]turtleware.BulkResult, error)
+
+// ResourceDeleteMiddleware is a middleware for deleting an existing tenant scoped resource.
+// It calls the provided DeleteFunc, and writes a 204 No Content on success, after which the
+// next handler is called. An If-Unmodified-Since header is optional, but is parsed via
+// turtleware.GetIfUnmodifiedSince if provided.
+// If the DeleteFunc returns sql.ErrNoRows or os.ErrNotExist, turtleware.ErrResourceNotFound is passed on instead.
+// If the DeleteFunc returns an turtleware.AcceptedError (see turtleware.Accepted), a 202 Accepted is written
+// instead of calling the next handler.
+// Errors encountered during the process are passed to the provided turtleware.ErrorHandlerFunc.
+func ResourceDeleteMiddleware(deleteFunc DeleteFunc, errorHandler turtleware.ErrorHandlerFunc) func(http.Handler) http.Handler {
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deleteContext, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			logger := zerolog.Ctx(deleteContext)
+
+			tenantUUID, err := UUIDFromRequestContext(deleteContext)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			userUUID, err := turtleware.UserUUIDFromRequestContext(deleteContext)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			entityUUID, err := turtleware.EntityUUIDFromRequestContext(deleteContext)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			// ----------------
+
+			ifUnmodifiedSince, err := turtleware.GetIfUnmodifiedSince(r)
+			if err != nil && !errors.Is(err, turtleware.ErrUnmodifiedSinceHeaderMissing) {
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			if err := deleteFunc(deleteContext, tenantUUID, entityUUID, userUUID, ifUnmodifiedSince); err != nil {
+				var acceptedError *turtleware.AcceptedError
+				if errors.As(err, &acceptedError) {
+					turtleware.WriteAccepted(deleteContext, w, acceptedError.StatusLocation)
+					return
+				}
+
+				if errors.Is(err, sql.ErrNoRows) || errors.Is(err, os.ErrNotExist) {
+					errorHandler(deleteContext, w, r, turtleware.ErrResourceNotFound)
+					return
+				}
+
+				logger.Error().Err(err).Msg("Delete failed")
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(http.StatusNoContent)
+
+			if next != nil {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// ResourceBulkDeleteMiddleware is a middleware for deleting multiple tenant scoped resources at once.
+// It parses a JSON array of ids from the request body, and then calls the provided BulkDeleteFunc.
+// The per-id outcomes are written with a 207 Multi-Status, which completes the response. That is,
+// the next handler is never called.
+// Errors encountered during the process are passed to the provided turtleware.ErrorHandlerFunc.
+func ResourceBulkDeleteMiddleware(bulkDeleteFunc BulkDeleteFunc, errorHandler turtleware.ErrorHandlerFunc) func(http.Handler) http.Handler {
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
+
+	return func(http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deleteContext, cancel := context.WithCancel(r.Context())
+			defer cancel()
+
+			logger := zerolog.Ctx(deleteContext)
+
+			tenantUUID, err := UUIDFromRequestContext(deleteContext)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			userUUID, err := turtleware.UserUUIDFromRequestContext(deleteContext)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			// ----------------
+
+			ids, err := turtleware.DecodeBody[[]string](r)
+			if err != nil {
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			if len(ids) == 0 {
+				errorHandler(deleteContext, w, r, turtleware.ErrNoBulkIDs)
+				return
+			}
+
+			results, err := bulkDeleteFunc(deleteContext, tenantUUID, userUUID, ids)
+			if err != nil {
+				logger.Error().Err(err).Msg("Bulk delete failed")
+				errorHandler(deleteContext, w, r, err)
+				return
+			}
+
+			turtleware.WriteBulkResults(w, r, results)
+		})
+	}
+}
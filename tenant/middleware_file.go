@@ -11,10 +11,25 @@ import (
 // FileHandleFunc is a function that handles a single tenant scoped file upload.
 type FileHandleFunc func(ctx context.Context, tenantUUID, entityUUID, userUUID string, fileName string, file multipart.File) error
 
+// FileHandleFuncExt is a function that handles a single tenant scoped file upload. In contrast to
+// FileHandleFunc, it receives the complete multipart.FileHeader of the file.
+type FileHandleFuncExt func(ctx context.Context, tenantUUID, entityUUID, userUUID string, fileHeader *multipart.FileHeader, file multipart.File) error
+
 // FileUploadMiddleware is a middleware that handles uploads of one or multiple files.
 // Uploads are parsed from the request via turtleware.HandleFileUpload, and then passed to the provided FileHandleFunc.
+// The behavior of the middleware can be adjusted via the provided options.
 // Errors encountered during the process are passed to the provided turtleware.ErrorHandlerFunc.
-func FileUploadMiddleware(partHandlerFunc FileHandleFunc, errorHandler turtleware.ErrorHandlerFunc) func(http.Handler) http.Handler {
+func FileUploadMiddleware(partHandlerFunc FileHandleFunc, errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.FileUploadOption) func(http.Handler) http.Handler {
+	return FileUploadMiddlewareExt(func(ctx context.Context, tenantUUID, entityUUID, userUUID string, fileHeader *multipart.FileHeader, file multipart.File) error {
+		return partHandlerFunc(ctx, tenantUUID, entityUUID, userUUID, fileHeader.Filename, file)
+	}, errorHandler, opts...)
+}
+
+// FileUploadMiddlewareExt is the same as FileUploadMiddleware, but passes the complete
+// multipart.FileHeader of each file to the provided FileHandleFuncExt.
+func FileUploadMiddlewareExt(partHandlerFunc FileHandleFuncExt, errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.FileUploadOption) func(http.Handler) http.Handler {
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			uploadContext, cancel := context.WithCancel(r.Context())
@@ -26,9 +41,9 @@ func FileUploadMiddleware(partHandlerFunc FileHandleFunc, errorHandler turtlewar
 				return
 			}
 
-			if err := turtleware.HandleFileUpload(uploadContext, r, func(ctx context.Context, entityUUID, userUUID string, fileName string, file multipart.File) error {
-				return partHandlerFunc(ctx, tenantUUID, entityUUID, userUUID, fileName, file)
-			}); err != nil {
+			if err := turtleware.HandleFileUploadExt(uploadContext, r, func(ctx context.Context, entityUUID, userUUID string, fileHeader *multipart.FileHeader, file multipart.File) error {
+				return partHandlerFunc(ctx, tenantUUID, entityUUID, userUUID, fileHeader, file)
+			}, opts...); err != nil {
 				errorHandler(uploadContext, w, r, err)
 				return
 			}
@@ -5,7 +5,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 )
@@ -13,10 +13,88 @@ import (
 // PatchFunc is a function called for delegating the actual updating of an existing tenant scoped resource.
 type PatchFunc[T turtleware.PatchDTO] func(ctx context.Context, tenantUUID, entityUUID, userUUID string, patch T, ifUnmodifiedSince time.Time) error
 
+// MergePatchFunc is a function called for delegating the actual updating of an existing tenant scoped
+// resource via a JSON Merge Patch (RFC 7386). The fields present in the merge patch document are passed
+// alongside the decoded patch, so fields explicitly set to null can be removed.
+type MergePatchFunc[T turtleware.PatchDTO] func(ctx context.Context, tenantUUID, entityUUID, userUUID string, patch T, fields turtleware.MergePatchFields, ifUnmodifiedSince time.Time) error
+
+// ConditionalPatchFunc is a function called for delegating the actual updating of an existing tenant
+// scoped resource. In contrast to PatchFunc, it receives the If-Match entity tag alongside the
+// If-Unmodified-Since time (see turtleware.PatchPreconditions).
+type ConditionalPatchFunc[T turtleware.PatchDTO] func(ctx context.Context, tenantUUID, entityUUID, userUUID string, patch T, preconditions turtleware.PatchPreconditions) error
+
 // ResourcePatchMiddleware is a middleware for patching or updating an existing tenant scoped resource.
 // It parses a turtleware.PatchDTO from the request body, validates it, and then calls the provided PatchFunc.
 // Errors encountered during the process are passed to the provided turtleware.ErrorHandlerFunc.
-func ResourcePatchMiddleware[T turtleware.PatchDTO](patchFunc PatchFunc[T], errorHandler turtleware.ErrorHandlerFunc) func(http.Handler) http.Handler {
+// The request body is decoded via turtleware.DecodeBody, using the provided options.
+// If the PatchFunc returns an turtleware.AcceptedError (see turtleware.Accepted), a 202 Accepted is written
+// instead of calling the next handler.
+func ResourcePatchMiddleware[T turtleware.PatchDTO](patchFunc PatchFunc[T], errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.BodyOption) func(http.Handler) http.Handler {
+	return resourcePatchMiddleware(
+		func(r *http.Request) (T, turtleware.MergePatchFields, error) {
+			patch, err := turtleware.DecodeBody[T](r, opts...)
+			return patch, nil, err
+		},
+		getIfUnmodifiedSincePreconditions,
+		func(ctx context.Context, tenantUUID, entityUUID, userUUID string, patch T, _ turtleware.MergePatchFields, preconditions turtleware.PatchPreconditions) error {
+			return patchFunc(ctx, tenantUUID, entityUUID, userUUID, patch, preconditions.IfUnmodifiedSince)
+		},
+		errorHandler,
+	)
+}
+
+// ResourceConditionalPatchMiddleware is a middleware for patching or updating an existing tenant scoped
+// resource. It works like ResourcePatchMiddleware, but additionally accepts an If-Match header with an
+// entity tag as precondition (see turtleware.GetPatchPreconditions), which is passed to the provided
+// ConditionalPatchFunc.
+func ResourceConditionalPatchMiddleware[T turtleware.PatchDTO](patchFunc ConditionalPatchFunc[T], errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.BodyOption) func(http.Handler) http.Handler {
+	return resourcePatchMiddleware(
+		func(r *http.Request) (T, turtleware.MergePatchFields, error) {
+			patch, err := turtleware.DecodeBody[T](r, opts...)
+			return patch, nil, err
+		},
+		turtleware.GetPatchPreconditions,
+		func(ctx context.Context, tenantUUID, entityUUID, userUUID string, patch T, _ turtleware.MergePatchFields, preconditions turtleware.PatchPreconditions) error {
+			return patchFunc(ctx, tenantUUID, entityUUID, userUUID, patch, preconditions)
+		},
+		errorHandler,
+	)
+}
+
+// ResourceMergePatchMiddleware is a middleware for patching an existing tenant scoped resource via a
+// JSON Merge Patch (RFC 7386). It works like ResourcePatchMiddleware, but decodes the request body via
+// turtleware.DecodeMergePatchBody, and passes the fields present in the body to the provided MergePatchFunc.
+// Fields explicitly set to null are considered changes (see turtleware.HasPatchChanges).
+func ResourceMergePatchMiddleware[T turtleware.PatchDTO](patchFunc MergePatchFunc[T], errorHandler turtleware.ErrorHandlerFunc, opts ...turtleware.BodyOption) func(http.Handler) http.Handler {
+	return resourcePatchMiddleware(
+		func(r *http.Request) (T, turtleware.MergePatchFields, error) {
+			return turtleware.DecodeMergePatchBody[T](r, opts...)
+		},
+		getIfUnmodifiedSincePreconditions,
+		func(ctx context.Context, tenantUUID, entityUUID, userUUID string, patch T, fields turtleware.MergePatchFields, preconditions turtleware.PatchPreconditions) error {
+			return patchFunc(ctx, tenantUUID, entityUUID, userUUID, patch, fields, preconditions.IfUnmodifiedSince)
+		},
+		errorHandler,
+	)
+}
+
+func getIfUnmodifiedSincePreconditions(r *http.Request) (turtleware.PatchPreconditions, error) {
+	ifUnmodifiedSince, err := turtleware.GetIfUnmodifiedSince(r)
+	if err != nil {
+		return turtleware.PatchPreconditions{}, err
+	}
+
+	return turtleware.PatchPreconditions{IfUnmodifiedSince: ifUnmodifiedSince}, nil
+}
+
+func resourcePatchMiddleware[T turtleware.PatchDTO](
+	decodeFunc func(r *http.Request) (T, turtleware.MergePatchFields, error),
+	preconditionsFunc func(r *http.Request) (turtleware.PatchPreconditions, error),
+	patchFunc func(ctx context.Context, tenantUUID, entityUUID, userUUID string, patch T, fields turtleware.MergePatchFields, preconditions turtleware.PatchPreconditions) error,
+	errorHandler turtleware.ErrorHandlerFunc,
+) func(http.Handler) http.Handler {
+	errorHandler = turtleware.RecoverErrorHandler(errorHandler)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			patchContext, cancel := context.WithCancel(r.Context())
@@ -44,13 +122,13 @@ func ResourcePatchMiddleware[T turtleware.PatchDTO](patchFunc PatchFunc[T], erro
 
 			// ----------------
 
-			var patch T
-			if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
-				errorHandler(patchContext, w, r, turtleware.ErrMarshalling)
+			patch, fields, err := decodeFunc(r)
+			if err != nil {
+				errorHandler(patchContext, w, r, err)
 				return
 			}
 
-			if !patch.HasChanges() {
+			if !turtleware.HasPatchChanges(patch, fields) {
 				errorHandler(patchContext, w, r, turtleware.ErrNoChanges)
 				return
 			}
@@ -60,13 +138,19 @@ func ResourcePatchMiddleware[T turtleware.PatchDTO](patchFunc PatchFunc[T], erro
 				return
 			}
 
-			ifUnmodifiedSince, err := turtleware.GetIfUnmodifiedSince(r)
+			preconditions, err := preconditionsFunc(r)
 			if err != nil {
 				errorHandler(patchContext, w, r, err)
 				return
 			}
 
-			if err := patchFunc(patchContext, tenantUUID, entityUUID, userUUID, patch, ifUnmodifiedSince); err != nil {
+			if err := patchFunc(patchContext, tenantUUID, entityUUID, userUUID, patch, fields, preconditions); err != nil {
+				var acceptedError *turtleware.AcceptedError
+				if errors.As(err, &acceptedError) {
+					turtleware.WriteAccepted(patchContext, w, acceptedError.StatusLocation)
+					return
+				}
+
 				logger.Error().Err(err).Msg("Patch failed")
 				errorHandler(patchContext, w, r, err)
 				return
@@ -24,6 +24,8 @@ type TracingTransport struct {
 	roundTripper    http.RoundTripper
 	headerWhitelist map[string]struct{}
 	headerBlacklist map[string]struct{}
+
+	stripHopByHopHeaders bool
 }
 
 func NewTracingTransport(opts ...TracingOption) *TracingTransport {
@@ -33,6 +35,8 @@ func NewTracingTransport(opts ...TracingOption) *TracingTransport {
 		roundTripper:    nil,
 		headerWhitelist: nil,
 		headerBlacklist: nil,
+
+		stripHopByHopHeaders: false,
 	}
 
 	// apply opts
@@ -45,6 +49,8 @@ func NewTracingTransport(opts ...TracingOption) *TracingTransport {
 		roundTripper:    config.roundTripper,
 		headerWhitelist: config.headerWhitelist,
 		headerBlacklist: config.headerBlacklist,
+
+		stripHopByHopHeaders: config.stripHopByHopHeaders,
 	}
 }
 
@@ -54,6 +60,12 @@ func (c TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 		tracerProvider = otel.GetTracerProvider()
 	}
 
+	if c.stripHopByHopHeaders {
+		// RoundTrippers must not modify the original request
+		req = req.Clone(req.Context())
+		StripHopByHopHeaders(req.Header)
+	}
+
 	tracer := tracerProvider.Tracer(TracerName)
 	spanCtx, span := tracer.Start(req.Context(), fmt.Sprintf("HTTP %s: %s", req.Method, req.Host))
 	defer span.End()
@@ -98,6 +110,36 @@ func (c TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return resp, err
 }
 
+// hopByHopHeaders are the headers, which are only meaningful for a single transport-level
+// connection, as defined by RFC 7230 section 6.1.
+var hopByHopHeaders = []string{
+	"Connection",
+	"Proxy-Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// StripHopByHopHeaders removes all hop-by-hop headers from the given headers. This
+// includes the headers listed in the Connection header.
+func StripHopByHopHeaders(header http.Header) {
+	for _, connectionHeader := range header.Values("Connection") {
+		for _, name := range strings.Split(connectionHeader, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				header.Del(name)
+			}
+		}
+	}
+
+	for _, name := range hopByHopHeaders {
+		header.Del(name)
+	}
+}
+
 // WrapZerologTracing fetches the zerolog.Logger attached with the context
 // (if existing), and creates a new logger with the context's spanID and
 // traceID fields set.
@@ -125,6 +167,22 @@ func WrapZerologTracing(ctx context.Context) zerolog.Logger {
 	return logger
 }
 
+// startContextSpan starts a new child span of the span of the given context, via the
+// TracerProvider of that span. If the context carries no span (e.g. as no TracingMiddleware
+// is used), the started span is a no-op.
+func startContextSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return trace.SpanFromContext(ctx).TracerProvider().Tracer(TracerName).Start(ctx, name)
+}
+
+// serializeTraced writes the given value with a 200 OK via the given ResponseSerializer,
+// traced via a turtleware.Serialize span.
+func serializeTraced(ctx context.Context, serializer ResponseSerializer, w http.ResponseWriter, r *http.Request, i interface{}) {
+	_, span := startContextSpan(ctx, "turtleware.Serialize")
+	defer span.End()
+
+	serializer.Write(w, r, http.StatusOK, i)
+}
+
 // TagContextSpanWithError tries to retrieve an open telemetry span from the given
 // context, and sets some error attributes, signaling that the current span
 // has failed. If no span exists, this function does nothing.
@@ -16,6 +16,8 @@ type tracingOptions struct {
 
 	headerWhitelist map[string]struct{}
 	headerBlacklist map[string]struct{}
+
+	stripHopByHopHeaders bool
 }
 
 // TracingOption represents an option for the tracing parameters.
@@ -64,3 +66,13 @@ func TraceHeaderBlacklist(headerBlacklist ...string) TracingOption {
 		}
 	}
 }
+
+// TracingStripHopByHopHeaders sets whether hop-by-hop headers (such as Connection and
+// Keep-Alive, see RFC 7230 section 6.1) are stripped from the request before the round
+// trip. This is required when forwarding incoming requests to another service.
+// The default is false, which means headers are passed through as-is.
+func TracingStripHopByHopHeaders(stripHopByHopHeaders bool) TracingOption {
+	return func(c *tracingOptions) {
+		c.stripHopByHopHeaders = stripHopByHopHeaders
+	}
+}
@@ -0,0 +1,82 @@
+package turtleware_test
+
+import (
+	"github.com/kernle32dll/turtleware"
+	"github.com/stretchr/testify/suite"
+
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type TracingSuite struct {
+	CommonSuite
+}
+
+func TestTracingSuite(t *testing.T) {
+	suite.Run(t, &TracingSuite{})
+}
+
+// roundTripperCapture is a http.RoundTripper, which captures the request it was called with.
+type roundTripperCapture struct {
+	request *http.Request
+}
+
+func (c *roundTripperCapture) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.request = req
+
+	return httptest.NewRecorder().Result(), nil
+}
+
+func (s *TracingSuite) hopByHopRequest() *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "https://example.com/foo", http.NoBody)
+	request.Header.Set("Connection", "keep-alive, X-Custom-Hop")
+	request.Header.Set("Keep-Alive", "timeout=5")
+	request.Header.Set("Upgrade", "websocket")
+	request.Header.Set("Proxy-Authorization", "Basic secret")
+	request.Header.Set("X-Custom-Hop", "some-value")
+	request.Header.Set("X-End-To-End", "some-value")
+
+	return request
+}
+
+func (s *TracingSuite) Test_TracingTransport_HopByHopHeaders() {
+	s.Run("Stripped", func() {
+		// given
+		capture := &roundTripperCapture{}
+		transport := turtleware.NewTracingTransport(
+			turtleware.TracingRoundTripper(capture),
+			turtleware.TracingStripHopByHopHeaders(true),
+		)
+
+		request := s.hopByHopRequest()
+
+		// when
+		_, err := transport.RoundTrip(request)
+
+		// then
+		s.Require().NoError(err)
+		s.Require().NotNil(capture.request)
+
+		for _, header := range []string{"Connection", "Keep-Alive", "Upgrade", "Proxy-Authorization", "X-Custom-Hop"} {
+			s.Empty(capture.request.Header.Get(header), header)
+		}
+
+		s.Equal("some-value", capture.request.Header.Get("X-End-To-End"))
+		s.Equal("keep-alive, X-Custom-Hop", request.Header.Get("Connection"), "original request must not be modified")
+	})
+
+	s.Run("Passed_Through", func() {
+		// given
+		capture := &roundTripperCapture{}
+		transport := turtleware.NewTracingTransport(turtleware.TracingRoundTripper(capture))
+
+		// when
+		_, err := transport.RoundTrip(s.hopByHopRequest())
+
+		// then
+		s.Require().NoError(err)
+		s.Equal("keep-alive, X-Custom-Hop", capture.request.Header.Get("Connection"))
+		s.Equal("some-value", capture.request.Header.Get("X-Custom-Hop"))
+	})
+}
@@ -1,8 +1,15 @@
 package turtleware_test
 
 import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/embedded"
+	"go.opentelemetry.io/otel/trace/noop"
+
 	"context"
 	"net/http"
+	"sync"
 )
 
 // ErrorHandlerCapture is a helper struct to capture errors for a turtleware.ErrorHandlerFunc.
@@ -22,3 +29,109 @@ type MiddlewareCapture struct {
 func (m *MiddlewareCapture) ServeHTTP(http.ResponseWriter, *http.Request) {
 	m.Called = true
 }
+
+// SpanRecorder is a minimal trace.TracerProvider, which records all spans started
+// via its tracers, for asserting tracing related functionality.
+type SpanRecorder struct {
+	embedded.TracerProvider
+
+	mu    sync.Mutex
+	Spans []*RecordedSpan
+}
+
+func (p *SpanRecorder) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return spanRecorderTracer{recorder: p}
+}
+
+// Span returns the first recorded span with the given name, or nil if none exists.
+func (p *SpanRecorder) Span(name string) *RecordedSpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, span := range p.Spans {
+		if span.Name == name {
+			return span
+		}
+	}
+
+	return nil
+}
+
+type spanRecorderTracer struct {
+	embedded.Tracer
+
+	recorder *SpanRecorder
+}
+
+func (t spanRecorderTracer) Start(ctx context.Context, name string, _ ...trace.SpanStartOption) (context.Context, trace.Span) {
+	t.recorder.mu.Lock()
+	defer t.recorder.mu.Unlock()
+
+	parent := trace.SpanContextFromContext(ctx)
+
+	traceID := parent.TraceID()
+	if !traceID.IsValid() {
+		traceID = trace.TraceID{1}
+	}
+
+	span := &RecordedSpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: map[attribute.Key]attribute.Value{},
+		recorder:   t.recorder,
+		spanContext: trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  trace.SpanID{byte(len(t.recorder.Spans) + 1)},
+		}),
+	}
+	t.recorder.Spans = append(t.recorder.Spans, span)
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+// RecordedSpan is a trace.Span recorded by a SpanRecorder.
+type RecordedSpan struct {
+	noop.Span
+
+	Name              string
+	Parent            trace.SpanContext
+	Attributes        map[attribute.Key]attribute.Value
+	StatusCode        codes.Code
+	StatusDescription string
+	Errors            []error
+	Ended             bool
+
+	recorder    *SpanRecorder
+	spanContext trace.SpanContext
+}
+
+func (s *RecordedSpan) SpanContext() trace.SpanContext {
+	return s.spanContext
+}
+
+func (s *RecordedSpan) IsRecording() bool {
+	return !s.Ended
+}
+
+func (s *RecordedSpan) SetAttributes(attributes ...attribute.KeyValue) {
+	for _, kv := range attributes {
+		s.Attributes[kv.Key] = kv.Value
+	}
+}
+
+func (s *RecordedSpan) SetStatus(code codes.Code, description string) {
+	s.StatusCode = code
+	s.StatusDescription = description
+}
+
+func (s *RecordedSpan) RecordError(err error, _ ...trace.EventOption) {
+	s.Errors = append(s.Errors, err)
+}
+
+func (s *RecordedSpan) End(...trace.SpanEndOption) {
+	s.Ended = true
+}
+
+func (s *RecordedSpan) TracerProvider() trace.TracerProvider {
+	return s.recorder
+}
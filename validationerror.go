@@ -39,3 +39,40 @@ func (validationWrapperError ValidationWrapperError) As(target interface{}) bool
 func (validationWrapperError ValidationWrapperError) Unwrap() []error {
 	return validationWrapperError.Errors
 }
+
+// FieldError is a validation error for a specific field of a request body. The field
+// is referenced via a JSON Pointer (RFC 6901), e.g. "/address/street".
+type FieldError struct {
+	Pointer string
+	Message string
+}
+
+// NewFieldError creates a new FieldError for the field referenced by the given reference
+// tokens, which are escaped and joined into a JSON Pointer.
+// For example, NewFieldError("must not be empty", "address", "street") references
+// the field via "/address/street".
+func NewFieldError(message string, referenceTokens ...string) *FieldError {
+	return &FieldError{
+		Pointer: JSONPointer(referenceTokens...),
+		Message: message,
+	}
+}
+
+func (fieldError FieldError) Error() string {
+	return fieldError.Pointer + ": " + fieldError.Message
+}
+
+// JSONPointer assembles a JSON Pointer (RFC 6901) from the given reference tokens.
+// Reference tokens are escaped as required, so "a/b" becomes "a~1b".
+func JSONPointer(referenceTokens ...string) string {
+	var builder strings.Builder
+
+	for _, token := range referenceTokens {
+		builder.WriteByte('/')
+		builder.WriteString(jsonPointerEscaper.Replace(token))
+	}
+
+	return builder.String()
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")